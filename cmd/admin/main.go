@@ -0,0 +1,251 @@
+// cmd/admin/main.go
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/audit"
+	"github.com/ffelixf/microblog-platform/internal/config"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/ffelixf/microblog-platform/pkg/database"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const usage = `uso: admin <comando> [opciones]
+
+comandos:
+  bootstrap      crea el primer administrador o da el rol admin a un usuario existente
+  verify-audit   recorre la cadena de auditoría y comprueba que no fue alterada
+  backfill-hashtags
+                 recalcula los hashtags guardados de todos los tweets
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "bootstrap":
+		err = bootstrap(os.Args[2:])
+	case "verify-audit":
+		err = verifyAudit(os.Args[2:])
+	case "backfill-hashtags":
+		err = backfillHashtags(os.Args[2:])
+	case "-h", "--help", "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "comando desconocido: %s\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// bootstrap crea el primer administrador. Si ya hay alguno se niega, salvo con
+// --force, que sirve para recuperar el acceso si se perdió la cuenta admin.
+func bootstrap(args []string) error {
+	fs := flag.NewFlagSet("bootstrap", flag.ExitOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "archivo YAML de configuración (opcional)")
+	username := fs.String("username", "", "nombre del usuario administrador (requerido)")
+	email := fs.String("email", "", "email del usuario si hay que crearlo")
+	force := fs.Bool("force", false, "conceder el rol aunque ya exista un administrador")
+	fs.Parse(args)
+
+	if *username == "" {
+		fs.Usage()
+		return errors.New("--username es requerido")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cfg, client, err := connect(ctx, *configFile)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+
+	users := repository.NewUserRepository(client, cfg.Mongo.Database)
+	if err := users.EnsureIndexes(ctx); err != nil {
+		return err
+	}
+	auditRepo := repository.NewAuditRepository(client, cfg.Mongo.Database)
+	if err := auditRepo.EnsureIndexes(ctx); err != nil {
+		return err
+	}
+
+	admins, err := users.CountByRole(ctx, rbac.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if admins > 0 && !*force {
+		return fmt.Errorf("ya hay %d administrador(es); usa --force para añadir otro", admins)
+	}
+
+	user, err := users.GetByUsername(ctx, *username)
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		if *email == "" {
+			return fmt.Errorf("el usuario %q no existe; indica --email para crearlo", *username)
+		}
+		user = &models.User{Username: *username, Email: *email}
+		if err := users.Create(ctx, user); err != nil {
+			return err
+		}
+		fmt.Printf("usuario %s creado (%s)\n", user.Username, user.ID.Hex())
+	case err != nil:
+		return err
+	}
+
+	if slices.Contains(user.Roles, rbac.RoleAdmin) {
+		fmt.Printf("%s ya es administrador\n", user.Username)
+		return nil
+	}
+	roles := append(slices.Clone(user.Roles), rbac.RoleAdmin)
+	if _, err := users.SetRoles(ctx, user.ID, roles, user.Permissions); err != nil {
+		return err
+	}
+	before, after := audit.Diff(map[string]any{"roles": user.Roles}, map[string]any{"roles": roles})
+	audit.NewLogger(auditRepo).Record(ctx, models.AuditEntry{
+		Action:     models.AuditRolesChanged,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID.Hex(),
+		Before:     before,
+		After:      after,
+		Metadata:   map[string]string{"command": "admin bootstrap"},
+	})
+	fmt.Printf("%s (%s) es administrador\n", user.Username, user.ID.Hex())
+	return nil
+}
+
+// verifyAudit recorre la cadena de auditoría desde el primer registro. Termina
+// con error en el primer registro alterado, borrado o insertado, o si la cadena
+// no llega hasta el punto de control. Si también se alteró el punto de control,
+// el hash final se puede comparar con uno guardado fuera de la base de datos,
+// como los del log de la API (--expect).
+func verifyAudit(args []string) error {
+	fs := flag.NewFlagSet("verify-audit", flag.ExitOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "archivo YAML de configuración (opcional)")
+	expect := fs.String("expect", "", "hash que debe tener el último registro, o uno de los registros")
+	fs.Parse(args)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	cfg, client, err := connect(ctx, *configFile)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+
+	repo := repository.NewAuditRepository(client, cfg.Mongo.Database)
+	checkpoint, err := repo.Checkpoint(ctx)
+	if err != nil {
+		return err
+	}
+	found := *expect == ""
+	result, err := audit.Verify(ctx, walkerFunc(func(ctx context.Context, fn func(*models.AuditEntry) error) error {
+		return repo.Walk(ctx, func(entry *models.AuditEntry) error {
+			found = found || entry.Hash == *expect
+			return fn(entry)
+		})
+	}), checkpoint)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("ningún registro tiene el hash %s: faltan registros al final de la cadena", *expect)
+	}
+
+	fmt.Printf("cadena íntegra: %d registros\n", result.Entries)
+	if result.Entries > 0 {
+		fmt.Printf("último hash: %s\n", result.LastHash)
+	}
+	return nil
+}
+
+// backfillHashtags recalcula los hashtags de cada tweet con las reglas
+// actuales: los publicados antes de que existieran los hashtags no aparecen en
+// los feeds ni en las búsquedas por hashtag, y los anteriores al cambio de las
+// reglas pueden tener fragmentos de URL como hashtags. Solo escribe los que
+// cambian, así que repetirlo no hace nada.
+func backfillHashtags(args []string) error {
+	fs := flag.NewFlagSet("backfill-hashtags", flag.ExitOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "archivo YAML de configuración (opcional)")
+	dryRun := fs.Bool("dry-run", false, "solo cuenta los tweets que cambiarían")
+	fs.Parse(args)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	cfg, client, err := connect(ctx, *configFile)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+
+	repo := repository.NewTweetRepository(client, cfg.Mongo.Database)
+	walked, updated := 0, 0
+	err = repo.Walk(ctx, func(tweet *models.Tweet) error {
+		walked++
+		tags := service.ExtractHashtags(tweet.Content)
+		if slices.Equal(tags, tweet.Hashtags) {
+			return nil
+		}
+		updated++
+		if *dryRun {
+			return nil
+		}
+		return repo.SetHashtags(ctx, tweet.ID, tags)
+	})
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Printf("%d de %d tweets cambiarían\n", updated, walked)
+		return nil
+	}
+	fmt.Printf("%d de %d tweets actualizados\n", updated, walked)
+	return nil
+}
+
+// walkerFunc adapta una función a audit.Walker
+type walkerFunc func(ctx context.Context, fn func(*models.AuditEntry) error) error
+
+func (f walkerFunc) Walk(ctx context.Context, fn func(*models.AuditEntry) error) error {
+	return f(ctx, fn)
+}
+
+// connect carga la configuración y se conecta a MongoDB
+func connect(ctx context.Context, configFile string) (*config.Config, *mongo.Client, error) {
+	cfg, err := config.Load(config.Sources{File: configFile, DotEnv: ".env"})
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := database.ConnectDB(ctx, database.Config{
+		URI:                    cfg.Mongo.URI,
+		ConnectTimeout:         cfg.Mongo.ConnectTimeout,
+		ServerSelectionTimeout: cfg.Mongo.ServerSelectionTimeout,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, client, nil
+}
@@ -3,43 +3,155 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/ffelixf/microblog-platform/internal/activitypub"
+	"github.com/ffelixf/microblog-platform/internal/audit"
+	"github.com/ffelixf/microblog-platform/internal/config"
+	"github.com/ffelixf/microblog-platform/internal/export"
+	"github.com/ffelixf/microblog-platform/internal/gql"
+	"github.com/ffelixf/microblog-platform/internal/grpcapi"
 	"github.com/ffelixf/microblog-platform/internal/handlers"
+	"github.com/ffelixf/microblog-platform/internal/health"
+	"github.com/ffelixf/microblog-platform/internal/i18n"
+	"github.com/ffelixf/microblog-platform/internal/lifecycle"
+	"github.com/ffelixf/microblog-platform/internal/logging"
+	"github.com/ffelixf/microblog-platform/internal/media"
+	"github.com/ffelixf/microblog-platform/internal/metrics"
+	"github.com/ffelixf/microblog-platform/internal/middleware"
+	"github.com/ffelixf/microblog-platform/internal/policy"
+	"github.com/ffelixf/microblog-platform/internal/ratelimit"
 	"github.com/ffelixf/microblog-platform/internal/repository"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/ffelixf/microblog-platform/internal/tracing"
+	"github.com/ffelixf/microblog-platform/internal/worker"
+	"github.com/ffelixf/microblog-platform/pkg/database"
+	pb "github.com/ffelixf/microblog-platform/pkg/pb/microblog/v1"
+	"github.com/ffelixf/microblog-platform/pkg/storage"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-func connectDB() (*mongo.Client, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// newBlobStore crea el almacenamiento de archivos configurado (local o s3)
+func newBlobStore(cfg config.Media) (storage.BlobStore, error) {
+	if cfg.Storage == "s3" {
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			PathStyle: cfg.S3.PathStyle,
+		}, nil)
+	}
+	return storage.NewLocalStore(cfg.Dir)
+}
 
-	uri := os.Getenv("MONGODB_URI")
-	if uri == "" {
-		return nil, fmt.Errorf("MONGODB_URI no está configurado en .env")
+// exportSigningKey devuelve la clave que firma los enlaces de descarga de las
+// exportaciones. Sin clave configurada se genera una: sirve para una sola
+// instancia, pero los enlaces dejan de valer al reiniciar.
+func exportSigningKey(cfg config.Export, logger *slog.Logger) ([]byte, error) {
+	if cfg.SigningKey != "" {
+		return []byte(cfg.SigningKey), nil
 	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("error al generar la clave de las exportaciones: %w", err)
+	}
+	logger.Warn("EXPORT_SIGNING_KEY no está definida; los enlaces de descarga no sobreviven a un reinicio ni valen entre réplicas")
+	return key, nil
+}
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
-	if err != nil {
-		return nil, err
+// policyHeuristics convierte la configuración en las heurísticas que evalúa el
+// servicio de políticas
+func policyHeuristics(cfg config.Policy) (policy.Heuristics, error) {
+	var actions [3]policy.Action
+	for i, action := range []string{cfg.Duplicate.Action, cfg.Links.Action, cfg.Burst.Action} {
+		parsed, err := policy.ParseAction(action)
+		if err != nil {
+			return policy.Heuristics{}, err
+		}
+		actions[i] = parsed
 	}
+	return policy.Heuristics{
+		Duplicate: policy.Duplicate{Action: actions[0], Threshold: cfg.Duplicate.Threshold, Window: cfg.Duplicate.Window},
+		Links:     policy.Links{Action: actions[1], Max: cfg.Links.Max},
+		Burst: policy.Burst{
+			Action:     actions[2],
+			AccountAge: cfg.Burst.AccountAge,
+			MaxTweets:  cfg.Burst.MaxTweets,
+			Window:     cfg.Burst.Window,
+		},
+	}, nil
+}
 
-	err = client.Ping(ctx, nil)
-	if err != nil {
-		return nil, err
+// rateLimitRules agrupa las rutas de la API; se aplica la primera regla que
+// coincide, así que las más específicas van primero
+func rateLimitRules(cfg config.RateLimit) []ratelimit.Rule {
+	return []ratelimit.Rule{
+		{Name: "tweets", Methods: []string{http.MethodPost}, Limit: cfg.Tweets,
+			Routes: []string{"/api/v1/tweets", "/api/v1/users/:id/scheduled-tweets"}},
+		{Name: "media", Methods: []string{http.MethodPost}, Limit: cfg.Media,
+			Routes: []string{"/api/v1/media"}},
+		{Name: "write", Methods: []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}, Limit: cfg.Write,
+			Routes: []string{"/api/v1/*"}},
+		{Name: "read", Methods: []string{http.MethodGet, http.MethodHead}, Limit: cfg.Read,
+			Routes: []string{"/api/v1/*"}},
 	}
+}
+
+// graphqlRateLimitRules son las reglas que aplica el handler GraphQL, que solo
+// sabe si la operación es una consulta o una mutación después de leer el
+// cuerpo: las consultas llegan como GET y las mutaciones como POST. Las
+// mutaciones comparten el grupo write con las escrituras REST; una consulta
+// reemplaza a varias lecturas y su tamaño lo acotan los límites de profundidad
+// y complejidad.
+func graphqlRateLimitRules(cfg config.RateLimit) []ratelimit.Rule {
+	return []ratelimit.Rule{
+		{Name: "write", Methods: []string{http.MethodPost}, Limit: cfg.Write,
+			Routes: []string{"/graphql"}},
+		{Name: "graphql", Methods: []string{http.MethodGet}, Limit: cfg.Read,
+			Routes: []string{"/graphql"}},
+	}
+}
 
-	log.Println("✅ Conexión exitosa a MongoDB")
-	return client, nil
+// grpcRateLimitRules aplica a los métodos gRPC los grupos de la API REST, con
+// los mismos buckets: publicar cuenta en tweets, seguir y dejar de seguir en
+// write y el resto de llamadas de la API en read. Salud y reflexión no se limitan.
+func grpcRateLimitRules(cfg config.RateLimit) []ratelimit.Rule {
+	return []ratelimit.Rule{
+		{Name: "tweets", Limit: cfg.Tweets,
+			Routes: []string{pb.TweetService_CreateTweet_FullMethodName}},
+		{Name: "write", Limit: cfg.Write,
+			Routes: []string{pb.UserService_Follow_FullMethodName, pb.UserService_Unfollow_FullMethodName}},
+		{Name: "read", Limit: cfg.Read,
+			Routes: []string{"/microblog.v1.*"}},
+	}
+}
+
+// newRateLimitStore crea el almacén de buckets configurado y, para MongoDB, el
+// paso que crea su índice TTL
+func newRateLimitStore(cfg config.RateLimit, client *mongo.Client, dbName string) (ratelimit.Store, func(context.Context) error) {
+	if cfg.Store == "mongodb" {
+		store := ratelimit.NewMongoStore(client, dbName)
+		return store, store.EnsureIndexes
+	}
+	return ratelimit.NewMemoryStore(), nil
 }
 
 func healthCheck(c *gin.Context) {
@@ -50,77 +162,258 @@ func healthCheck(c *gin.Context) {
 	})
 }
 
-func dbHealthCheck(client *mongo.Client) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		dbStatus := "ok"
-		dbError := ""
-
-		if client == nil {
-			dbStatus = "error"
-			dbError = "MongoDB client not initialized"
-		} else {
-			err := client.Ping(ctx, nil)
-			if err != nil {
-				dbStatus = "error"
-				dbError = err.Error()
+// ensureIndexes crea los índices en segundo plano, reintentando con una espera
+// creciente hasta conseguirlo o hasta que empiece el apagado
+func ensureIndexes(logger *slog.Logger, ready *health.Flag, steps ...func(context.Context) error) func(context.Context) {
+	return func(ctx context.Context) {
+		backoff := time.Second
+		for {
+			err := createIndexes(ctx, steps)
+			if err == nil {
+				ready.Set()
+				logger.Info("índices creados")
+				return
 			}
+			logger.Warn("error al crear índices, se reintentará",
+				slog.Any("error", err), slog.Duration("retry_in", backoff))
 
-			_, err = client.ListDatabaseNames(ctx, bson.M{})
-			if err != nil {
-				dbStatus = "error"
-				dbError = err.Error()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
 			}
+			backoff = min(backoff*2, 30*time.Second)
 		}
+	}
+}
 
-		response := gin.H{
-			"status":    "ok",
-			"timestamp": time.Now(),
-			"database": gin.H{
-				"status": dbStatus,
-				"type":   "mongodb",
-				"uri":    os.Getenv("MONGODB_URI"),
-			},
+func createIndexes(ctx context.Context, steps []func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		if dbError != "" {
-			response["database"].(gin.H)["error"] = dbError
-			c.JSON(500, response)
-			return
+// fatal registra el error y termina el proceso
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, slog.Any("error", err))
+	os.Exit(1)
+}
+
+func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "archivo YAML de configuración (opcional)")
+	printConfig := flag.Bool("print-config", false, "muestra la configuración efectiva, sin secretos, y termina")
+	flag.Parse()
+
+	// Configuración: valores por defecto < YAML < .env < variables de entorno
+	cfg, err := config.Load(config.Sources{File: *configFile, DotEnv: ".env"})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
+		return
+	}
 
-		c.JSON(200, response)
+	// Configurar logging: JSON en stdout, con el nivel configurado
+	logger, _ := logging.New(os.Stdout, cfg.Log.Level)
+	slog.SetDefault(logger)
+	gin.DebugPrintFunc = func(format string, values ...interface{}) {
+		logger.Debug(strings.TrimSpace(fmt.Sprintf(format, values...)), slog.String("component", "gin"))
+	}
+
+	// Apagado ordenado: SIGINT/SIGTERM deja de aceptar conexiones, drena las
+	// peticiones en curso y los workers y cierra Mongo y las trazas
+	app := lifecycle.New(logger)
+	app.ShutdownTimeout = cfg.Server.ShutdownTimeout
+	app.DrainDelay = cfg.Server.DrainDelay
+
+	srv, err := newServer(cfg, app, logger)
+	if err != nil {
+		// Cierra lo que llegó a abrirse (por ejemplo la conexión a Mongo)
+		fatal(logger, "error al iniciar el servidor", app.Shutdown(err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if err := app.Serve(ctx, srv); err != nil {
+		fatal(logger, "error al apagar el servidor", err)
 	}
 }
 
-func main() {
-	// Configurar logging
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
+// newServer conecta las dependencias, arranca los workers en app y devuelve el
+// servidor HTTP listo para Serve
+func newServer(cfg *config.Config, app *lifecycle.Manager, logger *slog.Logger) (*http.Server, error) {
+	// Métricas de Prometheus
+	appMetrics := metrics.New()
 
-	// Cargar variables de entorno
-	if err := godotenv.Load(); err != nil {
-		log.Printf("Warning: .env file not found: %v", err)
+	// Trazas de OpenTelemetry; se cierran las últimas para exportar los spans del apagado
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: cfg.Tracing.ServiceName,
+		Exporter:    cfg.Tracing.Exporter,
+		FilePath:    cfg.Tracing.File,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error al configurar las trazas: %w", err)
 	}
+	app.OnShutdown("trazas", shutdownTracing)
 
 	// Conectar a MongoDB
-	mongoClient, err := connectDB()
+	monitor := database.ChainMonitors(tracing.CommandMonitor(), logging.CommandMonitor(logger), appMetrics.CommandMonitor())
+	mongoClient, err := database.ConnectDB(context.Background(), database.Config{
+		URI:                    cfg.Mongo.URI,
+		MaxPoolSize:            cfg.Mongo.MaxPoolSize,
+		MinPoolSize:            cfg.Mongo.MinPoolSize,
+		ConnectTimeout:         cfg.Mongo.ConnectTimeout,
+		ServerSelectionTimeout: cfg.Mongo.ServerSelectionTimeout,
+		Monitor:                monitor,
+	})
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	defer mongoClient.Disconnect(context.Background())
+	logger.Info("conexión exitosa a MongoDB")
+	app.OnShutdown("mongodb", mongoClient.Disconnect)
 
 	// Inicializar repositorios
-	userRepo := repository.NewUserRepository(mongoClient, os.Getenv("MONGODB_DATABASE"))
-	tweetRepo := repository.NewTweetRepository(mongoClient, os.Getenv("MONGODB_DATABASE"))
+	userRepo := repository.NewUserRepository(mongoClient, cfg.Mongo.Database)
+	tweetRepo := repository.NewTweetRepository(mongoClient, cfg.Mongo.Database)
+	mediaRepo := repository.NewMediaRepository(mongoClient, cfg.Mongo.Database)
+	actorKeyRepo := repository.NewActorKeyRepository(mongoClient, cfg.Mongo.Database, activitypub.GenerateKeyPair)
+	pollRepo := repository.NewPollRepository(mongoClient, cfg.Mongo.Database)
+	notificationRepo := repository.NewNotificationRepository(mongoClient, cfg.Mongo.Database)
+	scheduledRepo := repository.NewScheduledTweetRepository(mongoClient, cfg.Mongo.Database)
+	reportRepo := repository.NewReportRepository(mongoClient, cfg.Mongo.Database)
+	accountDeletionRepo := repository.NewAccountDeletionRepository(mongoClient, cfg.Mongo.Database)
+	auditRepo := repository.NewAuditRepository(mongoClient, cfg.Mongo.Database)
+	policyRuleRepo := repository.NewPolicyRuleRepository(mongoClient, cfg.Mongo.Database)
+	exportJobRepo := repository.NewExportJobRepository(mongoClient, cfg.Mongo.Database)
+
+	// Rate limiting por usuario o IP; con MongoDB las réplicas comparten la cuenta
+	rateLimitStore, rateLimitIndexes := newRateLimitStore(cfg.RateLimit, mongoClient, cfg.Mongo.Database)
+
+	// Los índices únicos garantizan usuarios sin duplicados y un voto por usuario;
+	// la instancia no está lista hasta que existen
+	indexSteps := []func(context.Context) error{userRepo.EnsureIndexes, pollRepo.EnsureIndexes, scheduledRepo.EnsureIndexes, reportRepo.EnsureIndexes, accountDeletionRepo.EnsureIndexes, auditRepo.EnsureIndexes, tweetRepo.EnsureIndexes, policyRuleRepo.EnsureIndexes, exportJobRepo.EnsureIndexes}
+	if rateLimitIndexes != nil {
+		indexSteps = append(indexSteps, rateLimitIndexes)
+	}
+	indexesReady := health.NewFlag(errors.New("índices pendientes"))
+	app.Task("índices", ensureIndexes(logger, indexesReady, indexSteps...))
+
+	// Job de cierre de encuestas vencidas
+	app.Go("poll_closer", worker.NewPollCloser(pollRepo, notificationRepo, worker.DefaultPollCloseInterval).Run)
+
+	// Federación ActivityPub: necesita una URL pública absoluta para los IDs de los actores
+	federationURL := cfg.PublicBaseURL
+	if federationURL == "" {
+		federationURL = fmt.Sprintf("http://localhost:%d", cfg.Server.Port)
+	}
+	federationClient := activitypub.NewClient(&http.Client{Timeout: 10 * time.Second, Transport: tracing.Transport(activitypub.NewTransport())})
+	federation, err := activitypub.NewFederation(federationURL, userRepo, tweetRepo, actorKeyRepo, federationClient)
+	if err != nil {
+		return nil, fmt.Errorf("error al configurar la federación: %w", err)
+	}
+	// Las entregas pendientes terminan antes de desconectar Mongo
+	app.OnShutdown("federación", federation.Wait)
+
+	// Almacenamiento de archivos adjuntos y de exportaciones
+	blobStore, err := newBlobStore(cfg.Media)
+	if err != nil {
+		return nil, fmt.Errorf("error al configurar el almacenamiento de archivos: %w", err)
+	}
+	exportKey, err := exportSigningKey(cfg.Export, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	heuristics, err := policyHeuristics(cfg.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("error en las heurísticas de spam: %w", err)
+	}
+
+	// Inicializar servicios
+	auditLog := audit.NewLogger(auditRepo)
+	userService := service.NewUserService(userRepo, notificationRepo, appMetrics)
+	// La política de contenido publica los tweets retenidos cuando se aprueban
+	policyService := service.NewPolicyService(policyRuleRepo, tweetRepo, userRepo, heuristics, auditLog, appMetrics, federation)
+	tweetService := service.NewTweetService(tweetRepo, userRepo, mediaRepo, pollRepo, scheduledRepo, policyService, appMetrics, federation)
+	timelineService := service.NewTimelineService(tweetRepo, userRepo, pollRepo, appMetrics)
+	moderationService := service.NewModerationService(reportRepo, tweetRepo, userRepo, auditLog)
+	adminService := service.NewAdminService(userRepo, tweetRepo, auditLog)
+	accountService := service.NewAccountService(userRepo, accountDeletionRepo, auditLog)
+	auditService := service.NewAuditService(auditRepo)
+	mediaService := service.NewMediaService(mediaRepo, userRepo, blobStore, media.NewProcessor(cfg.Media.MaxBytes))
+	exportService := service.NewExportService(exportJobRepo, userRepo, blobStore, export.NewSigner(exportKey), cfg.PublicBaseURL, cfg.Export.LinkTTL, auditLog)
+
+	// Publicación de tweets programados; el lease permite varias instancias de la API
+	app.Go("scheduler", worker.NewScheduler(scheduledRepo, tweetService, worker.DefaultScheduleInterval, worker.DefaultScheduleLease).Run)
+	// Exportaciones de datos personales y borrado de los archivos vencidos
+	exporter := worker.NewExporter(exportJobRepo, userRepo, tweetRepo, mediaRepo, blobStore, worker.DefaultExportInterval, worker.DefaultExportLease, cfg.Export.Retention)
+	app.Go("exporter", exporter.Run)
+	// Suspensiones que vencen, desactivaciones fuera de plazo y borrados de cuentas
+	accountContent := []worker.UserContent{tweetRepo, scheduledRepo, notificationRepo, pollRepo, exporter}
+	app.Go("account_purger", worker.NewAccountPurger(accountDeletionRepo, userRepo, accountService, reportRepo, accountContent, auditLog, worker.DefaultAccountInterval, worker.DefaultPurgeLease).Run)
 
 	// Inicializar handlers
-	userHandler := handlers.NewUserHandler(userRepo)
-	tweetHandler := handlers.NewTweetHandler(tweetRepo)
+	userHandler := handlers.NewUserHandler(userService)
+	tweetHandler := handlers.NewTweetHandler(tweetService, timelineService)
+	activityPubHandler := handlers.NewActivityPubHandler(federation)
+	mediaHandler := handlers.NewMediaHandler(mediaService)
+	feedHandler := handlers.NewFeedHandler(userService, timelineService, cfg.PublicBaseURL)
+	pollHandler := handlers.NewPollHandler(tweetService, userService)
+	scheduledTweetHandler := handlers.NewScheduledTweetHandler(tweetService)
+	moderationHandler := handlers.NewModerationHandler(moderationService)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	policyHandler := handlers.NewPolicyHandler(policyService)
+	exportHandler := handlers.NewExportHandler(exportService)
+	graphqlServer, err := gql.NewServer(userService, timelineService, gql.Limits{
+		MaxDepth:      cfg.GraphQL.MaxDepth,
+		MaxComplexity: cfg.GraphQL.MaxComplexity,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error al armar el esquema GraphQL: %w", err)
+	}
+	var graphqlLimiter *ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		graphqlLimiter = ratelimit.NewLimiter(rateLimitStore, graphqlRateLimitRules(cfg.RateLimit)...)
+	}
+	graphqlHandler := handlers.NewGraphQLHandler(graphqlServer, graphqlLimiter)
 
 	// Configurar router
-	r := gin.Default()
+	messages, err := i18n.NewBundle(cfg.DefaultLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("error al cargar los catálogos de mensajes: %w", err)
+	}
+
+	r := gin.New()
+	r.Use(middleware.RequestID())
+	r.Use(middleware.Tracing())
+	r.Use(middleware.Metrics(appMetrics))
+	r.Use(middleware.AccessLog(logger))
+	r.Use(middleware.Recovery(logger))
+	r.Use(middleware.Locale(messages))
+	r.Use(middleware.Errors())
+	// Identifica al usuario de X-User-ID con sus roles
+	r.Use(middleware.Identity(userService.Principal))
+	r.Use(middleware.AuditSource())
+	if cfg.RateLimit.Enabled {
+		r.Use(middleware.RateLimit(ratelimit.NewLimiter(rateLimitStore, rateLimitRules(cfg.RateLimit)...)))
+	}
+	r.NoRoute(middleware.NoRoute)
+
+	// Métricas en formato Prometheus
+	r.GET("/metrics", gin.WrapH(appMetrics.Handler()))
 
 	// Swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -128,24 +421,66 @@ func main() {
 	// Registrar rutas
 	handlers.RegisterUserRoutes(r, userHandler)
 	handlers.RegisterTweetRoutes(r, tweetHandler)
+	handlers.RegisterPollRoutes(r, pollHandler)
+	handlers.RegisterScheduledTweetRoutes(r, scheduledTweetHandler)
+	handlers.RegisterMediaRoutes(r, mediaHandler)
+	handlers.RegisterModerationRoutes(r, moderationHandler)
+	handlers.RegisterAdminRoutes(r, adminHandler)
+	handlers.RegisterAccountRoutes(r, accountHandler)
+	handlers.RegisterAuditRoutes(r, auditHandler)
+	handlers.RegisterPolicyRoutes(r, policyHandler)
+	handlers.RegisterExportRoutes(r, exportHandler)
+	handlers.RegisterFeedRoutes(r, feedHandler)
+	handlers.RegisterActivityPubRoutes(r, activityPubHandler)
+	handlers.RegisterGraphQLRoutes(r, graphqlHandler)
 
-	// Health checks
+	// Health checks: /livez solo mira el proceso; /readyz también sus dependencias
+	checks := health.NewRegistry(cfg.Health.CacheTTL, cfg.Health.Timeout, logger)
+	checks.Liveness("workers", app.CheckWorkers, health.NoCache())
+	checks.Readiness("shutdown", app.CheckDraining, health.NoCache())
+	checks.Readiness("workers", app.CheckWorkers, health.NoCache())
+	checks.Readiness("indexes", indexesReady.Check, health.NoCache())
+	checks.Readiness("mongodb", func(ctx context.Context) error {
+		// ping no necesita privilegios sobre otras bases de datos
+		return mongoClient.Ping(ctx, readpref.Primary())
+	})
 	r.GET("/health", healthCheck)
-	r.GET("/health/db", dbHealthCheck(mongoClient))
+	handlers.RegisterHealthRoutes(r, handlers.NewHealthHandler(checks))
 
-	// Obtener puerto
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-		log.Printf("📝 Using default port: %s", port)
+	// API gRPC para los servicios internos, en su propio puerto y con los mismos
+	// servicios, identidad y rate limit que la API REST; su servicio de salud
+	// sigue a /readyz
+	if cfg.GRPC.Port > 0 {
+		listener, err := net.Listen("tcp", net.JoinHostPort(cfg.GRPC.Host, strconv.Itoa(cfg.GRPC.Port)))
+		if err != nil {
+			return nil, fmt.Errorf("error al abrir el puerto gRPC: %w", err)
+		}
+		var grpcLimiter *ratelimit.Limiter
+		if cfg.RateLimit.Enabled {
+			grpcLimiter = ratelimit.NewLimiter(rateLimitStore, grpcRateLimitRules(cfg.RateLimit)...)
+		}
+		grpcServer := grpcapi.NewServer(userService, tweetService, timelineService, grpcapi.Options{
+			WatchInterval: cfg.GRPC.WatchInterval,
+			Ready:         func(ctx context.Context) bool { return checks.Ready(ctx).OK() },
+			Messages:      messages,
+			Logger:        logger,
+			Resolve:       userService.Principal,
+			Limiter:       grpcLimiter,
+		})
+		app.Go("grpc", func(ctx context.Context) {
+			if err := grpcServer.Serve(ctx, listener); err != nil {
+				logger.Error("el servidor gRPC se detuvo", slog.Any("error", err))
+			}
+		})
 	}
 
-	// Iniciar servidor
-	log.Printf("🚀 Server starting on http://localhost:%s", port)
-	log.Printf("💡 Health endpoint: http://localhost:%s/health", port)
-	log.Printf("💡 DB Health endpoint: http://localhost:%s/health/db", port)
-
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("❌ Server failed to start: %v", err)
-	}
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:           r,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}, nil
 }
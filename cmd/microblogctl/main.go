@@ -0,0 +1,327 @@
+// cmd/microblogctl/main.go
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/config"
+	"github.com/ffelixf/microblog-platform/internal/dataset"
+	"github.com/ffelixf/microblog-platform/internal/loadtest"
+	"github.com/ffelixf/microblog-platform/internal/memstore"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"github.com/ffelixf/microblog-platform/pkg/database"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const usage = `uso: microblogctl <comando> [opciones]
+
+comandos:
+  export     exporta usuarios, follows y tweets como NDJSON (opcionalmente gzip)
+  import     importa un archivo exportado, con IDs nuevos y retomable si falla
+  generate   genera un conjunto de datos sintético para importar
+  loadtest   mide latencias y throughput de la API con una mezcla de operaciones
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = exportData(os.Args[2:])
+	case "import":
+		err = importData(os.Args[2:])
+	case "generate":
+		err = generateData(os.Args[2:])
+	case "loadtest":
+		err = runLoadTest(os.Args[2:])
+	case "-h", "--help", "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "comando desconocido: %s\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// exportData escribe el conjunto de datos completo de MongoDB
+func exportData(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "archivo YAML de configuración (opcional)")
+	output := fs.String("output", "-", "archivo de salida; - es la salida estándar")
+	compress := fs.Bool("gzip", false, "comprimir con gzip (implícito si el archivo termina en .gz)")
+	anonymize := fs.Bool("anonymize", false, "reemplazar los emails por direcciones que no existen")
+	fs.Parse(args)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	cfg, client, err := connect(ctx, *configFile)
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+
+	users := repository.NewUserRepository(client, cfg.Mongo.Database)
+	tweets := repository.NewTweetRepository(client, cfg.Mongo.Database)
+	var stats *dataset.Stats
+	err = writeOutput(*output, *compress, func(w io.Writer) (err error) {
+		stats, err = dataset.Export(ctx, w, users, tweets, dataset.ExportOptions{Anonymize: *anonymize})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error al exportar: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "exportados %d usuarios, %d follows y %d tweets\n", stats.Users, stats.Follows, stats.Tweets)
+	return nil
+}
+
+// importData carga un archivo exportado en MongoDB o, con --dry-run, lo valida
+// en memoria sin conectarse ni escribir nada
+func importData(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "archivo YAML de configuración (opcional)")
+	input := fs.String("input", "", "archivo a importar, comprimido o no; - es la entrada estándar (requerido)")
+	checkpoint := fs.String("checkpoint", "", "archivo de progreso para retomar la importación (por defecto <input>.checkpoint)")
+	batchSize := fs.Int("batch-size", dataset.DefaultBatchSize, "registros por lote")
+	dryRun := fs.Bool("dry-run", false, "solo validar el archivo en memoria, sin escribir en MongoDB")
+	fs.Parse(args)
+
+	if *input == "" {
+		fs.Usage()
+		return errors.New("--input es requerido")
+	}
+	// El progreso de una validación no sirve para retomar una importación real
+	if *checkpoint != "" && *dryRun {
+		return errors.New("--checkpoint no se puede usar con --dry-run")
+	}
+	if *checkpoint == "" && *input != "-" && !*dryRun {
+		*checkpoint = *input + ".checkpoint"
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var r io.Reader = os.Stdin
+	if *input != "-" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	var importer *dataset.Importer
+	if *dryRun {
+		importer = dataset.NewImporter(memstore.NewUsers(), memstore.NewTweets(), *batchSize)
+	} else {
+		cfg, client, err := connect(ctx, *configFile)
+		if err != nil {
+			return err
+		}
+		defer client.Disconnect(context.Background())
+
+		// Los índices únicos son los que detectan nombres y emails repetidos
+		users := repository.NewUserRepository(client, cfg.Mongo.Database)
+		if err := users.EnsureIndexes(ctx); err != nil {
+			return err
+		}
+		tweets := repository.NewTweetRepository(client, cfg.Mongo.Database)
+		if err := tweets.EnsureIndexes(ctx); err != nil {
+			return err
+		}
+		importer = dataset.NewImporter(users, tweets, *batchSize)
+	}
+
+	stats, err := importer.Import(ctx, r, *checkpoint)
+	if stats != nil {
+		verb := "importados"
+		if *dryRun {
+			verb = "validados"
+		}
+		fmt.Fprintf(os.Stderr, "%s %d usuarios, %d follows y %d tweets; %d registros rechazados\n",
+			verb, stats.Users, stats.Follows, stats.Tweets, stats.Rejected)
+	}
+	if err != nil {
+		if *checkpoint != "" {
+			return fmt.Errorf("error al importar: %w (repite el comando para continuar desde %s)", err, *checkpoint)
+		}
+		return fmt.Errorf("error al importar: %w", err)
+	}
+	return nil
+}
+
+// generateData escribe un conjunto de datos sintético con el formato de export
+func generateData(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	output := fs.String("output", "-", "archivo de salida; - es la salida estándar")
+	compress := fs.Bool("gzip", false, "comprimir con gzip (implícito si el archivo termina en .gz)")
+	opts := generateFlags(fs)
+	fs.Parse(args)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var stats *dataset.Stats
+	err := writeOutput(*output, *compress, func(w io.Writer) (err error) {
+		stats, err = dataset.Generate(ctx, w, *opts)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error al generar: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "generados %d usuarios, %d follows y %d tweets\n", stats.Users, stats.Follows, stats.Tweets)
+	return nil
+}
+
+// generateFlags registra las opciones del conjunto sintético
+func generateFlags(fs *flag.FlagSet) *dataset.GenerateOptions {
+	opts := &dataset.GenerateOptions{}
+	fs.IntVar(&opts.Users, "users", 1000, "usuarios")
+	fs.IntVar(&opts.Tweets, "tweets", 10000, "tweets")
+	fs.IntVar(&opts.FollowsPerUser, "follows", 20, "media de cuentas seguidas por usuario")
+	fs.Float64Var(&opts.Exponent, "exponent", 1, "exponente de la ley de potencias de los seguidores")
+	fs.DurationVar(&opts.Span, "span", 30*24*time.Hour, "período en el que se reparten los tweets")
+	fs.Uint64Var(&opts.Seed, "seed", 1, "semilla; la misma semilla da los mismos datos")
+	return opts
+}
+
+// runLoadTest reproduce una mezcla de lecturas del timeline, tweets y follows
+// contra una API en marcha o, sin --url, contra una API en memoria con datos
+// sintéticos
+func runLoadTest(args []string) error {
+	fs := flag.NewFlagSet("loadtest", flag.ExitOnError)
+	url := fs.String("url", "", "dirección de la API; vacía levanta una API en memoria con datos sintéticos")
+	checkpoint := fs.String("checkpoint", "", "checkpoint de la importación con los usuarios en cuyo nombre actuar (requerido con --url)")
+	mix := fs.String("mix", "timeline=80,tweet=15,follow=5", "peso de cada operación")
+	concurrency := fs.Int("concurrency", 8, "clientes simultáneos")
+	duration := fs.Duration("duration", 10*time.Second, "duración de la prueba")
+	requests := fs.Int("requests", 0, "terminar tras este número de peticiones; 0 sin límite")
+	rate := fs.Float64("rate", 0, "peticiones por segundo entre todos los clientes; 0 sin límite")
+	asJSON := fs.Bool("json", false, "escribir el informe como JSON")
+	genOpts := generateFlags(fs)
+	fs.Parse(args)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	opts := loadtest.Options{
+		BaseURL:     strings.TrimSuffix(*url, "/"),
+		Concurrency: *concurrency,
+		Duration:    *duration,
+		Requests:    *requests,
+		Rate:        *rate,
+		Seed:        genOpts.Seed,
+	}
+	var err error
+	if opts.Mix, err = loadtest.ParseMix(*mix); err != nil {
+		return err
+	}
+
+	if *url != "" {
+		if *checkpoint == "" {
+			fs.Usage()
+			return errors.New("--checkpoint es requerido con --url")
+		}
+		if opts.UserIDs, err = dataset.ImportedUserIDs(*checkpoint); err != nil {
+			return err
+		}
+	} else {
+		gin.SetMode(gin.ReleaseMode)
+		api, err := loadtest.NewMemoryAPI(ctx, *genOpts)
+		if err != nil {
+			return fmt.Errorf("error al generar los datos: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "API en memoria con %d usuarios, %d follows y %d tweets\n", api.Stats.Users, api.Stats.Follows, api.Stats.Tweets)
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return err
+		}
+		server := &http.Server{Handler: api.Handler}
+		go server.Serve(listener)
+		defer server.Close()
+		opts.BaseURL = "http://" + listener.Addr().String()
+		opts.UserIDs = api.UserIDs
+	}
+
+	report, err := loadtest.Run(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return report.Write(os.Stdout)
+}
+
+// writeOutput pasa a fn el archivo de salida, comprimido si hace falta. Si fn
+// falla el archivo a medias se borra: no sirve para importar.
+func writeOutput(path string, compress bool, fn func(w io.Writer) error) (err error) {
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+			}
+		}()
+		w = file
+	}
+	if compress || strings.HasSuffix(path, ".gz") {
+		zw := gzip.NewWriter(w)
+		defer func() {
+			if closeErr := zw.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		w = zw
+	}
+	return fn(w)
+}
+
+// connect carga la configuración y se conecta a MongoDB
+func connect(ctx context.Context, configFile string) (*config.Config, *mongo.Client, error) {
+	cfg, err := config.Load(config.Sources{File: configFile, DotEnv: ".env"})
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := database.ConnectDB(ctx, database.Config{
+		URI:                    cfg.Mongo.URI,
+		ConnectTimeout:         cfg.Mongo.ConnectTimeout,
+		ServerSelectionTimeout: cfg.Mongo.ServerSelectionTimeout,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, client, nil
+}
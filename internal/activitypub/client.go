@@ -0,0 +1,223 @@
+// internal/activitypub/client.go
+package activitypub
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+)
+
+const (
+	userAgent = "microblog-platform (+ActivityPub)"
+
+	// maxResponseSize limita el tamaño de los documentos remotos que se leen
+	maxResponseSize = 1 << 20
+
+	// actorCacheTTL es cuánto se reutiliza un actor remoto antes de volver a pedirlo
+	actorCacheTTL = 10 * time.Minute
+	// maxCachedActors limita los actores guardados en la caché
+	maxCachedActors = 1024
+)
+
+// ErrUnsafeURL rechaza las URLs remotas que no son https o cuyo actor publica el
+// inbox o la clave en otro dominio
+var ErrUnsafeURL = apperr.Unauthorized("unsafe_actor_url", "el actor remoto debe publicarse por https en un único dominio")
+
+// Client realiza las peticiones salientes hacia otras instancias. Solo habla
+// https y guarda en caché los actores obtenidos, para que cada petición al
+// inbox no provoque una nueva petición saliente.
+type Client struct {
+	http *http.Client
+	now  func() time.Time
+
+	mu     sync.Mutex
+	actors map[string]cachedActor
+}
+
+type cachedActor struct {
+	actor   *Actor
+	expires time.Time
+}
+
+// NewClient crea un cliente ActivityPub. Si httpClient es nil se usa uno con
+// timeout de 10s sobre NewTransport.
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second, Transport: NewTransport()}
+	}
+	return &Client{http: httpClient, now: time.Now, actors: make(map[string]cachedActor)}
+}
+
+// NewTransport devuelve un transporte HTTP que solo se conecta a direcciones
+// públicas. La comprobación se hace al conectar, con el nombre ya resuelto, así
+// que un DNS que cambie de respuesta no sirve para llegar a la red interna.
+func NewTransport() *http.Transport {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second, Control: publicOnly}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// Con un proxy la conexión la abre él y el control no se aplicaría
+	transport.Proxy = nil
+	return transport
+}
+
+// publicOnly rechaza las conexiones a direcciones privadas, de loopback,
+// link-local, multicast o sin especificar
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return fmt.Errorf("conexión a dirección no pública %s rechazada", ip)
+	}
+	return nil
+}
+
+// remoteURL comprueba que raw sea una URL https absoluta y la devuelve
+func remoteURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnsafeURL, raw)
+	}
+	return u, nil
+}
+
+// sameHost comprueba que raw sea una URL https del mismo dominio que base
+func sameHost(base *url.URL, raw string) error {
+	u, err := remoteURL(raw)
+	if err != nil {
+		return err
+	}
+	if u.Host != base.Host {
+		return fmt.Errorf("%w: %q no es de %s", ErrUnsafeURL, raw, base.Host)
+	}
+	return nil
+}
+
+// FetchActor obtiene el documento de un actor remoto, o el de la caché si no
+// venció. El actor, sus inbox y su clave deben estar en el dominio de actorID.
+func (c *Client) FetchActor(ctx context.Context, actorID string) (*Actor, error) {
+	base, err := remoteURL(actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor := c.cached(actorID); actor != nil {
+		return actor, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, actorID, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", ContentType)
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener actor %s: %v", actorID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error al obtener actor %s: estado %d", actorID, resp.StatusCode)
+	}
+
+	var actor Actor
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&actor); err != nil {
+		return nil, fmt.Errorf("error al decodificar actor %s: %v", actorID, err)
+	}
+	if actor.ID == "" || actor.Inbox == "" {
+		return nil, fmt.Errorf("actor %s incompleto", actorID)
+	}
+	urls := []string{actor.ID, actor.Inbox, actor.PublicKey.ID}
+	if actor.Endpoints != nil && actor.Endpoints.SharedInbox != "" {
+		urls = append(urls, actor.Endpoints.SharedInbox)
+	}
+	for _, raw := range urls {
+		if err := sameHost(base, raw); err != nil {
+			return nil, err
+		}
+	}
+	c.store(actorID, &actor)
+	return &actor, nil
+}
+
+func (c *Client) cached(actorID string) *Actor {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.actors[actorID]
+	if !ok || !c.now().Before(entry.expires) {
+		return nil
+	}
+	return entry.actor
+}
+
+// store guarda el actor en la caché; si está llena descarta primero los vencidos
+// y, si no alcanza, la vacía
+func (c *Client) store(actorID string, actor *Actor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.actors) >= maxCachedActors {
+		for id, entry := range c.actors {
+			if !now.Before(entry.expires) {
+				delete(c.actors, id)
+			}
+		}
+		if len(c.actors) >= maxCachedActors {
+			clear(c.actors)
+		}
+	}
+	c.actors[actorID] = cachedActor{actor: actor, expires: now.Add(actorCacheTTL)}
+}
+
+// Deliver envía una actividad firmada al inbox indicado
+func (c *Client) Deliver(ctx context.Context, inbox string, activity interface{}, keyID string, key *rsa.PrivateKey) error {
+	if _, err := remoteURL(inbox); err != nil {
+		return err
+	}
+	body, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, inbox, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", ContentType)
+	req.Header.Set("Accept", ContentType)
+	req.Header.Set("User-Agent", userAgent)
+	if err := SignRequest(req, keyID, key, body); err != nil {
+		return fmt.Errorf("error al firmar petición: %v", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("error al entregar actividad en %s: %v", inbox, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("error al entregar actividad en %s: estado %d", inbox, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,504 @@
+// internal/activitypub/federation.go
+package activitypub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrNotFound         = apperr.NotFound("actor_not_found", "actor no encontrado")
+	ErrInvalidActivity  = apperr.Validation("invalid_activity", "actividad inválida")
+	ErrActorMismatch    = apperr.Unauthorized("actor_mismatch", "el firmante no coincide con el actor de la actividad")
+	ErrInvalidWebFinger = apperr.InvalidField("invalid_webfinger_resource", "resource", "recurso WebFinger inválido")
+)
+
+// outboxSize es la cantidad de tweets publicados en el outbox
+const outboxSize = 20
+
+// UserStore es el subconjunto de UserRepository que usa la federación
+type UserStore interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	GetByActorID(ctx context.Context, actorID string) (*models.User, error)
+	UpsertRemoteUser(ctx context.Context, username string, remote *models.RemoteActor) (*models.User, error)
+	GetFollowers(ctx context.Context, userID string) ([]models.User, error)
+	FollowUser(ctx context.Context, userID, targetID string) error
+	UnfollowUser(ctx context.Context, userID, targetID string) error
+}
+
+// TweetStore es el subconjunto de TweetRepository que usa la federación
+type TweetStore interface {
+	GetByUserID(ctx context.Context, userID string) ([]models.Tweet, error)
+}
+
+// KeyStore entrega el par de claves de cada usuario local
+type KeyStore interface {
+	GetOrCreate(ctx context.Context, userID string) (*models.ActorKey, error)
+}
+
+// Federation expone a los usuarios locales como actores ActivityPub
+type Federation struct {
+	baseURL string
+	host    string
+	users   UserStore
+	tweets  TweetStore
+	keys    KeyStore
+	client  *Client
+	now     func() time.Time
+
+	// deliveries cuenta las entregas en segundo plano pendientes
+	deliveries sync.WaitGroup
+}
+
+func NewFederation(baseURL string, users UserStore, tweets TweetStore, keys KeyStore, client *Client) (*Federation, error) {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("URL pública inválida para federación: %q", baseURL)
+	}
+	if client == nil {
+		client = NewClient(nil)
+	}
+
+	return &Federation{
+		baseURL: baseURL,
+		host:    u.Host,
+		users:   users,
+		tweets:  tweets,
+		keys:    keys,
+		client:  client,
+		now:     time.Now,
+	}, nil
+}
+
+// ActorURL devuelve el IRI del actor de un usuario local
+func (f *Federation) ActorURL(username string) string {
+	return f.baseURL + "/users/" + url.PathEscape(username)
+}
+
+func (f *Federation) keyID(username string) string {
+	return f.ActorURL(username) + "#main-key"
+}
+
+func (f *Federation) noteURL(username string, tweetID primitive.ObjectID) string {
+	return f.ActorURL(username) + "/statuses/" + tweetID.Hex()
+}
+
+// WebFinger resuelve un recurso acct:usuario@dominio a su actor
+func (f *Federation) WebFinger(ctx context.Context, resource string) (*WebFinger, error) {
+	acct, ok := strings.CutPrefix(resource, "acct:")
+	if !ok {
+		return nil, ErrInvalidWebFinger
+	}
+	username, domain, ok := strings.Cut(acct, "@")
+	if !ok || username == "" || !strings.EqualFold(domain, f.host) {
+		return nil, ErrNotFound
+	}
+
+	user, err := f.localUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	actorURL := f.ActorURL(user.Username)
+	return &WebFinger{
+		Subject: "acct:" + user.Username + "@" + f.host,
+		Aliases: []string{actorURL},
+		Links: []WebFingerLink{
+			{Rel: "self", Type: ContentType, Href: actorURL},
+		},
+	}, nil
+}
+
+// Actor construye el documento del actor de un usuario local
+func (f *Federation) Actor(ctx context.Context, username string) (*Actor, error) {
+	user, err := f.localUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := f.keys.GetOrCreate(ctx, user.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	actorURL := f.ActorURL(user.Username)
+	return &Actor{
+		Context:           []string{activityStreamsContext, securityContext},
+		ID:                actorURL,
+		Type:              "Person",
+		PreferredUsername: user.Username,
+		Name:              user.Username,
+		URL:               actorURL,
+		Published:         user.CreatedAt.UTC().Format(time.RFC3339),
+		Inbox:             actorURL + "/inbox",
+		Outbox:            actorURL + "/outbox",
+		Followers:         actorURL + "/followers",
+		Following:         actorURL + "/following",
+		PublicKey: PublicKey{
+			ID:           f.keyID(user.Username),
+			Owner:        actorURL,
+			PublicKeyPEM: key.PublicKeyPEM,
+		},
+	}, nil
+}
+
+// Outbox devuelve los tweets más recientes del usuario como actividades Create{Note}
+func (f *Federation) Outbox(ctx context.Context, username string) (*OrderedCollection, error) {
+	user, err := f.localUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	tweets, err := f.tweets.GetByUserID(ctx, user.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &OrderedCollection{
+		Context:      activityStreamsContext,
+		ID:           f.ActorURL(user.Username) + "/outbox",
+		Type:         "OrderedCollection",
+		TotalItems:   len(tweets),
+		OrderedItems: make([]interface{}, 0, outboxSize),
+	}
+	for i, tweet := range tweets {
+		if i == outboxSize {
+			break
+		}
+		collection.OrderedItems = append(collection.OrderedItems, f.createActivity(user, &tweet))
+	}
+	return collection, nil
+}
+
+// Followers devuelve la colección de seguidores del usuario (locales y remotos)
+func (f *Federation) Followers(ctx context.Context, username string) (*OrderedCollection, error) {
+	user, err := f.localUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	followers, err := f.users.GetFollowers(ctx, user.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &OrderedCollection{
+		Context:      activityStreamsContext,
+		ID:           f.ActorURL(user.Username) + "/followers",
+		Type:         "OrderedCollection",
+		TotalItems:   len(followers),
+		OrderedItems: make([]interface{}, 0, len(followers)),
+	}
+	for _, follower := range followers {
+		if follower.IsRemote() {
+			collection.OrderedItems = append(collection.OrderedItems, follower.Remote.ActorID)
+		} else {
+			collection.OrderedItems = append(collection.OrderedItems, f.ActorURL(follower.Username))
+		}
+	}
+	return collection, nil
+}
+
+// HandleInbox procesa una actividad recibida en el inbox de un usuario local.
+// La firma HTTP se verifica contra la clave publicada por el actor remoto.
+func (f *Federation) HandleInbox(ctx context.Context, username string, r *http.Request, body []byte) error {
+	user, err := f.localUser(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	var activity Activity
+	if err := json.Unmarshal(body, &activity); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidActivity, err)
+	}
+	if activity.Actor == "" || activity.Type == "" {
+		return fmt.Errorf("%w: faltan actor o type", ErrInvalidActivity)
+	}
+
+	remote, err := f.verify(ctx, r, body, activity.Actor)
+	if err != nil {
+		return err
+	}
+
+	switch activity.Type {
+	case "Follow":
+		return f.handleFollow(ctx, user, remote, &activity)
+	case "Undo":
+		return f.handleUndo(ctx, user, remote, &activity)
+	default:
+		// El resto de actividades se aceptan y se ignoran
+		return nil
+	}
+}
+
+// verify comprueba que la petición está firmada por el actor de la actividad
+func (f *Federation) verify(ctx context.Context, r *http.Request, body []byte, actorID string) (*Actor, error) {
+	sig, err := ParseSignature(r)
+	if err != nil {
+		return nil, err
+	}
+
+	keyOwner, _, _ := strings.Cut(sig.KeyID, "#")
+	if keyOwner != actorID {
+		return nil, ErrActorMismatch
+	}
+
+	actor, err := f.client.FetchActor(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.ID != actorID || actor.PublicKey.ID != sig.KeyID || actor.PublicKey.Owner != actor.ID {
+		return nil, ErrActorMismatch
+	}
+
+	key, err := ParsePublicKey(actor.PublicKey.PublicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if err := sig.Verify(r, body, key, f.now()); err != nil {
+		return nil, err
+	}
+	return actor, nil
+}
+
+func (f *Federation) handleFollow(ctx context.Context, user *models.User, remote *Actor, activity *Activity) error {
+	if objectID(activity.Object) != f.ActorURL(user.Username) {
+		return fmt.Errorf("%w: el Follow no va dirigido a este actor", ErrInvalidActivity)
+	}
+
+	follower, err := f.users.UpsertRemoteUser(ctx, remoteUsername(remote), remoteActor(remote))
+	if err != nil {
+		return err
+	}
+
+	if !contains(follower.Following, user.ID.Hex()) {
+		if err := f.users.FollowUser(ctx, follower.ID.Hex(), user.ID.Hex()); err != nil {
+			return err
+		}
+	}
+
+	accept := &Activity{
+		Context: activityStreamsContext,
+		ID:      f.ActorURL(user.Username) + "#accepts/" + primitive.NewObjectID().Hex(),
+		Type:    "Accept",
+		Actor:   f.ActorURL(user.Username),
+		Object:  activity,
+	}
+	if err := f.deliver(ctx, user, follower.Remote.Inbox, accept); err != nil {
+		// El follow ya quedó registrado; el remoto puede reintentar el Follow
+		slog.WarnContext(ctx, "activitypub: error al enviar Accept",
+			slog.String("actor", remote.ID), slog.Any("error", err))
+	}
+	return nil
+}
+
+func (f *Federation) handleUndo(ctx context.Context, user *models.User, remote *Actor, activity *Activity) error {
+	undone, ok := embeddedActivity(activity.Object)
+	if !ok || undone.Type != "Follow" {
+		return nil
+	}
+	if undone.Actor != remote.ID || objectID(undone.Object) != f.ActorURL(user.Username) {
+		return ErrActorMismatch
+	}
+
+	follower, err := f.users.GetByActorID(ctx, remote.ID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+		return err
+	}
+
+	if !contains(follower.Following, user.ID.Hex()) {
+		return nil
+	}
+	return f.users.UnfollowUser(ctx, follower.ID.Hex(), user.ID.Hex())
+}
+
+// PublishTweet entrega el tweet en segundo plano a los seguidores remotos del autor.
+// La entrega sobrevive a la petición, pero conserva sus valores (request_id) para los logs.
+func (f *Federation) PublishTweet(ctx context.Context, tweet *models.Tweet) {
+	t := *tweet
+	ctx = context.WithoutCancel(ctx)
+	f.deliveries.Add(1)
+	go func() {
+		defer f.deliveries.Done()
+		ctx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		if err := f.DeliverTweet(ctx, &t); err != nil {
+			slog.ErrorContext(ctx, "activitypub: error al federar tweet",
+				slog.String("tweet_id", t.ID.Hex()), slog.Any("error", err))
+		}
+	}()
+}
+
+// Wait espera a que terminen las entregas en segundo plano o a que venza ctx
+func (f *Federation) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		f.deliveries.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("entregas pendientes: %w", ctx.Err())
+	}
+}
+
+// DeliverTweet envía Create{Note} a los inboxes de los seguidores remotos del autor.
+// Los seguidores de una misma instancia comparten un único envío al shared inbox.
+func (f *Federation) DeliverTweet(ctx context.Context, tweet *models.Tweet) error {
+	author, err := f.users.GetByID(ctx, tweet.UserID.Hex())
+	if err != nil {
+		return err
+	}
+	if author.IsRemote() {
+		return nil
+	}
+
+	followers, err := f.users.GetFollowers(ctx, author.ID.Hex())
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	var inboxes []string
+	for _, follower := range followers {
+		if !follower.IsRemote() {
+			continue
+		}
+		inbox := inboxOf(follower.Remote)
+		if inbox != "" && !seen[inbox] {
+			seen[inbox] = true
+			inboxes = append(inboxes, inbox)
+		}
+	}
+	if len(inboxes) == 0 {
+		return nil
+	}
+
+	activity := f.createActivity(author, tweet)
+	var errs []error
+	for _, inbox := range inboxes {
+		if err := f.deliver(ctx, author, inbox, activity); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f *Federation) deliver(ctx context.Context, user *models.User, inbox string, activity interface{}) error {
+	stored, err := f.keys.GetOrCreate(ctx, user.ID.Hex())
+	if err != nil {
+		return err
+	}
+	key, err := ParsePrivateKey(stored.PrivateKeyPEM)
+	if err != nil {
+		return err
+	}
+	return f.client.Deliver(ctx, inbox, activity, f.keyID(user.Username), key)
+}
+
+func (f *Federation) createActivity(user *models.User, tweet *models.Tweet) *Activity {
+	actorURL := f.ActorURL(user.Username)
+	published := tweet.CreatedAt.UTC().Format(time.RFC3339)
+	to := []string{PublicAddress}
+	cc := []string{actorURL + "/followers"}
+
+	noteID := f.noteURL(user.Username, tweet.ID)
+	return &Activity{
+		Context:   activityStreamsContext,
+		ID:        noteID + "/activity",
+		Type:      "Create",
+		Actor:     actorURL,
+		Published: published,
+		To:        to,
+		Cc:        cc,
+		Object: &Note{
+			ID:           noteID,
+			Type:         "Note",
+			AttributedTo: actorURL,
+			Content:      "<p>" + html.EscapeString(tweet.Content) + "</p>",
+			Published:    published,
+			URL:          noteID,
+			To:           to,
+			Cc:           cc,
+		},
+	}
+}
+
+// localUser busca un usuario local por username; las cuentas remotas no se exponen
+func (f *Federation) localUser(ctx context.Context, username string) (*models.User, error) {
+	user, err := f.users.GetByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if user.IsRemote() {
+		return nil, ErrNotFound
+	}
+	return user, nil
+}
+
+// remoteUsername arma el nombre usuario@instancia de un actor remoto
+func remoteUsername(actor *Actor) string {
+	host := actor.ID
+	if u, err := url.Parse(actor.ID); err == nil {
+		host = u.Host
+	}
+	name := actor.PreferredUsername
+	if name == "" {
+		name = actor.ID
+	}
+	return name + "@" + host
+}
+
+func remoteActor(actor *Actor) *models.RemoteActor {
+	remote := &models.RemoteActor{
+		ActorID: actor.ID,
+		Inbox:   actor.Inbox,
+	}
+	if actor.Endpoints != nil {
+		remote.SharedInbox = actor.Endpoints.SharedInbox
+	}
+	return remote
+}
+
+func inboxOf(remote *models.RemoteActor) string {
+	if remote == nil {
+		return ""
+	}
+	if remote.SharedInbox != "" {
+		return remote.SharedInbox
+	}
+	return remote.Inbox
+}
+
+func contains(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,501 @@
+// internal/activitypub/federation_test.go
+package activitypub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakeUsers implementa UserStore en memoria con la misma semántica de grafo que
+// UserRepository: los seguidores son los usuarios cuyo array following contiene el ID.
+type fakeUsers struct {
+	mu    sync.Mutex
+	users map[primitive.ObjectID]*models.User
+}
+
+func newFakeUsers() *fakeUsers {
+	return &fakeUsers{users: make(map[primitive.ObjectID]*models.User)}
+}
+
+func (s *fakeUsers) add(username string) *models.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := &models.User{ID: primitive.NewObjectID(), Username: username, CreatedAt: time.Now(), Following: []string{}}
+	s.users[u.ID] = u
+	return u
+}
+
+func (s *fakeUsers) copyOf(u *models.User) *models.User {
+	c := *u
+	c.Following = append([]string(nil), u.Following...)
+	return &c
+}
+
+func (s *fakeUsers) GetByID(_ context.Context, id string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	oid, _ := primitive.ObjectIDFromHex(id)
+	if u, ok := s.users[oid]; ok {
+		return s.copyOf(u), nil
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (s *fakeUsers) GetByUsername(_ context.Context, username string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.Username == username {
+			return s.copyOf(u), nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (s *fakeUsers) GetByActorID(_ context.Context, actorID string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.Remote != nil && u.Remote.ActorID == actorID {
+			return s.copyOf(u), nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (s *fakeUsers) UpsertRemoteUser(_ context.Context, username string, remote *models.RemoteActor) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.Remote != nil && u.Remote.ActorID == remote.ActorID {
+			u.Username, u.Remote = username, remote
+			return s.copyOf(u), nil
+		}
+	}
+	u := &models.User{ID: primitive.NewObjectID(), Username: username, Following: []string{}, Remote: remote}
+	s.users[u.ID] = u
+	return s.copyOf(u), nil
+}
+
+func (s *fakeUsers) GetFollowers(_ context.Context, userID string) ([]models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var followers []models.User
+	for _, u := range s.users {
+		if contains(u.Following, userID) {
+			followers = append(followers, *s.copyOf(u))
+		}
+	}
+	return followers, nil
+}
+
+func (s *fakeUsers) FollowUser(_ context.Context, userID, targetID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	oid, _ := primitive.ObjectIDFromHex(userID)
+	tid, _ := primitive.ObjectIDFromHex(targetID)
+	s.users[oid].Following = append(s.users[oid].Following, targetID)
+	s.users[tid].FollowersCount++
+	return nil
+}
+
+func (s *fakeUsers) UnfollowUser(_ context.Context, userID, targetID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	oid, _ := primitive.ObjectIDFromHex(userID)
+	tid, _ := primitive.ObjectIDFromHex(targetID)
+	var following []string
+	for _, id := range s.users[oid].Following {
+		if id != targetID {
+			following = append(following, id)
+		}
+	}
+	s.users[oid].Following = following
+	s.users[tid].FollowersCount--
+	return nil
+}
+
+type fakeTweets struct {
+	tweets []models.Tweet
+}
+
+func (s *fakeTweets) GetByUserID(_ context.Context, userID string) ([]models.Tweet, error) {
+	var out []models.Tweet
+	for _, t := range s.tweets {
+		if t.UserID.Hex() == userID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+type fakeKeys struct {
+	mu   sync.Mutex
+	keys map[string]*models.ActorKey
+}
+
+func (s *fakeKeys) GetOrCreate(_ context.Context, userID string) (*models.ActorKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if k, ok := s.keys[userID]; ok {
+		return k, nil
+	}
+	pub, priv, err := GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	s.keys[userID] = &models.ActorKey{PublicKeyPEM: pub, PrivateKeyPEM: priv}
+	return s.keys[userID], nil
+}
+
+// remoteInstance simula otra instancia del fediverso con un único actor
+type remoteInstance struct {
+	server  *httptest.Server
+	actor   *Actor
+	keyPEM  string
+	mu      sync.Mutex
+	inboxed []Activity
+	fetches int
+	verify  func(r *http.Request, body []byte) error
+}
+
+func newRemoteInstance(t *testing.T) *remoteInstance {
+	pub, priv, err := GenerateKeyPair()
+	require.NoError(t, err)
+
+	ri := &remoteInstance{keyPEM: priv}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/bob", func(w http.ResponseWriter, r *http.Request) {
+		ri.mu.Lock()
+		ri.fetches++
+		ri.mu.Unlock()
+		w.Header().Set("Content-Type", ContentType)
+		json.NewEncoder(w).Encode(ri.actor)
+	})
+	mux.HandleFunc("/users/bob/inbox", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if ri.verify != nil {
+			if err := ri.verify(r, body); err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+		var activity Activity
+		json.Unmarshal(body, &activity)
+		ri.mu.Lock()
+		ri.inboxed = append(ri.inboxed, activity)
+		ri.mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	})
+	ri.server = httptest.NewTLSServer(mux)
+	t.Cleanup(ri.server.Close)
+
+	actorID := ri.server.URL + "/users/bob"
+	ri.actor = &Actor{
+		ID:                actorID,
+		Type:              "Person",
+		PreferredUsername: "bob",
+		Inbox:             actorID + "/inbox",
+		PublicKey:         PublicKey{ID: actorID + "#main-key", Owner: actorID, PublicKeyPEM: pub},
+	}
+	return ri
+}
+
+func (ri *remoteInstance) received() []Activity {
+	ri.mu.Lock()
+	defer ri.mu.Unlock()
+	return append([]Activity(nil), ri.inboxed...)
+}
+
+// signedInboxRequest construye la petición que la instancia remota enviaría a nuestro inbox
+func (ri *remoteInstance) signedInboxRequest(t *testing.T, inbox string, activity interface{}) (*http.Request, []byte) {
+	body, err := json.Marshal(activity)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, inbox, bytes.NewReader(body))
+	require.NoError(t, err)
+	key, err := ParsePrivateKey(ri.keyPEM)
+	require.NoError(t, err)
+	require.NoError(t, SignRequest(req, ri.actor.PublicKey.ID, key, body))
+	return req, body
+}
+
+// testClient confía en el certificado de los servidores TLS de httptest, que es
+// el mismo para todos, y se conecta a loopback: no usa NewTransport
+func testClient(t *testing.T) *Client {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	return NewClient(server.Client())
+}
+
+func setupFederation(t *testing.T) (*Federation, *fakeUsers, *fakeTweets, *fakeKeys) {
+	users := newFakeUsers()
+	tweets := &fakeTweets{}
+	keys := &fakeKeys{keys: make(map[string]*models.ActorKey)}
+	f, err := NewFederation("https://local.test", users, tweets, keys, testClient(t))
+	require.NoError(t, err)
+	return f, users, tweets, keys
+}
+
+func TestFederation_WebFingerAndActor(t *testing.T) {
+	f, users, _, _ := setupFederation(t)
+	ctx := context.Background()
+	users.add("alice")
+
+	t.Run("resolve local account", func(t *testing.T) {
+		jrd, err := f.WebFinger(ctx, "acct:alice@local.test")
+		assert.NoError(t, err)
+		assert.Equal(t, "acct:alice@local.test", jrd.Subject)
+		assert.Equal(t, "https://local.test/users/alice", jrd.Links[0].Href)
+	})
+
+	t.Run("unknown domain or user", func(t *testing.T) {
+		_, err := f.WebFinger(ctx, "acct:alice@other.test")
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = f.WebFinger(ctx, "acct:nobody@local.test")
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = f.WebFinger(ctx, "https://local.test/users/alice")
+		assert.ErrorIs(t, err, ErrInvalidWebFinger)
+	})
+
+	t.Run("actor document exposes a stable key", func(t *testing.T) {
+		actor, err := f.Actor(ctx, "alice")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://local.test/users/alice/inbox", actor.Inbox)
+		assert.Equal(t, "https://local.test/users/alice#main-key", actor.PublicKey.ID)
+		_, err = ParsePublicKey(actor.PublicKey.PublicKeyPEM)
+		assert.NoError(t, err)
+
+		again, err := f.Actor(ctx, "alice")
+		assert.NoError(t, err)
+		assert.Equal(t, actor.PublicKey.PublicKeyPEM, again.PublicKey.PublicKeyPEM)
+	})
+}
+
+func TestFederation_Outbox(t *testing.T) {
+	f, users, tweets, _ := setupFederation(t)
+	alice := users.add("alice")
+	tweets.tweets = []models.Tweet{
+		{ID: primitive.NewObjectID(), UserID: alice.ID, Content: "hola <mundo>", CreatedAt: time.Now()},
+	}
+
+	outbox, err := f.Outbox(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, outbox.TotalItems)
+
+	create := outbox.OrderedItems[0].(*Activity)
+	assert.Equal(t, "Create", create.Type)
+	note := create.Object.(*Note)
+	assert.Equal(t, "<p>hola &lt;mundo&gt;</p>", note.Content)
+	assert.Contains(t, note.To, PublicAddress)
+}
+
+func TestFederation_InboxFollowAndUndo(t *testing.T) {
+	f, users, _, keys := setupFederation(t)
+	ctx := context.Background()
+	alice := users.add("alice")
+	remote := newRemoteInstance(t)
+	inbox := f.ActorURL("alice") + "/inbox"
+
+	// La instancia remota verifica que el Accept esté firmado por alice
+	remote.verify = func(r *http.Request, body []byte) error {
+		key, _ := keys.GetOrCreate(ctx, alice.ID.Hex())
+		pub, err := ParsePublicKey(key.PublicKeyPEM)
+		if err != nil {
+			return err
+		}
+		sig, err := ParseSignature(r)
+		if err != nil {
+			return err
+		}
+		return sig.Verify(r, body, pub, time.Now())
+	}
+
+	follow := map[string]interface{}{
+		"id":     remote.actor.ID + "#follows/1",
+		"type":   "Follow",
+		"actor":  remote.actor.ID,
+		"object": f.ActorURL("alice"),
+	}
+
+	t.Run("follow is stored in the graph and accepted", func(t *testing.T) {
+		req, body := remote.signedInboxRequest(t, inbox, follow)
+		err := f.HandleInbox(ctx, "alice", req, body)
+		assert.NoError(t, err)
+
+		followers, err := users.GetFollowers(ctx, alice.ID.Hex())
+		assert.NoError(t, err)
+		assert.Len(t, followers, 1)
+		assert.Equal(t, "bob@"+remote.server.Listener.Addr().String(), followers[0].Username)
+		assert.Equal(t, remote.actor.ID, followers[0].Remote.ActorID)
+
+		received := remote.received()
+		assert.Len(t, received, 1)
+		assert.Equal(t, "Accept", received[0].Type)
+		assert.Equal(t, f.ActorURL("alice"), received[0].Actor)
+	})
+
+	t.Run("duplicate follow does not double count", func(t *testing.T) {
+		req, body := remote.signedInboxRequest(t, inbox, follow)
+		assert.NoError(t, f.HandleInbox(ctx, "alice", req, body))
+
+		updated, _ := users.GetByID(ctx, alice.ID.Hex())
+		assert.Equal(t, 1, updated.FollowersCount)
+		assert.Equal(t, 1, remote.fetches, "el actor se reutiliza de la caché")
+	})
+
+	t.Run("undo follow removes the follower", func(t *testing.T) {
+		undo := map[string]interface{}{
+			"id":     remote.actor.ID + "#follows/1/undo",
+			"type":   "Undo",
+			"actor":  remote.actor.ID,
+			"object": follow,
+		}
+		req, body := remote.signedInboxRequest(t, inbox, undo)
+		assert.NoError(t, f.HandleInbox(ctx, "alice", req, body))
+
+		followers, err := users.GetFollowers(ctx, alice.ID.Hex())
+		assert.NoError(t, err)
+		assert.Empty(t, followers)
+	})
+}
+
+func TestFederation_InboxRejectsBadSignatures(t *testing.T) {
+	f, users, _, _ := setupFederation(t)
+	ctx := context.Background()
+	alice := users.add("alice")
+	remote := newRemoteInstance(t)
+	inbox := f.ActorURL("alice") + "/inbox"
+
+	follow := map[string]interface{}{
+		"id":     remote.actor.ID + "#follows/2",
+		"type":   "Follow",
+		"actor":  remote.actor.ID,
+		"object": f.ActorURL("alice"),
+	}
+
+	t.Run("unsigned request", func(t *testing.T) {
+		body, _ := json.Marshal(follow)
+		req := httptest.NewRequest(http.MethodPost, inbox, bytes.NewReader(body))
+		assert.ErrorIs(t, f.HandleInbox(ctx, "alice", req, body), ErrMissingSignature)
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		req, _ := remote.signedInboxRequest(t, inbox, follow)
+		tampered, _ := json.Marshal(map[string]interface{}{
+			"id": "x", "type": "Follow", "actor": remote.actor.ID, "object": f.ActorURL("alice") + "?evil",
+		})
+		assert.ErrorIs(t, f.HandleInbox(ctx, "alice", req, tampered), ErrInvalidSignature)
+	})
+
+	t.Run("signer is not the activity actor", func(t *testing.T) {
+		spoofed := map[string]interface{}{
+			"id": "x", "type": "Follow", "actor": "https://evil.test/users/mallory", "object": f.ActorURL("alice"),
+		}
+		req, body := remote.signedInboxRequest(t, inbox, spoofed)
+		assert.ErrorIs(t, f.HandleInbox(ctx, "alice", req, body), ErrActorMismatch)
+	})
+
+	t.Run("stale date", func(t *testing.T) {
+		body, _ := json.Marshal(follow)
+		req, _ := http.NewRequest(http.MethodPost, inbox, bytes.NewReader(body))
+		req.Header.Set("Date", time.Now().Add(-24*time.Hour).UTC().Format(http.TimeFormat))
+		key, _ := ParsePrivateKey(remote.keyPEM)
+		assert.NoError(t, SignRequest(req, remote.actor.PublicKey.ID, key, body))
+		assert.ErrorIs(t, f.HandleInbox(ctx, "alice", req, body), ErrInvalidSignature)
+	})
+
+	followers, _ := users.GetFollowers(ctx, alice.ID.Hex())
+	assert.Empty(t, followers)
+}
+
+func TestFederation_DeliverTweet(t *testing.T) {
+	f, users, _, _ := setupFederation(t)
+	ctx := context.Background()
+	alice := users.add("alice")
+	local := users.add("carol")
+	remote := newRemoteInstance(t)
+
+	// Un seguidor local y uno remoto: solo el remoto recibe la entrega
+	assert.NoError(t, users.FollowUser(ctx, local.ID.Hex(), alice.ID.Hex()))
+	bob, err := users.UpsertRemoteUser(ctx, "bob@remote", remoteActor(remote.actor))
+	assert.NoError(t, err)
+	assert.NoError(t, users.FollowUser(ctx, bob.ID.Hex(), alice.ID.Hex()))
+
+	tweet := &models.Tweet{ID: primitive.NewObjectID(), UserID: alice.ID, Content: "federado", CreatedAt: time.Now()}
+	assert.NoError(t, f.DeliverTweet(ctx, tweet))
+
+	received := remote.received()
+	assert.Len(t, received, 1)
+	assert.Equal(t, "Create", received[0].Type)
+	note := received[0].Object.(map[string]interface{})
+	assert.Equal(t, "Note", note["type"])
+	assert.Equal(t, "<p>federado</p>", note["content"])
+}
+
+func TestFederation_PublishTweetWait(t *testing.T) {
+	f, users, _, _ := setupFederation(t)
+	ctx := context.Background()
+	alice := users.add("alice")
+	remote := newRemoteInstance(t)
+	bob, err := users.UpsertRemoteUser(ctx, "bob@remote", remoteActor(remote.actor))
+	assert.NoError(t, err)
+	assert.NoError(t, users.FollowUser(ctx, bob.ID.Hex(), alice.ID.Hex()))
+
+	release := make(chan struct{})
+	remote.verify = func(r *http.Request, body []byte) error {
+		<-release
+		return nil
+	}
+
+	f.PublishTweet(ctx, &models.Tweet{ID: primitive.NewObjectID(), UserID: alice.ID, Content: "en vuelo", CreatedAt: time.Now()})
+
+	// Mientras la entrega sigue en curso, Wait respeta el plazo
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, f.Wait(short), context.DeadlineExceeded)
+
+	close(release)
+	assert.NoError(t, f.Wait(ctx))
+	assert.Len(t, remote.received(), 1)
+}
+
+func TestClient_RejectsUnsafeActors(t *testing.T) {
+	ctx := context.Background()
+	remote := newRemoteInstance(t)
+	client := testClient(t)
+
+	t.Run("plain http", func(t *testing.T) {
+		_, err := client.FetchActor(ctx, "http://127.0.0.1/users/bob")
+		assert.ErrorIs(t, err, ErrUnsafeURL)
+		assert.ErrorIs(t, client.Deliver(ctx, "http://127.0.0.1/inbox", map[string]string{}, "k", nil), ErrUnsafeURL)
+	})
+
+	t.Run("inbox on another host", func(t *testing.T) {
+		remote.actor.Inbox = "https://internal.example/admin"
+		_, err := client.FetchActor(ctx, remote.actor.ID)
+		assert.ErrorIs(t, err, ErrUnsafeURL)
+	})
+
+	t.Run("private addresses are refused at dial time", func(t *testing.T) {
+		for _, address := range []string{"127.0.0.1:443", "10.0.0.8:443", "192.168.1.1:80", "169.254.169.254:80", "[::1]:443", "[fe80::1]:443", "0.0.0.0:443"} {
+			assert.Error(t, publicOnly("tcp", address, nil), address)
+		}
+		assert.NoError(t, publicOnly("tcp", "93.184.216.34:443", nil))
+
+		_, err := NewClient(nil).FetchActor(ctx, remote.actor.ID)
+		assert.ErrorContains(t, err, "no pública")
+	})
+}
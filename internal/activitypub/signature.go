@@ -0,0 +1,276 @@
+// internal/activitypub/signature.go
+package activitypub
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+)
+
+// Implementación de HTTP Signatures (draft-cavage-http-signatures-12) con
+// rsa-sha256, que es la variante que usan Mastodon y el resto del fediverso.
+
+const (
+	signatureAlgorithm = "rsa-sha256"
+	keyBits            = 2048
+
+	// maxClockSkew es la diferencia máxima aceptada entre la cabecera Date y el reloj local
+	maxClockSkew = 12 * time.Hour
+)
+
+var (
+	ErrMissingSignature = apperr.Unauthorized("missing_signature", "la petición no está firmada")
+	ErrInvalidSignature = apperr.Unauthorized("invalid_signature", "firma HTTP inválida")
+)
+
+// Signature representa la cabecera Signature ya interpretada
+type Signature struct {
+	KeyID     string
+	Algorithm string
+	Headers   []string
+	Value     []byte
+}
+
+// GenerateKeyPair genera un par de claves RSA codificado en PEM (pública PKIX, privada PKCS#8)
+func GenerateKeyPair() (publicPEM, privatePEM string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return "", "", err
+	}
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", "", err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	publicPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+	privatePEM = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}))
+	return publicPEM, privatePEM, nil
+}
+
+// ParsePrivateKey decodifica una clave privada RSA en PEM (PKCS#8 o PKCS#1)
+func ParsePrivateKey(privatePEM string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, errors.New("clave privada PEM inválida")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("la clave privada no es RSA")
+	}
+	return key, nil
+}
+
+// ParsePublicKey decodifica una clave pública RSA en PEM (PKIX o PKCS#1)
+func ParsePublicKey(publicPEM string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicPEM))
+	if block == nil {
+		return nil, errors.New("clave pública PEM inválida")
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("la clave pública no es RSA")
+	}
+	return key, nil
+}
+
+// Digest calcula el valor de la cabecera Digest para el cuerpo dado
+func Digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// SignRequest firma la petición saliente. Completa las cabeceras Date y, si hay
+// cuerpo, Digest, que forman parte de la cadena firmada junto con el host.
+func SignRequest(r *http.Request, keyID string, key *rsa.PrivateKey, body []byte) error {
+	if r.Header.Get("Date") == "" {
+		r.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+
+	headers := []string{"(request-target)", "host", "date"}
+	if body != nil {
+		r.Header.Set("Digest", Digest(body))
+		headers = append(headers, "digest")
+	}
+
+	signingString, err := buildSigningString(r, headers)
+	if err != nil {
+		return err
+	}
+	hashed := sha256.Sum256([]byte(signingString))
+	value, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Signature", fmt.Sprintf(`keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+		keyID, signatureAlgorithm, strings.Join(headers, " "), base64.StdEncoding.EncodeToString(value)))
+	return nil
+}
+
+// ParseSignature interpreta la cabecera Signature de la petición
+func ParseSignature(r *http.Request) (*Signature, error) {
+	header := r.Header.Get("Signature")
+	if header == "" {
+		return nil, ErrMissingSignature
+	}
+
+	params := make(map[string]string)
+	for _, part := range splitParams(header) {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		params[strings.TrimSpace(name)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+
+	sig := &Signature{
+		KeyID:     params["keyId"],
+		Algorithm: params["algorithm"],
+		Headers:   strings.Fields(params["headers"]),
+	}
+	if sig.KeyID == "" || params["signature"] == "" {
+		return nil, fmt.Errorf("%w: faltan keyId o signature", ErrInvalidSignature)
+	}
+	if len(sig.Headers) == 0 {
+		// Valor por defecto según el borrador
+		sig.Headers = []string{"date"}
+	}
+
+	value, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return nil, fmt.Errorf("%w: firma no es base64", ErrInvalidSignature)
+	}
+	sig.Value = value
+	return sig, nil
+}
+
+// Verify comprueba la firma de la petición entrante con la clave pública del actor.
+// Exige que la firma cubra (request-target), host y date, y digest cuando hay cuerpo.
+func (s *Signature) Verify(r *http.Request, body []byte, key *rsa.PublicKey, now time.Time) error {
+	if s.Algorithm != "" && s.Algorithm != signatureAlgorithm && s.Algorithm != "hs2019" {
+		return fmt.Errorf("%w: algoritmo %q no soportado", ErrInvalidSignature, s.Algorithm)
+	}
+
+	required := []string{"(request-target)", "host", "date"}
+	if len(body) > 0 {
+		required = append(required, "digest")
+	}
+	for _, h := range required {
+		if !containsFold(s.Headers, h) {
+			return fmt.Errorf("%w: la firma no cubre %q", ErrInvalidSignature, h)
+		}
+	}
+
+	date, err := http.ParseTime(r.Header.Get("Date"))
+	if err != nil {
+		return fmt.Errorf("%w: cabecera Date inválida", ErrInvalidSignature)
+	}
+	if skew := now.Sub(date); skew > maxClockSkew || skew < -maxClockSkew {
+		return fmt.Errorf("%w: cabecera Date fuera de rango", ErrInvalidSignature)
+	}
+
+	if len(body) > 0 && r.Header.Get("Digest") != Digest(body) {
+		return fmt.Errorf("%w: el Digest no coincide con el cuerpo", ErrInvalidSignature)
+	}
+
+	signingString, err := buildSigningString(r, s.Headers)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	hashed := sha256.Sum256([]byte(signingString))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], s.Value); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// buildSigningString construye la cadena a firmar con las cabeceras indicadas
+func buildSigningString(r *http.Request, headers []string) (string, error) {
+	lines := make([]string, 0, len(headers))
+	for _, h := range headers {
+		h = strings.ToLower(h)
+		switch h {
+		case "(request-target)":
+			lines = append(lines, fmt.Sprintf("(request-target): %s %s", strings.ToLower(r.Method), r.URL.RequestURI()))
+		case "host":
+			host := r.Host
+			if host == "" {
+				host = r.URL.Host
+			}
+			lines = append(lines, "host: "+host)
+		default:
+			values := r.Header.Values(h)
+			if len(values) == 0 {
+				return "", fmt.Errorf("falta la cabecera firmada %q", h)
+			}
+			lines = append(lines, h+": "+strings.Join(values, ", "))
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// splitParams separa los parámetros de la cabecera respetando las comas dentro de comillas
+func splitParams(header string) []string {
+	var (
+		parts   []string
+		current strings.Builder
+		quoted  bool
+	)
+	for _, ch := range header {
+		switch {
+		case ch == '"':
+			quoted = !quoted
+			current.WriteRune(ch)
+		case ch == ',' && !quoted:
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(ch)
+		}
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+func containsFold(values []string, target string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+// internal/activitypub/signature_test.go
+package activitypub
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature_RoundTrip(t *testing.T) {
+	pub, priv, err := GenerateKeyPair()
+	require.NoError(t, err)
+	privateKey, err := ParsePrivateKey(priv)
+	require.NoError(t, err)
+	publicKey, err := ParsePublicKey(pub)
+	require.NoError(t, err)
+
+	body := []byte(`{"type":"Follow"}`)
+	req, err := http.NewRequest(http.MethodPost, "https://local.test/users/alice/inbox?x=1", bytes.NewReader(body))
+	require.NoError(t, err)
+	require.NoError(t, SignRequest(req, "https://remote.test/users/bob#main-key", privateKey, body))
+
+	sig, err := ParseSignature(req)
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote.test/users/bob#main-key", sig.KeyID)
+	assert.Equal(t, []string{"(request-target)", "host", "date", "digest"}, sig.Headers)
+
+	t.Run("valid signature", func(t *testing.T) {
+		assert.NoError(t, sig.Verify(req, body, publicKey, time.Now()))
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		otherPub, _, err := GenerateKeyPair()
+		require.NoError(t, err)
+		otherKey, _ := ParsePublicKey(otherPub)
+		assert.ErrorIs(t, sig.Verify(req, body, otherKey, time.Now()), ErrInvalidSignature)
+	})
+
+	t.Run("different path", func(t *testing.T) {
+		moved := req.Clone(req.Context())
+		moved.URL.Path = "/users/carol/inbox"
+		assert.ErrorIs(t, sig.Verify(moved, body, publicKey, time.Now()), ErrInvalidSignature)
+	})
+
+	t.Run("missing digest coverage", func(t *testing.T) {
+		partial := *sig
+		partial.Headers = []string{"(request-target)", "host", "date"}
+		assert.ErrorIs(t, partial.Verify(req, body, publicKey, time.Now()), ErrInvalidSignature)
+	})
+}
+
+func TestParseSignature(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://local.test/", nil)
+
+	_, err := ParseSignature(req)
+	assert.ErrorIs(t, err, ErrMissingSignature)
+
+	req.Header.Set("Signature", `keyId="https://a.test/u#k,1",algorithm="rsa-sha256",headers="date",signature="c2ln"`)
+	sig, err := ParseSignature(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://a.test/u#k,1", sig.KeyID)
+	assert.Equal(t, []byte("sig"), sig.Value)
+
+	req.Header.Set("Signature", `algorithm="rsa-sha256"`)
+	_, err = ParseSignature(req)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
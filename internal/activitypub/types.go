@@ -0,0 +1,117 @@
+// internal/activitypub/types.go
+package activitypub
+
+const (
+	// ContentType es el tipo de contenido de los documentos ActivityPub
+	ContentType = "application/activity+json"
+	// JRDContentType es el tipo de contenido de las respuestas WebFinger
+	JRDContentType = "application/jrd+json"
+
+	// PublicAddress es la colección especial que representa "público"
+	PublicAddress = "https://www.w3.org/ns/activitystreams#Public"
+
+	activityStreamsContext = "https://www.w3.org/ns/activitystreams"
+	securityContext        = "https://w3id.org/security/v1"
+)
+
+// Actor representa a un usuario como actor ActivityPub (tipo Person)
+type Actor struct {
+	Context           interface{} `json:"@context,omitempty"`
+	ID                string      `json:"id"`
+	Type              string      `json:"type"`
+	PreferredUsername string      `json:"preferredUsername"`
+	Name              string      `json:"name,omitempty"`
+	URL               string      `json:"url,omitempty"`
+	Published         string      `json:"published,omitempty"`
+	Inbox             string      `json:"inbox"`
+	Outbox            string      `json:"outbox,omitempty"`
+	Followers         string      `json:"followers,omitempty"`
+	Following         string      `json:"following,omitempty"`
+	Endpoints         *Endpoints  `json:"endpoints,omitempty"`
+	PublicKey         PublicKey   `json:"publicKey"`
+}
+
+// Endpoints contiene los endpoints opcionales de un actor
+type Endpoints struct {
+	SharedInbox string `json:"sharedInbox,omitempty"`
+}
+
+// PublicKey es la clave con la que se verifican las firmas HTTP del actor
+type PublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPEM string `json:"publicKeyPem"`
+}
+
+// Activity representa una actividad (Follow, Undo, Accept, Create...).
+// Object puede ser un IRI (string) o un objeto embebido.
+type Activity struct {
+	Context   interface{} `json:"@context,omitempty"`
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Actor     string      `json:"actor"`
+	Object    interface{} `json:"object"`
+	Published string      `json:"published,omitempty"`
+	To        []string    `json:"to,omitempty"`
+	Cc        []string    `json:"cc,omitempty"`
+}
+
+// Note representa el contenido de un tweet
+type Note struct {
+	ID           string   `json:"id"`
+	Type         string   `json:"type"`
+	AttributedTo string   `json:"attributedTo"`
+	Content      string   `json:"content"`
+	Published    string   `json:"published"`
+	URL          string   `json:"url,omitempty"`
+	To           []string `json:"to"`
+	Cc           []string `json:"cc,omitempty"`
+}
+
+// OrderedCollection se usa para el outbox y la lista de seguidores
+type OrderedCollection struct {
+	Context      interface{}   `json:"@context,omitempty"`
+	ID           string        `json:"id"`
+	Type         string        `json:"type"`
+	TotalItems   int           `json:"totalItems"`
+	OrderedItems []interface{} `json:"orderedItems"`
+}
+
+// WebFinger es la respuesta JRD de /.well-known/webfinger (RFC 7033)
+type WebFinger struct {
+	Subject string          `json:"subject"`
+	Aliases []string        `json:"aliases,omitempty"`
+	Links   []WebFingerLink `json:"links"`
+}
+
+// WebFingerLink es un enlace dentro de una respuesta WebFinger
+type WebFingerLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href,omitempty"`
+}
+
+// objectID devuelve el IRI de un objeto, sea una referencia o un objeto embebido
+func objectID(object interface{}) string {
+	switch o := object.(type) {
+	case string:
+		return o
+	case map[string]interface{}:
+		id, _ := o["id"].(string)
+		return id
+	}
+	return ""
+}
+
+// embeddedActivity interpreta un objeto embebido como actividad (p. ej. el Follow de un Undo)
+func embeddedActivity(object interface{}) (*Activity, bool) {
+	o, ok := object.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	activity := &Activity{Object: o["object"]}
+	activity.ID, _ = o["id"].(string)
+	activity.Type, _ = o["type"].(string)
+	activity.Actor, _ = o["actor"].(string)
+	return activity, true
+}
@@ -0,0 +1,168 @@
+// internal/apperr/apperr.go
+package apperr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind clasifica un error de dominio; determina el estado HTTP con que se responde
+type Kind int
+
+const (
+	// KindInternal es un error inesperado; su detalle nunca se muestra al cliente
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindTooLarge
+	KindUnsupportedMedia
+	KindUnavailable
+	KindTooManyRequests
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindValidation:
+		return "validation"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindForbidden:
+		return "forbidden"
+	case KindNotFound:
+		return "not_found"
+	case KindConflict:
+		return "conflict"
+	case KindTooLarge:
+		return "too_large"
+	case KindUnsupportedMedia:
+		return "unsupported_media"
+	case KindUnavailable:
+		return "unavailable"
+	case KindTooManyRequests:
+		return "too_many_requests"
+	default:
+		return "internal"
+	}
+}
+
+// FieldError describe un campo inválido de la petición
+type FieldError struct {
+	Field string `json:"field"`
+	// Code es la regla que no se cumplió (required, max, email...)
+	Code    string `json:"code"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error es un error de dominio con un código estable. Message es seguro para
+// mostrarlo al cliente; la causa solo aparece en Error() para los logs.
+// Params son los valores que los catálogos de mensajes interpolan ({max}, {min}...).
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	Params  map[string]string
+	cause   error
+}
+
+// New crea un error de dominio. Code debe ser estable: los clientes lo usan
+// para distinguir errores sin depender del texto.
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func Validation(code, message string, fields ...FieldError) *Error {
+	e := New(KindValidation, code, message)
+	e.Fields = fields
+	return e
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func Unavailable(code, message string) *Error {
+	return New(KindUnavailable, code, message)
+}
+
+// InvalidField crea un error de validación sobre un único campo
+func InvalidField(code, field, message string) *Error {
+	return Validation(code, message, FieldError{Field: field, Code: code, Message: message})
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is compara por tipo y código, de modo que errors.Is reconoce un error
+// aunque se haya creado con Wrap a partir del centinela
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// Wrap devuelve una copia del error con la causa original, que queda disponible
+// para errors.Is/As y para los logs
+func (e *Error) Wrap(cause error) *Error {
+	copied := *e
+	copied.cause = cause
+	return &copied
+}
+
+// WithFields devuelve una copia del error con el detalle de los campos inválidos
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	copied := *e
+	copied.Fields = append(append([]FieldError(nil), e.Fields...), fields...)
+	return &copied
+}
+
+// WithParam devuelve una copia del error con un valor más para los mensajes localizados
+func (e *Error) WithParam(key string, value interface{}) *Error {
+	copied := *e
+	copied.Params = make(map[string]string, len(e.Params)+1)
+	for k, v := range e.Params {
+		copied.Params[k] = v
+	}
+	copied.Params[key] = fmt.Sprint(value)
+	return &copied
+}
+
+// As devuelve el primer error de dominio de la cadena de err
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// KindOf devuelve el tipo del error; los errores sin tipo son KindInternal
+func KindOf(err error) Kind {
+	if e, ok := As(err); ok {
+		return e.Kind
+	}
+	return KindInternal
+}
@@ -0,0 +1,49 @@
+// internal/apperr/apperr_test.go
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError_IsAndWrap(t *testing.T) {
+	notFound := NotFound("user_not_found", "usuario no encontrado")
+	cause := errors.New("mongo: no documents in result")
+
+	wrapped := notFound.Wrap(cause)
+	assert.ErrorIs(t, wrapped, notFound)
+	assert.ErrorIs(t, wrapped, cause)
+	assert.Equal(t, "usuario no encontrado: mongo: no documents in result", wrapped.Error())
+	// Wrap no modifica el centinela
+	assert.Equal(t, "usuario no encontrado", notFound.Error())
+
+	assert.NotErrorIs(t, wrapped, NotFound("tweet_not_found", "tweet no encontrado"))
+	assert.NotErrorIs(t, wrapped, Conflict("user_not_found", "otro tipo"))
+}
+
+func TestAs(t *testing.T) {
+	conflict := Conflict("user_exists", "ya existe")
+	err := fmt.Errorf("crear usuario: %w", conflict)
+
+	e, ok := As(err)
+	assert.True(t, ok)
+	assert.Equal(t, "user_exists", e.Code)
+	assert.Equal(t, KindConflict, KindOf(err))
+
+	_, ok = As(errors.New("sin tipo"))
+	assert.False(t, ok)
+	assert.Equal(t, KindInternal, KindOf(errors.New("sin tipo")))
+}
+
+func TestValidationFields(t *testing.T) {
+	e := InvalidField("invalid_id", "id", "ID inválido")
+	assert.Equal(t, KindValidation, e.Kind)
+	assert.Equal(t, []FieldError{{Field: "id", Code: "invalid_id", Message: "ID inválido"}}, e.Fields)
+
+	extended := e.WithFields(FieldError{Field: "tag", Code: "required", Message: "requerido"})
+	assert.Len(t, extended.Fields, 2)
+	assert.Len(t, e.Fields, 1)
+}
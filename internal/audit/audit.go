@@ -0,0 +1,147 @@
+// internal/audit/audit.go
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/logging"
+	"github.com/ffelixf/microblog-platform/internal/models"
+)
+
+// Source es el origen de la petición que se guarda con cada registro
+type Source struct {
+	ActorID   string
+	IP        string
+	UserAgent string
+}
+
+type sourceKey struct{}
+
+// WithSource devuelve un contexto con el origen de la petición
+func WithSource(ctx context.Context, source Source) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// SourceFrom devuelve el origen de la petición del contexto; vacío fuera de una petición
+func SourceFrom(ctx context.Context) Source {
+	source, _ := ctx.Value(sourceKey{}).(Source)
+	return source
+}
+
+// Store guarda los registros encadenándolos al último
+type Store interface {
+	Append(ctx context.Context, entry *models.AuditEntry) error
+}
+
+// Logger completa los registros con el origen de la petición y los guarda. Un
+// fallo al guardar se registra en el log pero no deshace la acción auditada, que
+// ya ocurrió.
+type Logger struct {
+	store Store
+	now   func() time.Time
+}
+
+func NewLogger(store Store) *Logger {
+	return &Logger{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Record guarda entry. Sin ActorID se usa el usuario de la petición y, fuera de
+// una petición, models.AuditActorSystem.
+func (l *Logger) Record(ctx context.Context, entry models.AuditEntry) {
+	source := SourceFrom(ctx)
+	if entry.ActorID == "" {
+		entry.ActorID = source.ActorID
+	}
+	if entry.ActorID == "" {
+		entry.ActorID = models.AuditActorSystem
+	}
+	entry.IP = source.IP
+	entry.UserAgent = source.UserAgent
+	entry.RequestID = logging.RequestID(ctx)
+	entry.CreatedAt = l.now()
+
+	// La acción ya se hizo: el registro se guarda aunque la petición se cancele
+	if err := l.store.Append(context.WithoutCancel(ctx), &entry); err != nil {
+		slog.ErrorContext(ctx, "error al guardar registro de auditoría",
+			slog.String("action", entry.Action),
+			slog.String("target_id", entry.TargetID),
+			slog.Any("error", err))
+		return
+	}
+	// La cabeza de la cadena queda también en el log, fuera de la base de datos
+	slog.InfoContext(ctx, "registro de auditoría guardado",
+		slog.String("action", entry.Action),
+		slog.Int64("seq", entry.Seq),
+		slog.String("hash", entry.Hash))
+}
+
+// Diff compara dos estados campo a campo y devuelve solo los campos que cambiaron,
+// antes y después. Un campo ausente en uno de los dos estados cuenta como nil.
+func Diff(before, after map[string]any) (models.AuditState, models.AuditState) {
+	changedBefore := map[string]any{}
+	changedAfter := map[string]any{}
+	for field, value := range before {
+		if !equalJSON(value, after[field]) {
+			changedBefore[field] = value
+			changedAfter[field] = after[field]
+		}
+	}
+	for field, value := range after {
+		if _, ok := before[field]; !ok && !equalJSON(nil, value) {
+			changedBefore[field] = nil
+			changedAfter[field] = value
+		}
+	}
+	return State(changedBefore), State(changedAfter)
+}
+
+// State serializa un estado; un estado vacío no se guarda
+func State(values map[string]any) models.AuditState {
+	if len(values) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil
+	}
+	return models.AuditState(data)
+}
+
+// equalJSON compara dos valores por su representación JSON, de modo que una lista
+// nil y una vacía, o dos fechas iguales en distinta zona, no cuentan como cambio
+func equalJSON(a, b any) bool {
+	var ja, jb any
+	if !roundTrip(a, &ja) || !roundTrip(b, &jb) {
+		return reflect.DeepEqual(a, b)
+	}
+	return reflect.DeepEqual(emptyToNil(ja), emptyToNil(jb))
+}
+
+func roundTrip(value any, out *any) bool {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, out) == nil
+}
+
+func emptyToNil(value any) any {
+	switch v := value.(type) {
+	case []any:
+		if len(v) == 0 {
+			return nil
+		}
+	case map[string]any:
+		if len(v) == 0 {
+			return nil
+		}
+	}
+	return value
+}
@@ -0,0 +1,174 @@
+// internal/audit/audit_test.go
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/logging"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryChain encadena los registros en memoria como lo hace el repositorio
+type memoryChain struct {
+	entries []models.AuditEntry
+	err     error
+}
+
+func (m *memoryChain) Append(ctx context.Context, entry *models.AuditEntry) error {
+	if m.err != nil {
+		return m.err
+	}
+	entry.CreatedAt = entry.CreatedAt.UTC().Truncate(time.Millisecond)
+	entry.Seq, entry.PrevHash = int64(len(m.entries))+1, ""
+	if len(m.entries) > 0 {
+		entry.PrevHash = m.entries[len(m.entries)-1].Hash
+	}
+	entry.Hash = entry.ComputeHash()
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func (m *memoryChain) Walk(ctx context.Context, fn func(entry *models.AuditEntry) error) error {
+	for i := range m.entries {
+		entry := m.entries[i]
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newChain(t *testing.T, n int) *memoryChain {
+	chain := &memoryChain{}
+	logger := NewLogger(chain)
+	for i := 0; i < n; i++ {
+		before, after := Diff(map[string]any{"roles": []string{}}, map[string]any{"roles": []string{"moderator"}})
+		logger.Record(context.Background(), models.AuditEntry{
+			Action:     models.AuditRolesChanged,
+			TargetType: models.AuditTargetUser,
+			TargetID:   "u1",
+			Before:     before,
+			After:      after,
+		})
+	}
+	require.Len(t, chain.entries, n)
+	return chain
+}
+
+func TestLogger_Record(t *testing.T) {
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	ctx = WithSource(ctx, Source{ActorID: "admin", IP: "10.0.0.1", UserAgent: "curl/8"})
+	chain := &memoryChain{}
+
+	NewLogger(chain).Record(ctx, models.AuditEntry{Action: models.AuditUserSuspended, TargetID: "u1"})
+	NewLogger(chain).Record(context.Background(), models.AuditEntry{Action: models.AuditUserDeleted, TargetID: "u1"})
+
+	require.Len(t, chain.entries, 2)
+	first := chain.entries[0]
+	assert.Equal(t, "admin", first.ActorID)
+	assert.Equal(t, "10.0.0.1", first.IP)
+	assert.Equal(t, "curl/8", first.UserAgent)
+	assert.Equal(t, "req-1", first.RequestID)
+	assert.Equal(t, models.AuditActorSystem, chain.entries[1].ActorID, "fuera de una petición actúa el sistema")
+
+	t.Run("store errors do not panic", func(t *testing.T) {
+		failing := &memoryChain{err: errors.New("sin conexión")}
+		NewLogger(failing).Record(ctx, models.AuditEntry{Action: models.AuditUserDeleted})
+		assert.Empty(t, failing.entries)
+	})
+}
+
+func TestDiff(t *testing.T) {
+	before, after := Diff(
+		map[string]any{"roles": []string{"moderator"}, "permissions": []string(nil), "reason": "spam"},
+		map[string]any{"roles": []string{"admin"}, "permissions": []string{}, "expires_at": "2024-07-01T00:00:00Z"},
+	)
+	assert.JSONEq(t, `{"roles":["moderator"],"reason":"spam","expires_at":null}`, string(before))
+	assert.JSONEq(t, `{"roles":["admin"],"reason":null,"expires_at":"2024-07-01T00:00:00Z"}`, string(after))
+
+	before, after = Diff(map[string]any{"roles": nil}, map[string]any{"roles": []string{}})
+	assert.Nil(t, before, "sin cambios no hay estado")
+	assert.Nil(t, after)
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("intact chain", func(t *testing.T) {
+		chain := newChain(t, 5)
+		result, err := Verify(ctx, chain, nil)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), result.Entries)
+		assert.Equal(t, chain.entries[4].Hash, result.LastHash)
+	})
+
+	t.Run("checkpoint behind the head", func(t *testing.T) {
+		// El punto de control se actualiza después de insertar y puede quedar atrás
+		chain := newChain(t, 5)
+		checkpoint := &models.AuditCheckpoint{Seq: 3, Hash: chain.entries[2].Hash}
+		result, err := Verify(ctx, chain, checkpoint)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), result.Entries)
+	})
+
+	tampered := []struct {
+		name   string
+		tamper func(c *memoryChain)
+		seq    int64
+	}{
+		{"modified field", func(c *memoryChain) { c.entries[2].ActorID = "otro" }, 3},
+		{"modified diff", func(c *memoryChain) { c.entries[1].After = models.AuditState(`{"roles":["admin"]}`) }, 2},
+		{"rehashed entry", func(c *memoryChain) {
+			c.entries[2].TargetID = "u2"
+			c.entries[2].Hash = c.entries[2].ComputeHash()
+		}, 4},
+		{"deleted entry", func(c *memoryChain) { c.entries = append(c.entries[:1], c.entries[2:]...) }, 2},
+		{"reordered entries", func(c *memoryChain) { c.entries[1], c.entries[2] = c.entries[2], c.entries[1] }, 2},
+	}
+	for _, tc := range tampered {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := newChain(t, 5)
+			tc.tamper(chain)
+			_, err := Verify(ctx, chain, nil)
+			var chainErr *ChainError
+			require.ErrorAs(t, err, &chainErr)
+			assert.Equal(t, tc.seq, chainErr.Seq)
+		})
+	}
+
+	checkpointed := []struct {
+		name   string
+		tamper func(c *memoryChain)
+		seq    int64
+	}{
+		{"truncated tail", func(c *memoryChain) { c.entries = c.entries[:3] }, 4},
+		{"emptied chain", func(c *memoryChain) { c.entries = nil }, 1},
+		{"rewritten tail", func(c *memoryChain) {
+			// Borrar los últimos registros y encadenar otros no engaña al punto de control
+			c.entries = c.entries[:3]
+			for i := 0; i < 2; i++ {
+				entry := models.AuditEntry{Action: models.AuditUserDeleted, TargetID: "u9"}
+				require.NoError(t, c.Append(ctx, &entry))
+			}
+		}, 5},
+	}
+	for _, tc := range checkpointed {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := newChain(t, 5)
+			checkpoint := &models.AuditCheckpoint{Seq: 5, Hash: chain.entries[4].Hash}
+			tc.tamper(chain)
+
+			_, err := Verify(ctx, chain, nil)
+			require.NoError(t, err, "sin punto de control la cadena parece íntegra")
+			_, err = Verify(ctx, chain, checkpoint)
+			var chainErr *ChainError
+			require.ErrorAs(t, err, &chainErr)
+			assert.Equal(t, tc.seq, chainErr.Seq)
+		})
+	}
+}
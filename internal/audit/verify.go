@@ -0,0 +1,63 @@
+// internal/audit/verify.go
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+)
+
+// Walker recorre los registros en orden de la cadena
+type Walker interface {
+	Walk(ctx context.Context, fn func(entry *models.AuditEntry) error) error
+}
+
+// ChainError indica el primer registro donde la cadena no cuadra
+type ChainError struct {
+	Seq    int64
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("cadena de auditoría rota en el registro %d: %s", e.Seq, e.Reason)
+}
+
+// Result es el resumen de una verificación correcta
+type Result struct {
+	Entries  int64
+	LastHash string
+}
+
+// Verify recorre la cadena desde el primer registro y comprueba que las
+// posiciones sean consecutivas, que cada registro apunte al hash del anterior y
+// que su hash corresponda a su contenido. Con checkpoint comprueba además que la
+// cadena llegue hasta el punto de control y pase por su hash, lo que detecta que
+// se borraron los últimos registros. Devuelve *ChainError en el primer registro
+// alterado, borrado o insertado.
+func Verify(ctx context.Context, walker Walker, checkpoint *models.AuditCheckpoint) (Result, error) {
+	var result Result
+	err := walker.Walk(ctx, func(entry *models.AuditEntry) error {
+		expected := result.Entries + 1
+		switch {
+		case entry.Seq != expected:
+			return &ChainError{Seq: expected, Reason: fmt.Sprintf("se esperaba la posición %d y sigue la %d", expected, entry.Seq)}
+		case entry.PrevHash != result.LastHash:
+			return &ChainError{Seq: entry.Seq, Reason: "no apunta al hash del registro anterior"}
+		case entry.Hash != entry.ComputeHash():
+			return &ChainError{Seq: entry.Seq, Reason: "el contenido no corresponde a su hash"}
+		case checkpoint != nil && entry.Seq == checkpoint.Seq && entry.Hash != checkpoint.Hash:
+			return &ChainError{Seq: entry.Seq, Reason: "no corresponde al hash del punto de control"}
+		}
+		result.Entries++
+		result.LastHash = entry.Hash
+		return nil
+	})
+	if err == nil && checkpoint != nil && result.Entries < checkpoint.Seq {
+		return result, &ChainError{
+			Seq:    result.Entries + 1,
+			Reason: fmt.Sprintf("faltan registros: el punto de control está en la posición %d", checkpoint.Seq),
+		}
+	}
+	return result, err
+}
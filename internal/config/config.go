@@ -0,0 +1,382 @@
+// internal/config/config.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/i18n"
+	"github.com/ffelixf/microblog-platform/internal/logging"
+	"github.com/ffelixf/microblog-platform/internal/ratelimit"
+)
+
+// Config es la configuración completa de la API. Cada campo se puede definir en
+// el archivo YAML (clave yaml), en .env o en el entorno (clave env); ver Load
+// para el orden de precedencia. Los campos con secret no se muestran nunca.
+type Config struct {
+	Server    Server    `yaml:"server"`
+	Mongo     Mongo     `yaml:"mongodb"`
+	Log       Log       `yaml:"log"`
+	Tracing   Tracing   `yaml:"tracing"`
+	Media     Media     `yaml:"media"`
+	Health    Health    `yaml:"health"`
+	RateLimit RateLimit `yaml:"rate_limit"`
+	Policy    Policy    `yaml:"policy"`
+	Export    Export    `yaml:"export"`
+	GraphQL   GraphQL   `yaml:"graphql"`
+	GRPC      GRPC      `yaml:"grpc"`
+
+	// PublicBaseURL es la URL pública de la API, usada en feeds y en ActivityPub
+	PublicBaseURL string `yaml:"public_base_url" env:"PUBLIC_BASE_URL"`
+	// DefaultLanguage es el idioma de los errores cuando no hay Accept-Language
+	DefaultLanguage string `yaml:"default_language" env:"DEFAULT_LANGUAGE"`
+}
+
+// Server agrupa el puerto, los timeouts HTTP y el apagado ordenado
+type Server struct {
+	Port              int           `yaml:"port" env:"PORT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	// ReadTimeout incluye la lectura completa de las subidas de imágenes
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	DrainDelay      time.Duration `yaml:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY"`
+}
+
+// Mongo es la conexión a MongoDB
+type Mongo struct {
+	// URI puede incluir credenciales; al mostrarla solo se ocultan usuario y clave
+	URI                    string        `yaml:"uri" env:"MONGODB_URI" secret:"uri"`
+	Database               string        `yaml:"database" env:"MONGODB_DATABASE"`
+	MaxPoolSize            uint64        `yaml:"max_pool_size" env:"MONGODB_MAX_POOL_SIZE"`
+	MinPoolSize            uint64        `yaml:"min_pool_size" env:"MONGODB_MIN_POOL_SIZE"`
+	ConnectTimeout         time.Duration `yaml:"connect_timeout" env:"MONGODB_CONNECT_TIMEOUT"`
+	ServerSelectionTimeout time.Duration `yaml:"server_selection_timeout" env:"MONGODB_SERVER_SELECTION_TIMEOUT"`
+}
+
+type Log struct {
+	// Level es debug, info, warn o error
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+}
+
+type Tracing struct {
+	// Exporter es none, otlp, stdout o file; el destino OTLP se toma de las
+	// variables estándar OTEL_EXPORTER_OTLP_*
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER"`
+	File        string  `yaml:"file" env:"TRACING_FILE"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+}
+
+// Media configura el almacenamiento y el tamaño máximo de los archivos adjuntos
+type Media struct {
+	// Storage es local o s3
+	Storage  string `yaml:"storage" env:"MEDIA_STORAGE"`
+	Dir      string `yaml:"dir" env:"MEDIA_STORAGE_DIR"`
+	MaxBytes int64  `yaml:"max_bytes" env:"MEDIA_MAX_BYTES"`
+	S3       S3     `yaml:"s3"`
+}
+
+type S3 struct {
+	Endpoint  string `yaml:"endpoint" env:"S3_ENDPOINT"`
+	Region    string `yaml:"region" env:"S3_REGION"`
+	Bucket    string `yaml:"bucket" env:"S3_BUCKET"`
+	AccessKey string `yaml:"access_key" env:"S3_ACCESS_KEY" secret:"true"`
+	SecretKey string `yaml:"secret_key" env:"S3_SECRET_KEY" secret:"true"`
+	PathStyle bool   `yaml:"path_style" env:"S3_PATH_STYLE"`
+}
+
+// Health configura las sondas /livez y /readyz
+type Health struct {
+	CacheTTL time.Duration `yaml:"cache_ttl" env:"HEALTH_CACHE_TTL"`
+	Timeout  time.Duration `yaml:"timeout" env:"HEALTH_TIMEOUT"`
+}
+
+// RateLimit configura los límites por grupo de rutas. Cada límite es un token
+// bucket escrito como "30/m"; "0" desactiva el límite del grupo.
+type RateLimit struct {
+	Enabled bool `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	// Store es memory (una sola instancia) o mongodb (compartido entre réplicas)
+	Store string `yaml:"store" env:"RATE_LIMIT_STORE"`
+	// Tweets limita la publicación y programación de tweets
+	Tweets ratelimit.Limit `yaml:"tweets" env:"RATE_LIMIT_TWEETS"`
+	// Media limita las subidas de archivos
+	Media ratelimit.Limit `yaml:"media" env:"RATE_LIMIT_MEDIA"`
+	// Write limita el resto de peticiones que modifican datos
+	Write ratelimit.Limit `yaml:"write" env:"RATE_LIMIT_WRITE"`
+	// Read limita las consultas
+	Read ratelimit.Limit `yaml:"read" env:"RATE_LIMIT_READ"`
+}
+
+// Policy configura las heurísticas de spam que se evalúan al publicar. Cada una
+// tiene una acción: none, flag (publicar y marcar), hold (retener hasta que se
+// apruebe) o reject. La lista de bloqueo se gestiona desde la API.
+// policy.ParseAction interpreta las acciones al construir las heurísticas.
+type Policy struct {
+	Duplicate PolicyDuplicate `yaml:"duplicate"`
+	Links     PolicyLinks     `yaml:"links"`
+	Burst     PolicyBurst     `yaml:"burst"`
+}
+
+// PolicyDuplicate detecta el mismo contenido publicado muchas veces
+type PolicyDuplicate struct {
+	Action string `yaml:"action" env:"POLICY_DUPLICATE_ACTION"`
+	// Threshold es cuántos tweets iguales previos dentro de Window disparan la regla
+	Threshold int           `yaml:"threshold" env:"POLICY_DUPLICATE_THRESHOLD"`
+	Window    time.Duration `yaml:"window" env:"POLICY_DUPLICATE_WINDOW"`
+}
+
+// PolicyLinks detecta tweets con demasiados enlaces
+type PolicyLinks struct {
+	Action string `yaml:"action" env:"POLICY_LINKS_ACTION"`
+	Max    int    `yaml:"max" env:"POLICY_LINKS_MAX"`
+}
+
+// PolicyBurst detecta cuentas nuevas que publican muchos tweets seguidos
+type PolicyBurst struct {
+	Action     string        `yaml:"action" env:"POLICY_BURST_ACTION"`
+	AccountAge time.Duration `yaml:"account_age" env:"POLICY_BURST_ACCOUNT_AGE"`
+	// MaxTweets es cuántos tweets previos dentro de Window disparan la regla
+	MaxTweets int           `yaml:"max_tweets" env:"POLICY_BURST_MAX_TWEETS"`
+	Window    time.Duration `yaml:"window" env:"POLICY_BURST_WINDOW"`
+}
+
+// policyActions son las acciones que acepta policy.ParseAction y si activan la
+// heurística
+var policyActions = map[string]bool{"": false, "none": false, "flag": true, "hold": true, "reject": true}
+
+// Export configura la exportación de datos personales
+type Export struct {
+	// SigningKey firma los enlaces de descarga; debe ser la misma en todas las
+	// réplicas. Sin ella se genera una al arrancar y los enlaces dejan de valer
+	// al reiniciar.
+	SigningKey string `yaml:"signing_key" env:"EXPORT_SIGNING_KEY" secret:"true"`
+	// LinkTTL es cuánto dura un enlace de descarga
+	LinkTTL time.Duration `yaml:"link_ttl" env:"EXPORT_LINK_TTL"`
+	// Retention es cuánto se guarda el archivo generado antes de borrarlo
+	Retention time.Duration `yaml:"retention" env:"EXPORT_RETENTION"`
+}
+
+// GraphQL acota las consultas de /graphql
+type GraphQL struct {
+	// MaxDepth es el anidamiento máximo de campos de una consulta
+	MaxDepth int `yaml:"max_depth" env:"GRAPHQL_MAX_DEPTH"`
+	// MaxComplexity es el costo máximo de una consulta: cada campo cuenta una
+	// vez por cada elemento que puede traer la conexión que lo contiene
+	MaxComplexity int `yaml:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY"`
+}
+
+// GRPC configura la API gRPC para los servicios internos
+type GRPC struct {
+	// Host es la dirección en la que escucha la API gRPC. Por defecto solo
+	// loopback; vacío escucha en todas las interfaces.
+	Host string `yaml:"host" env:"GRPC_HOST"`
+	// Port es el puerto de la API gRPC, distinto del de la API REST; 0, el valor
+	// por defecto, la desactiva
+	Port int `yaml:"port" env:"GRPC_PORT"`
+	// WatchInterval es cada cuánto WatchTimeline busca tweets nuevos
+	WatchInterval time.Duration `yaml:"watch_interval" env:"GRPC_WATCH_INTERVAL"`
+}
+
+// Default devuelve la configuración por defecto, sobre la que se aplican el
+// archivo y las variables de entorno. Los tiempos de apagado, health y gRPC son
+// los mismos que usan esos paquetes cuando no se configuran.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Port:              8080,
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   30 * time.Second,
+		},
+		Mongo: Mongo{
+			Database:               "microblog",
+			MaxPoolSize:            100,
+			ConnectTimeout:         10 * time.Second,
+			ServerSelectionTimeout: 10 * time.Second,
+		},
+		Log: Log{Level: "info"},
+		Tracing: Tracing{
+			Exporter:    "none",
+			SampleRatio: 1,
+			ServiceName: "microblog-api",
+		},
+		Media: Media{
+			Storage: "local",
+			Dir:     "./data/media",
+		},
+		Health: Health{
+			CacheTTL: 5 * time.Second,
+			Timeout:  2 * time.Second,
+		},
+		RateLimit: RateLimit{
+			Enabled: true,
+			Store:   "memory",
+			Tweets:  ratelimit.Limit{Requests: 30, Period: time.Minute},
+			Media:   ratelimit.Limit{Requests: 10, Period: time.Minute},
+			Write:   ratelimit.Limit{Requests: 60, Period: time.Minute},
+			Read:    ratelimit.Limit{Requests: 300, Period: time.Minute},
+		},
+		Policy: Policy{
+			Duplicate: PolicyDuplicate{Action: "hold", Threshold: 5, Window: 24 * time.Hour},
+			Links:     PolicyLinks{Action: "flag", Max: 3},
+			Burst: PolicyBurst{
+				Action:     "hold",
+				AccountAge: 24 * time.Hour,
+				MaxTweets:  10,
+				Window:     10 * time.Minute,
+			},
+		},
+		Export: Export{
+			LinkTTL:   15 * time.Minute,
+			Retention: 7 * 24 * time.Hour,
+		},
+		GraphQL: GraphQL{
+			MaxDepth:      10,
+			MaxComplexity: 5000,
+		},
+		GRPC: GRPC{
+			Host:          "127.0.0.1",
+			WatchInterval: 2 * time.Second,
+		},
+		DefaultLanguage: i18n.DefaultLanguage,
+	}
+}
+
+// Validate comprueba la configuración completa y devuelve todos los problemas a
+// la vez, cada uno con su clave YAML y su variable de entorno
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port", "debe estar entre 1 y 65535")
+	v.check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout", "debe ser mayor que cero")
+	v.nonNegative("server.read_timeout", c.Server.ReadTimeout)
+	v.nonNegative("server.write_timeout", c.Server.WriteTimeout)
+	v.nonNegative("server.idle_timeout", c.Server.IdleTimeout)
+	v.check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "debe ser mayor que cero")
+	v.nonNegative("server.drain_delay", c.Server.DrainDelay)
+
+	if c.Mongo.URI == "" {
+		v.fail("mongodb.uri", "es obligatoria")
+	} else if u, err := url.Parse(c.Mongo.URI); err != nil || (u.Scheme != "mongodb" && u.Scheme != "mongodb+srv") {
+		// El error de url.Parse incluye la URI completa, con sus credenciales
+		v.fail("mongodb.uri", "debe ser una URI mongodb:// o mongodb+srv://")
+	}
+	v.check(c.Mongo.Database != "", "mongodb.database", "es obligatoria")
+	v.check(c.Mongo.MaxPoolSize == 0 || c.Mongo.MinPoolSize <= c.Mongo.MaxPoolSize,
+		"mongodb.min_pool_size", "no puede ser mayor que mongodb.max_pool_size")
+	v.check(c.Mongo.ConnectTimeout > 0, "mongodb.connect_timeout", "debe ser mayor que cero")
+	v.check(c.Mongo.ServerSelectionTimeout > 0, "mongodb.server_selection_timeout", "debe ser mayor que cero")
+
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		v.fail("log.level", "debe ser debug, info, warn o error")
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	case "file":
+		v.check(c.Tracing.File != "", "tracing.file", "es obligatorio con el exportador file")
+	default:
+		v.fail("tracing.exporter", "debe ser none, otlp, stdout o file")
+	}
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "debe estar entre 0 y 1")
+	v.check(c.Tracing.ServiceName != "", "tracing.service_name", "es obligatorio")
+
+	switch c.Media.Storage {
+	case "local":
+		v.check(c.Media.Dir != "", "media.dir", "es obligatorio con el almacenamiento local")
+	case "s3":
+		v.check(c.Media.S3.Bucket != "", "media.s3.bucket", "es obligatorio con el almacenamiento s3")
+	default:
+		v.fail("media.storage", "debe ser local o s3")
+	}
+	v.check(c.Media.MaxBytes >= 0, "media.max_bytes", "no puede ser negativo")
+
+	v.nonNegative("health.cache_ttl", c.Health.CacheTTL)
+	v.check(c.Health.Timeout > 0, "health.timeout", "debe ser mayor que cero")
+
+	switch c.RateLimit.Store {
+	case "memory", "mongodb":
+	default:
+		v.fail("rate_limit.store", "debe ser memory o mongodb")
+	}
+
+	duplicate := v.policyAction("policy.duplicate.action", c.Policy.Duplicate.Action)
+	v.policyAction("policy.links.action", c.Policy.Links.Action)
+	burst := v.policyAction("policy.burst.action", c.Policy.Burst.Action)
+	if duplicate {
+		v.check(c.Policy.Duplicate.Threshold > 0, "policy.duplicate.threshold", "debe ser mayor que cero")
+		v.check(c.Policy.Duplicate.Window > 0, "policy.duplicate.window", "debe ser mayor que cero")
+	}
+	v.check(c.Policy.Links.Max >= 0, "policy.links.max", "no puede ser negativo")
+	if burst {
+		v.check(c.Policy.Burst.MaxTweets > 0, "policy.burst.max_tweets", "debe ser mayor que cero")
+		v.check(c.Policy.Burst.Window > 0, "policy.burst.window", "debe ser mayor que cero")
+		v.check(c.Policy.Burst.AccountAge > 0, "policy.burst.account_age", "debe ser mayor que cero")
+	}
+
+	v.check(c.Export.SigningKey == "" || len(c.Export.SigningKey) >= 32, "export.signing_key", "debe tener al menos 32 caracteres")
+	v.check(c.Export.LinkTTL > 0, "export.link_ttl", "debe ser mayor que cero")
+	v.check(c.Export.Retention > 0, "export.retention", "debe ser mayor que cero")
+
+	v.check(c.GraphQL.MaxDepth > 0, "graphql.max_depth", "debe ser mayor que cero")
+	v.check(c.GraphQL.MaxComplexity > 0, "graphql.max_complexity", "debe ser mayor que cero")
+
+	v.check(c.GRPC.Port >= 0 && c.GRPC.Port <= 65535, "grpc.port", "debe estar entre 0 y 65535")
+	v.check(c.GRPC.Port == 0 || c.GRPC.Port != c.Server.Port, "grpc.port", "no puede ser el mismo que server.port")
+	v.check(c.GRPC.WatchInterval > 0, "grpc.watch_interval", "debe ser mayor que cero")
+
+	if c.PublicBaseURL != "" {
+		u, err := url.Parse(c.PublicBaseURL)
+		v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"public_base_url", "debe ser una URL absoluta http o https")
+	}
+	if _, err := i18n.NewBundle(c.DefaultLanguage); err != nil {
+		v.fail("default_language", "idioma no soportado")
+	}
+
+	return v.err()
+}
+
+// validator acumula los problemas de Validate
+type validator struct {
+	errs []error
+}
+
+func (v *validator) fail(key, msg string) {
+	name := key
+	if env := envName(key); env != "" {
+		name = fmt.Sprintf("%s (%s)", key, env)
+	}
+	v.errs = append(v.errs, fmt.Errorf("%s: %s", name, msg))
+}
+
+func (v *validator) check(ok bool, key, msg string) {
+	if !ok {
+		v.fail(key, msg)
+	}
+}
+
+func (v *validator) nonNegative(key string, d time.Duration) {
+	v.check(d >= 0, key, "no puede ser negativo")
+}
+
+// policyAction comprueba una acción de las heurísticas y devuelve si la activa
+func (v *validator) policyAction(key, action string) bool {
+	enabled, ok := policyActions[strings.ToLower(strings.TrimSpace(action))]
+	v.check(ok, key, "debe ser none, flag, hold o reject")
+	return enabled
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("configuración inválida:\n%w", errors.Join(v.errs...))
+}
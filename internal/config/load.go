@@ -0,0 +1,196 @@
+// internal/config/load.go
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources indica de dónde se lee la configuración
+type Sources struct {
+	// File es un archivo YAML opcional; si se indica debe existir
+	File string
+	// DotEnv es el archivo .env; si no existe se ignora
+	DotEnv string
+	// LookupEnv consulta el entorno del proceso; os.LookupEnv si es nil
+	LookupEnv func(key string) (string, bool)
+}
+
+// Load construye la configuración aplicando, de menor a mayor precedencia: los
+// valores por defecto, el archivo YAML, el archivo .env y las variables de
+// entorno del proceso. Devuelve un error si alguna fuente no se puede leer, si
+// un valor no tiene el tipo esperado o si la configuración no es válida.
+func Load(src Sources) (*Config, error) {
+	cfg := Default()
+
+	if src.File != "" {
+		if err := loadYAML(src.File, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	dotenv := map[string]string{}
+	if src.DotEnv != "" {
+		values, err := godotenv.Read(src.DotEnv)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("error al leer %s: %w", src.DotEnv, err)
+		}
+		if values != nil {
+			dotenv = values
+		}
+	}
+
+	lookup := src.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	env := func(key string) (string, bool) {
+		if v, ok := lookup(key); ok {
+			return v, true
+		}
+		v, ok := dotenv[key]
+		return v, ok
+	}
+
+	var errs []error
+	for _, f := range fields(&cfg) {
+		// Una variable vacía cuenta como no definida, igual que con os.Getenv
+		raw, ok := env(f.env)
+		raw = strings.TrimSpace(raw)
+		if !ok || raw == "" {
+			continue
+		}
+		if err := setValue(f.value, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", f.key, f.env, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("configuración inválida:\n%w", errors.Join(errs...))
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func loadYAML(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error al leer el archivo de configuración: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	// Una clave mal escrita no debe ignorarse en silencio
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("archivo de configuración %s inválido: %w", path, err)
+	}
+	return nil
+}
+
+// field es un valor configurable con su clave YAML completa y su variable de entorno
+type field struct {
+	key    string
+	env    string
+	secret string
+	value  reflect.Value
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// fields recorre la configuración y devuelve los campos configurables
+func fields(cfg *Config) []field {
+	var out []field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			key := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			fv := v.Field(i)
+			// Los tipos que se leen como texto (ratelimit.Limit) son un único valor
+			if sf.Type.Kind() == reflect.Struct && !reflect.PointerTo(sf.Type).Implements(textUnmarshalerType) {
+				walk(fv, key)
+				continue
+			}
+			out = append(out, field{key: key, env: sf.Tag.Get("env"), secret: sf.Tag.Get("secret"), value: fv})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return out
+}
+
+// envName devuelve la variable de entorno de una clave YAML
+func envName(key string) string {
+	var cfg Config
+	for _, f := range fields(&cfg) {
+		if f.key == key {
+			return f.env
+		}
+	}
+	return ""
+}
+
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("duración inválida %q (ejemplos: 500ms, 30s, 2m)", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("se esperaba true o false, no %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("se esperaba un entero, no %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("se esperaba un entero no negativo, no %q", raw)
+		}
+		v.SetUint(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("se esperaba un número, no %q", raw)
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("tipo no soportado: %s", v.Type())
+	}
+	return nil
+}
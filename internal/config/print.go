@@ -0,0 +1,39 @@
+// internal/config/print.go
+package config
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ffelixf/microblog-platform/internal/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted devuelve una copia de la configuración con los secretos ocultos: los
+// campos secret:"true" se reemplazan por completo y en las URIs (secret:"uri")
+// solo se ocultan usuario y contraseña
+func (c Config) Redacted() Config {
+	for _, f := range fields(&c) {
+		if f.value.String() == "" {
+			continue
+		}
+		switch f.secret {
+		case "true":
+			f.value.SetString(logging.Redacted)
+		case "uri":
+			f.value.SetString(logging.Redact(f.value.String()))
+		}
+	}
+	return c
+}
+
+// Print escribe la configuración efectiva en YAML, con los secretos ocultos. La
+// salida sirve como archivo de configuración salvo por los valores ocultos.
+func (c Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return fmt.Errorf("error al escribir la configuración: %w", err)
+	}
+	return enc.Close()
+}
@@ -0,0 +1,142 @@
+// internal/dataset/checkpoint.go
+package dataset
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Checkpoint es el progreso de una importación. Se guarda después de aplicar
+// cada lote, así que al repetir la importación se retoma desde Line; el lote
+// que falló se vuelve a aplicar y, como los IDs remapeados son los mismos, lo
+// que ya se había insertado no se duplica.
+type Checkpoint struct {
+	// ExportedAt identifica el archivo que se está importando
+	ExportedAt time.Time `json:"exported_at"`
+	// Seed es la semilla del Remapper, en hexadecimal
+	Seed string `json:"seed"`
+	// Line es la última línea del archivo ya aplicada
+	Line int `json:"line"`
+	// Users son los IDs originales de los usuarios importados, a los que pueden
+	// referirse los follows y tweets siguientes
+	Users    []string `json:"users"`
+	Stats    Stats    `json:"stats"`
+	Complete bool     `json:"complete"`
+
+	path  string
+	users map[string]bool
+	saved time.Time
+}
+
+// newCheckpoint empieza una importación nueva con una semilla aleatoria
+func newCheckpoint(path string, header *Header) (*Checkpoint, error) {
+	seed := make([]byte, 16)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return &Checkpoint{
+		ExportedAt: header.ExportedAt,
+		Seed:       hex.EncodeToString(seed),
+		path:       path,
+		users:      make(map[string]bool),
+	}, nil
+}
+
+// loadCheckpoint lee el checkpoint de path; devuelve nil si no existe
+func loadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("checkpoint %s dañado: %w", path, err)
+	}
+	cp.path = path
+	cp.users = make(map[string]bool, len(cp.Users))
+	for _, id := range cp.Users {
+		cp.users[id] = true
+	}
+	return &cp, nil
+}
+
+func (cp *Checkpoint) seed() ([]byte, error) {
+	seed, err := hex.DecodeString(cp.Seed)
+	if err != nil || len(seed) == 0 {
+		return nil, fmt.Errorf("checkpoint %s sin semilla válida", cp.path)
+	}
+	return seed, nil
+}
+
+// save escribe el checkpoint si pasó every desde la última vez o si force. Se
+// escribe en un archivo temporal y se renombra para no dejar uno a medias.
+func (cp *Checkpoint) save(force bool, every time.Duration) error {
+	if cp.path == "" || (!force && time.Since(cp.saved) < every) {
+		return nil
+	}
+	cp.Users = make([]string, 0, len(cp.users))
+	for id := range cp.users {
+		cp.Users = append(cp.Users, id)
+	}
+	slices.Sort(cp.Users)
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(cp.path), filepath.Base(cp.path)+".*")
+	if err != nil {
+		return fmt.Errorf("error al guardar checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error al guardar checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error al guardar checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), cp.path); err != nil {
+		return fmt.Errorf("error al guardar checkpoint: %w", err)
+	}
+	cp.saved = time.Now()
+	return nil
+}
+
+// ImportedUserIDs devuelve los IDs en el destino de los usuarios que importó la
+// importación del checkpoint de path, para actuar en su nombre contra la API
+func ImportedUserIDs(path string) ([]string, error) {
+	cp, err := loadCheckpoint(path)
+	if err != nil {
+		return nil, err
+	}
+	if cp == nil {
+		return nil, fmt.Errorf("no existe el checkpoint %s", path)
+	}
+	seed, err := cp.seed()
+	if err != nil {
+		return nil, err
+	}
+	remap := NewRemapper(seed)
+	ids := make([]string, 0, len(cp.Users))
+	for _, id := range cp.Users {
+		original, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("checkpoint %s dañado: %w", path, err)
+		}
+		ids = append(ids, remap.Map(original).Hex())
+	}
+	return ids, nil
+}
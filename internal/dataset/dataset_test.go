@@ -0,0 +1,268 @@
+// internal/dataset/dataset_test.go
+package dataset
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/memstore"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sourceFixture es una base de datos de origen con tres cuentas, una de ellas
+// pendiente de borrado, sus follows y sus tweets
+type sourceFixture struct {
+	users   *memstore.Users
+	tweets  *memstore.Tweets
+	ana     models.User
+	beto    models.User
+	borrado models.User
+	base    time.Time
+}
+
+func setupSource(t *testing.T) *sourceFixture {
+	ctx := context.Background()
+	f := &sourceFixture{
+		users:  memstore.NewUsers(),
+		tweets: memstore.NewTweets(),
+		base:   time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+	deletion := f.base
+	f.ana = models.User{ID: primitive.NewObjectID(), Username: "ana", Email: "ana@example.com", CreatedAt: f.base, MutedWords: []string{"spoiler"}}
+	f.beto = models.User{ID: primitive.NewObjectID(), Username: "beto", Email: "beto@example.com", CreatedAt: f.base,
+		Suspension: &models.Suspension{Reason: "spam", ModeratorID: f.ana.ID, Since: f.base}}
+	f.borrado = models.User{ID: primitive.NewObjectID(), Username: "borrado", Email: "borrado@example.com", DeletionRequestedAt: &deletion}
+	_, err := f.users.ImportMany(ctx, []models.User{f.ana, f.beto, f.borrado})
+	require.NoError(t, err)
+
+	for _, follow := range [][2]models.User{{f.ana, f.beto}, {f.beto, f.ana}, {f.ana, f.borrado}} {
+		require.NoError(t, f.users.FollowUser(ctx, follow[0].ID.Hex(), follow[1].ID.Hex()))
+	}
+
+	votes := 2
+	hidden := f.base.Add(time.Hour)
+	require.NoError(t, f.tweets.ImportMany(ctx, []models.Tweet{
+		{ID: primitive.NewObjectID(), UserID: f.ana.ID, Content: "Hola #Go", Hashtags: []string{"go"}, CreatedAt: f.base.Add(time.Minute)},
+		{ID: primitive.NewObjectID(), UserID: f.beto.ID, Content: "¿Sí o no?", CreatedAt: f.base.Add(2 * time.Minute), Poll: &models.Poll{
+			Options: []models.PollOption{{Text: "sí", Votes: &votes}, {Text: "no"}}, ExpiresAt: f.base.Add(time.Hour), Closed: true, TotalVotes: &votes,
+		}},
+		{ID: primitive.NewObjectID(), UserID: f.ana.ID, Content: "oculto", CreatedAt: f.base.Add(3 * time.Minute), HiddenAt: &hidden},
+		{ID: primitive.NewObjectID(), UserID: f.borrado.ID, Content: "adiós", CreatedAt: f.base.Add(4 * time.Minute)},
+	}))
+	return f
+}
+
+func (f *sourceFixture) export(t *testing.T, opts ExportOptions) []byte {
+	var buf bytes.Buffer
+	stats, err := Export(context.Background(), &buf, f.users, f.tweets, opts)
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{Users: 2, Follows: 2, Tweets: 3}, stats, "sin la cuenta pendiente de borrado")
+	return buf.Bytes()
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := setupSource(t)
+	data := src.export(t, ExportOptions{Now: src.base})
+
+	for _, compressed := range []bool{false, true} {
+		input := data
+		if compressed {
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			_, err := zw.Write(data)
+			require.NoError(t, err)
+			require.NoError(t, zw.Close())
+			input = buf.Bytes()
+		}
+
+		users, tweets := memstore.NewUsers(), memstore.NewTweets()
+		stats, err := NewImporter(users, tweets, 2).Import(ctx, bytes.NewReader(input), "")
+		require.NoError(t, err)
+		assert.Equal(t, &Stats{Users: 2, Follows: 2, Tweets: 3}, stats)
+
+		ana, err := users.GetByUsername(ctx, "ana")
+		require.NoError(t, err)
+		assert.NotEqual(t, src.ana.ID, ana.ID, "los IDs se remapean")
+		assert.Equal(t, src.ana.ID.Timestamp(), ana.ID.Timestamp())
+		assert.Equal(t, "ana@example.com", ana.Email)
+		assert.Equal(t, []string{"spoiler"}, ana.MutedWords)
+		assert.Equal(t, 1, ana.FollowersCount)
+
+		beto, err := users.GetByUsername(ctx, "beto")
+		require.NoError(t, err)
+		assert.Equal(t, []string{ana.ID.Hex()}, beto.Following)
+		require.NotNil(t, beto.Suspension)
+		assert.True(t, beto.Suspension.ModeratorID.IsZero(), "el moderador no se exporta")
+
+		_, err = users.GetByUsername(ctx, "borrado")
+		assert.Error(t, err)
+
+		all, err := tweets.AllByUser(ctx, ana.ID)
+		require.NoError(t, err)
+		require.Len(t, all, 2)
+		assert.Equal(t, []string{"go"}, all[0].Hashtags)
+		assert.True(t, src.base.Add(time.Minute).Equal(all[0].CreatedAt), "conserva la fecha original")
+		assert.NotNil(t, all[1].HiddenAt)
+
+		polls, err := tweets.GetByUserID(ctx, beto.ID.Hex())
+		require.NoError(t, err)
+		require.Len(t, polls, 1)
+		assert.True(t, polls[0].Poll.Closed)
+		assert.Equal(t, 2, *polls[0].Poll.TotalVotes)
+	}
+}
+
+func TestExport_Anonymize(t *testing.T) {
+	src := setupSource(t)
+	data := string(src.export(t, ExportOptions{Anonymize: true}))
+	assert.NotContains(t, data, "ana@example.com")
+	assert.Contains(t, data, "user-"+src.ana.ID.Hex()+"@example.invalid")
+}
+
+func TestImport_Rejections(t *testing.T) {
+	ctx := context.Background()
+	src := setupSource(t)
+	data := src.export(t, ExportOptions{})
+	// Un tweet inválido, uno de un usuario desconocido y una línea rota
+	extra := strings.Join([]string{
+		`{"type":"tweet","tweet":{"id":"` + primitive.NewObjectID().Hex() + `","user_id":"` + src.ana.ID.Hex() + `","content":"` + strings.Repeat("a", 281) + `","created_at":"2023-03-01T10:00:00Z"}}`,
+		`{"type":"tweet","tweet":{"id":"` + primitive.NewObjectID().Hex() + `","user_id":"` + primitive.NewObjectID().Hex() + `","content":"hola","created_at":"2023-03-01T10:00:00Z"}}`,
+		`{"type":`,
+	}, "\n") + "\n"
+	data = append(data, extra...)
+
+	// beto ya existe en el destino: se rechaza con lo que se refiere a él
+	users, tweets := memstore.NewUsers(), memstore.NewTweets()
+	require.NoError(t, users.Create(ctx, &models.User{Username: "beto", Email: "otro@example.com"}))
+
+	stats, err := NewImporter(users, tweets, 10).Import(ctx, bytes.NewReader(data), "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Users)
+	assert.Equal(t, 0, stats.Follows)
+	assert.Equal(t, 2, stats.Tweets)
+	assert.Equal(t, 1+2+1+3, stats.Rejected)
+}
+
+func TestImport_Header(t *testing.T) {
+	ctx := context.Background()
+	im := NewImporter(memstore.NewUsers(), memstore.NewTweets(), 0)
+
+	_, err := im.Import(ctx, strings.NewReader(""), "")
+	assert.Error(t, err)
+	_, err = im.Import(ctx, strings.NewReader(`{"type":"user","user":{}}`+"\n"), "")
+	assert.ErrorContains(t, err, "cabecera")
+	_, err = im.Import(ctx, strings.NewReader(`{"type":"header","header":{"version":99}}`+"\n"), "")
+	assert.ErrorContains(t, err, "versión")
+}
+
+// failingTweets falla en el lote número failAt
+type failingTweets struct {
+	*memstore.Tweets
+	calls  int
+	failAt int
+}
+
+func (f *failingTweets) ImportMany(ctx context.Context, tweets []models.Tweet) error {
+	f.calls++
+	if f.calls == f.failAt {
+		// La mitad del lote llega a escribirse antes del fallo
+		if err := f.Tweets.ImportMany(ctx, tweets[:len(tweets)/2]); err != nil {
+			return err
+		}
+		return errors.New("conexión perdida")
+	}
+	return f.Tweets.ImportMany(ctx, tweets)
+}
+
+func TestImport_Resume(t *testing.T) {
+	ctx := context.Background()
+	src := setupSource(t)
+	data := src.export(t, ExportOptions{})
+	checkpoint := filepath.Join(t.TempDir(), "import.checkpoint")
+
+	users := memstore.NewUsers()
+	tweets := &failingTweets{Tweets: memstore.NewTweets(), failAt: 1}
+	_, err := NewImporter(users, tweets, 2).Import(ctx, bytes.NewReader(data), checkpoint)
+	assert.ErrorContains(t, err, "conexión perdida")
+	assert.Equal(t, 1, tweets.Len(), "la mitad del primer lote")
+
+	cp, err := loadCheckpoint(checkpoint)
+	require.NoError(t, err)
+	require.NotNil(t, cp)
+	assert.False(t, cp.Complete)
+	assert.Len(t, cp.Users, 2)
+
+	stats, err := NewImporter(users, tweets, 2).Import(ctx, bytes.NewReader(data), checkpoint)
+	require.NoError(t, err)
+	assert.Equal(t, &Stats{Users: 2, Follows: 2, Tweets: 3}, stats)
+	assert.Equal(t, 2, users.Len())
+	assert.Equal(t, 3, tweets.Len(), "el lote repetido no duplica tweets")
+
+	// Una importación completa no se repite
+	stats, err = NewImporter(users, tweets, 2).Import(ctx, bytes.NewReader(data), checkpoint)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.Tweets)
+	assert.Equal(t, 3, tweets.Len())
+
+	// El checkpoint de otro archivo no se usa
+	other := src.export(t, ExportOptions{Now: src.base.Add(time.Hour)})
+	_, err = NewImporter(users, tweets, 2).Import(ctx, bytes.NewReader(other), checkpoint)
+	assert.ErrorContains(t, err, "otro archivo")
+}
+
+func TestRemapper(t *testing.T) {
+	id := primitive.NewObjectID()
+	a, b := NewRemapper([]byte("semilla-a")), NewRemapper([]byte("semilla-b"))
+	assert.Equal(t, a.Map(id), a.Map(id), "es determinista")
+	assert.NotEqual(t, a.Map(id), b.Map(id))
+	assert.Equal(t, id.Timestamp(), a.Map(id).Timestamp())
+}
+
+func TestGenerate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	opts := GenerateOptions{Users: 500, Tweets: 2000, FollowsPerUser: 10, Span: 24 * time.Hour, Now: now, Seed: 42}
+
+	var first, second bytes.Buffer
+	stats, err := Generate(ctx, &first, opts)
+	require.NoError(t, err)
+	_, err = Generate(ctx, &second, opts)
+	require.NoError(t, err)
+	assert.Equal(t, first.String(), second.String(), "la misma semilla da los mismos datos")
+
+	users, tweets := memstore.NewUsers(), memstore.NewTweets()
+	imported, err := NewImporter(users, tweets, 0).Import(ctx, &first, "")
+	require.NoError(t, err)
+	assert.Equal(t, stats, imported, "todo lo generado es válido")
+	assert.Equal(t, 500, stats.Users)
+	assert.Equal(t, 2000, stats.Tweets)
+	assert.InDelta(t, 500*10, stats.Follows, 500*10*0.2)
+
+	// Unos pocos usuarios concentran los seguidores
+	most := 0
+	err = users.Walk(ctx, func(user *models.User) error {
+		most = max(most, user.FollowersCount)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Greater(t, most, 10*stats.Follows/stats.Users)
+
+	err = tweets.Walk(ctx, func(tweet *models.Tweet) error {
+		assert.False(t, tweet.CreatedAt.Before(now.Add(-24*time.Hour)) || tweet.CreatedAt.After(now), tweet.CreatedAt)
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = Generate(ctx, &first, GenerateOptions{})
+	assert.Error(t, err)
+}
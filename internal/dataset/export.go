@@ -0,0 +1,106 @@
+// internal/dataset/export.go
+package dataset
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserSource recorre todos los usuarios; lo implementan
+// repository.UserRepository y memstore.Users
+type UserSource interface {
+	Walk(ctx context.Context, fn func(user *models.User) error) error
+}
+
+// TweetSource recorre todos los tweets, ocultos y retenidos incluidos
+type TweetSource interface {
+	Walk(ctx context.Context, fn func(tweet *models.Tweet) error) error
+}
+
+// Stats cuenta los registros exportados o importados
+type Stats struct {
+	Users    int `json:"users"`
+	Follows  int `json:"follows"`
+	Tweets   int `json:"tweets"`
+	Rejected int `json:"rejected"`
+}
+
+// ExportOptions ajusta la exportación
+type ExportOptions struct {
+	// Anonymize reemplaza los emails por direcciones que no existen
+	Anonymize bool
+	// Now es la fecha de la cabecera; vacía es la hora actual
+	Now time.Time
+}
+
+// Export escribe en w todos los usuarios, follows y tweets como NDJSON, de uno
+// en uno y sin cargarlos en memoria. Las cuentas pendientes de borrado se
+// omiten con sus follows y sus tweets. No es una instantánea: lo que cambie
+// durante la exportación puede salir o no.
+func Export(ctx context.Context, w io.Writer, users UserSource, tweets TweetSource, opts ExportOptions) (*Stats, error) {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	stats := &Stats{}
+
+	header := &Header{Version: FormatVersion, ExportedAt: opts.Now.UTC()}
+	if err := enc.Encode(Record{Type: TypeHeader, Header: header}); err != nil {
+		return stats, err
+	}
+
+	// Primero los usuarios, para que los follows y tweets solo se refieran a
+	// usuarios que ya aparecieron
+	exported := make(map[primitive.ObjectID]bool)
+	err := users.Walk(ctx, func(user *models.User) error {
+		if user.IsPendingDeletion() {
+			return nil
+		}
+		exported[user.ID] = true
+		stats.Users++
+		return enc.Encode(Record{Type: TypeUser, User: NewUserRecord(user, opts.Anonymize)})
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	err = users.Walk(ctx, func(user *models.User) error {
+		if !exported[user.ID] {
+			return nil
+		}
+		for _, id := range user.Following {
+			target, err := primitive.ObjectIDFromHex(id)
+			if err != nil || !exported[target] {
+				continue
+			}
+			stats.Follows++
+			follow := &FollowRecord{FollowerID: user.ID.Hex(), FolloweeID: id}
+			if err := enc.Encode(Record{Type: TypeFollow, Follow: follow}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	err = tweets.Walk(ctx, func(tweet *models.Tweet) error {
+		if !exported[tweet.UserID] {
+			return nil
+		}
+		stats.Tweets++
+		return enc.Encode(Record{Type: TypeTweet, Tweet: NewTweetRecord(tweet)})
+	})
+	if err != nil {
+		return stats, err
+	}
+	return stats, buf.Flush()
+}
@@ -0,0 +1,202 @@
+// internal/dataset/generate.go
+package dataset
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GenerateOptions describe el conjunto de datos sintético
+type GenerateOptions struct {
+	Users  int
+	Tweets int
+	// FollowsPerUser es la media de cuentas que sigue cada usuario; por defecto 20
+	FollowsPerUser int
+	// Exponent es el exponente de la ley de potencias de la popularidad: con 1
+	// el usuario en el puesto k tiene del orden de 1/k de los seguidores del
+	// primero. Por defecto 1.
+	Exponent float64
+	// Span es el período en el que se reparten los tweets, hasta Now; por defecto 30 días
+	Span time.Duration
+	// Now es el final del período y la fecha de la cabecera; vacía es la hora actual
+	Now time.Time
+	// Seed hace reproducible el conjunto: la misma semilla da los mismos datos
+	Seed uint64
+}
+
+// Vocabulario de los tweets sintéticos
+var (
+	generatedWords = strings.Fields(`hoy mañana ayer café código tren lluvia sol
+		partido música libro película proyecto reunión equipo ciudad playa montaña
+		noticia idea pregunta respuesta versión error prueba despliegue servidor
+		base datos rápido lento nuevo viejo bueno malo mejor peor gracias hola
+		siempre nunca quizás vamos vemos pienso creo parece funciona falla`)
+	generatedTags = []string{"go", "mongodb", "futbol", "musica", "cine", "viajes", "trabajo", "lunes", "noticias", "tecnologia"}
+)
+
+// Generate escribe en w un conjunto de datos sintético con el formato de
+// Export, listo para importar. Los seguidores siguen una ley de potencias:
+// pocos usuarios concentran la mayoría y la mayor parte tiene pocos. La
+// actividad también se concentra en pocos autores, y los tweets se reparten de
+// forma uniforme en el período, en orden cronológico.
+func Generate(ctx context.Context, w io.Writer, opts GenerateOptions) (*Stats, error) {
+	if opts.Users < 1 {
+		return nil, errors.New("hace falta al menos un usuario")
+	}
+	if opts.Tweets < 0 || opts.FollowsPerUser < 0 || opts.Exponent < 0 || opts.Span < 0 {
+		return nil, errors.New("las opciones no pueden ser negativas")
+	}
+	if opts.FollowsPerUser == 0 {
+		opts.FollowsPerUser = 20
+	}
+	if opts.Exponent == 0 {
+		opts.Exponent = 1
+	}
+	if opts.Span == 0 {
+		opts.Span = 30 * 24 * time.Hour
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed))
+	start := opts.Now.Add(-opts.Span)
+
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	stats := &Stats{}
+
+	header := &Header{Version: FormatVersion, ExportedAt: opts.Now.UTC()}
+	if err := enc.Encode(Record{Type: TypeHeader, Header: header}); err != nil {
+		return stats, err
+	}
+
+	// Las cuentas existen antes del primer tweet
+	ids := make([]primitive.ObjectID, opts.Users)
+	for i := range ids {
+		if i%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return stats, err
+			}
+		}
+		createdAt := start.Add(-time.Duration(rng.Int64N(int64(opts.Span)) + 1)).UTC().Truncate(time.Second)
+		ids[i] = generatedID(rng, createdAt)
+		user := &UserRecord{
+			ID:        ids[i].Hex(),
+			Username:  fmt.Sprintf("user%06d", i),
+			Email:     fmt.Sprintf("user%06d@example.invalid", i),
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		}
+		if err := enc.Encode(Record{Type: TypeUser, User: user}); err != nil {
+			return stats, err
+		}
+		stats.Users++
+	}
+
+	popularity := newPowerLaw(rng, opts.Users, opts.Exponent)
+	for i, follower := range ids {
+		if i%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return stats, err
+			}
+		}
+		want := min(int(rng.ExpFloat64()*float64(opts.FollowsPerUser)), opts.Users-1)
+		chosen := make(map[int]bool, want)
+		// Con want cerca del total de usuarios casi todos los sorteos repiten
+		for attempts := 0; len(chosen) < want && attempts < 4*want+10; attempts++ {
+			target := popularity.pick(rng)
+			if target == i || chosen[target] {
+				continue
+			}
+			chosen[target] = true
+			follow := &FollowRecord{FollowerID: follower.Hex(), FolloweeID: ids[target].Hex()}
+			if err := enc.Encode(Record{Type: TypeFollow, Follow: follow}); err != nil {
+				return stats, err
+			}
+			stats.Follows++
+		}
+	}
+
+	activity := newPowerLaw(rng, opts.Users, opts.Exponent)
+	for i := 0; i < opts.Tweets; i++ {
+		if i%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return stats, err
+			}
+		}
+		offset := time.Duration((float64(i) + rng.Float64()) / float64(opts.Tweets) * float64(opts.Span))
+		createdAt := start.Add(offset).UTC().Truncate(time.Millisecond)
+		tweet := &TweetRecord{
+			ID:        generatedID(rng, createdAt).Hex(),
+			UserID:    ids[activity.pick(rng)].Hex(),
+			Content:   generatedContent(rng),
+			CreatedAt: createdAt,
+		}
+		if err := enc.Encode(Record{Type: TypeTweet, Tweet: tweet}); err != nil {
+			return stats, err
+		}
+		stats.Tweets++
+	}
+	return stats, buf.Flush()
+}
+
+// powerLaw sortea usuarios con probabilidad proporcional a 1/rango^exponente;
+// el rango de cada usuario es una permutación aleatoria
+type powerLaw struct {
+	cumulative []float64
+}
+
+func newPowerLaw(rng *rand.Rand, n int, exponent float64) *powerLaw {
+	p := &powerLaw{cumulative: make([]float64, n)}
+	total := 0.0
+	for i, rank := range rng.Perm(n) {
+		total += 1 / math.Pow(float64(rank+1), exponent)
+		p.cumulative[i] = total
+	}
+	return p
+}
+
+func (p *powerLaw) pick(rng *rand.Rand) int {
+	x := rng.Float64() * p.cumulative[len(p.cumulative)-1]
+	return min(sort.SearchFloat64s(p.cumulative, x), len(p.cumulative)-1)
+}
+
+// generatedID crea un ObjectID con la fecha indicada y el resto sorteado, para
+// que el orden por ID coincida con el cronológico como en los IDs reales
+func generatedID(rng *rand.Rand, at time.Time) primitive.ObjectID {
+	id := primitive.NewObjectIDFromTimestamp(at)
+	binary.BigEndian.PutUint64(id[4:], rng.Uint64())
+	return id
+}
+
+// generatedContent arma un texto de entre 3 y 20 palabras, a veces con
+// hashtags, sin pasar del largo máximo de un tweet
+func generatedContent(rng *rand.Rand) string {
+	words := make([]string, 0, 22)
+	for n := 3 + rng.IntN(18); len(words) < n; {
+		words = append(words, generatedWords[rng.IntN(len(generatedWords))])
+	}
+	if rng.IntN(5) == 0 {
+		for n := 1 + rng.IntN(2); n > 0; n-- {
+			words = append(words, "#"+generatedTags[rng.IntN(len(generatedTags))])
+		}
+	}
+	content := strings.Join(words, " ")
+	for len(content) > service.MaxTweetLength {
+		content = content[:strings.LastIndexByte(content, ' ')]
+	}
+	return content
+}
@@ -0,0 +1,412 @@
+// internal/dataset/import.go
+package dataset
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// DefaultBatchSize es cuántos registros se escriben en cada lote
+	DefaultBatchSize = 500
+	// checkpointInterval es cada cuánto se guarda el checkpoint como mucho;
+	// al fallar y al terminar se guarda siempre
+	checkpointInterval = time.Second
+	// maxLineSize es el tamaño máximo de una línea del archivo
+	maxLineSize = 1 << 20
+)
+
+// UserTarget recibe los usuarios y follows importados; lo implementan
+// repository.UserRepository y memstore.Users
+type UserTarget interface {
+	ImportMany(ctx context.Context, users []models.User) ([]int, error)
+	AddFollowing(ctx context.Context, id primitive.ObjectID, targetIDs []string) error
+	RecountFollowers(ctx context.Context, ids []string) error
+}
+
+// TweetTarget recibe los tweets importados
+type TweetTarget interface {
+	ImportMany(ctx context.Context, tweets []models.Tweet) error
+}
+
+// Importer carga un archivo de datos en lotes. Los IDs se remapean para no
+// chocar con los del destino y los tweets pasan las validaciones de
+// service.ValidateImportedTweet. Los registros inválidos, los usuarios cuyo
+// nombre o email ya existe y lo que se refiere a ellos se rechazan y se
+// registran en el log sin detener la importación.
+type Importer struct {
+	users     UserTarget
+	tweets    TweetTarget
+	batchSize int
+}
+
+func NewImporter(users UserTarget, tweets TweetTarget, batchSize int) *Importer {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &Importer{
+		users:     users,
+		tweets:    tweets,
+		batchSize: batchSize,
+	}
+}
+
+// NewReader devuelve un lector del archivo que descomprime si viene en gzip
+func NewReader(r io.Reader) (io.Reader, error) {
+	buf := bufio.NewReader(r)
+	magic, err := buf.Peek(2)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(buf)
+	}
+	return buf, nil
+}
+
+// Import carga el archivo r, comprimido o no. Con checkpoint guarda el
+// progreso en ese archivo y, si ya existe, continúa desde donde quedó; una
+// importación completa no se repite. Devuelve lo importado en total, incluidas
+// las ejecuciones anteriores.
+func (im *Importer) Import(ctx context.Context, r io.Reader, checkpoint string) (*Stats, error) {
+	input, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	header, err := readHeader(scanner)
+	if err != nil {
+		return nil, err
+	}
+	cp, err := im.checkpoint(checkpoint, header)
+	if err != nil {
+		return nil, err
+	}
+	if cp.Complete {
+		return &cp.Stats, nil
+	}
+	seed, err := cp.seed()
+	if err != nil {
+		return nil, err
+	}
+
+	run := &importRun{
+		im:    im,
+		cp:    cp,
+		remap: NewRemapper(seed),
+	}
+	if err := run.process(ctx, scanner); err != nil {
+		if saveErr := cp.save(true, 0); saveErr != nil {
+			slog.ErrorContext(ctx, "error al guardar checkpoint", slog.Any("error", saveErr))
+		}
+		return &cp.Stats, err
+	}
+	cp.Complete = true
+	return &cp.Stats, cp.save(true, 0)
+}
+
+func readHeader(scanner *bufio.Scanner) (*Header, error) {
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("archivo vacío")
+	}
+	var record Record
+	if err := json.Unmarshal(scanner.Bytes(), &record); err != nil || record.Type != TypeHeader || record.Header == nil {
+		return nil, errors.New("el archivo no empieza con la cabecera")
+	}
+	if record.Header.Version != FormatVersion {
+		return nil, fmt.Errorf("versión de formato %d no soportada (se espera %d)", record.Header.Version, FormatVersion)
+	}
+	return record.Header, nil
+}
+
+func (im *Importer) checkpoint(path string, header *Header) (*Checkpoint, error) {
+	if path != "" {
+		cp, err := loadCheckpoint(path)
+		if err != nil {
+			return nil, err
+		}
+		if cp != nil {
+			if !cp.ExportedAt.Equal(header.ExportedAt) {
+				return nil, fmt.Errorf("el checkpoint %s es de otro archivo (exportado el %s)", path, cp.ExportedAt.Format(time.RFC3339))
+			}
+			return cp, nil
+		}
+	}
+	return newCheckpoint(path, header)
+}
+
+// importRun es una ejecución de Import con sus lotes pendientes
+type importRun struct {
+	im    *Importer
+	cp    *Checkpoint
+	remap *Remapper
+
+	// line es la última línea leída; los lotes pendientes llegan hasta ella
+	line    int
+	kind    string
+	users   []pendingUser
+	follows []pendingFollow
+	tweets  []models.Tweet
+	// rejected son los rechazos desde el último lote aplicado; se suman a las
+	// estadísticas con el lote para no contarlos dos veces al retomar
+	rejected int
+}
+
+type pendingUser struct {
+	line     int
+	original string
+	user     models.User
+}
+
+type pendingFollow struct {
+	follower primitive.ObjectID
+	followee string
+}
+
+func (r *importRun) process(ctx context.Context, scanner *bufio.Scanner) error {
+	// La cabecera es la línea 1
+	r.line = 1
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := r.line + 1
+		if line <= r.cp.Line {
+			r.line = line
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			r.reject(ctx, line, fmt.Errorf("JSON inválido: %w", err))
+			r.line = line
+			continue
+		}
+		// Un lote es de un solo tipo: al cambiar de tipo se aplica el anterior
+		if record.Type != r.kind {
+			if err := r.flush(ctx); err != nil {
+				return err
+			}
+			r.kind = record.Type
+		}
+		r.line = line
+		if err := r.add(ctx, &record); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := r.flush(ctx); err != nil {
+		return err
+	}
+
+	// Los follows no tocan los contadores; se recalculan una sola vez al final
+	ids := make([]string, 0, len(r.cp.users))
+	for original := range r.cp.users {
+		ids = append(ids, r.mapHex(original).Hex())
+	}
+	return r.im.users.RecountFollowers(ctx, ids)
+}
+
+// add valida el registro de la línea actual y lo agrega al lote pendiente,
+// aplicándolo si se llenó
+func (r *importRun) add(ctx context.Context, record *Record) error {
+	switch {
+	case record.Type == TypeUser && record.User != nil:
+		r.addUser(ctx, record.User)
+	case record.Type == TypeFollow && record.Follow != nil:
+		r.addFollow(ctx, record.Follow)
+	case record.Type == TypeTweet && record.Tweet != nil:
+		r.addTweet(ctx, record.Tweet)
+	default:
+		r.reject(ctx, r.line, fmt.Errorf("registro de tipo %q inválido", record.Type))
+	}
+	if len(r.users)+len(r.follows)+len(r.tweets) >= r.im.batchSize {
+		return r.flush(ctx)
+	}
+	return nil
+}
+
+func (r *importRun) addUser(ctx context.Context, record *UserRecord) {
+	original, err := primitive.ObjectIDFromHex(record.ID)
+	if err != nil {
+		r.reject(ctx, r.line, fmt.Errorf("ID de usuario inválido: %q", record.ID))
+		return
+	}
+	if record.Username == "" {
+		r.reject(ctx, r.line, errors.New("usuario sin nombre"))
+		return
+	}
+	pending := slices.ContainsFunc(r.users, func(p pendingUser) bool { return p.original == record.ID })
+	if r.cp.users[record.ID] || pending {
+		r.reject(ctx, r.line, fmt.Errorf("usuario %s repetido", record.ID))
+		return
+	}
+
+	user := models.User{
+		ID:            r.remap.Map(original),
+		Username:      record.Username,
+		Email:         record.Email,
+		CreatedAt:     record.CreatedAt,
+		UpdatedAt:     record.UpdatedAt,
+		Following:     make([]string, 0),
+		Remote:        record.Remote,
+		Roles:         record.Roles,
+		Permissions:   record.Permissions,
+		MutedWords:    record.MutedWords,
+		DeactivatedAt: record.DeactivatedAt,
+	}
+	if s := record.Suspension; s != nil {
+		user.Suspension = &models.Suspension{Reason: s.Reason, Since: s.Since, ExpiresAt: s.ExpiresAt}
+	}
+	r.users = append(r.users, pendingUser{line: r.line, original: record.ID, user: user})
+}
+
+func (r *importRun) addFollow(ctx context.Context, record *FollowRecord) {
+	if !r.cp.users[record.FollowerID] || !r.cp.users[record.FolloweeID] {
+		r.reject(ctx, r.line, errors.New("follow entre usuarios que no se importaron"))
+		return
+	}
+	if record.FollowerID == record.FolloweeID {
+		r.reject(ctx, r.line, service.ErrSelfFollow)
+		return
+	}
+	r.follows = append(r.follows, pendingFollow{
+		follower: r.mapHex(record.FollowerID),
+		followee: r.mapHex(record.FolloweeID).Hex(),
+	})
+}
+
+func (r *importRun) addTweet(ctx context.Context, record *TweetRecord) {
+	original, err := primitive.ObjectIDFromHex(record.ID)
+	if err != nil {
+		r.reject(ctx, r.line, fmt.Errorf("ID de tweet inválido: %q", record.ID))
+		return
+	}
+	if !r.cp.users[record.UserID] {
+		r.reject(ctx, r.line, errors.New("tweet de un usuario que no se importó"))
+		return
+	}
+
+	tweet := models.Tweet{
+		ID:          r.remap.Map(original),
+		UserID:      r.mapHex(record.UserID),
+		Content:     record.Content,
+		CreatedAt:   record.CreatedAt,
+		HiddenAt:    record.HiddenAt,
+		ContentHash: record.ContentHash,
+		Policy:      record.Policy,
+	}
+	if p := record.Poll; p != nil {
+		tweet.Poll = &models.Poll{Options: p.Options, ExpiresAt: p.ExpiresAt, Closed: p.Closed, TotalVotes: p.TotalVotes}
+	}
+	if err := service.ValidateImportedTweet(&tweet); err != nil {
+		r.reject(ctx, r.line, err)
+		return
+	}
+	if tweet.CreatedAt.IsZero() {
+		r.reject(ctx, r.line, errors.New("tweet sin fecha de creación"))
+		return
+	}
+	r.tweets = append(r.tweets, tweet)
+}
+
+// flush aplica el lote pendiente y avanza el checkpoint hasta la línea actual
+func (r *importRun) flush(ctx context.Context) error {
+	var err error
+	switch {
+	case len(r.users) > 0:
+		err = r.flushUsers(ctx)
+	case len(r.follows) > 0:
+		err = r.flushFollows(ctx)
+	case len(r.tweets) > 0:
+		err = r.flushTweets(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	r.users, r.follows, r.tweets = r.users[:0], r.follows[:0], r.tweets[:0]
+	r.cp.Stats.Rejected += r.rejected
+	r.rejected = 0
+	r.cp.Line = r.line
+	return r.cp.save(false, checkpointInterval)
+}
+
+func (r *importRun) flushUsers(ctx context.Context) error {
+	users := make([]models.User, len(r.users))
+	for i, p := range r.users {
+		users[i] = p.user
+	}
+	conflicts, err := r.im.users.ImportMany(ctx, users)
+	if err != nil {
+		return err
+	}
+	rejected := make(map[int]bool, len(conflicts))
+	for _, i := range conflicts {
+		rejected[i] = true
+		r.reject(ctx, r.users[i].line, fmt.Errorf("el nombre %q o el email ya están registrados", r.users[i].user.Username))
+	}
+	for i, p := range r.users {
+		if !rejected[i] {
+			r.cp.users[p.original] = true
+			r.cp.Stats.Users++
+		}
+	}
+	return nil
+}
+
+func (r *importRun) flushFollows(ctx context.Context) error {
+	// Un follow por seguidor y lote, en el orden del archivo
+	var order []primitive.ObjectID
+	targets := make(map[primitive.ObjectID][]string)
+	for _, p := range r.follows {
+		if _, ok := targets[p.follower]; !ok {
+			order = append(order, p.follower)
+		}
+		targets[p.follower] = append(targets[p.follower], p.followee)
+	}
+	for _, follower := range order {
+		if err := r.im.users.AddFollowing(ctx, follower, targets[follower]); err != nil {
+			return err
+		}
+	}
+	r.cp.Stats.Follows += len(r.follows)
+	return nil
+}
+
+func (r *importRun) flushTweets(ctx context.Context) error {
+	if err := r.im.tweets.ImportMany(ctx, r.tweets); err != nil {
+		return err
+	}
+	r.cp.Stats.Tweets += len(r.tweets)
+	return nil
+}
+
+func (r *importRun) reject(ctx context.Context, line int, reason error) {
+	r.rejected++
+	slog.WarnContext(ctx, "registro rechazado", slog.Int("line", line), slog.String("reason", reason.Error()))
+}
+
+// mapHex remapea un ID original ya validado
+func (r *importRun) mapHex(id string) primitive.ObjectID {
+	original, _ := primitive.ObjectIDFromHex(id)
+	return r.remap.Map(original)
+}
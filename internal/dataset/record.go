@@ -0,0 +1,130 @@
+// internal/dataset/record.go
+package dataset
+
+import (
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+)
+
+// FormatVersion es la versión del formato de los archivos de datos
+const FormatVersion = 1
+
+// Tipos de registro. Un archivo empieza con la cabecera y sigue con los
+// usuarios, los follows y los tweets, en ese orden: cada registro solo puede
+// referirse a usuarios que aparecieron antes.
+const (
+	TypeHeader = "header"
+	TypeUser   = "user"
+	TypeFollow = "follow"
+	TypeTweet  = "tweet"
+)
+
+// Record es una línea del archivo NDJSON; según Type viene uno de los campos
+type Record struct {
+	Type   string        `json:"type"`
+	Header *Header       `json:"header,omitempty"`
+	User   *UserRecord   `json:"user,omitempty"`
+	Follow *FollowRecord `json:"follow,omitempty"`
+	Tweet  *TweetRecord  `json:"tweet,omitempty"`
+}
+
+// Header identifica el archivo; la importación la usa para no continuar con
+// el checkpoint de otro archivo
+type Header struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// UserRecord es una cuenta con los datos que no se pueden recalcular. Los
+// seguidos van en registros aparte y el contador de seguidores se recalcula.
+type UserRecord struct {
+	ID            string              `json:"id"`
+	Username      string              `json:"username"`
+	Email         string              `json:"email,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+	Roles         []string            `json:"roles,omitempty"`
+	Permissions   []string            `json:"permissions,omitempty"`
+	MutedWords    []string            `json:"muted_words,omitempty"`
+	Remote        *models.RemoteActor `json:"remote,omitempty"`
+	Suspension    *SuspensionRecord   `json:"suspension,omitempty"`
+	DeactivatedAt *time.Time          `json:"deactivated_at,omitempty"`
+}
+
+// SuspensionRecord es una suspensión sin el moderador ni el caso, que no
+// existen en la base de datos de destino
+type SuspensionRecord struct {
+	Reason    string     `json:"reason"`
+	Since     time.Time  `json:"since"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// FollowRecord es una relación de seguimiento entre dos usuarios del archivo
+type FollowRecord struct {
+	FollowerID string `json:"follower_id"`
+	FolloweeID string `json:"followee_id"`
+}
+
+// TweetRecord es un tweet con su estado de moderación. Los adjuntos no se
+// exportan: sus archivos no forman parte del conjunto de datos.
+type TweetRecord struct {
+	ID          string              `json:"id"`
+	UserID      string              `json:"user_id"`
+	Content     string              `json:"content"`
+	Poll        *PollRecord         `json:"poll,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	HiddenAt    *time.Time          `json:"hidden_at,omitempty"`
+	ContentHash string              `json:"content_hash,omitempty"`
+	Policy      *models.TweetPolicy `json:"policy,omitempty"`
+}
+
+// PollRecord es una encuesta con sus resultados. Los votos individuales no se
+// exportan, así que después de importarla nadie figura como votante.
+type PollRecord struct {
+	Options    []models.PollOption `json:"options"`
+	ExpiresAt  time.Time           `json:"expires_at"`
+	Closed     bool                `json:"closed"`
+	TotalVotes *int                `json:"total_votes,omitempty"`
+}
+
+// NewUserRecord arma el registro de un usuario. Con anonymize el email se
+// reemplaza por uno que no existe, para usar los datos fuera de producción.
+func NewUserRecord(user *models.User, anonymize bool) *UserRecord {
+	record := &UserRecord{
+		ID:            user.ID.Hex(),
+		Username:      user.Username,
+		Email:         user.Email,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		Roles:         user.Roles,
+		Permissions:   user.Permissions,
+		MutedWords:    user.MutedWords,
+		Remote:        user.Remote,
+		DeactivatedAt: user.DeactivatedAt,
+	}
+	if anonymize && record.Email != "" {
+		record.Email = "user-" + record.ID + "@example.invalid"
+	}
+	if s := user.Suspension; s != nil {
+		record.Suspension = &SuspensionRecord{Reason: s.Reason, Since: s.Since, ExpiresAt: s.ExpiresAt}
+	}
+	return record
+}
+
+// NewTweetRecord arma el registro de un tweet
+func NewTweetRecord(tweet *models.Tweet) *TweetRecord {
+	record := &TweetRecord{
+		ID:          tweet.ID.Hex(),
+		UserID:      tweet.UserID.Hex(),
+		Content:     tweet.Content,
+		CreatedAt:   tweet.CreatedAt,
+		HiddenAt:    tweet.HiddenAt,
+		ContentHash: tweet.ContentHash,
+		Policy:      tweet.Policy,
+	}
+	if p := tweet.Poll; p != nil {
+		record.Poll = &PollRecord{Options: p.Options, ExpiresAt: p.ExpiresAt, Closed: p.Closed, TotalVotes: p.TotalVotes}
+	}
+	return record
+}
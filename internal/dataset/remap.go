@@ -0,0 +1,34 @@
+// internal/dataset/remap.go
+package dataset
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Remapper asigna IDs nuevos a los de un archivo para que no choquen con los
+// que ya hay en la base de datos de destino. El ID nuevo conserva la marca de
+// tiempo del original, así que el orden por ID no cambia, y el resto sale de un
+// HMAC con la semilla de la importación: repetirla tras un fallo da los mismos
+// IDs sin guardar la tabla de correspondencias.
+type Remapper struct {
+	seed []byte
+}
+
+func NewRemapper(seed []byte) *Remapper {
+	return &Remapper{seed: seed}
+}
+
+// Map devuelve el ID nuevo de id
+func (m *Remapper) Map(id primitive.ObjectID) primitive.ObjectID {
+	mac := hmac.New(sha256.New, m.seed)
+	mac.Write(id[:])
+	sum := mac.Sum(nil)
+
+	var mapped primitive.ObjectID
+	copy(mapped[:4], id[:4])
+	copy(mapped[4:], sum)
+	return mapped
+}
@@ -0,0 +1,242 @@
+// internal/export/archive.go
+package export
+
+import (
+	"archive/zip"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+)
+
+// ContentType es el tipo de los archivos de exportación
+const ContentType = "application/zip"
+
+// ErrFileMissing lo devuelve Archive.Open cuando una variante ya no está en el
+// almacenamiento: se lista sin archivo en lugar de fallar la exportación
+var ErrFileMissing = errors.New("archivo no encontrado")
+
+//go:embed index.html.tmpl
+var indexSource string
+
+var indexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 UTC") },
+}).Parse(indexSource))
+
+// Profile son los datos de la cuenta tal como se exportan. Incluye los campos
+// privados que la API no muestra, como las palabras silenciadas.
+type Profile struct {
+	ID             string             `json:"id"`
+	Username       string             `json:"username"`
+	Email          string             `json:"email"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+	FollowersCount int                `json:"followers_count"`
+	Roles          []string           `json:"roles,omitempty"`
+	Permissions    []string           `json:"permissions,omitempty"`
+	MutedWords     []string           `json:"muted_words,omitempty"`
+	Suspension     *models.Suspension `json:"suspension,omitempty"`
+	DeactivatedAt  *time.Time         `json:"deactivated_at,omitempty"`
+}
+
+// NewProfile prepara el perfil de un usuario para exportarlo
+func NewProfile(u *models.User) Profile {
+	return Profile{
+		ID:             u.ID.Hex(),
+		Username:       u.Username,
+		Email:          u.Email,
+		CreatedAt:      u.CreatedAt,
+		UpdatedAt:      u.UpdatedAt,
+		FollowersCount: u.FollowersCount,
+		Roles:          u.Roles,
+		Permissions:    u.Permissions,
+		MutedWords:     u.MutedWords,
+		Suspension:     u.Suspension,
+		DeactivatedAt:  u.DeactivatedAt,
+	}
+}
+
+// Account es otra cuenta en las listas de seguidos y seguidores. Solo lleva
+// los datos públicos: el email de los demás no forma parte del archivo.
+type Account struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	ActorID  string `json:"actor_id,omitempty"`
+}
+
+// NewAccounts prepara una lista de usuarios para exportarla
+func NewAccounts(users []models.User) []Account {
+	accounts := make([]Account, 0, len(users))
+	for _, u := range users {
+		a := Account{ID: u.ID.Hex(), Username: u.Username}
+		if u.Remote != nil {
+			a.ActorID = u.Remote.ActorID
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts
+}
+
+// Archive es el contenido de una exportación de datos personales
+type Archive struct {
+	GeneratedAt time.Time
+	Profile     Profile
+	Tweets      []models.Tweet
+	Following   []Account
+	Followers   []Account
+	Likes       []models.Tweet
+	Bookmarks   []models.Tweet
+	Media       []models.Media
+	// Open abre el contenido de una variante de Media por su clave de
+	// almacenamiento; nil exporta los metadatos sin archivos
+	Open func(key string) (io.ReadCloser, error)
+}
+
+// Build escribe el ZIP en w: un JSON por tipo de dato, las imágenes en media/
+// y un index.html para consultarlo sin herramientas. Cada imagen se copia de
+// Open a su entrada sin cargarla entera en memoria.
+func (a *Archive) Build(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	// Las imágenes van primero porque media.json e index.html enlazan solo las
+	// que se pudieron leer
+	stored, err := a.writeFiles(zw)
+	if err != nil {
+		return err
+	}
+
+	media := a.withPaths(stored)
+	for _, f := range []struct {
+		name string
+		data any
+	}{
+		{"profile.json", a.Profile},
+		{"tweets.json", nonNil(a.Tweets)},
+		{"following.json", nonNil(a.Following)},
+		{"followers.json", nonNil(a.Followers)},
+		{"likes.json", nonNil(a.Likes)},
+		{"bookmarks.json", nonNil(a.Bookmarks)},
+		{"media.json", nonNil(media)},
+	} {
+		if err := a.writeJSON(zw, f.name, f.data); err != nil {
+			return err
+		}
+	}
+
+	index, err := a.create(zw, "index.html")
+	if err != nil {
+		return err
+	}
+	if err := indexTemplate.Execute(index, struct {
+		*Archive
+		Media []models.Media
+	}{a, media}); err != nil {
+		return fmt.Errorf("error al generar index.html: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("error al cerrar el archivo: %w", err)
+	}
+	return nil
+}
+
+// writeFiles copia al ZIP el contenido de cada variante y devuelve las claves
+// que se pudieron leer
+func (a *Archive) writeFiles(zw *zip.Writer) (map[string]bool, error) {
+	stored := make(map[string]bool)
+	if a.Open == nil {
+		return stored, nil
+	}
+	for _, m := range a.Media {
+		for name, v := range m.Variants {
+			if stored[v.Key] {
+				continue
+			}
+			ok, err := a.writeFile(zw, mediaPath(m, name, v), v.Key)
+			if err != nil {
+				return nil, err
+			}
+			stored[v.Key] = ok
+		}
+	}
+	return stored, nil
+}
+
+func (a *Archive) writeFile(zw *zip.Writer, name, key string) (bool, error) {
+	r, err := a.Open(key)
+	if errors.Is(err, ErrFileMissing) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error al leer archivo %s: %w", key, err)
+	}
+	defer r.Close()
+
+	// Las imágenes ya están comprimidas; se guardan tal cual
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: a.GeneratedAt})
+	if err != nil {
+		return false, fmt.Errorf("error al añadir %s: %w", name, err)
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return false, fmt.Errorf("error al añadir %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// withPaths copia los metadatos de Media con la ruta de cada variante dentro
+// del archivo en URL, o vacía si no se pudo leer
+func (a *Archive) withPaths(stored map[string]bool) []models.Media {
+	media := make([]models.Media, len(a.Media))
+	for i, m := range a.Media {
+		m.Variants = make(map[string]models.MediaVariant, len(a.Media[i].Variants))
+		for name, v := range a.Media[i].Variants {
+			v.URL = ""
+			if stored[v.Key] {
+				v.URL = mediaPath(m, name, v)
+			}
+			m.Variants[name] = v
+		}
+		media[i] = m
+	}
+	return media
+}
+
+// mediaPath es la ruta de una variante dentro del archivo
+func mediaPath(m models.Media, name string, v models.MediaVariant) string {
+	return fmt.Sprintf("media/%s/%s%s", m.ID.Hex(), name, path.Ext(v.Key))
+}
+
+func (a *Archive) writeJSON(zw *zip.Writer, name string, v any) error {
+	w, err := a.create(zw, name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("error al añadir %s: %w", name, err)
+	}
+	return nil
+}
+
+func (a *Archive) create(zw *zip.Writer, name string) (io.Writer, error) {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: a.GeneratedAt})
+	if err != nil {
+		return nil, fmt.Errorf("error al añadir %s: %w", name, err)
+	}
+	return w, nil
+}
+
+// nonNil hace que las listas vacías se escriban como [] y no como null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
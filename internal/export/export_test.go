@@ -0,0 +1,102 @@
+// internal/export/export_test.go
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func readZip(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = content
+	}
+	return files
+}
+
+func TestArchive_Build(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID(), Username: "ana", Email: "ana@example.com", MutedWords: []string{"spoiler"}}
+	other := models.User{ID: primitive.NewObjectID(), Username: "beto", Email: "beto@example.com"}
+	remote := models.User{ID: primitive.NewObjectID(), Username: "carla@remote.example", Remote: &models.RemoteActor{ActorID: "https://remote.example/users/carla"}}
+	mediaID := primitive.NewObjectID()
+	originalKey := "media/u/m/original.png"
+
+	a := &Archive{
+		GeneratedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Profile:     NewProfile(user),
+		Tweets:      []models.Tweet{{ID: primitive.NewObjectID(), UserID: user.ID, Content: "<script>alert(1)</script>", Media: []primitive.ObjectID{mediaID}}},
+		Following:   NewAccounts([]models.User{other, remote}),
+		Media: []models.Media{{ID: mediaID, UserID: user.ID, Variants: map[string]models.MediaVariant{
+			"original":  {Key: originalKey, ContentType: "image/png"},
+			"thumbnail": {Key: "media/u/m/thumbnail.png", ContentType: "image/png"},
+		}}},
+		Open: func(key string) (io.ReadCloser, error) {
+			if key != originalKey {
+				return nil, ErrFileMissing
+			}
+			return io.NopCloser(strings.NewReader("png-data")), nil
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, a.Build(&buf))
+	files := readZip(t, buf.Bytes())
+
+	for _, name := range []string{"profile.json", "tweets.json", "following.json", "followers.json", "likes.json", "bookmarks.json", "media.json", "index.html"} {
+		assert.Contains(t, files, name)
+	}
+
+	var profile map[string]any
+	require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+	assert.Equal(t, "ana@example.com", profile["email"])
+	assert.Equal(t, []any{"spoiler"}, profile["muted_words"])
+
+	assert.NotContains(t, string(files["following.json"]), "beto@example.com", "no se exporta el email de otras cuentas")
+	assert.Contains(t, string(files["following.json"]), "https://remote.example/users/carla")
+	assert.JSONEq(t, "[]", string(files["followers.json"]))
+	assert.JSONEq(t, "[]", string(files["likes.json"]))
+
+	path := "media/" + mediaID.Hex() + "/original.png"
+	assert.Equal(t, []byte("png-data"), files[path])
+	var media []models.Media
+	require.NoError(t, json.Unmarshal(files["media.json"], &media))
+	assert.Equal(t, path, media[0].Variants["original"].URL)
+	assert.Empty(t, media[0].Variants["thumbnail"].URL, "una variante que no se pudo leer queda sin archivo")
+	assert.Empty(t, a.Media[0].Variants["original"].URL, "no modifica los metadatos recibidos")
+
+	index := string(files["index.html"])
+	assert.Contains(t, index, "@ana")
+	assert.Contains(t, index, `href="`+path+`"`)
+	assert.NotContains(t, index, "<script>alert(1)</script>", "el contenido se escapa")
+	assert.Contains(t, index, "&lt;script&gt;")
+}
+
+func TestSigner(t *testing.T) {
+	s := NewSigner([]byte("secreto"))
+	expires := time.Unix(1714564800, 0)
+	sig := s.Sign("job1", expires)
+
+	assert.True(t, s.Verify("job1", expires, sig))
+	assert.False(t, s.Verify("job2", expires, sig), "otra exportación")
+	assert.False(t, s.Verify("job1", expires.Add(time.Hour), sig), "otro vencimiento")
+	assert.False(t, s.Verify("job1", expires, "zz"), "firma mal formada")
+	assert.False(t, NewSigner([]byte("otro")).Verify("job1", expires, sig), "otra clave")
+}
@@ -0,0 +1,237 @@
+// internal/feed/feed.go
+package feed
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	RSSContentType  = "application/rss+xml; charset=utf-8"
+	AtomContentType = "application/atom+xml; charset=utf-8"
+
+	// titleLength es la cantidad de caracteres del contenido usada como título
+	titleLength = 60
+)
+
+// Entry representa un elemento del feed (un tweet)
+type Entry struct {
+	ID        string
+	Author    string
+	Link      string
+	Content   string
+	Published time.Time
+}
+
+// Feed representa un feed independiente del formato de salida
+type Feed struct {
+	ID          string
+	Title       string
+	Description string
+	Link        string
+	SelfLink    string
+	Author      string
+	Entries     []Entry
+}
+
+// Updated devuelve la fecha de la entrada más reciente, o cero si no hay entradas
+func (f *Feed) Updated() time.Time {
+	var updated time.Time
+	for _, e := range f.Entries {
+		if e.Published.After(updated) {
+			updated = e.Published
+		}
+	}
+	return updated
+}
+
+// ETag calcula un validador fuerte a partir de las entradas del feed.
+// Los tweets son inmutables, por lo que sus IDs y fechas identifican el contenido;
+// variant distingue las distintas representaciones (rss, atom) del mismo feed.
+func (f *Feed) ETag(variant string) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", variant, f.ID, f.Title)
+	for _, e := range f.Entries {
+		fmt.Fprintf(h, "%s %d\n", e.ID, e.Published.UnixNano())
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)) + `"`
+}
+
+// TagURI construye un identificador único y estable según RFC 4151
+func TagURI(baseURL string, date time.Time, specific string) string {
+	host := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		host = u.Hostname()
+	}
+	return fmt.Sprintf("tag:%s,%s:%s", host, date.UTC().Format("2006-01-02"), specific)
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	Description string  `xml:"description"`
+	Author      string  `xml:"dc:creator,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS serializa el feed en formato RSS 2.0
+func (f *Feed) RSS() ([]byte, error) {
+	channel := rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: f.Description,
+		AtomLink:    atomLink{Href: f.SelfLink, Rel: "self", Type: strings.Split(RSSContentType, ";")[0]},
+		Items:       make([]rssItem, 0, len(f.Entries)),
+	}
+	if updated := f.Updated(); !updated.IsZero() {
+		channel.LastBuildDate = updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, e := range f.Entries {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       title(e.Content),
+			Link:        e.Link,
+			Description: e.Content,
+			Author:      e.Author,
+			GUID:        rssGUID{IsPermaLink: "false", Value: e.ID},
+			PubDate:     e.Published.UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: channel,
+	}
+	return marshal(doc)
+}
+
+type atomDocument struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Author   *atomPerson `xml:"author,omitempty"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published"`
+	Author    *atomPerson `xml:"author,omitempty"`
+	Link      *atomLink   `xml:"link,omitempty"`
+	Content   atomContent `xml:"content"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Atom serializa el feed en formato Atom 1.0 (RFC 4287)
+func (f *Feed) Atom() ([]byte, error) {
+	updated := f.Updated()
+	if updated.IsZero() {
+		// Atom exige <updated>; sin entradas usamos el epoch para mantener el documento estable
+		updated = time.Unix(0, 0)
+	}
+
+	doc := atomDocument{
+		ID:       f.ID,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.SelfLink, Rel: "self", Type: strings.Split(AtomContentType, ";")[0]},
+			{Href: f.Link, Rel: "alternate"},
+		},
+		Entries: make([]atomEntry, 0, len(f.Entries)),
+	}
+	if f.Author != "" {
+		doc.Author = &atomPerson{Name: f.Author}
+	}
+
+	for _, e := range f.Entries {
+		entry := atomEntry{
+			ID:        e.ID,
+			Title:     title(e.Content),
+			Updated:   e.Published.UTC().Format(time.RFC3339),
+			Published: e.Published.UTC().Format(time.RFC3339),
+			Content:   atomContent{Type: "text", Value: e.Content},
+		}
+		if e.Author != "" {
+			entry.Author = &atomPerson{Name: e.Author}
+		} else if doc.Author == nil {
+			// Atom exige autor a nivel de feed o de cada entrada
+			entry.Author = &atomPerson{Name: "unknown"}
+		}
+		if e.Link != "" {
+			entry.Link = &atomLink{Href: e.Link, Rel: "alternate"}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return marshal(doc)
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	out, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// title genera un título corto a partir del contenido del tweet
+func title(content string) string {
+	content = strings.Join(strings.Fields(content), " ")
+	if utf8.RuneCountInString(content) <= titleLength {
+		return content
+	}
+	runes := []rune(content)
+	return strings.TrimSpace(string(runes[:titleLength])) + "…"
+}
@@ -0,0 +1,92 @@
+// internal/feed/feed_test.go
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testFeed() *Feed {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &Feed{
+		ID:          "tag:example.com,2024-01-01:users/1",
+		Title:       "Tweets de @testuser",
+		Description: "Últimos tweets",
+		Link:        "https://example.com/api/v1/users/1",
+		SelfLink:    "https://example.com/users/testuser/feed.atom",
+		Author:      "testuser",
+		Entries: []Entry{
+			{ID: "tag:example.com,2024-05-01:tweets/b", Content: "<b>hola</b> & adiós", Published: base.Add(time.Hour)},
+			{ID: "tag:example.com,2024-05-01:tweets/a", Content: "primer tweet", Published: base},
+		},
+	}
+}
+
+func TestFeed_RSS(t *testing.T) {
+	out, err := testFeed().RSS()
+	assert.NoError(t, err)
+
+	var doc rssDocument
+	assert.NoError(t, xml.Unmarshal(out, &doc))
+	assert.Equal(t, "2.0", doc.Version)
+	assert.Len(t, doc.Channel.Items, 2)
+	assert.Equal(t, "false", doc.Channel.Items[0].GUID.IsPermaLink)
+	assert.Equal(t, "tag:example.com,2024-05-01:tweets/b", doc.Channel.Items[0].GUID.Value)
+	assert.Equal(t, "<b>hola</b> & adiós", doc.Channel.Items[0].Description)
+	assert.Equal(t, "Wed, 01 May 2024 13:00:00 +0000", doc.Channel.LastBuildDate)
+
+	// El contenido debe quedar escapado en el XML
+	assert.Contains(t, string(out), "&lt;b&gt;hola&lt;/b&gt; &amp; adiós")
+	assert.NotContains(t, string(out), "<b>hola</b>")
+}
+
+func TestFeed_Atom(t *testing.T) {
+	out, err := testFeed().Atom()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(out), xml.Header))
+	assert.Contains(t, string(out), `xmlns="http://www.w3.org/2005/Atom"`)
+
+	var doc atomDocument
+	assert.NoError(t, xml.Unmarshal(out, &doc))
+	assert.Equal(t, "2024-05-01T13:00:00Z", doc.Updated)
+	assert.Len(t, doc.Entries, 2)
+	assert.Equal(t, "2024-05-01T12:00:00Z", doc.Entries[1].Updated)
+	assert.Equal(t, "<b>hola</b> & adiós", doc.Entries[0].Content.Value)
+	assert.Equal(t, "testuser", doc.Author.Name)
+}
+
+func TestFeed_AtomWithoutEntries(t *testing.T) {
+	f := testFeed()
+	f.Entries = nil
+	f.Author = ""
+
+	out, err := f.Atom()
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "<updated>1970-01-01T00:00:00Z</updated>")
+}
+
+func TestFeed_ETag(t *testing.T) {
+	f := testFeed()
+	etag := f.ETag("rss")
+
+	assert.Equal(t, etag, testFeed().ETag("rss"), "el ETag debe ser estable")
+	assert.NotEqual(t, etag, f.ETag("atom"), "cada representación tiene su propio ETag")
+
+	f.Entries = append(f.Entries, Entry{ID: "c", Content: "nuevo", Published: time.Now()})
+	assert.NotEqual(t, etag, f.ETag("rss"), "un tweet nuevo debe cambiar el ETag")
+}
+
+func TestTagURI(t *testing.T) {
+	date := time.Date(2024, 3, 9, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, "tag:example.com,2024-03-09:tweets/abc", TagURI("https://example.com:8080", date, "tweets/abc"))
+}
+
+func TestTitle(t *testing.T) {
+	assert.Equal(t, "corto", title("  corto \n"))
+	long := strings.Repeat("á", 100)
+	assert.Equal(t, strings.Repeat("á", titleLength)+"…", title(long))
+}
@@ -0,0 +1,272 @@
+// internal/gql/gql_test.go
+package gql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ffelixf/microblog-platform/internal/memstore"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// countingUsers cuenta las consultas por lotes de usuarios
+type countingUsers struct {
+	*memstore.Users
+	batches int
+}
+
+func (c *countingUsers) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.User, error) {
+	c.batches++
+	return c.Users.GetByIDs(ctx, ids)
+}
+
+type fixture struct {
+	server *Server
+	users  *countingUsers
+	ids    []string
+}
+
+// setupServer crea n usuarios; el primero sigue a todos los demás, que publican
+// dos tweets cada uno
+func setupServer(t *testing.T, n int, limits Limits) *fixture {
+	ctx := context.Background()
+	users := &countingUsers{Users: memstore.NewUsers()}
+	tweets := memstore.NewTweets()
+
+	ids, err := memstore.SeedUsers(ctx, users.Users, n)
+	require.NoError(t, err)
+	f := &fixture{users: users, ids: ids}
+	for _, id := range f.ids[1:] {
+		require.NoError(t, users.FollowUser(ctx, f.ids[0], id))
+		author, _ := primitive.ObjectIDFromHex(id)
+		for j := range 2 {
+			require.NoError(t, tweets.Create(ctx, &models.Tweet{UserID: author, Content: fmt.Sprintf("tweet %d", j)}))
+		}
+	}
+
+	userService := service.NewUserService(users, nil, nil)
+	timelineService := service.NewTimelineService(tweets, users, memstore.NewPolls(tweets), nil)
+	server, err := NewServer(userService, timelineService, limits)
+	require.NoError(t, err)
+	f.server = server
+	return f
+}
+
+func (f *fixture) do(t *testing.T, req Request) map[string]interface{} {
+	result := f.server.Do(context.Background(), req)
+	require.Empty(t, result.Errors)
+	return result.Data.(map[string]interface{})
+}
+
+// field recorre el resultado por los nombres de los campos
+func field(data interface{}, path ...string) interface{} {
+	for _, name := range path {
+		data = data.(map[string]interface{})[name]
+	}
+	return data
+}
+
+func TestLoader_BatchesPendingKeys(t *testing.T) {
+	var calls [][]int
+	loader := NewLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		calls = append(calls, keys)
+		values := make(map[int]string)
+		for _, key := range keys {
+			if key > 0 {
+				values[key] = fmt.Sprint(key)
+			}
+		}
+		return values, nil
+	})
+	ctx := context.Background()
+
+	one, two, missing := loader.Load(ctx, 1), loader.Load(ctx, 2), loader.Load(ctx, -1)
+	many := loader.LoadMany(ctx, []int{2, -1, 1})
+	value, err := two()
+	require.NoError(t, err)
+	assert.Equal(t, "2", value)
+	value, _ = one()
+	assert.Equal(t, "1", value)
+	value, _ = missing()
+	assert.Empty(t, value)
+	values, err := many()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "1"}, values)
+	assert.Equal(t, [][]int{{1, 2, -1}}, calls)
+
+	// Lo ya cargado no se vuelve a pedir
+	value, _ = loader.Load(ctx, 1)()
+	assert.Equal(t, "1", value)
+	assert.Equal(t, 1, loader.Batches())
+
+	failing := NewLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		return nil, errors.New("boom")
+	})
+	_, err = failing.Load(ctx, 1)()
+	assert.EqualError(t, err, "boom")
+}
+
+func TestServer_TimelineLoadsAuthorsInOneBatch(t *testing.T) {
+	f := setupServer(t, 6, Limits{})
+	query := `query($id: ID!, $after: String) {
+		timeline(userId: $id, first: 4, after: $after) {
+			edges { cursor node { id content author { username } } }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`
+
+	seen := make(map[string]bool)
+	var after interface{}
+	pages := 0
+	for {
+		data := f.do(t, Request{Query: query, Variables: map[string]interface{}{"id": f.ids[0], "after": after}})
+		edges := field(data, "timeline", "edges").([]interface{})
+		for _, e := range edges {
+			id := field(e, "node", "id").(string)
+			assert.False(t, seen[id], "tweet repetido %s", id)
+			seen[id] = true
+			assert.NotEmpty(t, field(e, "node", "author", "username"))
+		}
+		pages++
+		if !field(data, "timeline", "pageInfo", "hasNextPage").(bool) {
+			break
+		}
+		after = field(data, "timeline", "pageInfo", "endCursor")
+	}
+	assert.Len(t, seen, 10)
+	assert.Equal(t, 3, pages)
+	// Una consulta de autores por página, no una por tweet
+	assert.Equal(t, pages, f.users.batches)
+}
+
+func TestServer_NestedConnections(t *testing.T) {
+	f := setupServer(t, 4, Limits{})
+	data := f.do(t, Request{
+		Query: `query($id: ID!) {
+			viewer { username }
+			user(id: $id) {
+				followingCount
+				following(first: 2) {
+					edges { node { username followers { edges { node { id } } } tweets(first: 1) { edges { node { content } } } } }
+					pageInfo { hasNextPage endCursor }
+				}
+			}
+		}`,
+		Variables: map[string]interface{}{"id": f.ids[0]},
+		ViewerID:  f.ids[1],
+	})
+	assert.Equal(t, "user1", field(data, "viewer", "username"))
+	assert.Equal(t, 3, field(data, "user", "followingCount"))
+
+	following := field(data, "user", "following")
+	edges := field(following, "edges").([]interface{})
+	require.Len(t, edges, 2)
+	assert.Equal(t, "user1", field(edges[0], "node", "username"))
+	followers := field(edges[0], "node", "followers", "edges").([]interface{})
+	require.Len(t, followers, 1)
+	assert.Equal(t, f.ids[0], field(followers[0], "node", "id"))
+	assert.Len(t, field(edges[0], "node", "tweets", "edges"), 1)
+	assert.Equal(t, true, field(following, "pageInfo", "hasNextPage"))
+
+	// La segunda página empieza después del cursor
+	data = f.do(t, Request{
+		Query:     `query($id: ID!, $after: String) { user(id: $id) { following(first: 2, after: $after) { edges { node { username } } pageInfo { hasNextPage } } } }`,
+		Variables: map[string]interface{}{"id": f.ids[0], "after": field(following, "pageInfo", "endCursor")},
+	})
+	edges = field(data, "user", "following", "edges").([]interface{})
+	require.Len(t, edges, 1)
+	assert.Equal(t, "user3", field(edges[0], "node", "username"))
+	assert.Equal(t, false, field(data, "user", "following", "pageInfo", "hasNextPage"))
+}
+
+func TestServer_Follow(t *testing.T) {
+	f := setupServer(t, 3, Limits{})
+	mutation := `mutation($target: ID!) { follow(targetId: $target) { id followersCount } }`
+	vars := map[string]interface{}{"target": f.ids[0]}
+
+	data := f.do(t, Request{Query: mutation, Variables: vars, ViewerID: f.ids[2]})
+	assert.Equal(t, f.ids[0], field(data, "follow", "id"))
+	assert.Equal(t, 1, field(data, "follow", "followersCount"))
+
+	data = f.do(t, Request{Query: `mutation($target: ID!) { unfollow(targetId: $target) { followersCount } }`, Variables: vars, ViewerID: f.ids[2]})
+	assert.Equal(t, 0, field(data, "unfollow", "followersCount"))
+
+	result := f.server.Do(context.Background(), Request{Query: mutation, Variables: vars})
+	require.Len(t, result.Errors, 1)
+	assert.ErrorIs(t, OriginalError(result.Errors[0]), rbac.ErrUnauthenticated)
+
+	result = f.server.Do(context.Background(), Request{Query: mutation, Variables: vars, ViewerID: f.ids[2], QueryOnly: true})
+	require.Len(t, result.Errors, 1)
+	assert.ErrorIs(t, OriginalError(result.Errors[0]), ErrMutationNotAllowed)
+}
+
+func TestIsMutation(t *testing.T) {
+	assert.True(t, IsMutation(`mutation { follow(targetId: "x") { id } }`, ""))
+	assert.False(t, IsMutation(`{ user(id: "x") { id } }`, ""))
+	assert.False(t, IsMutation(`query { user(id: "x") { id } }`, ""))
+
+	both := `query Read { user(id: "x") { id } } mutation Write { follow(targetId: "x") { id } }`
+	assert.False(t, IsMutation(both, "Read"))
+	assert.True(t, IsMutation(both, "Write"))
+	assert.False(t, IsMutation(`mutation {`, ""), "una consulta inválida no es una mutación")
+}
+
+func TestServer_Errors(t *testing.T) {
+	f := setupServer(t, 2, Limits{})
+	ctx := context.Background()
+
+	result := f.server.Do(ctx, Request{Query: `{ timeline(userId: "x") { edges { cursor } } }`})
+	require.Len(t, result.Errors, 1)
+	assert.ErrorIs(t, OriginalError(result.Errors[0]), service.ErrInvalidID)
+
+	// Los errores de los loaders también conservan el error original
+	result = f.server.Do(ctx, Request{Query: fmt.Sprintf(`{ timeline(userId: %q, after: "nope") { edges { cursor } } }`, f.ids[0])})
+	require.Len(t, result.Errors, 1)
+	assert.ErrorIs(t, OriginalError(result.Errors[0]), ErrInvalidCursor)
+
+	result = f.server.Do(ctx, Request{Query: `{ user(id: "000000000000000000000000") { username } }`})
+	assert.Empty(t, result.Errors)
+	assert.Nil(t, field(result.Data, "user"))
+
+	// Los errores de sintaxis y validación no tienen error original
+	result = f.server.Do(ctx, Request{Query: `{ user(id: "x") { password } }`})
+	require.NotEmpty(t, result.Errors)
+	assert.Nil(t, OriginalError(result.Errors[0]))
+}
+
+func TestLimits(t *testing.T) {
+	f := setupServer(t, 2, Limits{MaxDepth: 6, MaxComplexity: 100})
+	ctx := context.Background()
+	measure := func(query string, vars map[string]interface{}) error {
+		result := f.server.Do(ctx, Request{Query: query, Variables: vars})
+		if len(result.Errors) == 0 {
+			return nil
+		}
+		return OriginalError(result.Errors[0])
+	}
+
+	assert.NoError(t, measure(`{ viewer { following { edges { node { username } } } } }`, nil))
+	assert.ErrorIs(t, measure(`{ viewer { following { edges { node { followers { edges { cursor } } } } } } }`, nil), ErrQueryTooDeep)
+	// Los fragmentos cuentan como si los campos estuvieran en línea
+	assert.ErrorIs(t, measure(`{ viewer { ...F } } fragment F on User { following { edges { node { tweets { pageInfo { hasNextPage } } } } } }`, nil), ErrQueryTooDeep)
+
+	// Cada usuario de la conexión cuesta 7: con 20 se superan los 100
+	wide := `query($n: Int) { viewer { following(first: $n) { edges { cursor node { id username createdAt remote } } } } }`
+	assert.NoError(t, measure(wide, map[string]interface{}{"n": 5}))
+	assert.ErrorIs(t, measure(wide, map[string]interface{}{"n": 20}), ErrQueryTooComplex)
+	// Sin first cuenta el tamaño de página por defecto, 10
+	assert.NoError(t, measure(wide, nil))
+
+	// La introspección no cuenta
+	assert.NoError(t, measure(`{ __schema { types { name fields { name type { name ofType { name ofType { name } } } } } } }`, nil))
+}
+
+var _ Users = (*service.UserService)(nil)
+var _ Timelines = (*service.TimelineService)(nil)
@@ -0,0 +1,158 @@
+// internal/gql/limits.go
+package gql
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+var (
+	ErrQueryTooDeep    = apperr.Validation("query_too_deep", "la consulta tiene demasiados niveles")
+	ErrQueryTooComplex = apperr.Validation("query_too_complex", "la consulta pide demasiados datos")
+)
+
+// Limits acota lo que puede pedir una consulta; se comprueba antes de ejecutarla
+type Limits struct {
+	// MaxDepth es el anidamiento máximo de campos
+	MaxDepth int
+	// MaxComplexity es el costo máximo de la consulta. Cada campo cuesta 1 y lo
+	// que se pide dentro de una conexión cuenta tantas veces como elementos
+	// puede traer (first, o el tamaño de página por defecto).
+	MaxComplexity int
+}
+
+// cost es la profundidad y el costo de una selección
+type cost struct {
+	depth      int
+	complexity int
+}
+
+// check mide la operación que se va a ejecutar. Los campos de introspección no
+// cuentan: su tamaño depende del esquema, no de los datos.
+func (l Limits) check(schema *graphql.Schema, doc *ast.Document, operationName string, variables map[string]interface{}) error {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				op = def
+			}
+		}
+	}
+	if op == nil {
+		// La ejecución informa la operación que falta o sobra
+		return nil
+	}
+
+	root := schema.QueryType()
+	if op.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+	m := &measure{fragments: fragments, variables: variables, visiting: make(map[string]bool)}
+	c := m.selectionSet(op.SelectionSet, root)
+	if l.MaxDepth > 0 && c.depth > l.MaxDepth {
+		return ErrQueryTooDeep
+	}
+	if l.MaxComplexity > 0 && c.complexity > l.MaxComplexity {
+		return ErrQueryTooComplex
+	}
+	return nil
+}
+
+type measure struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	// visiting evita recorrer sin fin fragmentos cíclicos, que la validación rechaza
+	visiting map[string]bool
+}
+
+// selectionSet mide una selección sobre el tipo parent; parent es nil si no se
+// conoce, y entonces cada campo cuesta 1 sin multiplicar
+func (m *measure) selectionSet(set *ast.SelectionSet, parent *graphql.Object) cost {
+	var total cost
+	if set == nil {
+		return total
+	}
+	for _, selection := range set.Selections {
+		var c cost
+		switch selection := selection.(type) {
+		case *ast.Field:
+			c = m.field(selection, parent)
+		case *ast.InlineFragment:
+			c = m.selectionSet(selection.SelectionSet, parent)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment := m.fragments[name]
+			if fragment == nil || m.visiting[name] {
+				continue
+			}
+			m.visiting[name] = true
+			c = m.selectionSet(fragment.SelectionSet, parent)
+			delete(m.visiting, name)
+		}
+		total.depth = max(total.depth, c.depth)
+		total.complexity += c.complexity
+	}
+	return total
+}
+
+func (m *measure) field(field *ast.Field, parent *graphql.Object) cost {
+	name := field.Name.Value
+	if strings.HasPrefix(name, "__") {
+		return cost{}
+	}
+
+	var child *graphql.Object
+	multiplier := 1
+	if parent != nil {
+		if def := parent.Fields()[name]; def != nil {
+			child, _ = graphql.GetNamed(def.Type).(*graphql.Object)
+			if hasArgument(def, "first") {
+				multiplier = m.first(field)
+			}
+		}
+	}
+	c := m.selectionSet(field.SelectionSet, child)
+	return cost{depth: c.depth + 1, complexity: 1 + multiplier*c.complexity}
+}
+
+// first devuelve cuántos elementos puede traer una conexión, normalizado como
+// lo normalizan los servicios
+func (m *measure) first(field *ast.Field) int {
+	first := 0
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			first, _ = strconv.Atoi(value.Value)
+		case *ast.Variable:
+			switch v := m.variables[value.Name.Value].(type) {
+			case int:
+				first = v
+			case float64:
+				first = int(v)
+			}
+		}
+	}
+	_, first = service.Paginate(1, first)
+	return first
+}
+
+func hasArgument(def *graphql.FieldDefinition, name string) bool {
+	for _, arg := range def.Args {
+		if arg.Name() == name {
+			return true
+		}
+	}
+	return false
+}
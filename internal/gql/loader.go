@@ -0,0 +1,107 @@
+// internal/gql/loader.go
+package gql
+
+import (
+	"context"
+	"sync"
+)
+
+// Loader agrupa en una sola llamada a fetch las claves que se piden durante la
+// ejecución de una consulta. Load no carga nada: devuelve una función que, la
+// primera vez que se llama cualquiera de ellas, carga todas las claves
+// pendientes. graphql-go completa primero todos los campos de un nivel y
+// después llama a esas funciones, así que, por ejemplo, los autores de una
+// página de tweets se cargan con una sola consulta. Un Loader vive lo que una
+// petición: su caché no llega a quedar desactualizada.
+type Loader[K, V comparable] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	loaded  map[K]bool
+	values  map[K]V
+	errs    map[K]error
+	batches int
+}
+
+// NewLoader crea un loader; fetch devuelve los valores encontrados indexados
+// por clave y las claves que faltan se resuelven con el valor cero
+func NewLoader[K, V comparable](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:  fetch,
+		queued: make(map[K]bool),
+		loaded: make(map[K]bool),
+		values: make(map[K]V),
+		errs:   make(map[K]error),
+	}
+}
+
+// Load encola la clave y devuelve la función que obtiene su valor
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	if !l.loaded[key] && !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if !l.loaded[key] {
+			l.dispatch(ctx)
+		}
+		return l.values[key], l.errs[key]
+	}
+}
+
+// LoadMany encola varias claves y devuelve la función que obtiene sus valores,
+// en el mismo orden y sin las que no existen
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) func() ([]V, error) {
+	thunks := make([]func() (V, error), len(keys))
+	for i, key := range keys {
+		thunks[i] = l.Load(ctx, key)
+	}
+	return func() ([]V, error) {
+		var zero V
+		values := make([]V, 0, len(keys))
+		for _, thunk := range thunks {
+			value, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			if value != zero {
+				values = append(values, value)
+			}
+		}
+		return values, nil
+	}
+}
+
+// Batches devuelve cuántas veces se llamó a fetch
+func (l *Loader[K, V]) Batches() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.batches
+}
+
+// dispatch carga todas las claves pendientes; hay que tener el lock
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+	l.batches++
+
+	values, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		delete(l.queued, key)
+		l.loaded[key] = true
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+		if value, ok := values[key]; ok {
+			l.values[key] = value
+		}
+	}
+}
@@ -0,0 +1,408 @@
+// internal/gql/schema.go
+package gql
+
+import (
+	"context"
+	"encoding/base64"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/graphql-go/graphql"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrInvalidCursor = service.ErrInvalidCursor
+
+// connection, edge y pageInfo son las conexiones de Relay; los resolvers por
+// defecto leen sus campos por la etiqueta json
+type connection struct {
+	Edges    []edge   `json:"edges"`
+	PageInfo pageInfo `json:"pageInfo"`
+}
+
+type edge struct {
+	Cursor string      `json:"cursor"`
+	Node   interface{} `json:"node"`
+}
+
+type pageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor"`
+}
+
+// buildSchema arma el esquema; los resolvers cierran sobre el servidor
+func (s *Server) buildSchema() (graphql.Schema, error) {
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"endCursor":   &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	// User y Tweet se refieren el uno al otro, así que sus campos se arman tarde
+	var userType, tweetType *graphql.Object
+	userConnection := connectionType("User", func() *graphql.Object { return userType }, pageInfoType)
+	tweetConnection := connectionType("Tweet", func() *graphql.Object { return tweetType }, pageInfoType)
+
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: (graphql.FieldsThunk)(func() graphql.Fields {
+			return graphql.Fields{
+				"id":             &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: resolveUser(func(u *models.User) interface{} { return u.ID.Hex() })},
+				"username":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"createdAt":      &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: resolveUser(func(u *models.User) interface{} { return u.CreatedAt })},
+				"remote":         &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: resolveUser(func(u *models.User) interface{} { return u.IsRemote() })},
+				"followersCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: resolveUser(func(u *models.User) interface{} { return u.FollowersCount })},
+				"followingCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: resolveUser(func(u *models.User) interface{} { return len(u.Following) })},
+				"following": &graphql.Field{
+					Type:    graphql.NewNonNull(userConnection),
+					Args:    pageArgs(),
+					Resolve: s.resolveFollowing,
+				},
+				"followers": &graphql.Field{
+					Type:    graphql.NewNonNull(userConnection),
+					Args:    pageArgs(),
+					Resolve: s.resolveFollowers,
+				},
+				"tweets": &graphql.Field{
+					Type:    graphql.NewNonNull(tweetConnection),
+					Args:    pageArgs(),
+					Resolve: s.resolveUserTweets,
+				},
+			}
+		}),
+	})
+
+	tweetType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Tweet",
+		Fields: (graphql.FieldsThunk)(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: resolveTweet(func(t *models.Tweet) interface{} { return t.ID.Hex() })},
+				"content":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"hashtags":  &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))), Resolve: resolveTweet(func(t *models.Tweet) interface{} { return nonNil(t.Hashtags) })},
+				"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: resolveTweet(func(t *models.Tweet) interface{} { return t.CreatedAt })},
+				// Los autores de todos los tweets de una respuesta se cargan juntos
+				"author": &graphql.Field{
+					Type: userType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						tweet := p.Source.(*models.Tweet)
+						return thunk(stateFrom(p.Context).users.Load(p.Context, tweet.UserID)), nil
+					},
+				},
+			}
+		}),
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"viewer": &graphql.Field{
+				Type:        userType,
+				Description: "El usuario que hace la petición; null si es anónima",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					viewerID := stateFrom(p.Context).viewerID
+					if viewerID == "" {
+						return nil, nil
+					}
+					return s.loadUser(p, viewerID)
+				},
+			},
+			"user": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return s.loadUser(p, p.Args["id"].(string))
+				},
+			},
+			"tweet": &graphql.Field{
+				Type: tweetType,
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := primitive.ObjectIDFromHex(p.Args["id"].(string))
+					if err != nil {
+						return nil, service.ErrInvalidID
+					}
+					return thunk(stateFrom(p.Context).tweets.Load(p.Context, id)), nil
+				},
+			},
+			"timeline": &graphql.Field{
+				Type: graphql.NewNonNull(tweetConnection),
+				Args: graphql.FieldConfigArgument{
+					"userId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"first":  &graphql.ArgumentConfig{Type: graphql.Int},
+					"after":  &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					first, after, err := tweetPage(p)
+					if err != nil {
+						return nil, err
+					}
+					slice, err := s.timelines.TimelineAfter(p.Context, p.Args["userId"].(string), after, first)
+					if err != nil {
+						return nil, err
+					}
+					return tweetEdges(slice), nil
+				},
+			},
+		},
+	})
+
+	followArgs := graphql.FieldConfigArgument{"targetId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}}
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"follow": &graphql.Field{
+				Type:        graphql.NewNonNull(userType),
+				Description: "El usuario que hace la petición sigue a targetId; devuelve el usuario seguido",
+				Args:        followArgs,
+				Resolve:     s.resolveFollow(s.users.Follow),
+			},
+			"unfollow": &graphql.Field{
+				Type:        graphql.NewNonNull(userType),
+				Description: "El usuario que hace la petición deja de seguir a targetId; devuelve ese usuario",
+				Args:        followArgs,
+				Resolve:     s.resolveFollow(s.users.Unfollow),
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// connectionType arma el tipo de conexión de Relay de los nodos de node
+func connectionType(name string, node func() *graphql.Object, pageInfoType *graphql.Object) *graphql.Object {
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: name + "Edge",
+		Fields: (graphql.FieldsThunk)(func() graphql.Fields {
+			return graphql.Fields{
+				"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"node":   &graphql.Field{Type: graphql.NewNonNull(node())},
+			}
+		}),
+	})
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name + "Connection",
+		Fields: graphql.Fields{
+			"edges":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType)))},
+			"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+		},
+	})
+}
+
+// pageArgs son los argumentos de una conexión; first se normaliza como el
+// límite de las páginas de la API REST
+func pageArgs() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"first": &graphql.ArgumentConfig{Type: graphql.Int},
+		"after": &graphql.ArgumentConfig{Type: graphql.String},
+	}
+}
+
+func resolveUser(field func(*models.User) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return field(p.Source.(*models.User)), nil
+	}
+}
+
+func resolveTweet(field func(*models.Tweet) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return field(p.Source.(*models.Tweet)), nil
+	}
+}
+
+// thunk adapta la función de un loader a la que espera graphql-go; un valor que
+// no existe se resuelve a null
+func thunk[V comparable](load func() (V, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		var zero V
+		value, err := load()
+		if err != nil || value == zero {
+			return nil, err
+		}
+		return value, nil
+	}
+}
+
+func nonNil(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// loadUser carga un usuario por ID con el loader de la petición
+func (s *Server) loadUser(p graphql.ResolveParams, id string) (interface{}, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, service.ErrInvalidID
+	}
+	return thunk(stateFrom(p.Context).users.Load(p.Context, oid)), nil
+}
+
+func (s *Server) resolveUserTweets(p graphql.ResolveParams) (interface{}, error) {
+	first, after, err := tweetPage(p)
+	if err != nil {
+		return nil, err
+	}
+	slice, err := s.timelines.UserTweetsAfter(p.Context, p.Source.(*models.User).ID.Hex(), after, first)
+	if err != nil {
+		return nil, err
+	}
+	return tweetEdges(slice), nil
+}
+
+// resolveFollowing pagina los usuarios seguidos, que están en el propio usuario,
+// y los carga con el loader: los de todos los usuarios de un nivel van juntos.
+// Las cuentas inactivas no aparecen, como en la API REST.
+func (s *Server) resolveFollowing(p graphql.ResolveParams) (interface{}, error) {
+	user := p.Source.(*models.User)
+	page, hasNext, err := idPage(user.Following, p)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]primitive.ObjectID, 0, len(page))
+	for _, id := range page {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			ids = append(ids, oid)
+		}
+	}
+	load := stateFrom(p.Context).users.LoadMany(p.Context, ids)
+	return func() (interface{}, error) {
+		users, err := load()
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		users = slices.DeleteFunc(users, func(u *models.User) bool { return !u.IsActiveAt(now) })
+		return userEdges(users, page, hasNext), nil
+	}, nil
+}
+
+// resolveFollowers pagina los seguidores, que sí hay que consultar
+func (s *Server) resolveFollowers(p graphql.ResolveParams) (interface{}, error) {
+	followers, err := s.users.Followers(p.Context, p.Source.(*models.User).ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(followers))
+	for i := range followers {
+		ids[i] = followers[i].ID.Hex()
+	}
+	page, hasNext, err := idPage(ids, p)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]*models.User, 0, len(page))
+	for i := range followers {
+		if slices.Contains(page, ids[i]) {
+			users = append(users, &followers[i])
+		}
+	}
+	return userEdges(users, page, hasNext), nil
+}
+
+// resolveFollow resuelve follow y unfollow con la acción del servicio
+func (s *Server) resolveFollow(action func(ctx context.Context, userID, targetID string) error) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		viewerID := stateFrom(p.Context).viewerID
+		if viewerID == "" {
+			return nil, rbac.ErrUnauthenticated
+		}
+		targetID := p.Args["targetId"].(string)
+		if err := action(p.Context, viewerID, targetID); err != nil {
+			return nil, err
+		}
+		// Se lee de nuevo para devolver el contador de seguidores actualizado
+		return s.users.Get(p.Context, targetID)
+	}
+}
+
+// tweetPage lee first y after de una conexión de tweets
+func tweetPage(p graphql.ResolveParams) (int, *models.TweetPosition, error) {
+	first, _ := p.Args["first"].(int)
+	cursor, _ := p.Args["after"].(string)
+	if cursor == "" {
+		return first, nil, nil
+	}
+	after, err := service.DecodeTweetCursor(cursor)
+	if err != nil {
+		return 0, nil, err
+	}
+	return first, after, nil
+}
+
+// tweetEdges arma la conexión de una porción de tweets. endCursor es la
+// posición donde siguen los tweets aunque se hayan filtrado todos los de la
+// porción, por ejemplo por palabras silenciadas.
+func tweetEdges(slice *service.TimelineSlice) *connection {
+	conn := &connection{Edges: make([]edge, len(slice.Tweets))}
+	for i := range slice.Tweets {
+		conn.Edges[i] = edge{Cursor: service.EncodeTweetCursor(slice.Tweets[i].Position()), Node: &slice.Tweets[i]}
+	}
+	switch {
+	case slice.Next != nil:
+		end := service.EncodeTweetCursor(*slice.Next)
+		conn.PageInfo = pageInfo{HasNextPage: true, EndCursor: &end}
+	case len(conn.Edges) > 0:
+		conn.PageInfo.EndCursor = &conn.Edges[len(conn.Edges)-1].Cursor
+	}
+	return conn
+}
+
+// idPage devuelve los IDs que siguen al cursor after, hasta first. Si el ID del
+// cursor ya no está en la lista (por ejemplo, se dejó de seguir) no hay más.
+func idPage(ids []string, p graphql.ResolveParams) ([]string, bool, error) {
+	first, _ := p.Args["first"].(int)
+	_, first = service.Paginate(1, first)
+	start := 0
+	if cursor, _ := p.Args["after"].(string); cursor != "" {
+		id, err := decodeUserCursor(cursor)
+		if err != nil {
+			return nil, false, err
+		}
+		start = len(ids)
+		if i := slices.Index(ids, id); i >= 0 {
+			start = i + 1
+		}
+	}
+	end := min(start+first, len(ids))
+	return ids[start:end], end < len(ids), nil
+}
+
+// userEdges arma la conexión de una página de usuarios. El cursor final es el
+// último ID de la página aunque ese usuario no se muestre.
+func userEdges(users []*models.User, page []string, hasNext bool) *connection {
+	conn := &connection{Edges: make([]edge, len(users)), PageInfo: pageInfo{HasNextPage: hasNext}}
+	for i, user := range users {
+		conn.Edges[i] = edge{Cursor: encodeUserCursor(user.ID.Hex()), Node: user}
+	}
+	if len(page) > 0 {
+		end := encodeUserCursor(page[len(page)-1])
+		conn.PageInfo.EndCursor = &end
+	}
+	return conn
+}
+
+// Los cursores de usuarios siguen el formato de los de tweets
+// (service.EncodeTweetCursor) con su propio prefijo: uno no sirve en el otro listado
+
+func encodeUserCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("user:" + id))
+}
+
+func decodeUserCursor(cursor string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+	id, ok := strings.CutPrefix(string(raw), "user:")
+	if !ok || !primitive.IsValidObjectID(id) {
+		return "", ErrInvalidCursor
+	}
+	return id, nil
+}
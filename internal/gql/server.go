@@ -0,0 +1,168 @@
+// internal/gql/server.go
+package gql
+
+import (
+	"context"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrMutationNotAllowed = apperr.Validation("graphql_mutation_not_allowed", "las mutaciones solo se aceptan por POST")
+
+// Users es lo que la API GraphQL necesita de los usuarios; lo implementa
+// service.UserService
+type Users interface {
+	Get(ctx context.Context, id string) (*models.User, error)
+	GetMany(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*models.User, error)
+	Followers(ctx context.Context, userID string) ([]models.User, error)
+	Follow(ctx context.Context, userID, targetID string) error
+	Unfollow(ctx context.Context, userID, targetID string) error
+}
+
+// Timelines es lo que la API GraphQL necesita de los tweets; lo implementa
+// service.TimelineService
+type Timelines interface {
+	TimelineAfter(ctx context.Context, userID string, after *models.TweetPosition, limit int) (*service.TimelineSlice, error)
+	UserTweetsAfter(ctx context.Context, userID string, after *models.TweetPosition, limit int) (*service.TimelineSlice, error)
+	TweetsByID(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*models.Tweet, error)
+}
+
+// Server ejecuta consultas GraphQL sobre los mismos servicios que la API REST
+type Server struct {
+	schema    graphql.Schema
+	users     Users
+	timelines Timelines
+	limits    Limits
+}
+
+// NewServer arma el esquema
+func NewServer(users Users, timelines Timelines, limits Limits) (*Server, error) {
+	s := &Server{users: users, timelines: timelines, limits: limits}
+	schema, err := s.buildSchema()
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+	return s, nil
+}
+
+// Request es una petición GraphQL
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	// ViewerID es el usuario que hace la petición; vacío si es anónima
+	ViewerID string `json:"-"`
+	// QueryOnly rechaza las mutaciones, como corresponde a una petición GET
+	QueryOnly bool `json:"-"`
+}
+
+// requestState es lo que comparten los resolvers de una petición
+type requestState struct {
+	viewerID string
+	users    *Loader[primitive.ObjectID, *models.User]
+	tweets   *Loader[primitive.ObjectID, *models.Tweet]
+}
+
+type stateKey struct{}
+
+func stateFrom(ctx context.Context) *requestState {
+	return ctx.Value(stateKey{}).(*requestState)
+}
+
+// Do ejecuta una petición. Como graphql.Do, pero mide la consulta después de
+// validarla y la rechaza si supera los límites, sin llegar a ejecutarla.
+func (s *Server) Do(ctx context.Context, req Request) *graphql.Result {
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"})})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	validation := graphql.ValidateDocument(&s.schema, doc, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+	if req.QueryOnly && isMutation(doc, req.OperationName) {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(ErrMutationNotAllowed)}
+	}
+	if err := s.limits.check(&s.schema, doc, req.OperationName, req.Variables); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	state := &requestState{
+		viewerID: req.ViewerID,
+		users:    NewLoader(s.loadUsers),
+		tweets:   NewLoader(s.timelines.TweetsByID),
+	}
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       context.WithValue(ctx, stateKey{}, state),
+	})
+}
+
+// loadUsers carga usuarios para el loader; las cuentas que se están borrando ya
+// no existen para la API
+func (s *Server) loadUsers(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*models.User, error) {
+	users, err := s.users.GetMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for id, user := range users {
+		if user.IsPendingDeletion() {
+			delete(users, id)
+		}
+	}
+	return users, nil
+}
+
+// IsMutation indica si la operación operationName de query es una mutación. Una
+// consulta que no se puede analizar no lo es; Do responderá el error de sintaxis.
+func IsMutation(query, operationName string) bool {
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"})})
+	if err != nil {
+		return false
+	}
+	return isMutation(doc, operationName)
+}
+
+func isMutation(doc *ast.Document, operationName string) bool {
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" || (op.Name != nil && op.Name.Value == operationName) {
+			return op.Operation == ast.OperationTypeMutation
+		}
+	}
+	return false
+}
+
+// OriginalError devuelve el error que produjo un error de la respuesta, si lo
+// hay. graphql-go lo envuelve una o más veces según dónde se produjo.
+func OriginalError(formatted gqlerrors.FormattedError) error {
+	var err error = formatted
+	for err != nil {
+		switch e := err.(type) {
+		case gqlerrors.FormattedError:
+			err = e.OriginalError()
+		case *gqlerrors.FormattedError:
+			err = e.OriginalError()
+		case *gqlerrors.Error:
+			err = e.OriginalError
+		default:
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// internal/grpcapi/auth.go
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+
+	"github.com/ffelixf/microblog-platform/internal/middleware"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	pb "github.com/ffelixf/microblog-platform/pkg/pb/microblog/v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// UserIDMetadata es la metadata con el ID del usuario que hace la llamada, como
+// la cabecera X-User-ID de la API REST
+const UserIDMetadata = "x-user-id"
+
+// selfMethods son las llamadas que actúan en nombre del user_id de la petición:
+// solo las puede hacer ese mismo usuario
+var selfMethods = map[string]bool{
+	pb.TweetService_CreateTweet_FullMethodName:      true,
+	pb.UserService_Follow_FullMethodName:            true,
+	pb.UserService_Unfollow_FullMethodName:          true,
+	pb.TimelineService_GetTimeline_FullMethodName:   true,
+	pb.TimelineService_WatchTimeline_FullMethodName: true,
+}
+
+type principalKey struct{}
+
+// Principal devuelve el usuario identificado de la llamada o nil si es anónima
+func Principal(ctx context.Context) *rbac.Principal {
+	principal, _ := ctx.Value(principalKey{}).(*rbac.Principal)
+	return principal
+}
+
+// identify identifica al usuario de la metadata x-user-id, como Identity en la
+// API REST: sin metadata la llamada sigue como anónima y con un usuario
+// inexistente o suspendido se rechaza
+func (s *Server) identify(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ids := md.Get(UserIDMetadata)
+	if len(ids) == 0 || ids[0] == "" || s.opts.Resolve == nil {
+		return ctx, nil
+	}
+	principal, err := s.opts.Resolve(ctx, ids[0])
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, principalKey{}, principal), nil
+}
+
+// authorize exige que las llamadas de selfMethods las haga el usuario de la
+// petición: 401 si es anónima y 403 si es otro
+func authorize(ctx context.Context, method string, req any) error {
+	if !selfMethods[method] {
+		return nil
+	}
+	principal := Principal(ctx)
+	if principal == nil {
+		return rbac.ErrUnauthenticated
+	}
+	if principal.UserID != requestUserID(req) {
+		return rbac.ErrPermissionDenied
+	}
+	return nil
+}
+
+// rateLimit aplica la regla del limiter que corresponde al método, con la IP del
+// cliente como clave igual que RateLimit en la API REST, así que ambas APIs
+// comparten los buckets de cada grupo. Si el almacén falla la llamada se admite.
+func (s *Server) rateLimit(ctx context.Context, method string) error {
+	if s.opts.Limiter == nil {
+		return nil
+	}
+	rule := s.opts.Limiter.Rule("", method)
+	if rule == nil {
+		return nil
+	}
+	res, err := s.opts.Limiter.Allow(ctx, rule, "ip:"+peerIP(ctx))
+	if err != nil {
+		s.opts.Logger.WarnContext(ctx, "rate limit no disponible, se admite la llamada",
+			slog.String("rule", rule.Name), slog.Any("error", err))
+		return nil
+	}
+	if !res.Allowed {
+		return middleware.ErrRateLimited.WithParam("retry_after", int(math.Ceil(res.RetryAfter.Seconds())))
+	}
+	return nil
+}
+
+// peerIP devuelve la IP del cliente de la llamada
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
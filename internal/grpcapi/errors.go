@@ -0,0 +1,91 @@
+// internal/grpcapi/errors.go
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/i18n"
+	"github.com/ffelixf/microblog-platform/internal/middleware"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain es el dominio de los google.rpc.ErrorInfo de los errores
+const ErrorDomain = "microblog"
+
+// ErrShuttingDown termina los WatchTimeline abiertos cuando se apaga el servidor
+var ErrShuttingDown = apperr.Unavailable("server_shutting_down", "el servidor se está apagando")
+
+// CodeFor devuelve el código gRPC que corresponde a cada tipo de error
+func CodeFor(kind apperr.Kind) codes.Code {
+	switch kind {
+	case apperr.KindValidation, apperr.KindTooLarge, apperr.KindUnsupportedMedia:
+		return codes.InvalidArgument
+	case apperr.KindUnauthorized:
+		return codes.Unauthenticated
+	case apperr.KindForbidden:
+		return codes.PermissionDenied
+	case apperr.KindNotFound:
+		return codes.NotFound
+	case apperr.KindConflict:
+		return codes.AlreadyExists
+	case apperr.KindUnavailable:
+		return codes.Unavailable
+	case apperr.KindTooManyRequests:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
+
+// statusFor arma el estado de la respuesta para err, como NewProblem para la API
+// REST: los errores sin tipo se reducen a un error interno genérico y el mensaje
+// se traduce al idioma de la llamada. Los detalles llevan el código estable del
+// error en un ErrorInfo y los campos inválidos en un BadRequest.
+func (s *Server) statusFor(ctx context.Context, err error) *status.Status {
+	if _, ok := apperr.As(err); !ok {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return status.FromContextError(err)
+		}
+		// Los errores que ya son un estado gRPC, como los de decodificación, se
+		// responden tal cual
+		if st, ok := status.FromError(err); ok && st.Code() != codes.Unknown {
+			return st
+		}
+	}
+
+	problem := middleware.NewProblem(err, "", s.localizer(ctx))
+	st := status.New(CodeFor(apperr.KindOf(err)), problem.Detail)
+	info := &errdetails.ErrorInfo{Reason: problem.Code, Domain: ErrorDomain}
+	var detailed *status.Status
+	if len(problem.Errors) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range problem.Errors {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Message,
+			})
+		}
+		detailed, err = st.WithDetails(info, badRequest)
+	} else {
+		detailed, err = st.WithDetails(info)
+	}
+	if err != nil {
+		return st
+	}
+	return detailed
+}
+
+// localizer elige el idioma según la metadata accept-language de la llamada
+func (s *Server) localizer(ctx context.Context) *i18n.Localizer {
+	if s.opts.Messages == nil {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	return s.opts.Messages.Negotiate(strings.Join(md.Get("accept-language"), ","))
+}
@@ -0,0 +1,368 @@
+// internal/grpcapi/grpcapi_test.go
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/i18n"
+	"github.com/ffelixf/microblog-platform/internal/memstore"
+	"github.com/ffelixf/microblog-platform/internal/ratelimit"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	pb "github.com/ffelixf/microblog-platform/pkg/pb/microblog/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fixture struct {
+	users     pb.UserServiceClient
+	tweets    pb.TweetServiceClient
+	timelines pb.TimelineServiceClient
+	conn      *grpc.ClientConn
+	ready     *atomic.Bool
+	stop      context.CancelFunc
+	served    chan error
+	ids       []string
+}
+
+// setupServer crea n usuarios sobre memstore y atiende el servidor en memoria;
+// con rules limita las llamadas sobre un MemoryStore
+func setupServer(t *testing.T, n int, rules ...ratelimit.Rule) *fixture {
+	ctx := context.Background()
+	users := memstore.NewUsers()
+	tweets := memstore.NewTweets()
+	polls := memstore.NewPolls(tweets)
+
+	ids, err := memstore.SeedUsers(ctx, users, n)
+	require.NoError(t, err)
+	f := &fixture{ready: &atomic.Bool{}, served: make(chan error, 1), ids: ids}
+	f.ready.Store(true)
+
+	messages, err := i18n.NewBundle("es")
+	require.NoError(t, err)
+	var limiter *ratelimit.Limiter
+	if len(rules) > 0 {
+		limiter = ratelimit.NewLimiter(ratelimit.NewMemoryStore(), rules...)
+	}
+	userService := service.NewUserService(users, nil, nil)
+	server := NewServer(
+		userService,
+		service.NewTweetService(tweets, users, nil, polls, nil, nil, nil),
+		service.NewTimelineService(tweets, users, polls, nil),
+		Options{
+			WatchInterval:  10 * time.Millisecond,
+			HealthInterval: 10 * time.Millisecond,
+			Ready:          func(ctx context.Context) bool { return f.ready.Load() },
+			Messages:       messages,
+			Resolve:        userService.Principal,
+			Limiter:        limiter,
+		},
+	)
+
+	lis := bufconn.Listen(1 << 20)
+	serveCtx, stop := context.WithCancel(ctx)
+	f.stop = stop
+	go func() { f.served <- server.Serve(serveCtx, lis) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	f.conn = conn
+	t.Cleanup(func() {
+		stop()
+		conn.Close()
+	})
+
+	f.users = pb.NewUserServiceClient(conn)
+	f.tweets = pb.NewTweetServiceClient(conn)
+	f.timelines = pb.NewTimelineServiceClient(conn)
+	return f
+}
+
+func (f *fixture) post(t *testing.T, userID, content string) *pb.Tweet {
+	tweet, err := f.tweets.CreateTweet(as(context.Background(), userID), &pb.CreateTweetRequest{UserId: userID, Content: content})
+	require.NoError(t, err)
+	return tweet
+}
+
+// as identifica las llamadas del contexto como userID
+func as(ctx context.Context, userID string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, UserIDMetadata, userID)
+}
+
+// errorInfo devuelve el código estable del error
+func errorInfo(t *testing.T, err error) (codes.Code, string) {
+	st := status.Convert(err)
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			assert.Equal(t, ErrorDomain, info.Domain)
+			return st.Code(), info.Reason
+		}
+	}
+	t.Fatalf("el error no tiene ErrorInfo: %v", err)
+	return st.Code(), ""
+}
+
+func TestServer_UsersAndFollows(t *testing.T) {
+	f := setupServer(t, 3)
+	ctx := context.Background()
+
+	user, err := f.users.GetUser(ctx, &pb.GetUserRequest{Id: f.ids[0]})
+	require.NoError(t, err)
+	assert.Equal(t, "user0", user.Username)
+	assert.NotNil(t, user.CreateTime)
+
+	followed, err := f.users.Follow(as(ctx, f.ids[1]), &pb.FollowRequest{UserId: f.ids[1], TargetId: f.ids[0]})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), followed.Target.FollowersCount)
+
+	followers, err := f.users.ListFollowers(ctx, &pb.ListFollowsRequest{UserId: f.ids[0]})
+	require.NoError(t, err)
+	require.Len(t, followers.Users, 1)
+	assert.Equal(t, f.ids[1], followers.Users[0].Id)
+	following, err := f.users.ListFollowing(ctx, &pb.ListFollowsRequest{UserId: f.ids[1]})
+	require.NoError(t, err)
+	require.Len(t, following.Users, 1)
+	assert.Equal(t, f.ids[0], following.Users[0].Id)
+
+	unfollowed, err := f.users.Unfollow(as(ctx, f.ids[1]), &pb.FollowRequest{UserId: f.ids[1], TargetId: f.ids[0]})
+	require.NoError(t, err)
+	assert.Zero(t, unfollowed.Target.FollowersCount)
+
+	// El lote respeta el orden pedido y omite los que no existen
+	batch, err := f.users.BatchGetUsers(ctx, &pb.BatchGetUsersRequest{Ids: []string{f.ids[2], primitive.NewObjectID().Hex(), f.ids[0]}})
+	require.NoError(t, err)
+	require.Len(t, batch.Users, 2)
+	assert.Equal(t, []string{"user2", "user0"}, []string{batch.Users[0].Username, batch.Users[1].Username})
+}
+
+func TestServer_TweetsAndTimeline(t *testing.T) {
+	f := setupServer(t, 3)
+	ctx := context.Background()
+	for _, id := range f.ids[1:] {
+		_, err := f.users.Follow(as(ctx, f.ids[0]), &pb.FollowRequest{UserId: f.ids[0], TargetId: id})
+		require.NoError(t, err)
+		for i := range 3 {
+			f.post(t, id, fmt.Sprintf("tweet %d #grpc", i))
+		}
+	}
+
+	tweet, err := f.tweets.GetTweet(ctx, &pb.GetTweetRequest{Id: f.post(t, f.ids[1], "último").Id})
+	require.NoError(t, err)
+	assert.Equal(t, "último", tweet.Content)
+	assert.False(t, tweet.PendingReview)
+
+	own, err := f.tweets.ListUserTweets(ctx, &pb.ListUserTweetsRequest{UserId: f.ids[1], PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, own.Tweets, 2)
+	assert.Equal(t, "último", own.Tweets[0].Content)
+	assert.Equal(t, []string{"grpc"}, own.Tweets[1].Hashtags)
+	assert.NotEmpty(t, own.NextPageToken)
+
+	seen := make(map[string]bool)
+	var token, resume string
+	pages := 0
+	for {
+		page, err := f.timelines.GetTimeline(as(ctx, f.ids[0]), &pb.GetTimelineRequest{UserId: f.ids[0], PageSize: 3, PageToken: token})
+		require.NoError(t, err)
+		if pages == 0 {
+			resume = page.ResumeToken
+		} else {
+			assert.Empty(t, page.ResumeToken, "solo la primera página")
+		}
+		for _, tw := range page.Tweets {
+			assert.False(t, seen[tw.Id], "tweet repetido %s", tw.Id)
+			seen[tw.Id] = true
+		}
+		pages++
+		if page.NextPageToken == "" {
+			break
+		}
+		token = page.NextPageToken
+	}
+	assert.Len(t, seen, 7)
+	assert.Equal(t, 3, pages)
+	position, err := service.DecodeTweetCursor(resume)
+	require.NoError(t, err)
+	assert.Equal(t, tweet.Id, position.ID.Hex())
+}
+
+func TestServer_Errors(t *testing.T) {
+	f := setupServer(t, 1)
+	ctx := context.Background()
+
+	_, err := f.users.GetUser(ctx, &pb.GetUserRequest{Id: primitive.NewObjectID().Hex()})
+	code, reason := errorInfo(t, err)
+	assert.Equal(t, codes.NotFound, code)
+	assert.Equal(t, "user_not_found", reason)
+
+	_, err = f.timelines.GetTimeline(as(ctx, f.ids[0]), &pb.GetTimelineRequest{UserId: f.ids[0], PageToken: "nope"})
+	code, reason = errorInfo(t, err)
+	assert.Equal(t, codes.InvalidArgument, code)
+	assert.Equal(t, "invalid_cursor", reason)
+
+	_, err = f.users.BatchGetUsers(ctx, &pb.BatchGetUsersRequest{Ids: make([]string, MaxBatchUsers+1)})
+	_, reason = errorInfo(t, err)
+	assert.Equal(t, "too_many_ids", reason)
+
+	// El mensaje se traduce según accept-language y los campos van en un BadRequest
+	_, err = f.tweets.CreateTweet(as(ctx, f.ids[0]), &pb.CreateTweetRequest{UserId: f.ids[0]})
+	spanish := status.Convert(err).Message()
+	en := metadata.AppendToOutgoingContext(as(ctx, f.ids[0]), "accept-language", "en")
+	_, err = f.tweets.CreateTweet(en, &pb.CreateTweetRequest{UserId: f.ids[0]})
+	code, reason = errorInfo(t, err)
+	assert.Equal(t, codes.InvalidArgument, code)
+	assert.Equal(t, "content_required", reason)
+	assert.NotEqual(t, spanish, status.Convert(err).Message())
+	var fields []string
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.FieldViolations {
+				fields = append(fields, violation.Field)
+			}
+		}
+	}
+	assert.Equal(t, []string{"content"}, fields)
+}
+
+func TestServer_Auth(t *testing.T) {
+	f := setupServer(t, 2)
+	ctx := context.Background()
+
+	// Las lecturas públicas no necesitan identificarse
+	_, err := f.users.GetUser(ctx, &pb.GetUserRequest{Id: f.ids[0]})
+	require.NoError(t, err)
+
+	_, err = f.tweets.CreateTweet(ctx, &pb.CreateTweetRequest{UserId: f.ids[0], Content: "anónimo"})
+	code, reason := errorInfo(t, err)
+	assert.Equal(t, codes.Unauthenticated, code)
+	assert.Equal(t, "authentication_required", reason)
+
+	// Nadie actúa en nombre de otro usuario
+	_, err = f.users.Follow(as(ctx, f.ids[1]), &pb.FollowRequest{UserId: f.ids[0], TargetId: f.ids[1]})
+	code, reason = errorInfo(t, err)
+	assert.Equal(t, codes.PermissionDenied, code)
+	assert.Equal(t, "permission_denied", reason)
+
+	_, err = f.timelines.GetTimeline(as(ctx, primitive.NewObjectID().Hex()), &pb.GetTimelineRequest{UserId: f.ids[0]})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	stream, err := f.timelines.WatchTimeline(as(ctx, f.ids[1]), &pb.WatchTimelineRequest{UserId: f.ids[0]})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	code, reason = errorInfo(t, err)
+	assert.Equal(t, codes.PermissionDenied, code)
+	assert.Equal(t, "permission_denied", reason)
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	limit, err := ratelimit.ParseLimit("2/m")
+	require.NoError(t, err)
+	f := setupServer(t, 1, ratelimit.Rule{Name: "tweets", Limit: limit,
+		Routes: []string{pb.TweetService_CreateTweet_FullMethodName}})
+	ctx := context.Background()
+
+	f.post(t, f.ids[0], "uno")
+	f.post(t, f.ids[0], "dos")
+	_, err = f.tweets.CreateTweet(as(ctx, f.ids[0]), &pb.CreateTweetRequest{UserId: f.ids[0], Content: "tres"})
+	code, reason := errorInfo(t, err)
+	assert.Equal(t, codes.ResourceExhausted, code)
+	assert.Equal(t, "rate_limited", reason)
+
+	// Los métodos sin regla no se limitan
+	for range 3 {
+		_, err = f.users.GetUser(ctx, &pb.GetUserRequest{Id: f.ids[0]})
+		require.NoError(t, err)
+	}
+}
+
+func TestServer_WatchTimeline(t *testing.T) {
+	f := setupServer(t, 2)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = as(ctx, f.ids[0])
+	_, err := f.users.Follow(ctx, &pb.FollowRequest{UserId: f.ids[0], TargetId: f.ids[1]})
+	require.NoError(t, err)
+
+	seen := f.post(t, f.ids[1], "ya leído")
+	page, err := f.timelines.GetTimeline(ctx, &pb.GetTimelineRequest{UserId: f.ids[0]})
+	require.NoError(t, err)
+	// Publicado entre la lectura y la suscripción: no se pierde
+	missed := f.post(t, f.ids[1], "entre medio")
+
+	stream, err := f.timelines.WatchTimeline(ctx, &pb.WatchTimelineRequest{UserId: f.ids[0], ResumeToken: page.ResumeToken})
+	require.NoError(t, err)
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, missed.Id, event.Tweet.Id)
+
+	fresh := f.post(t, f.ids[0], "nuevo")
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, fresh.Id, event.Tweet.Id)
+	assert.NotEqual(t, seen.Id, event.Tweet.Id)
+
+	// Reanudar con el último resume_token no repite tweets
+	resumed, err := f.timelines.WatchTimeline(ctx, &pb.WatchTimelineRequest{UserId: f.ids[0], ResumeToken: event.ResumeToken})
+	require.NoError(t, err)
+	later := f.post(t, f.ids[1], "después")
+	event, err = resumed.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, later.Id, event.Tweet.Id)
+
+	// Al apagar el servidor los streams terminan con UNAVAILABLE
+	f.stop()
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.NoError(t, <-f.served)
+}
+
+func TestServer_HealthAndReflection(t *testing.T) {
+	f := setupServer(t, 0)
+	ctx := context.Background()
+	health := healthpb.NewHealthClient(f.conn)
+
+	check := func(name string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := health.Check(ctx, &healthpb.HealthCheckRequest{Service: name})
+		require.NoError(t, err)
+		return resp.Status
+	}
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(pb.TimelineService_ServiceDesc.ServiceName))
+
+	f.ready.Store(false)
+	assert.Eventually(t, func() bool {
+		return check(pb.UserService_ServiceDesc.ServiceName) == healthpb.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 10*time.Millisecond)
+
+	stream, err := reflectionpb.NewServerReflectionClient(f.conn).ServerReflectionInfo(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	var names []string
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		names = append(names, svc.Name)
+	}
+	assert.Subset(t, names, append(serviceNames, healthpb.Health_ServiceDesc.ServiceName))
+}
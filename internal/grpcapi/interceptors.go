@@ -0,0 +1,137 @@
+// internal/grpcapi/interceptors.go
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// userRequest es una petición que indica el usuario sobre el que actúa
+type userRequest interface {
+	GetUserId() string
+}
+
+// unaryInterceptor recupera los panics, convierte los errores en estados gRPC y
+// registra cada llamada, como Recovery, ErrorHandler y AccessLog en la API REST.
+// Antes de la llamada identifica al usuario, aplica el rate limit y comprueba
+// que actúe sobre sí mismo, como Identity, RateLimit y los handlers REST.
+func (s *Server) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	start := time.Now()
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = s.recovered(ctx, rec)
+		}
+		err = s.finish(ctx, info.FullMethod, requestUserID(req), start, err)
+	}()
+
+	if ctx, err = s.identify(ctx); err != nil {
+		return nil, err
+	}
+	if err = s.rateLimit(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	if err = authorize(ctx, info.FullMethod, req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamInterceptor hace lo mismo que unaryInterceptor para las llamadas con
+// stream; la petición llega con el primer RecvMsg, que es donde se autoriza
+func (s *Server) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	start := time.Now()
+	stream := &loggedStream{ServerStream: ss, ctx: ss.Context(), method: info.FullMethod}
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = s.recovered(ss.Context(), rec)
+		}
+		err = s.finish(ss.Context(), info.FullMethod, stream.userID, start, err)
+	}()
+
+	if stream.ctx, err = s.identify(stream.ctx); err != nil {
+		return err
+	}
+	if err = s.rateLimit(stream.ctx, info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+func (s *Server) recovered(ctx context.Context, rec any) error {
+	s.opts.Logger.ErrorContext(ctx, "panic en handler gRPC",
+		slog.Any("panic", rec),
+		slog.String("stack", string(debug.Stack())),
+	)
+	return fmt.Errorf("panic: %v", rec)
+}
+
+// finish registra la llamada y devuelve el error convertido en estado gRPC. La
+// causa completa solo queda en el log. Que el cliente corte la llamada no es un
+// error, y UNAVAILABLE es lo normal al apagar el servidor con streams abiertos.
+func (s *Server) finish(ctx context.Context, method, userID string, start time.Time, err error) error {
+	code := codes.OK
+	var result error
+	if err != nil {
+		st := s.statusFor(ctx, err)
+		code, result = st.Code(), st.Err()
+	}
+
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK, codes.Canceled:
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+	}
+	if userID != "" {
+		attrs = append(attrs, slog.String("user_id", userID))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	s.opts.Logger.LogAttrs(ctx, level, "llamada gRPC", attrs...)
+	return result
+}
+
+func requestUserID(req any) string {
+	if r, ok := req.(userRequest); ok {
+		return r.GetUserId()
+	}
+	return ""
+}
+
+// loggedStream lleva el contexto con el usuario identificado, autoriza la
+// petición de una llamada con stream y guarda su usuario para el log
+type loggedStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	method string
+	userID string
+}
+
+func (s *loggedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *loggedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.userID == "" {
+		s.userID = requestUserID(m)
+	}
+	return authorize(s.ctx, s.method, m)
+}
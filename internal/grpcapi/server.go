@@ -0,0 +1,153 @@
+// internal/grpcapi/server.go
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/i18n"
+	"github.com/ffelixf/microblog-platform/internal/middleware"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/ratelimit"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	pb "github.com/ffelixf/microblog-platform/pkg/pb/microblog/v1"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+const (
+	// DefaultWatchInterval es cada cuánto WatchTimeline busca tweets nuevos
+	DefaultWatchInterval = 2 * time.Second
+	// DefaultHealthInterval es cada cuánto se actualiza el servicio de salud
+	DefaultHealthInterval = 5 * time.Second
+)
+
+// Users es lo que la API gRPC necesita de los usuarios; lo implementa
+// service.UserService
+type Users interface {
+	Get(ctx context.Context, id string) (*models.User, error)
+	GetMany(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*models.User, error)
+	Follow(ctx context.Context, userID, targetID string) error
+	Unfollow(ctx context.Context, userID, targetID string) error
+	Following(ctx context.Context, userID string) ([]models.User, error)
+	Followers(ctx context.Context, userID string) ([]models.User, error)
+}
+
+// Tweets publica tweets; lo implementa service.TweetService
+type Tweets interface {
+	Create(ctx context.Context, tweet *models.Tweet) error
+}
+
+// Timelines es lo que la API gRPC necesita de los listados de tweets; lo
+// implementa service.TimelineService
+type Timelines interface {
+	TimelineAfter(ctx context.Context, userID string, after *models.TweetPosition, limit int) (*service.TimelineSlice, error)
+	TimelineSince(ctx context.Context, userID string, since models.TweetPosition, limit int) (*service.TimelineSlice, error)
+	UserTweetsAfter(ctx context.Context, userID string, after *models.TweetPosition, limit int) (*service.TimelineSlice, error)
+	TweetsByID(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*models.Tweet, error)
+}
+
+// Options configura el servidor gRPC
+type Options struct {
+	// WatchInterval es cada cuánto WatchTimeline busca tweets nuevos
+	WatchInterval time.Duration
+	// Ready indica si la instancia puede atender; el servicio de salud lo
+	// consulta cada HealthInterval. Sin Ready los servicios siempre atienden.
+	Ready          func(ctx context.Context) bool
+	HealthInterval time.Duration
+	// Messages traduce los errores al idioma de la metadata accept-language
+	Messages *i18n.Bundle
+	Logger   *slog.Logger
+	// Resolve identifica al usuario de la metadata x-user-id, como Identity en
+	// la API REST. Sin Resolve todas las llamadas son anónimas y las que actúan
+	// en nombre de un usuario se rechazan.
+	Resolve middleware.PrincipalResolver
+	// Limiter aplica el rate limit por método; nil no limita
+	Limiter *ratelimit.Limiter
+}
+
+// Server atiende la API gRPC sobre los mismos servicios que la API REST, con
+// los servicios de salud y reflexión
+type Server struct {
+	grpc   *grpc.Server
+	health *health.Server
+	opts   Options
+	// stopping se cierra al empezar el apagado para terminar los WatchTimeline
+	stopping chan struct{}
+}
+
+// serviceNames son los servicios cuyo estado publica el servicio de salud,
+// además del estado general con nombre vacío
+var serviceNames = []string{
+	pb.UserService_ServiceDesc.ServiceName,
+	pb.TweetService_ServiceDesc.ServiceName,
+	pb.TimelineService_ServiceDesc.ServiceName,
+}
+
+func NewServer(users Users, tweets Tweets, timelines Timelines, opts Options) *Server {
+	if opts.WatchInterval <= 0 {
+		opts.WatchInterval = DefaultWatchInterval
+	}
+	if opts.HealthInterval <= 0 {
+		opts.HealthInterval = DefaultHealthInterval
+	}
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	s := &Server{health: health.NewServer(), opts: opts, stopping: make(chan struct{})}
+	s.grpc = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	)
+	pb.RegisterUserServiceServer(s.grpc, &userServer{users: users})
+	pb.RegisterTweetServiceServer(s.grpc, &tweetServer{tweets: tweets, timelines: timelines})
+	pb.RegisterTimelineServiceServer(s.grpc, &timelineServer{timelines: timelines, interval: opts.WatchInterval, stopping: s.stopping})
+	healthpb.RegisterHealthServer(s.grpc, s.health)
+	reflection.Register(s.grpc)
+	return s
+}
+
+// Serve atiende las llamadas que llegan por ln hasta que se cancela ctx. Al
+// apagarse los servicios pasan a NOT_SERVING, los WatchTimeline abiertos
+// terminan con UNAVAILABLE y se espera a que terminen las demás llamadas.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	served := make(chan error, 1)
+	go func() { served <- s.grpc.Serve(ln) }()
+	s.opts.Logger.Info("servidor gRPC escuchando", slog.String("addr", ln.Addr().String()))
+
+	s.updateHealth(ctx)
+	ticker := time.NewTicker(s.opts.HealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-served:
+			return fmt.Errorf("error del servidor gRPC: %w", err)
+		case <-ticker.C:
+			s.updateHealth(ctx)
+		case <-ctx.Done():
+			s.health.Shutdown()
+			close(s.stopping)
+			s.grpc.GracefulStop()
+			return nil
+		}
+	}
+}
+
+// updateHealth publica el resultado de Ready en el servicio de salud
+func (s *Server) updateHealth(ctx context.Context) {
+	status := healthpb.HealthCheckResponse_SERVING
+	if s.opts.Ready != nil && !s.opts.Ready(ctx) {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	s.health.SetServingStatus("", status)
+	for _, name := range serviceNames {
+		s.health.SetServingStatus(name, status)
+	}
+}
@@ -0,0 +1,301 @@
+// internal/grpcapi/services.go
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	pb "github.com/ffelixf/microblog-platform/pkg/pb/microblog/v1"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MaxBatchUsers es la cantidad máxima de usuarios de BatchGetUsers
+const MaxBatchUsers = 100
+
+var ErrTooManyIDs = apperr.InvalidField("too_many_ids", "ids",
+	fmt.Sprintf("no se pueden pedir más de %d usuarios a la vez", MaxBatchUsers)).
+	WithParam("max", MaxBatchUsers)
+
+type userServer struct {
+	pb.UnimplementedUserServiceServer
+	users Users
+}
+
+func (s *userServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	user, err := s.users.Get(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toUser(user), nil
+}
+
+// BatchGetUsers respeta el orden de los IDs; las cuentas que se están borrando
+// ya no existen para la API
+func (s *userServer) BatchGetUsers(ctx context.Context, req *pb.BatchGetUsersRequest) (*pb.BatchGetUsersResponse, error) {
+	if len(req.GetIds()) > MaxBatchUsers {
+		return nil, ErrTooManyIDs
+	}
+	ids, err := parseIDs(req.GetIds())
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.users.GetMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.BatchGetUsersResponse{}
+	for _, id := range ids {
+		if user := users[id]; user != nil && !user.IsPendingDeletion() {
+			resp.Users = append(resp.Users, toUser(user))
+		}
+	}
+	return resp, nil
+}
+
+func (s *userServer) Follow(ctx context.Context, req *pb.FollowRequest) (*pb.FollowResponse, error) {
+	if err := s.users.Follow(ctx, req.GetUserId(), req.GetTargetId()); err != nil {
+		return nil, err
+	}
+	return s.followResponse(ctx, req.GetTargetId())
+}
+
+func (s *userServer) Unfollow(ctx context.Context, req *pb.FollowRequest) (*pb.FollowResponse, error) {
+	if err := s.users.Unfollow(ctx, req.GetUserId(), req.GetTargetId()); err != nil {
+		return nil, err
+	}
+	return s.followResponse(ctx, req.GetTargetId())
+}
+
+func (s *userServer) followResponse(ctx context.Context, targetID string) (*pb.FollowResponse, error) {
+	target, err := s.users.Get(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.FollowResponse{Target: toUser(target)}, nil
+}
+
+func (s *userServer) ListFollowing(ctx context.Context, req *pb.ListFollowsRequest) (*pb.ListFollowsResponse, error) {
+	users, err := s.users.Following(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListFollowsResponse{Users: toUsers(users)}, nil
+}
+
+func (s *userServer) ListFollowers(ctx context.Context, req *pb.ListFollowsRequest) (*pb.ListFollowsResponse, error) {
+	users, err := s.users.Followers(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListFollowsResponse{Users: toUsers(users)}, nil
+}
+
+type tweetServer struct {
+	pb.UnimplementedTweetServiceServer
+	tweets    Tweets
+	timelines Timelines
+}
+
+// CreateTweet publica por el mismo camino que la API REST; un tweet retenido
+// por la política de contenido vuelve con pending_review
+func (s *tweetServer) CreateTweet(ctx context.Context, req *pb.CreateTweetRequest) (*pb.Tweet, error) {
+	tweet := &models.Tweet{Content: req.GetContent()}
+	if req.GetUserId() != "" {
+		userID, err := primitive.ObjectIDFromHex(req.GetUserId())
+		if err != nil {
+			return nil, service.ErrInvalidID
+		}
+		tweet.UserID = userID
+	}
+	if len(req.GetMediaIds()) > 0 {
+		media, err := parseIDs(req.GetMediaIds())
+		if err != nil {
+			return nil, err
+		}
+		tweet.Media = media
+	}
+
+	if err := s.tweets.Create(ctx, tweet); err != nil {
+		return nil, err
+	}
+	return toTweet(tweet), nil
+}
+
+func (s *tweetServer) GetTweet(ctx context.Context, req *pb.GetTweetRequest) (*pb.Tweet, error) {
+	id, err := primitive.ObjectIDFromHex(req.GetId())
+	if err != nil {
+		return nil, service.ErrInvalidID
+	}
+	tweets, err := s.timelines.TweetsByID(ctx, []primitive.ObjectID{id})
+	if err != nil {
+		return nil, err
+	}
+	tweet := tweets[id]
+	if tweet == nil {
+		return nil, service.ErrTweetNotFound
+	}
+	return toTweet(tweet), nil
+}
+
+func (s *tweetServer) ListUserTweets(ctx context.Context, req *pb.ListUserTweetsRequest) (*pb.ListUserTweetsResponse, error) {
+	after, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+	slice, err := s.timelines.UserTweetsAfter(ctx, req.GetUserId(), after, int(req.GetPageSize()))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListUserTweetsResponse{Tweets: toTweets(slice.Tweets), NextPageToken: nextPageToken(slice)}, nil
+}
+
+type timelineServer struct {
+	pb.UnimplementedTimelineServiceServer
+	timelines Timelines
+	interval  time.Duration
+	stopping  <-chan struct{}
+}
+
+func (s *timelineServer) GetTimeline(ctx context.Context, req *pb.GetTimelineRequest) (*pb.GetTimelineResponse, error) {
+	after, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+	slice, err := s.timelines.TimelineAfter(ctx, req.GetUserId(), after, int(req.GetPageSize()))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetTimelineResponse{Tweets: toTweets(slice.Tweets), NextPageToken: nextPageToken(slice)}
+	if after == nil && len(slice.Tweets) > 0 {
+		resp.ResumeToken = service.EncodeTweetCursor(slice.Tweets[0].Position())
+	}
+	return resp, nil
+}
+
+// WatchTimeline busca tweets nuevos cada intervalo y los envía del más antiguo
+// al más reciente. Sin resume_token empieza por los que se publiquen desde ahora.
+func (s *timelineServer) WatchTimeline(req *pb.WatchTimelineRequest, stream grpc.ServerStreamingServer[pb.TimelineEvent]) error {
+	ctx := stream.Context()
+	since := models.TweetPosition{CreatedAt: time.Now()}
+	if req.GetResumeToken() != "" {
+		position, err := service.DecodeTweetCursor(req.GetResumeToken())
+		if err != nil {
+			return err
+		}
+		since = *position
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.sendSince(ctx, stream, req.GetUserId(), &since); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.stopping:
+			return ErrShuttingDown
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendSince envía los tweets posteriores a since y lo avanza hasta el último leído
+func (s *timelineServer) sendSince(ctx context.Context, stream grpc.ServerStreamingServer[pb.TimelineEvent], userID string, since *models.TweetPosition) error {
+	for {
+		slice, err := s.timelines.TimelineSince(ctx, userID, *since, service.MaxPageSize)
+		if err != nil {
+			return err
+		}
+		if slice.Next == nil {
+			return nil
+		}
+		for i := range slice.Tweets {
+			event := &pb.TimelineEvent{
+				Tweet:       toTweet(&slice.Tweets[i]),
+				ResumeToken: service.EncodeTweetCursor(slice.Tweets[i].Position()),
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+		*since = *slice.Next
+	}
+}
+
+func decodePageToken(token string) (*models.TweetPosition, error) {
+	if token == "" {
+		return nil, nil
+	}
+	return service.DecodeTweetCursor(token)
+}
+
+func nextPageToken(slice *service.TimelineSlice) string {
+	if slice.Next == nil {
+		return ""
+	}
+	return service.EncodeTweetCursor(*slice.Next)
+}
+
+func parseIDs(hexIDs []string) ([]primitive.ObjectID, error) {
+	ids := make([]primitive.ObjectID, 0, len(hexIDs))
+	for _, hexID := range hexIDs {
+		id, err := primitive.ObjectIDFromHex(hexID)
+		if err != nil {
+			return nil, service.ErrInvalidID
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func toUser(user *models.User) *pb.User {
+	return &pb.User{
+		Id:             user.ID.Hex(),
+		Username:       user.Username,
+		CreateTime:     timestamppb.New(user.CreatedAt),
+		FollowersCount: int32(user.FollowersCount),
+		FollowingCount: int32(len(user.Following)),
+		Remote:         user.IsRemote(),
+	}
+}
+
+func toUsers(users []models.User) []*pb.User {
+	out := make([]*pb.User, len(users))
+	for i := range users {
+		out[i] = toUser(&users[i])
+	}
+	return out
+}
+
+func toTweet(tweet *models.Tweet) *pb.Tweet {
+	out := &pb.Tweet{
+		Id:            tweet.ID.Hex(),
+		UserId:        tweet.UserID.Hex(),
+		Content:       tweet.Content,
+		Hashtags:      tweet.Hashtags,
+		CreateTime:    timestamppb.New(tweet.CreatedAt),
+		PendingReview: tweet.Held(),
+	}
+	for _, id := range tweet.Media {
+		out.MediaIds = append(out.MediaIds, id.Hex())
+	}
+	return out
+}
+
+func toTweets(tweets []models.Tweet) []*pb.Tweet {
+	out := make([]*pb.Tweet, len(tweets))
+	for i := range tweets {
+		out[i] = toTweet(&tweets[i])
+	}
+	return out
+}
@@ -0,0 +1,134 @@
+// internal/handlers/account_handler.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ffelixf/microblog-platform/internal/middleware"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// Deactivate desactiva la cuenta del usuario que hace la petición
+func (h *AccountHandler) Deactivate(c *gin.Context) {
+	if !requireSelf(c) {
+		return
+	}
+
+	user, err := h.accountService.Deactivate(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// Reactivate reactiva la cuenta del usuario que hace la petición
+func (h *AccountHandler) Reactivate(c *gin.Context) {
+	if !requireSelf(c) {
+		return
+	}
+
+	user, err := h.accountService.Reactivate(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// Suspend suspende una cuenta con un motivo y un vencimiento opcional
+func (h *AccountHandler) Suspend(c *gin.Context) {
+	var req models.SuspensionRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.accountService.Suspend(c.Request.Context(), middleware.Principal(c), c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// LiftSuspension levanta la suspensión de una cuenta
+func (h *AccountHandler) LiftSuspension(c *gin.Context) {
+	user, err := h.accountService.LiftSuspension(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteUser pide el borrado definitivo de una cuenta; el borrado se ejecuta en
+// segundo plano y su estado se consulta en GetDeletion
+func (h *AccountHandler) DeleteUser(c *gin.Context) {
+	deletion, err := h.accountService.RequestDeletion(c.Request.Context(), c.Param("id"), models.DeletionReasonAdmin)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, deletion)
+}
+
+// GetDeletion devuelve el estado del borrado de una cuenta
+func (h *AccountHandler) GetDeletion(c *gin.Context) {
+	deletion, err := h.accountService.Deletion(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deletion)
+}
+
+// requireSelf exige que la petición la haga el dueño de la cuenta de la ruta
+func requireSelf(c *gin.Context) bool {
+	principal := middleware.Principal(c)
+	if principal == nil {
+		c.Error(rbac.ErrUnauthenticated)
+		return false
+	}
+	if principal.UserID != c.Param("id") {
+		c.Error(rbac.ErrPermissionDenied)
+		return false
+	}
+	return true
+}
+
+// RegisterAccountRoutes registra las rutas del ciclo de vida de las cuentas: la
+// desactivación la hace el propio usuario y el resto exige permisos
+func RegisterAccountRoutes(router *gin.Engine, handler *AccountHandler) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/users/:id/deactivate", handler.Deactivate)
+		api.POST("/users/:id/reactivate", handler.Reactivate)
+	}
+
+	admin := router.Group("/api/v1/admin/users/:id")
+	{
+		admin.POST("/suspension", middleware.RequirePermission(rbac.UsersSuspend), handler.Suspend)
+		admin.DELETE("/suspension", middleware.RequirePermission(rbac.UsersSuspend), handler.LiftSuspension)
+		admin.DELETE("", middleware.RequirePermission(rbac.UsersDelete), handler.DeleteUser)
+		admin.GET("/deletion", middleware.RequirePermission(rbac.UsersDelete), handler.GetDeletion)
+	}
+}
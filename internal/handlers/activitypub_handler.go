@@ -0,0 +1,115 @@
+// internal/handlers/activitypub_handler.go
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/ffelixf/microblog-platform/internal/activitypub"
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/gin-gonic/gin"
+)
+
+// maxInboxBodySize limita el tamaño de las actividades recibidas
+const maxInboxBodySize = 1 << 20
+
+var errResourceRequired = apperr.InvalidField("resource_required", "resource", "el parámetro resource es requerido")
+
+type ActivityPubHandler struct {
+	federation *activitypub.Federation
+}
+
+func NewActivityPubHandler(federation *activitypub.Federation) *ActivityPubHandler {
+	return &ActivityPubHandler{
+		federation: federation,
+	}
+}
+
+// WebFinger resuelve acct:usuario@dominio al actor correspondiente
+func (h *ActivityPubHandler) WebFinger(c *gin.Context) {
+	resource := c.Query("resource")
+	if resource == "" {
+		c.Error(errResourceRequired)
+		return
+	}
+
+	jrd, err := h.federation.WebFinger(c.Request.Context(), resource)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.render(c, activitypub.JRDContentType, jrd)
+}
+
+// GetActor devuelve el documento de actor de un usuario
+func (h *ActivityPubHandler) GetActor(c *gin.Context) {
+	actor, err := h.federation.Actor(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.render(c, activitypub.ContentType, actor)
+}
+
+// GetOutbox devuelve los tweets recientes del usuario como actividades Create
+func (h *ActivityPubHandler) GetOutbox(c *gin.Context) {
+	outbox, err := h.federation.Outbox(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.render(c, activitypub.ContentType, outbox)
+}
+
+// GetFollowers devuelve la colección de seguidores del usuario
+func (h *ActivityPubHandler) GetFollowers(c *gin.Context) {
+	followers, err := h.federation.Followers(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	h.render(c, activitypub.ContentType, followers)
+}
+
+// PostInbox recibe actividades firmadas de otras instancias
+func (h *ActivityPubHandler) PostInbox(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxInboxBodySize))
+	if err != nil {
+		c.Error(activitypub.ErrInvalidActivity.Wrap(err))
+		return
+	}
+
+	if err := h.federation.HandleInbox(c.Request.Context(), c.Param("username"), c.Request, body); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+func (h *ActivityPubHandler) render(c *gin.Context, contentType string, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Data(http.StatusOK, contentType+"; charset=utf-8", body)
+}
+
+// RegisterActivityPubRoutes registra WebFinger y los endpoints de cada actor
+func RegisterActivityPubRoutes(router *gin.Engine, handler *ActivityPubHandler) {
+	router.GET("/.well-known/webfinger", handler.WebFinger)
+
+	actors := router.Group("/users/:username")
+	{
+		actors.GET("", handler.GetActor)
+		actors.GET("/outbox", handler.GetOutbox)
+		actors.GET("/followers", handler.GetFollowers)
+		actors.POST("/inbox", handler.PostInbox)
+	}
+}
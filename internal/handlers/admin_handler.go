@@ -0,0 +1,67 @@
+// internal/handlers/admin_handler.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ffelixf/microblog-platform/internal/middleware"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	adminService *service.AdminService
+}
+
+func NewAdminHandler(adminService *service.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+// ListRoles devuelve los roles con sus permisos y los permisos que se pueden conceder
+func (h *AdminHandler) ListRoles(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"roles":       rbac.Roles(),
+		"permissions": rbac.Permissions(),
+	})
+}
+
+// SetUserRoles reemplaza los roles y permisos de un usuario
+func (h *AdminHandler) SetUserRoles(c *gin.Context) {
+	var assignment models.RoleAssignment
+	if !bindJSON(c, &assignment) {
+		return
+	}
+
+	user, err := h.adminService.SetRoles(c.Request.Context(), middleware.Principal(c), c.Param("id"), assignment)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteTweet borra el tweet de cualquier usuario
+func (h *AdminHandler) DeleteTweet(c *gin.Context) {
+	if err := h.adminService.DeleteTweet(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RegisterAdminRoutes registra las rutas de administración; cada una exige su
+// permiso además de un usuario identificado
+func RegisterAdminRoutes(router *gin.Engine, handler *AdminHandler) {
+	admin := router.Group("/api/v1/admin")
+	{
+		admin.GET("/roles", middleware.RequirePermission(rbac.RolesManage), handler.ListRoles)
+		admin.PUT("/users/:id/roles", middleware.RequirePermission(rbac.RolesManage), handler.SetUserRoles)
+		admin.DELETE("/tweets/:id", middleware.RequirePermission(rbac.TweetsDeleteAny), handler.DeleteTweet)
+	}
+}
@@ -0,0 +1,43 @@
+// internal/handlers/audit_handler.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ffelixf/microblog-platform/internal/middleware"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListAudit consulta la auditoría con los filtros de la URL
+func (h *AuditHandler) ListAudit(c *gin.Context) {
+	var query models.AuditQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	page, err := h.auditService.Query(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// RegisterAuditRoutes registra la consulta de auditoría, que exige audit:read
+func RegisterAuditRoutes(router *gin.Engine, handler *AuditHandler) {
+	router.GET("/api/v1/admin/audit", middleware.RequirePermission(rbac.AuditRead), handler.ListAudit)
+}
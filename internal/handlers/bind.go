@@ -0,0 +1,98 @@
+// internal/handlers/bind.go
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ErrInvalidBody indica un cuerpo que no es JSON válido o no respeta los tipos
+var ErrInvalidBody = apperr.Validation("invalid_body", "el cuerpo de la petición no es válido")
+
+func init() {
+	// Los errores de validación usan los nombres JSON de los campos, no los de Go
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// bindJSON decodifica y valida el cuerpo; si falla registra el error de validación
+// en el contexto y devuelve false
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		c.Error(bindingError(err))
+		return false
+	}
+	return true
+}
+
+// bindQuery es bindJSON para los parámetros de la URL
+func bindQuery(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindQuery(obj); err != nil {
+		c.Error(bindingError(err))
+		return false
+	}
+	return true
+}
+
+// bindingError traduce los errores de ShouldBindJSON a un error de validación con
+// el detalle de cada campo
+func bindingError(err error) error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return ErrInvalidBody.Wrap(err)
+	}
+
+	fields := make([]apperr.FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		fields = append(fields, apperr.FieldError{
+			Field:   fieldPath(fe),
+			Code:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fieldMessage(fe),
+		})
+	}
+	return apperr.Validation("validation_failed", "la petición tiene campos inválidos", fields...).Wrap(err)
+}
+
+// fieldPath devuelve la ruta del campo sin el nombre del struct raíz (poll.options[0])
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return ns
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "el campo es requerido"
+	case "max":
+		return fmt.Sprintf("el campo no puede exceder %s", fe.Param())
+	case "min":
+		return fmt.Sprintf("el campo debe ser al menos %s", fe.Param())
+	case "email":
+		return "el campo debe ser un email válido"
+	default:
+		return fmt.Sprintf("el campo no cumple la regla %s", fe.Tag())
+	}
+}
@@ -0,0 +1,81 @@
+// internal/handlers/export_handler.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+func NewExportHandler(exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// RequestExport pide la exportación de los datos del usuario que hace la
+// petición; se genera en segundo plano y su estado se consulta en GetExport
+func (h *ExportHandler) RequestExport(c *gin.Context) {
+	if !requireSelf(c) {
+		return
+	}
+
+	job, err := h.exportService.Request(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetExport devuelve el estado de una exportación y, si terminó, el enlace de descarga
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	if !requireSelf(c) {
+		return
+	}
+
+	job, err := h.exportService.Get(c.Request.Context(), c.Param("id"), c.Param("export_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// Download sirve el archivo de una exportación a partir de un enlace firmado
+func (h *ExportHandler) Download(c *gin.Context) {
+	job, blob, err := h.exportService.Open(c.Request.Context(), c.Param("id"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer blob.Close()
+
+	// Son datos personales: ni cachés compartidas ni copias en el navegador.
+	// El archivo se copia a la respuesta a medida que se lee del almacenamiento,
+	// con el tamaño guardado al generarlo.
+	c.DataFromReader(http.StatusOK, job.Size, blob.ContentType, blob, map[string]string{
+		"Cache-Control":          "private, no-store",
+		"X-Content-Type-Options": "nosniff",
+		"Content-Disposition":    fmt.Sprintf(`attachment; filename="microblog-export-%s.zip"`, job.ID.Hex()),
+	})
+}
+
+// RegisterExportRoutes registra las rutas de la exportación de datos. La
+// descarga no exige identificarse: la autoriza la firma del enlace.
+func RegisterExportRoutes(router *gin.Engine, handler *ExportHandler) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/users/:id/export", handler.RequestExport)
+		api.GET("/users/:id/export/:export_id", handler.GetExport)
+	}
+	router.GET("/exports/:id/download", handler.Download)
+}
@@ -0,0 +1,200 @@
+// internal/handlers/feed_handler.go
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/feed"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// maxFeedEntries limita la cantidad de tweets incluidos en cada feed
+const maxFeedEntries = 50
+
+// hashtagFeedDate es la fecha del tag URI (RFC 4151) de los feeds de hashtags,
+// que no tienen fecha de creación propia: la RFC pide una fecha en la que el
+// dominio ya era nuestro, y se usa la de la primera versión de los feeds. No
+// debe cambiar, porque cambiaría el ID de todos los feeds de hashtags.
+var hashtagFeedDate = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type FeedHandler struct {
+	userService     *service.UserService
+	timelineService *service.TimelineService
+	baseURL         string
+}
+
+// NewFeedHandler crea el handler de feeds. Si baseURL está vacío, los enlaces
+// se construyen a partir del host de cada petición.
+func NewFeedHandler(userService *service.UserService, timelineService *service.TimelineService, baseURL string) *FeedHandler {
+	return &FeedHandler{
+		userService:     userService,
+		timelineService: timelineService,
+		baseURL:         strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// UserFeed sirve los tweets de un usuario como feed RSS o Atom
+func (h *FeedHandler) UserFeed(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.Param("username")
+
+		user, err := h.userService.GetByUsername(c.Request.Context(), username)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		recent, err := h.timelineService.UserTweetsAfter(c.Request.Context(), user.ID.Hex(), nil, maxFeedEntries)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		base := h.base(c)
+		f := &feed.Feed{
+			ID:          feed.TagURI(base, user.CreatedAt, "users/"+user.ID.Hex()),
+			Title:       fmt.Sprintf("Tweets de @%s", user.Username),
+			Description: fmt.Sprintf("Últimos tweets publicados por @%s", user.Username),
+			Link:        base + "/api/v1/users/" + user.ID.Hex(),
+			SelfLink:    base + c.Request.URL.Path,
+			Author:      user.Username,
+			Entries:     h.entries(base, recent.Tweets, map[string]string{user.ID.Hex(): user.Username}),
+		}
+
+		h.render(c, f, format)
+	}
+}
+
+// HashtagFeed sirve los tweets más recientes de un hashtag como feed RSS o Atom
+func (h *FeedHandler) HashtagFeed(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tag := strings.ToLower(strings.TrimPrefix(c.Param("tag"), "#"))
+
+		tweets, err := h.timelineService.HashtagTweets(c.Request.Context(), tag, maxFeedEntries)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		authors := make(map[string]string)
+		for _, t := range tweets {
+			id := t.UserID.Hex()
+			if _, ok := authors[id]; ok {
+				continue
+			}
+			if user, err := h.userService.Get(c.Request.Context(), id); err == nil {
+				authors[id] = user.Username
+			} else {
+				authors[id] = ""
+			}
+		}
+
+		base := h.base(c)
+		f := &feed.Feed{
+			ID:          feed.TagURI(base, hashtagFeedDate, "hashtags/"+tag),
+			Title:       "#" + tag,
+			Description: fmt.Sprintf("Últimos tweets con el hashtag #%s", tag),
+			Link:        base + c.Request.URL.Path,
+			SelfLink:    base + c.Request.URL.Path,
+			Entries:     h.entries(base, tweets, authors),
+		}
+
+		h.render(c, f, format)
+	}
+}
+
+func (h *FeedHandler) entries(base string, tweets []models.Tweet, authors map[string]string) []feed.Entry {
+	entries := make([]feed.Entry, 0, len(tweets))
+	for _, t := range tweets {
+		entries = append(entries, feed.Entry{
+			ID:        feed.TagURI(base, t.CreatedAt, "tweets/"+t.ID.Hex()),
+			Author:    authors[t.UserID.Hex()],
+			Link:      base + "/api/v1/tweets/" + t.ID.Hex(),
+			Content:   t.Content,
+			Published: t.CreatedAt,
+		})
+	}
+	return entries
+}
+
+// render escribe el feed respetando If-None-Match e If-Modified-Since
+func (h *FeedHandler) render(c *gin.Context, f *feed.Feed, format string) {
+	etag := f.ETag(format)
+	updated := f.Updated().UTC().Truncate(time.Second)
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=300")
+	if !updated.IsZero() {
+		c.Header("Last-Modified", updated.Format(http.TimeFormat))
+	}
+
+	if notModified(c.Request, etag, updated) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	var (
+		body        []byte
+		err         error
+		contentType string
+	)
+	switch format {
+	case "atom":
+		body, err = f.Atom()
+		contentType = feed.AtomContentType
+	default:
+		body, err = f.RSS()
+		contentType = feed.RSSContentType
+	}
+	if err != nil {
+		c.Error(fmt.Errorf("error al generar feed: %w", err))
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// notModified evalúa las cabeceras condicionales según RFC 9110:
+// If-None-Match tiene prioridad sobre If-Modified-Since.
+func notModified(r *http.Request, etag string, updated time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !updated.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !updated.After(t)
+		}
+	}
+	return false
+}
+
+func (h *FeedHandler) base(c *gin.Context) string {
+	if h.baseURL != "" {
+		return h.baseURL
+	}
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// RegisterFeedRoutes registra las rutas de feeds RSS y Atom
+func RegisterFeedRoutes(router *gin.Engine, handler *FeedHandler) {
+	router.GET("/users/:username/feed.rss", handler.UserFeed("rss"))
+	router.GET("/users/:username/feed.atom", handler.UserFeed("atom"))
+	router.GET("/hashtags/:tag/feed.rss", handler.HashtagFeed("rss"))
+	router.GET("/hashtags/:tag/feed.atom", handler.HashtagFeed("atom"))
+}
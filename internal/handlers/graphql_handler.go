@@ -0,0 +1,88 @@
+// internal/handlers/graphql_handler.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/gql"
+	"github.com/ffelixf/microblog-platform/internal/middleware"
+	"github.com/ffelixf/microblog-platform/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrMissingQuery indica una petición GraphQL sin consulta
+var ErrMissingQuery = apperr.InvalidField("graphql_missing_query", "query", "falta la consulta")
+
+type GraphQLHandler struct {
+	server *gql.Server
+	// limiter aplica el rate limit según el tipo de operación; nil no limita
+	limiter *ratelimit.Limiter
+}
+
+func NewGraphQLHandler(server *gql.Server, limiter *ratelimit.Limiter) *GraphQLHandler {
+	return &GraphQLHandler{server: server, limiter: limiter}
+}
+
+// Query ejecuta una petición GraphQL. Por POST el cuerpo es JSON con query,
+// operationName y variables; por GET van en la URL y solo se aceptan consultas.
+// Los errores de la consulta se responden con estado 200 en el campo errors,
+// con el código estable del error en extensions.code.
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req gql.Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		req.QueryOnly = true
+		if vars := c.Query("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				c.Error(ErrInvalidBody.Wrap(err))
+				return
+			}
+		}
+	} else if !bindJSON(c, &req) {
+		return
+	}
+	if req.Query == "" {
+		c.Error(ErrMissingQuery)
+		return
+	}
+	// Las consultas cuentan como lecturas y las mutaciones como escrituras, con
+	// las reglas de GET y POST del limiter
+	method := http.MethodGet
+	if gql.IsMutation(req.Query, req.OperationName) {
+		method = http.MethodPost
+	}
+	if h.limiter != nil && !middleware.CheckRateLimit(c, h.limiter, method) {
+		return
+	}
+	if principal := middleware.Principal(c); principal != nil {
+		req.ViewerID = principal.UserID
+	}
+
+	result := h.server.Do(c.Request.Context(), req)
+	loc := middleware.LocalizerFrom(c)
+	for i, formatted := range result.Errors {
+		err := gql.OriginalError(formatted)
+		if err == nil {
+			// Errores de sintaxis o validación: el mensaje de graphql-go ya sirve
+			continue
+		}
+		if e, ok := apperr.As(err); !ok || e.Kind == apperr.KindInternal {
+			// La causa queda en el log de acceso; el cliente solo ve el error genérico
+			c.Error(err)
+		}
+		problem := middleware.NewProblem(err, "", loc)
+		result.Errors[i].Message = problem.Detail
+		result.Errors[i].Extensions = map[string]interface{}{"code": problem.Code}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RegisterGraphQLRoutes registra el endpoint GraphQL
+func RegisterGraphQLRoutes(router *gin.Engine, handler *GraphQLHandler) {
+	router.GET("/graphql", handler.Query)
+	router.POST("/graphql", handler.Query)
+}
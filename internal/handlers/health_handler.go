@@ -0,0 +1,42 @@
+// internal/handlers/health_handler.go
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ffelixf/microblog-platform/internal/health"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{registry: registry}
+}
+
+// Livez responde si el proceso está vivo; si falla, el orquestador lo reinicia
+func (h *HealthHandler) Livez(c *gin.Context) {
+	writeReport(c, h.registry.Live(c.Request.Context()))
+}
+
+// Readyz responde si la instancia puede recibir tráfico
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	writeReport(c, h.registry.Ready(c.Request.Context()))
+}
+
+func writeReport(c *gin.Context, report health.Report) {
+	c.Header("Cache-Control", "no-store")
+	status := http.StatusOK
+	if !report.OK() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// RegisterHealthRoutes registra las sondas de liveness y readiness
+func RegisterHealthRoutes(router *gin.Engine, handler *HealthHandler) {
+	router.GET("/livez", handler.Livez)
+	router.GET("/readyz", handler.Readyz)
+}
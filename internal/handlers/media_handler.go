@@ -0,0 +1,129 @@
+// internal/handlers/media_handler.go
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/media"
+	"github.com/ffelixf/microblog-platform/internal/middleware"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// multipartOverhead es el margen para los campos y cabeceras del formulario
+const multipartOverhead = 64 << 10
+
+var (
+	errUploadUserID   = apperr.InvalidField("invalid_user_id", "user_id", "ID de usuario inválido")
+	errFileRequired   = apperr.InvalidField("file_required", "file", "el archivo es requerido")
+	errFileUnreadable = apperr.InvalidField("file_unreadable", "file", "error al leer archivo")
+)
+
+type MediaHandler struct {
+	mediaService *service.MediaService
+}
+
+func NewMediaHandler(mediaService *service.MediaService) *MediaHandler {
+	return &MediaHandler{mediaService: mediaService}
+}
+
+// UploadMedia recibe una imagen (multipart, campos user_id y file), la valida,
+// elimina sus metadatos y guarda las variantes en el almacenamiento configurado
+func (h *MediaHandler) UploadMedia(c *gin.Context) {
+	maxBytes := h.mediaService.MaxBytes()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+multipartOverhead)
+
+	userID, err := primitive.ObjectIDFromHex(c.PostForm("user_id"))
+	if err != nil {
+		c.Error(errUploadUserID.Wrap(err))
+		return
+	}
+	middleware.SetUserID(c, userID.Hex())
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.Error(media.ErrTooLarge.Wrap(err))
+			return
+		}
+		c.Error(errFileRequired.Wrap(err))
+		return
+	}
+	if fileHeader.Size > maxBytes {
+		c.Error(media.ErrTooLarge)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Error(errFileUnreadable.Wrap(err))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		c.Error(errFileUnreadable.Wrap(err))
+		return
+	}
+
+	m, err := h.mediaService.Upload(c.Request.Context(), userID, data)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, withURLs(m))
+}
+
+// GetMedia devuelve los metadatos de un archivo con las URLs de sus variantes
+func (h *MediaHandler) GetMedia(c *gin.Context) {
+	m, err := h.mediaService.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, withURLs(m))
+}
+
+// ServeMedia sirve el contenido de una variante
+func (h *MediaHandler) ServeMedia(c *gin.Context) {
+	variant, blob, err := h.mediaService.OpenVariant(c.Request.Context(), c.Param("id"), c.Param("variant"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	defer blob.Close()
+
+	// Las variantes nunca cambian una vez subidas
+	c.DataFromReader(http.StatusOK, variant.Size, variant.ContentType, blob, map[string]string{
+		"Cache-Control":          "public, max-age=31536000, immutable",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func withURLs(m *models.Media) *models.Media {
+	for name, v := range m.Variants {
+		v.URL = "/media/" + m.ID.Hex() + "/" + name
+		m.Variants[name] = v
+	}
+	return m
+}
+
+// RegisterMediaRoutes registra las rutas de subida y descarga de archivos
+func RegisterMediaRoutes(router *gin.Engine, handler *MediaHandler) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/media", handler.UploadMedia)
+		api.GET("/media/:id", handler.GetMedia)
+	}
+	router.GET("/media/:id/:variant", handler.ServeMedia)
+}
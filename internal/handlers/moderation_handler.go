@@ -0,0 +1,122 @@
+// internal/handlers/moderation_handler.go
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ffelixf/microblog-platform/internal/middleware"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ModerationHandler struct {
+	moderationService *service.ModerationService
+}
+
+func NewModerationHandler(moderationService *service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+	}
+}
+
+// CreateReport registra la denuncia del usuario identificado sobre un tweet o una
+// cuenta. La respuesta no incluye el caso completo: quien denuncia no ve las
+// demás denuncias.
+func (h *ModerationHandler) CreateReport(c *gin.Context) {
+	principal := middleware.Principal(c)
+	if principal == nil {
+		c.Error(rbac.ErrUnauthenticated)
+		return
+	}
+	var req models.ReportRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	reporterID, err := primitive.ObjectIDFromHex(principal.UserID)
+	if err != nil {
+		c.Error(service.ErrInvalidID)
+		return
+	}
+	req.ReporterID = reporterID
+	middleware.SetUserID(c, principal.UserID)
+
+	report, err := h.moderationService.Report(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Denuncia registrada exitosamente",
+		"report_id":   report.ID,
+		"target_type": report.TargetType,
+		"target_id":   report.TargetID,
+	})
+}
+
+// ListReports devuelve la cola de moderación; ?status=open|resolved, open por defecto
+func (h *ModerationHandler) ListReports(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(service.DefaultPageSize)))
+
+	queue, err := h.moderationService.Queue(c.Request.Context(), c.Query("status"), page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"page":    queue.Page,
+		"limit":   queue.Limit,
+		"count":   len(queue.Reports),
+		"reports": queue.Reports,
+	})
+}
+
+// GetReport obtiene un caso de moderación con todas sus denuncias
+func (h *ModerationHandler) GetReport(c *gin.Context) {
+	report, err := h.moderationService.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ResolveReport cierra un caso con la acción del moderador: dismiss, hide_tweet o
+// suspend_user. El moderador es el usuario identificado en la petición.
+func (h *ModerationHandler) ResolveReport(c *gin.Context) {
+	var resolution models.ReportResolution
+	if !bindJSON(c, &resolution) {
+		return
+	}
+
+	report, err := h.moderationService.Resolve(c.Request.Context(), middleware.Principal(c), c.Param("id"), resolution)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RegisterModerationRoutes registra las denuncias y la cola de moderación. La
+// cola solo está disponible para quien tiene los permisos de moderación.
+func RegisterModerationRoutes(router *gin.Engine, handler *ModerationHandler) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/reports", handler.CreateReport)
+	}
+
+	moderation := router.Group("/api/v1/moderation", middleware.RequirePermission(rbac.ReportsRead))
+	{
+		moderation.GET("/reports", handler.ListReports)
+		moderation.GET("/reports/:id", handler.GetReport)
+		moderation.POST("/reports/:id/resolve", middleware.RequirePermission(rbac.ReportsResolve), handler.ResolveReport)
+	}
+}
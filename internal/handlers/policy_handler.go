@@ -0,0 +1,146 @@
+// internal/handlers/policy_handler.go
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ffelixf/microblog-platform/internal/middleware"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type PolicyHandler struct {
+	policyService *service.PolicyService
+}
+
+func NewPolicyHandler(policyService *service.PolicyService) *PolicyHandler {
+	return &PolicyHandler{
+		policyService: policyService,
+	}
+}
+
+// ListRules devuelve la lista de bloqueo
+func (h *PolicyHandler) ListRules(c *gin.Context) {
+	rules, err := h.policyService.Rules(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count": len(rules),
+		"rules": rules,
+	})
+}
+
+// CreateRule añade una regla a la lista de bloqueo
+func (h *PolicyHandler) CreateRule(c *gin.Context) {
+	var rule models.PolicyRule
+	if !bindJSON(c, &rule) {
+		return
+	}
+
+	if err := h.policyService.CreateRule(c.Request.Context(), middleware.Principal(c), &rule); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// DeleteRule quita una regla de la lista de bloqueo
+func (h *PolicyHandler) DeleteRule(c *gin.Context) {
+	if err := h.policyService.DeleteRule(c.Request.Context(), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListReview devuelve la cola de revisión; ?status=held|flagged, held por defecto
+func (h *PolicyHandler) ListReview(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(service.DefaultPageSize)))
+
+	queue, err := h.policyService.ReviewQueue(c.Request.Context(), c.Query("status"), page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"page":   queue.Page,
+		"limit":  queue.Limit,
+		"count":  len(queue.Tweets),
+		"tweets": queue.Tweets,
+	})
+}
+
+// ApproveTweet aprueba un tweet retenido o marcado
+func (h *PolicyHandler) ApproveTweet(c *gin.Context) {
+	tweet, err := h.policyService.Approve(c.Request.Context(), middleware.Principal(c), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tweet)
+}
+
+// GetMutedWords devuelve las palabras silenciadas del usuario que hace la petición
+func (h *PolicyHandler) GetMutedWords(c *gin.Context) {
+	if !requireSelf(c) {
+		return
+	}
+
+	words, err := h.policyService.MutedWords(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, words)
+}
+
+// SetMutedWords reemplaza las palabras silenciadas del usuario que hace la petición
+func (h *PolicyHandler) SetMutedWords(c *gin.Context) {
+	if !requireSelf(c) {
+		return
+	}
+	var req models.MutedWords
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	words, err := h.policyService.SetMutedWords(c.Request.Context(), c.Param("id"), req.Words)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, words)
+}
+
+// RegisterPolicyRoutes registra la política de contenido: la lista de bloqueo
+// (policy:manage), la cola de revisión (policy:review) y las palabras
+// silenciadas, que solo gestiona su dueño
+func RegisterPolicyRoutes(router *gin.Engine, handler *PolicyHandler) {
+	api := router.Group("/api/v1")
+	{
+		api.GET("/users/:id/muted-words", handler.GetMutedWords)
+		api.PUT("/users/:id/muted-words", handler.SetMutedWords)
+	}
+
+	admin := router.Group("/api/v1/admin/policy")
+	{
+		admin.GET("/rules", middleware.RequirePermission(rbac.PolicyManage), handler.ListRules)
+		admin.POST("/rules", middleware.RequirePermission(rbac.PolicyManage), handler.CreateRule)
+		admin.DELETE("/rules/:id", middleware.RequirePermission(rbac.PolicyManage), handler.DeleteRule)
+		admin.GET("/tweets", middleware.RequirePermission(rbac.PolicyReview), handler.ListReview)
+		admin.POST("/tweets/:id/approve", middleware.RequirePermission(rbac.PolicyReview), handler.ApproveTweet)
+	}
+}
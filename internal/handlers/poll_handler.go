@@ -0,0 +1,73 @@
+// internal/handlers/poll_handler.go
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ffelixf/microblog-platform/internal/middleware"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type voteRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+	Option *int   `json:"option" binding:"required"`
+}
+
+type PollHandler struct {
+	tweetService *service.TweetService
+	userService  *service.UserService
+}
+
+func NewPollHandler(tweetService *service.TweetService, userService *service.UserService) *PollHandler {
+	return &PollHandler{
+		tweetService: tweetService,
+		userService:  userService,
+	}
+}
+
+// Vote registra el voto de un usuario en la encuesta de un tweet
+func (h *PollHandler) Vote(c *gin.Context) {
+	var req voteRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	middleware.SetUserID(c, req.UserID)
+
+	tweet, err := h.tweetService.Vote(c.Request.Context(), c.Param("id"), req.UserID, *req.Option)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tweet)
+}
+
+// GetNotifications devuelve las notificaciones más recientes de un usuario
+func (h *PollHandler) GetNotifications(c *gin.Context) {
+	userID := c.Param("id")
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	notifications, err := h.userService.Notifications(c.Request.Context(), userID, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":       userID,
+		"count":         len(notifications),
+		"notifications": notifications,
+	})
+}
+
+// RegisterPollRoutes registra las rutas de votación y notificaciones
+func RegisterPollRoutes(router *gin.Engine, handler *PollHandler) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/tweets/:id/poll/votes", handler.Vote)
+		api.GET("/users/:id/notifications", handler.GetNotifications)
+	}
+}
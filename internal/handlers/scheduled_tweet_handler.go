@@ -0,0 +1,119 @@
+// internal/handlers/scheduled_tweet_handler.go
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ScheduledTweetHandler struct {
+	tweetService *service.TweetService
+}
+
+func NewScheduledTweetHandler(tweetService *service.TweetService) *ScheduledTweetHandler {
+	return &ScheduledTweetHandler{
+		tweetService: tweetService,
+	}
+}
+
+// CreateScheduledTweet crea un borrador, o un tweet programado si se indica publish_at
+func (h *ScheduledTweetHandler) CreateScheduledTweet(c *gin.Context) {
+	var st models.ScheduledTweet
+	if !bindJSON(c, &st) {
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(service.ErrInvalidID.Wrap(err))
+		return
+	}
+	st.UserID = userID
+
+	if err := h.tweetService.CreateScheduled(c.Request.Context(), &st); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, st)
+}
+
+// ListScheduledTweets lista los borradores y tweets programados de un usuario;
+// ?status=draft,scheduled,published,canceled,failed filtra por estado
+func (h *ScheduledTweetHandler) ListScheduledTweets(c *gin.Context) {
+	userID := c.Param("id")
+
+	var statuses []string
+	if raw := c.Query("status"); raw != "" {
+		statuses = strings.Split(raw, ",")
+	}
+
+	scheduled, err := h.tweetService.ListScheduled(c.Request.Context(), userID, statuses)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":          userID,
+		"count":            len(scheduled),
+		"scheduled_tweets": scheduled,
+	})
+}
+
+// GetScheduledTweet obtiene un borrador o tweet programado
+func (h *ScheduledTweetHandler) GetScheduledTweet(c *gin.Context) {
+	st, err := h.tweetService.GetScheduled(c.Request.Context(), c.Param("id"), c.Param("scheduled_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, st)
+}
+
+// UpdateScheduledTweet edita un borrador o reprograma un tweet
+func (h *ScheduledTweetHandler) UpdateScheduledTweet(c *gin.Context) {
+	var update models.ScheduledTweetUpdate
+	if !bindJSON(c, &update) {
+		return
+	}
+
+	st, err := h.tweetService.UpdateScheduled(c.Request.Context(), c.Param("id"), c.Param("scheduled_id"), update)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, st)
+}
+
+// CancelScheduledTweet cancela un borrador o tweet programado pendiente
+func (h *ScheduledTweetHandler) CancelScheduledTweet(c *gin.Context) {
+	if err := h.tweetService.CancelScheduled(c.Request.Context(), c.Param("id"), c.Param("scheduled_id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tweet programado cancelado exitosamente",
+		"id":      c.Param("scheduled_id"),
+	})
+}
+
+// RegisterScheduledTweetRoutes registra las rutas de borradores y tweets programados
+func RegisterScheduledTweetRoutes(router *gin.Engine, handler *ScheduledTweetHandler) {
+	api := router.Group("/api/v1")
+	{
+		api.POST("/users/:id/scheduled-tweets", handler.CreateScheduledTweet)
+		api.GET("/users/:id/scheduled-tweets", handler.ListScheduledTweets)
+		api.GET("/users/:id/scheduled-tweets/:scheduled_id", handler.GetScheduledTweet)
+		api.PATCH("/users/:id/scheduled-tweets/:scheduled_id", handler.UpdateScheduledTweet)
+		api.DELETE("/users/:id/scheduled-tweets/:scheduled_id", handler.CancelScheduledTweet)
+	}
+}
@@ -2,46 +2,77 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
 
+	"github.com/ffelixf/microblog-platform/internal/middleware"
 	"github.com/ffelixf/microblog-platform/internal/models"
-	"github.com/ffelixf/microblog-platform/internal/repository"
+	"github.com/ffelixf/microblog-platform/internal/service"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type TweetHandler struct {
-	tweetRepo *repository.TweetRepository
+	tweetService    *service.TweetService
+	timelineService *service.TimelineService
 }
 
-func NewTweetHandler(tweetRepo *repository.TweetRepository) *TweetHandler {
+func NewTweetHandler(tweetService *service.TweetService, timelineService *service.TimelineService) *TweetHandler {
 	return &TweetHandler{
-		tweetRepo: tweetRepo,
+		tweetService:    tweetService,
+		timelineService: timelineService,
 	}
 }
 
 func (h *TweetHandler) CreateTweet(c *gin.Context) {
 	var tweet models.Tweet
-	if err := c.ShouldBindJSON(&tweet); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &tweet) {
 		return
 	}
+	middleware.SetUserID(c, tweet.UserID.Hex())
 
-	if err := h.tweetRepo.Create(c.Request.Context(), &tweet); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.tweetService.Create(c.Request.Context(), &tweet); err != nil {
+		c.Error(err)
 		return
 	}
 
+	// Un tweet retenido por la política de contenido queda pendiente de revisión
+	if tweet.Held() {
+		c.JSON(http.StatusAccepted, tweet)
+		return
+	}
 	c.JSON(http.StatusCreated, tweet)
 }
 
+// GetTweet devuelve un tweet visto por un lector anónimo; es el enlace de cada
+// entrada de los feeds
+func (h *TweetHandler) GetTweet(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.Error(service.ErrInvalidID)
+		return
+	}
+
+	tweets, err := h.timelineService.TweetsByID(c.Request.Context(), []primitive.ObjectID{id})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	tweet := tweets[id]
+	if tweet == nil {
+		c.Error(service.ErrTweetNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, tweet)
+}
+
 func (h *TweetHandler) GetUserTweets(c *gin.Context) {
 	userID := c.Param("id")
 
-	tweets, err := h.tweetRepo.GetByUserID(c.Request.Context(), userID)
+	tweets, err := h.timelineService.UserTweets(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -55,32 +86,22 @@ func (h *TweetHandler) GetUserTweets(c *gin.Context) {
 func (h *TweetHandler) GetTimeline(c *gin.Context) {
 	userID := c.Param("id")
 
-	// Obtener parámetros de paginación
+	// Obtener parámetros de paginación; el servicio los normaliza
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(service.DefaultPageSize)))
 
-	// Validar parámetros
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 || limit > 50 {
-		limit = 10
-	}
-
-	tweets, err := h.tweetRepo.GetTimeline(c.Request.Context(), userID, page, limit)
+	timeline, err := h.timelineService.Timeline(c.Request.Context(), userID, page, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Error al obtener timeline: %v", err),
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"user_id": userID,
-		"page":    page,
-		"limit":   limit,
-		"count":   len(tweets),
-		"tweets":  tweets,
+		"page":    timeline.Page,
+		"limit":   timeline.Limit,
+		"count":   len(timeline.Tweets),
+		"tweets":  timeline.Tweets,
 	})
 }
 
@@ -88,6 +109,7 @@ func RegisterTweetRoutes(router *gin.Engine, handler *TweetHandler) {
 	api := router.Group("/api/v1")
 	{
 		api.POST("/tweets", handler.CreateTweet)
+		api.GET("/tweets/:id", handler.GetTweet)
 		api.GET("/users/:id/tweets", handler.GetUserTweets)
 		api.GET("/users/:id/timeline", handler.GetTimeline)
 	}
@@ -5,17 +5,17 @@ import (
 	"net/http"
 
 	"github.com/ffelixf/microblog-platform/internal/models"
-	"github.com/ffelixf/microblog-platform/internal/repository"
+	"github.com/ffelixf/microblog-platform/internal/service"
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
-	userRepo *repository.UserRepository
+	userService *service.UserService
 }
 
-func NewUserHandler(userRepo *repository.UserRepository) *UserHandler {
+func NewUserHandler(userService *service.UserService) *UserHandler {
 	return &UserHandler{
-		userRepo: userRepo,
+		userService: userService,
 	}
 }
 
@@ -27,24 +27,19 @@ func NewUserHandler(userRepo *repository.UserRepository) *UserHandler {
 // @Produce      json
 // @Param        user  body      models.User  true  "Información del usuario"
 // @Success      201   {object}  models.User
-// @Failure      400   {object}  models.Error
-// @Failure      409   {object}  models.Error
+// @Failure      400   {object}  models.Problem
+// @Failure      409   {object}  models.Problem
 // @Router       /users [post]
 
 // CreateUser maneja la creación de nuevos usuarios
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+	if !bindJSON(c, &user) {
 		return
 	}
 
-	if err := h.userRepo.Create(c.Request.Context(), &user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Error al crear usuario: " + err.Error(),
-		})
+	if err := h.userService.Create(c.Request.Context(), &user); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -59,18 +54,16 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 // @Produce      json
 // @Param        id   path      string  true  "ID del usuario"
 // @Success      200  {object}  models.User
-// @Failure      404  {object}  models.Error
+// @Failure      404  {object}  models.Problem
 // @Router       /users/{id} [get]
 
 // GetUser maneja la obtención de un usuario por ID
 func (h *UserHandler) GetUser(c *gin.Context) {
 	id := c.Param("id")
 
-	user, err := h.userRepo.GetByID(c.Request.Context(), id)
+	user, err := h.userService.Get(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Usuario no encontrado",
-		})
+		c.Error(err)
 		return
 	}
 
@@ -81,11 +74,9 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 func (h *UserHandler) GetFollowing(c *gin.Context) {
 	userID := c.Param("id")
 
-	following, err := h.userRepo.GetFollowing(c.Request.Context(), userID)
+	following, err := h.userService.Following(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -101,11 +92,9 @@ func (h *UserHandler) GetFollowing(c *gin.Context) {
 func (h *UserHandler) GetFollowers(c *gin.Context) {
 	userID := c.Param("id")
 
-	followers, err := h.userRepo.GetFollowers(c.Request.Context(), userID)
+	followers, err := h.userService.Followers(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Error al obtener seguidores: " + err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
@@ -124,8 +113,8 @@ func (h *UserHandler) GetFollowers(c *gin.Context) {
 // @Param        id         path      string  true  "ID del usuario que sigue"
 // @Param        target_id  path      string  true  "ID del usuario a seguir"
 // @Success      200        {object}  models.FollowResponse
-// @Failure      400        {object}  models.Error
-// @Failure      404        {object}  models.Error
+// @Failure      400        {object}  models.Problem
+// @Failure      404        {object}  models.Problem
 // @Router       /users/{id}/follow/{target_id} [post]
 
 // FollowUser maneja la acción de seguir a otro usuario
@@ -133,10 +122,8 @@ func (h *UserHandler) FollowUser(c *gin.Context) {
 	userID := c.Param("id")
 	targetID := c.Param("target_id")
 
-	if err := h.userRepo.FollowUser(c.Request.Context(), userID, targetID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Error al seguir usuario: " + err.Error(),
-		})
+	if err := h.userService.Follow(c.Request.Context(), userID, targetID); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -152,10 +139,8 @@ func (h *UserHandler) UnfollowUser(c *gin.Context) {
 	userID := c.Param("id")
 	targetID := c.Param("target_id")
 
-	if err := h.userRepo.UnfollowUser(c.Request.Context(), userID, targetID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Error al dejar de seguir usuario: " + err.Error(),
-		})
+	if err := h.userService.Unfollow(c.Request.Context(), userID, targetID); err != nil {
+		c.Error(err)
 		return
 	}
 
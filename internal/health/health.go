@@ -0,0 +1,210 @@
+// internal/health/health.go
+package health
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/logging"
+)
+
+const (
+	// DefaultCacheTTL es cuánto se reutiliza el resultado de un check cacheado
+	DefaultCacheTTL = 5 * time.Second
+	// DefaultTimeout es el tiempo máximo de cada check
+	DefaultTimeout = 2 * time.Second
+)
+
+// Estados de un check y del informe
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// CheckFunc comprueba una dependencia; un error la marca como no disponible
+type CheckFunc func(ctx context.Context) error
+
+// Option ajusta un check al registrarlo
+type Option func(*check)
+
+// NoCache ejecuta el check en cada sonda. Sirve para checks baratos que deben
+// reflejar los cambios al instante, como el inicio del apagado.
+func NoCache() Option {
+	return func(c *check) { c.cached = false }
+}
+
+type check struct {
+	name   string
+	run    CheckFunc
+	cached bool
+
+	mu        sync.Mutex
+	status    string
+	err       error
+	duration  time.Duration
+	checkedAt time.Time
+}
+
+// Registry agrupa los checks de liveness y readiness. Los resultados de los
+// checks cacheados se reutilizan durante el TTL, de modo que las sondas de varios
+// orquestadores o réplicas no multiplican las consultas a la base de datos.
+type Registry struct {
+	ttl       time.Duration
+	timeout   time.Duration
+	logger    *slog.Logger
+	now       func() time.Time
+	liveness  []*check
+	readiness []*check
+}
+
+func NewRegistry(ttl, timeout time.Duration, logger *slog.Logger) *Registry {
+	if ttl < 0 {
+		ttl = 0
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Registry{ttl: ttl, timeout: timeout, logger: logger, now: time.Now}
+}
+
+// Liveness registra un check de /livez: solo debe fallar si el proceso no puede
+// recuperarse sin reiniciarse, nunca por una dependencia externa caída
+func (r *Registry) Liveness(name string, fn CheckFunc, opts ...Option) {
+	r.liveness = append(r.liveness, newCheck(name, fn, opts))
+}
+
+// Readiness registra un check de /readyz: mientras falle la instancia no recibe tráfico
+func (r *Registry) Readiness(name string, fn CheckFunc, opts ...Option) {
+	r.readiness = append(r.readiness, newCheck(name, fn, opts))
+}
+
+func newCheck(name string, fn CheckFunc, opts []Option) *check {
+	c := &check{name: name, run: fn, cached: true}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// CheckResult es el resultado público de un check. No incluye el error: puede
+// contener hosts o credenciales, así que solo queda en los logs.
+type CheckResult struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report es la respuesta de /livez y /readyz
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// OK indica si todos los checks pasaron
+func (rep Report) OK() bool {
+	return rep.Status == StatusOK
+}
+
+// Live ejecuta los checks de liveness
+func (r *Registry) Live(ctx context.Context) Report {
+	return r.run(ctx, r.liveness)
+}
+
+// Ready ejecuta los checks de readiness
+func (r *Registry) Ready(ctx context.Context) Report {
+	return r.run(ctx, r.readiness)
+}
+
+func (r *Registry) run(ctx context.Context, checks []*check) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c *check) {
+			defer wg.Done()
+			result := r.result(ctx, c)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}(c)
+	}
+	wg.Wait()
+	return report
+}
+
+// result devuelve el resultado cacheado o ejecuta el check. El mutex del check
+// hace que las sondas simultáneas esperen a una única ejecución.
+func (r *Registry) result(ctx context.Context, c *check) CheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := r.now()
+	if c.cached && !c.checkedAt.IsZero() && now.Sub(c.checkedAt) < r.ttl {
+		return CheckResult{Status: c.status, DurationMs: c.duration.Milliseconds()}
+	}
+
+	// El check no depende de la sonda: si el cliente corta, el resultado sigue
+	// siendo válido para las siguientes
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.timeout)
+	defer cancel()
+	start := time.Now()
+	err := c.run(checkCtx)
+	if err == nil && errors.Is(checkCtx.Err(), context.DeadlineExceeded) {
+		err = checkCtx.Err()
+	}
+
+	status := StatusOK
+	if err != nil {
+		status = StatusFail
+	}
+	// Solo se registran los cambios para no llenar los logs con cada sonda
+	switch {
+	case err != nil && (c.err == nil || c.err.Error() != err.Error()):
+		r.logger.WarnContext(ctx, "check de salud fallido",
+			slog.String("check", c.name), slog.String("error", logging.Redact(err.Error())))
+	case err == nil && c.err != nil:
+		r.logger.InfoContext(ctx, "check de salud recuperado", slog.String("check", c.name))
+	}
+
+	c.status = status
+	c.err = err
+	c.duration = time.Since(start)
+	c.checkedAt = now
+	return CheckResult{Status: c.status, DurationMs: c.duration.Milliseconds()}
+}
+
+// Flag es un check que falla hasta que se marca como listo, por ejemplo al
+// terminar una tarea de arranque
+type Flag struct {
+	ready   atomic.Bool
+	pending error
+}
+
+// NewFlag crea un Flag que devuelve pending mientras no esté listo
+func NewFlag(pending error) *Flag {
+	return &Flag{pending: pending}
+}
+
+// Set marca el flag como listo
+func (f *Flag) Set() {
+	f.ready.Store(true)
+}
+
+// Check implementa CheckFunc
+func (f *Flag) Check(context.Context) error {
+	if f.ready.Load() {
+		return nil
+	}
+	return f.pending
+}
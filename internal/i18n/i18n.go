@@ -0,0 +1,179 @@
+// internal/i18n/i18n.go
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"golang.org/x/text/language"
+)
+
+// DefaultLanguage es el idioma que se usa si no se configura otro
+const DefaultLanguage = "es"
+
+// validationPrefix antecede a las reglas de validator/v10 en los catálogos
+const validationPrefix = "validation."
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Catalog traduce códigos de error estables a plantillas de mensaje. Las plantillas
+// pueden referirse a los parámetros del error entre llaves: {max}, {param}...
+type Catalog map[string]string
+
+// Bundle agrupa los catálogos de todos los idiomas soportados y negocia cuál usar
+// para cada petición
+type Bundle struct {
+	catalogs map[string]Catalog
+	langs    []string
+	matcher  language.Matcher
+}
+
+// NewBundle carga los catálogos incluidos en el binario. defaultLang es el idioma
+// que se usa cuando Accept-Language falta o no coincide con ninguno soportado.
+func NewBundle(defaultLang string) (*Bundle, error) {
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("error al leer catálogos: %w", err)
+	}
+
+	catalogs := make(map[string]Catalog, len(files))
+	for _, f := range files {
+		data, err := locales.ReadFile(path.Join("locales", f.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error al leer catálogo %s: %w", f.Name(), err)
+		}
+		var catalog Catalog
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("catálogo %s inválido: %w", f.Name(), err)
+		}
+		catalogs[strings.TrimSuffix(f.Name(), ".json")] = catalog
+	}
+	return NewBundleFromCatalogs(defaultLang, catalogs)
+}
+
+// NewBundleFromCatalogs crea un Bundle con catálogos propios
+func NewBundleFromCatalogs(defaultLang string, catalogs map[string]Catalog) (*Bundle, error) {
+	if _, ok := catalogs[defaultLang]; !ok {
+		return nil, fmt.Errorf("idioma por defecto no soportado: %q", defaultLang)
+	}
+
+	// El matcher usa el primer idioma como respaldo, así que va el idioma por defecto
+	langs := []string{defaultLang}
+	for lang := range catalogs {
+		if lang != defaultLang {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs[1:])
+
+	tags := make([]language.Tag, len(langs))
+	for i, lang := range langs {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return nil, fmt.Errorf("idioma inválido %q: %w", lang, err)
+		}
+		tags[i] = tag
+	}
+
+	return &Bundle{catalogs: catalogs, langs: langs, matcher: language.NewMatcher(tags)}, nil
+}
+
+// Languages devuelve los idiomas soportados, empezando por el idioma por defecto
+func (b *Bundle) Languages() []string {
+	return append([]string(nil), b.langs...)
+}
+
+// Negotiate elige el idioma según una cabecera Accept-Language (es-AR, en;q=0.8...)
+func (b *Bundle) Negotiate(acceptLanguage string) *Localizer {
+	lang := b.langs[0]
+	if desired, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil && len(desired) > 0 {
+		if _, i, confidence := b.matcher.Match(desired...); confidence != language.No {
+			lang = b.langs[i]
+		}
+	}
+	return b.Localizer(lang)
+}
+
+// Localizer devuelve el traductor de un idioma; si no está soportado usa el de por defecto
+func (b *Bundle) Localizer(lang string) *Localizer {
+	catalog, ok := b.catalogs[lang]
+	if !ok {
+		lang, catalog = b.langs[0], b.catalogs[b.langs[0]]
+	}
+	return &Localizer{lang: lang, catalog: catalog, fallback: b.catalogs[b.langs[0]]}
+}
+
+// Localizer traduce mensajes a un idioma concreto
+type Localizer struct {
+	lang     string
+	catalog  Catalog
+	fallback Catalog
+}
+
+// Language devuelve el código del idioma (es, en, pt)
+func (l *Localizer) Language() string {
+	return l.lang
+}
+
+// Message devuelve el mensaje del código con los parámetros interpolados. Si el
+// idioma no tiene el código se usa el idioma por defecto; ok es false si ninguno lo tiene.
+func (l *Localizer) Message(code string, params map[string]string) (string, bool) {
+	tmpl, ok := l.catalog[code]
+	if !ok {
+		tmpl, ok = l.fallback[code]
+	}
+	if !ok {
+		return "", false
+	}
+	return interpolate(tmpl, params), true
+}
+
+// Localize devuelve una copia del error con el mensaje y los campos inválidos
+// traducidos. Lo que no está en los catálogos conserva el texto original.
+func (l *Localizer) Localize(e *apperr.Error) *apperr.Error {
+	localized := *e
+	if msg, ok := l.Message(e.Code, e.Params); ok {
+		localized.Message = msg
+	}
+
+	if len(e.Fields) > 0 {
+		localized.Fields = make([]apperr.FieldError, len(e.Fields))
+		for i, f := range e.Fields {
+			localized.Fields[i] = l.localizeField(f, e.Params)
+		}
+	}
+	return &localized
+}
+
+// localizeField traduce un campo inválido. Los campos de las reglas de validator/v10
+// usan la regla como código; los de los errores de dominio, el código del error.
+func (l *Localizer) localizeField(f apperr.FieldError, params map[string]string) apperr.FieldError {
+	fieldParams := map[string]string{"field": f.Field, "param": f.Param}
+	for k, v := range params {
+		fieldParams[k] = v
+	}
+
+	if msg, ok := l.Message(validationPrefix+f.Code, fieldParams); ok {
+		f.Message = msg
+	} else if msg, ok := l.Message(f.Code, fieldParams); ok {
+		f.Message = msg
+	}
+	return f
+}
+
+func interpolate(tmpl string, params map[string]string) string {
+	if len(params) == 0 || !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for k, v := range params {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(tmpl)
+}
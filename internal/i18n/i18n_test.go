@@ -0,0 +1,128 @@
+// internal/i18n/i18n_test.go
+package i18n
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBundle_CatalogsHaveSameCodes(t *testing.T) {
+	b, err := NewBundle(DefaultLanguage)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"es", "en", "pt"}, b.Languages())
+
+	reference := b.catalogs[DefaultLanguage]
+	for lang, catalog := range b.catalogs {
+		for code := range reference {
+			assert.Contains(t, catalog, code, "falta %s en %s", code, lang)
+		}
+		assert.Len(t, catalog, len(reference), lang)
+	}
+}
+
+// domainCode encuentra los códigos de los errores creados con el paquete apperr
+var domainCode = regexp.MustCompile(`apperr\.(?:New\(apperr\.Kind\w+|NotFound|Conflict|Validation|Unauthorized|Forbidden|Unavailable|InvalidField)\(?,?\s*"([a-z_]+)"`)
+
+func TestCatalogs_CoverDomainErrors(t *testing.T) {
+	b, err := NewBundle(DefaultLanguage)
+	require.NoError(t, err)
+	catalog := b.catalogs[DefaultLanguage]
+
+	found := 0
+	err = filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, m := range domainCode.FindAllStringSubmatch(string(src), -1) {
+			found++
+			assert.Contains(t, catalog, m[1], "%s: el código no está en los catálogos", path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, found)
+}
+
+func TestNewBundle_UnsupportedDefault(t *testing.T) {
+	_, err := NewBundle("fr")
+	assert.Error(t, err)
+}
+
+func TestBundle_Negotiate(t *testing.T) {
+	b, err := NewBundle("en")
+	require.NoError(t, err)
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "en"},
+		{"es-AR,es;q=0.9", "es"},
+		{"pt-BR", "pt"},
+		{"fr-FR, pt;q=0.5", "pt"},
+		{"fr-FR", "en"},
+		{"de;q=0.8, es;q=0.9", "es"},
+		{"no es una cabecera válida;;", "en"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, b.Negotiate(tt.header).Language(), tt.header)
+	}
+}
+
+func TestLocalizer_Localize(t *testing.T) {
+	b, err := NewBundle(DefaultLanguage)
+	require.NoError(t, err)
+	en := b.Localizer("en")
+
+	t.Run("message with params", func(t *testing.T) {
+		e := apperr.InvalidField("content_too_long", "content", "muy largo").WithParam("max", 280)
+		localized := en.Localize(e)
+		assert.Equal(t, "the tweet content cannot exceed 280 characters", localized.Message)
+		assert.Equal(t, "the tweet content cannot exceed 280 characters", localized.Fields[0].Message)
+		// El original no cambia
+		assert.Equal(t, "muy largo", e.Message)
+	})
+
+	t.Run("validator rules", func(t *testing.T) {
+		e := apperr.Validation("validation_failed", "campos inválidos",
+			apperr.FieldError{Field: "email", Code: "email", Message: "email inválido"},
+			apperr.FieldError{Field: "content", Code: "max", Param: "280", Message: "muy largo"},
+		)
+		localized := b.Localizer("pt").Localize(e)
+		assert.Equal(t, "a requisição tem campos inválidos", localized.Message)
+		assert.Equal(t, "o campo deve ser um email válido", localized.Fields[0].Message)
+		assert.Equal(t, "o campo não pode exceder 280", localized.Fields[1].Message)
+	})
+
+	t.Run("unknown codes keep the original text", func(t *testing.T) {
+		e := apperr.Validation("unknown_code", "texto original",
+			apperr.FieldError{Field: "x", Code: "unknown_rule", Message: "regla desconocida"})
+		localized := en.Localize(e)
+		assert.Equal(t, "texto original", localized.Message)
+		assert.Equal(t, "regla desconocida", localized.Fields[0].Message)
+	})
+
+	t.Run("missing translation falls back to default language", func(t *testing.T) {
+		b, err := NewBundleFromCatalogs("es", map[string]Catalog{
+			"es": {"user_not_found": "usuario no encontrado"},
+			"en": {},
+		})
+		require.NoError(t, err)
+		msg, ok := b.Localizer("en").Message("user_not_found", nil)
+		assert.True(t, ok)
+		assert.Equal(t, "usuario no encontrado", msg)
+	})
+}
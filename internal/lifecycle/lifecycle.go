@@ -0,0 +1,232 @@
+// internal/lifecycle/lifecycle.go
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultShutdownTimeout es el tiempo máximo para drenar peticiones y workers
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// ErrDraining indica que la instancia se está apagando
+var ErrDraining = errors.New("la instancia se está apagando")
+
+// Manager coordina el apagado ordenado del proceso: al recibir la señal marca la
+// instancia como no lista, deja de aceptar conexiones, espera a las peticiones en
+// curso y a los workers y por último ejecuta los cierres registrados (Mongo,
+// trazas...). Todo el drenaje comparte un mismo plazo.
+type Manager struct {
+	logger *slog.Logger
+
+	// DrainDelay es la espera entre marcar la instancia como no lista y cerrar el
+	// listener, para que el balanceador deje de enviar tráfico antes del cierre
+	DrainDelay time.Duration
+	// ShutdownTimeout es el plazo total del drenaje
+	ShutdownTimeout time.Duration
+
+	draining atomic.Bool
+
+	workerCtx   context.Context
+	stopWorkers context.CancelFunc
+	workers     sync.WaitGroup
+	mu          sync.Mutex
+	closers     []closer
+	stopped     []string
+}
+
+type closer struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func New(logger *slog.Logger) *Manager {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		logger:          logger,
+		ShutdownTimeout: DefaultShutdownTimeout,
+		workerCtx:       ctx,
+		stopWorkers:     cancel,
+	}
+}
+
+// Draining indica si la instancia ya está apagándose; la readiness debe fallar
+func (m *Manager) Draining() bool {
+	return m.draining.Load()
+}
+
+// Go arranca un worker en segundo plano que debe correr hasta el apagado. Su
+// contexto se cancela al empezar el drenaje y el apagado espera a que la función
+// termine. Si termina antes (o entra en pánico) CheckWorkers falla.
+func (m *Manager) Go(name string, run func(ctx context.Context)) {
+	m.start(name, run, true)
+}
+
+// Task arranca una tarea en segundo plano que termina por sí sola, como la
+// creación de índices al arrancar. El apagado la cancela y la espera igual que a
+// los workers, pero que termine no es un fallo.
+func (m *Manager) Task(name string, run func(ctx context.Context)) {
+	m.start(name, run, false)
+}
+
+func (m *Manager) start(name string, run func(ctx context.Context), longLived bool) {
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		defer func() {
+			if p := recover(); p != nil {
+				m.logger.Error("panic en segundo plano", slog.String("worker", name), slog.Any("panic", p))
+				m.markStopped(name)
+			}
+		}()
+		run(m.workerCtx)
+		if longLived && !m.Draining() {
+			m.logger.Error("worker detenido antes del apagado", slog.String("worker", name))
+			m.markStopped(name)
+			return
+		}
+		m.logger.Debug("worker detenido", slog.String("worker", name))
+	}()
+}
+
+func (m *Manager) markStopped(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopped = append(m.stopped, name)
+}
+
+// CheckWorkers falla si algún worker terminó o entró en pánico fuera del
+// apagado. Es un check de liveness: el proceso necesita reiniciarse.
+func (m *Manager) CheckWorkers(context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.stopped) > 0 {
+		return fmt.Errorf("workers detenidos: %s", strings.Join(m.stopped, ", "))
+	}
+	return nil
+}
+
+// CheckDraining falla en cuanto empieza el apagado, para que la readiness saque
+// a la instancia del balanceador mientras drena
+func (m *Manager) CheckDraining(context.Context) error {
+	if m.Draining() {
+		return ErrDraining
+	}
+	return nil
+}
+
+// OnShutdown registra un cierre que se ejecuta después de drenar peticiones y
+// workers. Como con defer, los cierres se ejecutan en orden inverso al de
+// registro: lo que se creó último se cierra primero.
+func (m *Manager) OnShutdown(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closers = append(m.closers, closer{name: name, fn: fn})
+}
+
+// Serve atiende peticiones con srv hasta que se cancela ctx (normalmente por
+// SIGINT o SIGTERM) y entonces realiza el apagado ordenado. Devuelve el error del
+// servidor si no pudo arrancar o los errores del drenaje.
+func (m *Manager) Serve(ctx context.Context, srv *http.Server) error {
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return m.Shutdown(fmt.Errorf("error al escuchar en %s: %w", srv.Addr, err))
+	}
+	return m.ServeListener(ctx, srv, ln)
+}
+
+// ServeListener es como Serve pero con un listener ya abierto
+func (m *Manager) ServeListener(ctx context.Context, srv *http.Server, ln net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+	m.logger.Info("servidor iniciado", slog.String("addr", ln.Addr().String()))
+
+	select {
+	case err := <-serveErr:
+		// El servidor terminó sin que nadie lo pidiera
+		return m.Shutdown(fmt.Errorf("error del servidor: %w", err))
+	case <-ctx.Done():
+	}
+
+	m.draining.Store(true)
+	m.logger.Info("apagando el servidor", slog.Duration("timeout", m.ShutdownTimeout))
+
+	if m.DrainDelay > 0 {
+		time.Sleep(m.DrainDelay)
+	}
+
+	deadline, cancel := context.WithTimeout(context.Background(), m.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	// Shutdown cierra el listener y espera a que terminen las peticiones en curso
+	if err := srv.Shutdown(deadline); err != nil {
+		errs = append(errs, fmt.Errorf("peticiones sin terminar: %w", err))
+		srv.Close()
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errs = append(errs, err)
+	}
+	errs = append(errs, m.drain(deadline))
+	return errors.Join(errs...)
+}
+
+// Shutdown detiene los workers y ejecuta los cierres sin servidor HTTP, por
+// ejemplo cuando el arranque falla después de conectar a Mongo. cause se
+// devuelve junto con los errores del drenaje.
+func (m *Manager) Shutdown(cause error) error {
+	m.draining.Store(true)
+	deadline, cancel := context.WithTimeout(context.Background(), m.ShutdownTimeout)
+	defer cancel()
+	return errors.Join(cause, m.drain(deadline))
+}
+
+// drain cancela los workers, espera a que terminen y ejecuta los cierres
+func (m *Manager) drain(ctx context.Context) error {
+	var errs []error
+
+	m.stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("workers sin terminar: %w", ctx.Err()))
+	}
+
+	m.mu.Lock()
+	closers := m.closers
+	m.closers = nil
+	m.mu.Unlock()
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		c := closers[i]
+		if err := c.fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("error al cerrar %s: %w", c.name, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	m.logger.Info("apagado completado")
+	return nil
+}
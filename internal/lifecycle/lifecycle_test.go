@@ -0,0 +1,177 @@
+// internal/lifecycle/lifecycle_test.go
+package lifecycle
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager() *Manager {
+	m := New(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	m.ShutdownTimeout = 2 * time.Second
+	return m
+}
+
+func listen(t *testing.T) net.Listener {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	return ln
+}
+
+func TestServe_DrainsInFlightRequests(t *testing.T) {
+	m := newTestManager()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var drainingDuringRequest atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		drainingDuringRequest.Store(m.Draining())
+		w.Write([]byte("ok"))
+	})
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, name)
+	}
+	m.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		record("worker")
+	})
+	m.OnShutdown("mongodb", func(ctx context.Context) error {
+		record("mongodb")
+		return nil
+	})
+	m.OnShutdown("federación", func(ctx context.Context) error {
+		record("federación")
+		return nil
+	})
+
+	ln := listen(t)
+	ctx, stop := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- m.ServeListener(ctx, &http.Server{Handler: mux}, ln)
+	}()
+
+	response := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/slow")
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+
+	<-started
+	assert.False(t, m.Draining())
+	stop()
+	require.Eventually(t, m.Draining, time.Second, 5*time.Millisecond)
+
+	// Las conexiones nuevas se rechazan mientras la petición en curso sigue
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	}, time.Second, 5*time.Millisecond)
+
+	close(release)
+	assert.Equal(t, "ok", <-response)
+	require.NoError(t, <-served)
+	assert.True(t, drainingDuringRequest.Load())
+	assert.Equal(t, []string{"worker", "federación", "mongodb"}, order)
+}
+
+func TestServe_DeadlineExceeded(t *testing.T) {
+	m := newTestManager()
+	m.ShutdownTimeout = 50 * time.Millisecond
+
+	m.Go("stuck", func(ctx context.Context) {
+		time.Sleep(time.Second)
+	})
+	closed := false
+	m.OnShutdown("mongodb", func(ctx context.Context) error {
+		closed = true
+		return nil
+	})
+
+	ctx, stop := context.WithCancel(context.Background())
+	stop()
+	err := m.ServeListener(ctx, &http.Server{Handler: http.NotFoundHandler()}, listen(t))
+	assert.ErrorContains(t, err, "workers sin terminar")
+	assert.True(t, closed, "los cierres se ejecutan aunque venza el plazo")
+}
+
+func TestShutdown_WithoutServer(t *testing.T) {
+	m := newTestManager()
+	closed := false
+	m.OnShutdown("mongodb", func(ctx context.Context) error {
+		closed = true
+		return nil
+	})
+
+	cause := assert.AnError
+	err := m.Shutdown(cause)
+	assert.ErrorIs(t, err, cause)
+	assert.True(t, closed)
+	assert.True(t, m.Draining())
+}
+
+func TestServe_ListenError(t *testing.T) {
+	m := newTestManager()
+	ln := listen(t)
+	defer ln.Close()
+
+	err := m.Serve(context.Background(), &http.Server{Addr: ln.Addr().String()})
+	assert.ErrorContains(t, err, "error al escuchar")
+}
+
+func TestCheckWorkers(t *testing.T) {
+	m := newTestManager()
+
+	done := make(chan struct{}, 3)
+	m.Go("scheduler", func(ctx context.Context) {
+		done <- struct{}{}
+		<-ctx.Done()
+	})
+	m.Task("índices", func(ctx context.Context) {
+		done <- struct{}{}
+	})
+	for i := 0; i < 2; i++ {
+		<-done
+	}
+	require.Eventually(t, func() bool { return m.CheckWorkers(context.Background()) == nil }, time.Second, 5*time.Millisecond)
+	assert.NoError(t, m.CheckWorkers(context.Background()), "una tarea que termina no es un fallo")
+
+	m.Go("poll_closer", func(ctx context.Context) {
+		panic("fallo")
+	})
+	require.Eventually(t, func() bool { return m.CheckWorkers(context.Background()) != nil }, time.Second, 5*time.Millisecond)
+	assert.ErrorContains(t, m.CheckWorkers(context.Background()), "poll_closer")
+
+	assert.NoError(t, m.CheckDraining(context.Background()))
+	m.Shutdown(nil)
+	assert.ErrorIs(t, m.CheckDraining(context.Background()), ErrDraining)
+}
@@ -0,0 +1,230 @@
+// internal/loadtest/driver.go
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/middleware"
+)
+
+// Operaciones que reproduce el driver
+const (
+	OpTimeline = "timeline"
+	OpTweet    = "tweet"
+	OpFollow   = "follow"
+)
+
+// Mix es el peso relativo de cada operación
+type Mix struct {
+	Timeline int
+	Tweet    int
+	Follow   int
+}
+
+// DefaultMix es una carga dominada por lecturas del timeline
+var DefaultMix = Mix{Timeline: 80, Tweet: 15, Follow: 5}
+
+// ParseMix lee una mezcla como "timeline=80,tweet=15,follow=5"; las
+// operaciones que no aparecen tienen peso 0
+func ParseMix(s string) (Mix, error) {
+	var mix Mix
+	for _, part := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		weight, err := strconv.Atoi(value)
+		if !ok || err != nil || weight < 0 {
+			return Mix{}, fmt.Errorf("peso inválido en la mezcla: %q", part)
+		}
+		switch name {
+		case OpTimeline:
+			mix.Timeline = weight
+		case OpTweet:
+			mix.Tweet = weight
+		case OpFollow:
+			mix.Follow = weight
+		default:
+			return Mix{}, fmt.Errorf("operación desconocida en la mezcla: %q", name)
+		}
+	}
+	if mix.total() == 0 {
+		return Mix{}, errors.New("la mezcla no tiene ninguna operación")
+	}
+	return mix, nil
+}
+
+func (m Mix) total() int {
+	return m.Timeline + m.Tweet + m.Follow
+}
+
+// pick sortea una operación según los pesos
+func (m Mix) pick(rng *rand.Rand) string {
+	n := rng.IntN(m.total())
+	switch {
+	case n < m.Timeline:
+		return OpTimeline
+	case n < m.Timeline+m.Tweet:
+		return OpTweet
+	default:
+		return OpFollow
+	}
+}
+
+// Options configura una prueba de carga
+type Options struct {
+	// BaseURL es la dirección de la API, sin barra final
+	BaseURL string
+	// UserIDs son los usuarios en cuyo nombre se actúa; hacen falta al menos dos
+	UserIDs []string
+	Mix     Mix
+	// Concurrency es el número de clientes simultáneos; por defecto 8
+	Concurrency int
+	// Duration limita la prueba por tiempo y Requests por número de peticiones;
+	// la prueba termina con el primero que se cumpla. Sin ninguno dura 10s.
+	Duration time.Duration
+	Requests int
+	// Rate limita las peticiones por segundo entre todos los clientes; 0 no limita
+	Rate float64
+	// TimelineLimit es el tamaño de página de las lecturas del timeline; por defecto 20
+	TimelineLimit int
+	Seed          uint64
+	Client        *http.Client
+}
+
+// Run reproduce la mezcla de operaciones contra la API hasta que se cumpla la
+// duración o el número de peticiones, o se cancele ctx, y devuelve las latencias
+// medidas. Cada cliente espera la respuesta antes de la siguiente petición.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if len(opts.UserIDs) < 2 {
+		return nil, errors.New("hacen falta al menos dos usuarios")
+	}
+	if opts.Mix.total() == 0 {
+		opts.Mix = DefaultMix
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 8
+	}
+	if opts.Duration <= 0 && opts.Requests <= 0 {
+		opts.Duration = 10 * time.Second
+	}
+	if opts.TimelineLimit <= 0 {
+		opts.TimelineLimit = 20
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	var tick <-chan time.Time
+	if opts.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var issued atomic.Int64
+	recorders := make([]*recorder, opts.Concurrency)
+	started := time.Now()
+	var wg sync.WaitGroup
+	for i := range recorders {
+		recorders[i] = newRecorder()
+		wg.Add(1)
+		go func(rec *recorder, rng *rand.Rand) {
+			defer wg.Done()
+			c := &client{opts: &opts, rng: rng}
+			for {
+				if tick != nil {
+					select {
+					case <-tick:
+					case <-ctx.Done():
+						return
+					}
+				}
+				if ctx.Err() != nil || (opts.Requests > 0 && issued.Add(1) > int64(opts.Requests)) {
+					return
+				}
+				op := opts.Mix.pick(rng)
+				begin := time.Now()
+				err := c.do(ctx, op)
+				// Las peticiones cortadas al terminar la prueba no cuentan
+				if err != nil && ctx.Err() != nil {
+					return
+				}
+				rec.record(op, time.Since(begin), err)
+			}
+		}(recorders[i], rand.New(rand.NewPCG(opts.Seed, uint64(i))))
+	}
+	wg.Wait()
+	return newReport(time.Since(started), recorders), nil
+}
+
+// client hace las peticiones de un cliente simulado
+type client struct {
+	opts *Options
+	rng  *rand.Rand
+}
+
+func (c *client) do(ctx context.Context, op string) error {
+	users := c.opts.UserIDs
+	actor := users[c.rng.IntN(len(users))]
+	switch op {
+	case OpTimeline:
+		url := fmt.Sprintf("%s/api/v1/users/%s/timeline?limit=%d", c.opts.BaseURL, actor, c.opts.TimelineLimit)
+		return c.send(ctx, http.MethodGet, url, actor, nil)
+	case OpTweet:
+		body, err := json.Marshal(map[string]string{
+			"user_id": actor,
+			"content": fmt.Sprintf("prueba de carga %d", c.rng.Uint32()),
+		})
+		if err != nil {
+			return err
+		}
+		return c.send(ctx, http.MethodPost, c.opts.BaseURL+"/api/v1/tweets", actor, body)
+	default:
+		target := users[c.rng.IntN(len(users))]
+		for target == actor {
+			target = users[c.rng.IntN(len(users))]
+		}
+		url := fmt.Sprintf("%s/api/v1/users/%s/follow/%s", c.opts.BaseURL, actor, target)
+		return c.send(ctx, http.MethodPost, url, actor, nil)
+	}
+}
+
+// send hace la petición en nombre de actor; una respuesta que no es 2xx es un error
+func (c *client) send(ctx context.Context, method, url, actor string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(middleware.UserIDHeader, actor)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Leer todo el cuerpo es parte de la latencia y permite reutilizar la conexión
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: %s", method, url, resp.Status)
+	}
+	return nil
+}
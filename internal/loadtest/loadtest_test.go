@@ -0,0 +1,74 @@
+// internal/loadtest/loadtest_test.go
+package loadtest
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/dataset"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMix(t *testing.T) {
+	mix, err := ParseMix("timeline=70, tweet=20,follow=10")
+	require.NoError(t, err)
+	assert.Equal(t, Mix{Timeline: 70, Tweet: 20, Follow: 10}, mix)
+
+	mix, err = ParseMix("tweet=1")
+	require.NoError(t, err)
+	assert.Equal(t, Mix{Tweet: 1}, mix)
+
+	for _, invalid := range []string{"", "timeline", "timeline=-1", "retweet=5", "timeline=0"} {
+		_, err := ParseMix(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 200; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, 100*time.Millisecond, percentile(latencies, 50))
+	assert.Equal(t, 190*time.Millisecond, percentile(latencies, 95))
+	assert.Equal(t, 198*time.Millisecond, percentile(latencies, 99))
+	assert.Equal(t, time.Millisecond, percentile(latencies[:1], 99))
+	assert.Zero(t, percentile(nil, 50))
+}
+
+func TestRun_MemoryAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	api, err := NewMemoryAPI(ctx, dataset.GenerateOptions{Users: 50, Tweets: 300, FollowsPerUser: 5, Seed: 7})
+	require.NoError(t, err)
+	assert.Equal(t, 50, api.Stats.Users)
+	assert.Equal(t, 300, api.Stats.Tweets)
+	require.Len(t, api.UserIDs, 50)
+
+	server := httptest.NewServer(api.Handler)
+	defer server.Close()
+
+	report, err := Run(ctx, Options{
+		BaseURL:     server.URL,
+		UserIDs:     api.UserIDs,
+		Mix:         Mix{Timeline: 6, Tweet: 3, Follow: 1},
+		Concurrency: 4,
+		Requests:    200,
+		Seed:        1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 200, report.Total.Requests)
+	assert.Zero(t, report.Total.Errors, report.LastError)
+	require.Len(t, report.Ops, 3)
+	for _, op := range report.Ops {
+		assert.Positive(t, op.Requests, op.Op)
+		assert.LessOrEqual(t, op.P50, op.P95, op.Op)
+		assert.LessOrEqual(t, op.P95, op.P99, op.Op)
+		assert.LessOrEqual(t, op.P99, op.Max, op.Op)
+	}
+	assert.Positive(t, report.Total.Throughput)
+}
@@ -0,0 +1,133 @@
+// internal/loadtest/report.go
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+)
+
+// recorder guarda las latencias de un cliente; cada cliente tiene el suyo para
+// no competir por un lock en cada petición
+type recorder struct {
+	latencies map[string][]time.Duration
+	errors    map[string]int
+	lastError error
+}
+
+func newRecorder() *recorder {
+	return &recorder{latencies: make(map[string][]time.Duration), errors: make(map[string]int)}
+}
+
+func (r *recorder) record(op string, latency time.Duration, err error) {
+	r.latencies[op] = append(r.latencies[op], latency)
+	if err != nil {
+		r.errors[op]++
+		r.lastError = err
+	}
+}
+
+// OpReport son las latencias de una operación, errores incluidos
+type OpReport struct {
+	Op         string        `json:"op"`
+	Requests   int           `json:"requests"`
+	Errors     int           `json:"errors"`
+	Throughput float64       `json:"throughput"`
+	P50        time.Duration `json:"p50"`
+	P95        time.Duration `json:"p95"`
+	P99        time.Duration `json:"p99"`
+	Max        time.Duration `json:"max"`
+}
+
+// Report es el resultado de una prueba de carga; Total resume todas las operaciones
+type Report struct {
+	Duration time.Duration `json:"duration"`
+	Total    OpReport      `json:"total"`
+	Ops      []OpReport    `json:"ops"`
+	// LastError es el último error visto, para diagnosticar sin revisar los logs
+	LastError string `json:"last_error,omitempty"`
+}
+
+func newReport(elapsed time.Duration, recorders []*recorder) *Report {
+	report := &Report{Duration: elapsed}
+	var all []time.Duration
+	failed := 0
+	for _, op := range []string{OpTimeline, OpTweet, OpFollow} {
+		var latencies []time.Duration
+		opErrors := 0
+		for _, rec := range recorders {
+			latencies = append(latencies, rec.latencies[op]...)
+			opErrors += rec.errors[op]
+		}
+		if len(latencies) == 0 {
+			continue
+		}
+		report.Ops = append(report.Ops, summarize(op, latencies, opErrors, elapsed))
+		all = append(all, latencies...)
+		failed += opErrors
+	}
+	report.Total = summarize("total", all, failed, elapsed)
+	for _, rec := range recorders {
+		if rec.lastError != nil {
+			report.LastError = rec.lastError.Error()
+		}
+	}
+	return report
+}
+
+// summarize ordena las latencias y calcula los percentiles
+func summarize(op string, latencies []time.Duration, failed int, elapsed time.Duration) OpReport {
+	slices.Sort(latencies)
+	s := OpReport{
+		Op:       op,
+		Requests: len(latencies),
+		Errors:   failed,
+		P50:      percentile(latencies, 50),
+		P95:      percentile(latencies, 95),
+		P99:      percentile(latencies, 99),
+	}
+	if len(latencies) > 0 {
+		s.Max = latencies[len(latencies)-1]
+	}
+	if elapsed > 0 {
+		s.Throughput = float64(len(latencies)) / elapsed.Seconds()
+	}
+	return s
+}
+
+// percentile devuelve el percentil p de latencias ordenadas por el método del
+// rango más cercano
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// Write escribe el informe como tabla
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "operación\tpeticiones\terrores\tpet/s\tp50\tp95\tp99\tmáx\t")
+	for _, op := range append(r.Ops, r.Total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t\n", op.Op, op.Requests, op.Errors, op.Throughput,
+			round(op.P50), round(op.P95), round(op.P99), round(op.Max))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "duración %s\n", round(r.Duration))
+	if err == nil && r.LastError != "" {
+		_, err = fmt.Fprintf(w, "último error: %s\n", r.LastError)
+	}
+	return err
+}
+
+func round(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(10 * time.Microsecond)
+}
@@ -0,0 +1,79 @@
+// internal/loadtest/server.go
+package loadtest
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/ffelixf/microblog-platform/internal/dataset"
+	"github.com/ffelixf/microblog-platform/internal/handlers"
+	"github.com/ffelixf/microblog-platform/internal/memstore"
+	"github.com/ffelixf/microblog-platform/internal/middleware"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryAPI es la API de usuarios, tweets y encuestas sobre el almacenamiento
+// en memoria, cargada con un conjunto de datos sintético. Sirve para medir los
+// servicios sin MongoDB, por ejemplo en CI.
+type MemoryAPI struct {
+	Handler http.Handler
+	// UserIDs son los IDs de los usuarios generados
+	UserIDs []string
+	Stats   *dataset.Stats
+}
+
+// NewMemoryAPI genera el conjunto de datos, lo importa en memoria y arma el
+// router con los mismos handlers y servicios que cmd/api
+func NewMemoryAPI(ctx context.Context, opts dataset.GenerateOptions) (*MemoryAPI, error) {
+	users, tweets := memstore.NewUsers(), memstore.NewTweets()
+
+	// El conjunto pasa por la importación sin guardarse completo en memoria
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := dataset.Generate(ctx, pw, opts)
+		pw.CloseWithError(err)
+	}()
+	stats, err := dataset.NewImporter(users, tweets, dataset.DefaultBatchSize).Import(ctx, pr, "")
+	pr.CloseWithError(err)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, users.Len())
+	err = users.Walk(ctx, func(user *models.User) error {
+		ids = append(ids, user.ID.Hex())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	polls := memstore.NewPolls(tweets)
+	userService := service.NewUserService(users, nil, nil)
+	tweetService := service.NewTweetService(tweets, users, noMedia{}, polls, nil, nil, nil)
+	timelineService := service.NewTimelineService(tweets, users, polls, nil)
+
+	r := gin.New()
+	r.Use(middleware.Recovery(slog.Default()))
+	r.Use(middleware.Errors())
+	r.Use(middleware.Identity(userService.Principal))
+	r.NoRoute(middleware.NoRoute)
+	handlers.RegisterUserRoutes(r, handlers.NewUserHandler(userService))
+	handlers.RegisterTweetRoutes(r, handlers.NewTweetHandler(tweetService, timelineService))
+	handlers.RegisterPollRoutes(r, handlers.NewPollHandler(tweetService, userService))
+
+	return &MemoryAPI{Handler: r, UserIDs: ids, Stats: stats}, nil
+}
+
+// noMedia es el almacenamiento de adjuntos de la API en memoria, que no
+// acepta subidas: ningún adjunto pertenece a nadie
+type noMedia struct{}
+
+func (noMedia) CountOwned(ctx context.Context, ids []primitive.ObjectID, userID primitive.ObjectID) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,32 @@
+// internal/logging/mongo.go
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// CommandMonitor registra los comandos que los repositorios envían a Mongo con el
+// request_id de la petición que los originó. Los comandos exitosos van en debug y
+// los fallidos en warn; el cuerpo de los comandos nunca se registra.
+func CommandMonitor(logger *slog.Logger) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			logger.LogAttrs(ctx, slog.LevelDebug, "comando mongo",
+				slog.String("command", e.CommandName),
+				slog.String("database", e.DatabaseName),
+				slog.Float64("duration_ms", float64(e.Duration.Microseconds())/1000),
+			)
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			logger.LogAttrs(ctx, slog.LevelWarn, "comando mongo fallido",
+				slog.String("command", e.CommandName),
+				slog.String("database", e.DatabaseName),
+				slog.Float64("duration_ms", float64(e.Duration.Microseconds())/1000),
+				slog.String("error", e.Failure),
+			)
+		},
+	}
+}
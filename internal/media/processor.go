@@ -0,0 +1,227 @@
+// internal/media/processor.go
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/gabriel-vasile/mimetype"
+	"golang.org/x/image/draw"
+)
+
+const (
+	VariantOriginal  = "original"
+	VariantLarge     = "large"
+	VariantThumbnail = "thumbnail"
+
+	// Tamaño máximo (lado mayor) de cada variante redimensionada
+	largeSize     = 1280
+	thumbnailSize = 320
+
+	jpegQuality = 85
+
+	// DefaultMaxBytes es el tamaño máximo de subida por defecto
+	DefaultMaxBytes = 5 << 20
+	// maxPixels protege contra imágenes que ocupan poco pero se expanden en memoria
+	maxPixels = 40_000_000
+)
+
+var (
+	ErrTooLarge        = apperr.New(apperr.KindTooLarge, "media_too_large", "el archivo excede el tamaño máximo permitido")
+	ErrUnsupportedType = apperr.New(apperr.KindUnsupportedMedia, "media_unsupported_type", "tipo de archivo no soportado")
+	ErrInvalidImage    = apperr.InvalidField("media_invalid_image", "file", "imagen inválida o corrupta")
+)
+
+// allowedTypes son los tipos MIME aceptados, detectados por contenido y no por extensión
+var allowedTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+// Variant es una representación procesada de la imagen subida
+type Variant struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// Processor valida las imágenes subidas y genera sus variantes sin metadatos
+type Processor struct {
+	maxBytes int64
+}
+
+func NewProcessor(maxBytes int64) *Processor {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	return &Processor{maxBytes: maxBytes}
+}
+
+// MaxBytes devuelve el tamaño máximo aceptado
+func (p *Processor) MaxBytes() int64 {
+	return p.maxBytes
+}
+
+// DetectType devuelve el tipo MIME real del contenido si está permitido
+func DetectType(data []byte) (string, error) {
+	mtype := mimetype.Detect(data)
+	for _, allowed := range allowedTypes {
+		if mtype.Is(allowed) {
+			return allowed, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedType, mtype.String())
+}
+
+// Process valida la imagen y genera las variantes original, large y thumbnail.
+// Todas las imágenes se vuelven a codificar, lo que descarta EXIF, comentarios y
+// demás metadatos; antes se aplica la orientación EXIF para no perder la rotación.
+func (p *Processor) Process(data []byte) ([]Variant, error) {
+	if int64(len(data)) > p.maxBytes {
+		return nil, ErrTooLarge
+	}
+
+	contentType, err := DetectType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("%w: dimensiones %dx%d", ErrInvalidImage, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	var original Variant
+	switch contentType {
+	case "image/jpeg":
+		img = applyOrientation(img, jpegOrientation(data))
+		original, err = encode(VariantOriginal, img, contentType)
+		if err != nil {
+			return nil, err
+		}
+	case "image/png":
+		original, err = encode(VariantOriginal, img, contentType)
+		if err != nil {
+			return nil, err
+		}
+	case "image/gif":
+		original, err = encodeGIF(data, cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Las variantes redimensionadas de PNG y GIF se guardan como PNG para conservar transparencia
+	resizedType := contentType
+	if contentType == "image/gif" {
+		resizedType = "image/png"
+	}
+
+	variants := []Variant{original}
+	for _, v := range []struct {
+		name string
+		size int
+	}{{VariantLarge, largeSize}, {VariantThumbnail, thumbnailSize}} {
+		resized, err := encode(v.name, fit(img, v.size), resizedType)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, resized)
+	}
+
+	return variants, nil
+}
+
+// encodeGIF vuelve a codificar todos los cuadros del GIF. Conserva la animación,
+// los tiempos y la repetición, y descarta los comentarios y las extensiones de
+// aplicación, que pueden llevar datos arbitrarios.
+func encodeGIF(data []byte, cfg image.Config) (Variant, error) {
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return Variant{}, ErrInvalidImage
+	}
+	// maxPixels cuenta todos los cuadros, que se decodifican a la vez
+	if len(g.Image)*cfg.Width*cfg.Height > maxPixels {
+		return Variant{}, fmt.Errorf("%w: %d cuadros de %dx%d", ErrInvalidImage, len(g.Image), cfg.Width, cfg.Height)
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		return Variant{}, fmt.Errorf("error al codificar imagen: %v", err)
+	}
+	return Variant{
+		Name:        VariantOriginal,
+		Data:        buf.Bytes(),
+		ContentType: "image/gif",
+		Extension:   ".gif",
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}, nil
+}
+
+// fit reduce la imagen para que su lado mayor no supere size, manteniendo la proporción
+func fit(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+
+	if w >= h {
+		h = max(1, h*size/w)
+		w = size
+	} else {
+		w = max(1, w*size/h)
+		h = size
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Over, nil)
+	return dst
+}
+
+func encode(name string, img image.Image, contentType string) (Variant, error) {
+	var (
+		buf bytes.Buffer
+		err error
+		ext string
+	)
+	switch contentType {
+	case "image/jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+		ext = ".jpg"
+	case "image/png":
+		err = png.Encode(&buf, img)
+		ext = ".png"
+	case "image/gif":
+		err = gif.Encode(&buf, img, nil)
+		ext = ".gif"
+	default:
+		return Variant{}, ErrUnsupportedType
+	}
+	if err != nil {
+		return Variant{}, fmt.Errorf("error al codificar imagen: %v", err)
+	}
+
+	return Variant{
+		Name:        name,
+		Data:        buf.Bytes(),
+		ContentType: contentType,
+		Extension:   ext,
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+	}, nil
+}
@@ -0,0 +1,188 @@
+// internal/media/processor_test.go
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+// withEXIF inserta un segmento APP1 con la etiqueta Orientation y un GPS ficticio
+func withEXIF(jpg []byte, orientation uint16) []byte {
+	tiff := new(bytes.Buffer)
+	tiff.WriteString("MM")
+	binary.Write(tiff, binary.BigEndian, uint16(42))
+	binary.Write(tiff, binary.BigEndian, uint32(8))
+	binary.Write(tiff, binary.BigEndian, uint16(1))
+	binary.Write(tiff, binary.BigEndian, uint16(exifOrientationTag))
+	binary.Write(tiff, binary.BigEndian, uint16(3)) // SHORT
+	binary.Write(tiff, binary.BigEndian, uint32(1))
+	binary.Write(tiff, binary.BigEndian, orientation)
+	binary.Write(tiff, binary.BigEndian, uint16(0))
+	binary.Write(tiff, binary.BigEndian, uint32(0))
+	tiff.WriteString("GPS-SECRET-LOCATION")
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, segment...)
+	return append(out, jpg[2:]...)
+}
+
+func variantByName(variants []Variant, name string) Variant {
+	for _, v := range variants {
+		if v.Name == name {
+			return v
+		}
+	}
+	return Variant{}
+}
+
+func TestProcessor_JPEG(t *testing.T) {
+	p := NewProcessor(0)
+	data := withEXIF(encodeJPEG(t, testImage(2000, 1000)), 6)
+	require.Equal(t, 6, jpegOrientation(data))
+
+	variants, err := p.Process(data)
+	require.NoError(t, err)
+	require.Len(t, variants, 3)
+
+	original := variantByName(variants, VariantOriginal)
+	assert.Equal(t, "image/jpeg", original.ContentType)
+	// Orientación 6 rota 90°: las dimensiones se intercambian
+	assert.Equal(t, 1000, original.Width)
+	assert.Equal(t, 2000, original.Height)
+
+	for _, v := range variants {
+		assert.NotContains(t, string(v.Data), "Exif", "la variante %s conserva EXIF", v.Name)
+		assert.NotContains(t, string(v.Data), "GPS-SECRET-LOCATION")
+		assert.Equal(t, 1, jpegOrientation(v.Data))
+	}
+
+	large := variantByName(variants, VariantLarge)
+	assert.Equal(t, 640, large.Width)
+	assert.Equal(t, largeSize, large.Height)
+
+	thumb := variantByName(variants, VariantThumbnail)
+	assert.Equal(t, thumbnailSize, thumb.Height)
+	assert.Equal(t, ".jpg", thumb.Extension)
+}
+
+func TestProcessor_PNGKeepsSmallSize(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(100, 50)))
+
+	variants, err := NewProcessor(0).Process(buf.Bytes())
+	require.NoError(t, err)
+
+	for _, v := range variants {
+		assert.Equal(t, "image/png", v.ContentType)
+		assert.Equal(t, 100, v.Width, "no se amplían imágenes pequeñas")
+	}
+}
+
+// withGIFComment inserta una extensión de comentario antes del primer cuadro
+func withGIFComment(data []byte, comment string) []byte {
+	offset := 13 // cabecera y descriptor de pantalla
+	if flags := data[10]; flags&0x80 != 0 {
+		offset += 3 << (flags&0x07 + 1) // tabla de colores global
+	}
+	block := []byte{0x21, 0xFE, byte(len(comment))}
+	block = append(block, comment...)
+	block = append(block, 0x00)
+
+	out := append([]byte{}, data[:offset]...)
+	out = append(out, block...)
+	return append(out, data[offset:]...)
+}
+
+func TestProcessor_GIF(t *testing.T) {
+	anim := &gif.GIF{LoopCount: 0}
+	for i := range 3 {
+		frame := image.NewPaletted(image.Rect(0, 0, 40, 20), palette.Plan9)
+		frame.SetColorIndex(i, i, uint8(i+1))
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 10*(i+1))
+	}
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, anim))
+	data := withGIFComment(buf.Bytes(), "GPS-SECRET-LOCATION")
+	require.Contains(t, string(data), "GPS-SECRET-LOCATION")
+
+	variants, err := NewProcessor(0).Process(data)
+	require.NoError(t, err)
+
+	original := variantByName(variants, VariantOriginal)
+	assert.Equal(t, "image/gif", original.ContentType)
+	assert.Equal(t, 40, original.Width)
+	assert.NotContains(t, string(original.Data), "GPS-SECRET-LOCATION", "el comentario se descarta")
+
+	decoded, err := gif.DecodeAll(bytes.NewReader(original.Data))
+	require.NoError(t, err)
+	assert.Len(t, decoded.Image, 3, "se conserva la animación")
+	assert.Equal(t, []int{10, 20, 30}, decoded.Delay)
+
+	assert.Equal(t, "image/png", variantByName(variants, VariantThumbnail).ContentType)
+}
+
+func TestProcessor_Rejections(t *testing.T) {
+	t.Run("too large", func(t *testing.T) {
+		data := encodeJPEG(t, testImage(50, 50))
+		_, err := NewProcessor(int64(len(data) - 1)).Process(data)
+		assert.ErrorIs(t, err, ErrTooLarge)
+	})
+
+	t.Run("unsupported type detected by content", func(t *testing.T) {
+		_, err := NewProcessor(0).Process([]byte("%PDF-1.4 fake document"))
+		assert.ErrorIs(t, err, ErrUnsupportedType)
+	})
+
+	t.Run("corrupt image", func(t *testing.T) {
+		data := encodeJPEG(t, testImage(50, 50))
+		_, err := NewProcessor(0).Process(data[:len(data)/3])
+		assert.ErrorIs(t, err, ErrInvalidImage)
+	})
+}
+
+func TestApplyOrientation(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	red := color.RGBA{255, 0, 0, 255}
+	src.Set(0, 0, red)
+
+	// Rotación 90° horaria: el píxel superior izquierdo pasa a la esquina superior derecha
+	rotated := applyOrientation(src, 6)
+	assert.Equal(t, image.Rect(0, 0, 1, 2), rotated.Bounds())
+	assert.Equal(t, red, rotated.At(0, 0))
+
+	// Rotación 180°
+	flipped := applyOrientation(src, 3)
+	assert.Equal(t, red, flipped.At(1, 0))
+}
@@ -0,0 +1,28 @@
+// internal/memstore/memstore.go
+package memstore
+
+import (
+	"bytes"
+
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Implementaciones en memoria del acceso a usuarios, tweets y votos, con la
+// misma semántica que los repositorios de MongoDB. Sirven para trabajar con un
+// conjunto de datos sin escribir en la base de datos y para levantar la API en
+// las pruebas de carga; no persisten nada.
+
+// parseID convierte un ID hexadecimal; los IDs mal formados son
+// repository.ErrInvalidID, como en los repositorios
+func parseID(id string) (primitive.ObjectID, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, repository.ErrInvalidID.Wrap(err)
+	}
+	return oid, nil
+}
+
+func compareIDs(a, b primitive.ObjectID) int {
+	return bytes.Compare(a[:], b[:])
+}
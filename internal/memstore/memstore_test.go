@@ -0,0 +1,176 @@
+// internal/memstore/memstore_test.go
+package memstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUsers(t *testing.T) {
+	ctx := context.Background()
+	users := NewUsers()
+	ana := &models.User{Username: "ana", Email: "ana@example.com"}
+	beto := &models.User{Username: "beto", Email: "beto@example.com"}
+	require.NoError(t, users.Create(ctx, ana))
+	require.NoError(t, users.Create(ctx, beto))
+
+	t.Run("unique username and email", func(t *testing.T) {
+		err := users.Create(ctx, &models.User{Username: "ana", Email: "otra@example.com"})
+		assert.ErrorIs(t, err, repository.ErrUserExists)
+		err = users.Create(ctx, &models.User{Username: "otra", Email: "ana@example.com"})
+		assert.ErrorIs(t, err, repository.ErrUserExists)
+		_, err = users.GetByID(ctx, "no-es-un-id")
+		assert.ErrorIs(t, err, repository.ErrInvalidID)
+	})
+
+	t.Run("follow is idempotent", func(t *testing.T) {
+		require.NoError(t, users.FollowUser(ctx, ana.ID.Hex(), beto.ID.Hex()))
+		require.NoError(t, users.FollowUser(ctx, ana.ID.Hex(), beto.ID.Hex()))
+		got, err := users.GetByID(ctx, beto.ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, 1, got.FollowersCount)
+
+		followers, err := users.GetFollowers(ctx, beto.ID.Hex())
+		require.NoError(t, err)
+		require.Len(t, followers, 1)
+		assert.Equal(t, "ana", followers[0].Username)
+
+		require.NoError(t, users.UnfollowUser(ctx, ana.ID.Hex(), beto.ID.Hex()))
+		require.NoError(t, users.UnfollowUser(ctx, ana.ID.Hex(), beto.ID.Hex()))
+		got, err = users.GetByID(ctx, beto.ID.Hex())
+		require.NoError(t, err)
+		assert.Zero(t, got.FollowersCount)
+	})
+
+	t.Run("returned users are copies", func(t *testing.T) {
+		got, err := users.GetByID(ctx, ana.ID.Hex())
+		require.NoError(t, err)
+		got.Following = append(got.Following, "cambiado")
+		again, err := users.GetByID(ctx, ana.ID.Hex())
+		require.NoError(t, err)
+		assert.NotContains(t, again.Following, "cambiado")
+	})
+}
+
+func TestTweets_GetByAuthors(t *testing.T) {
+	ctx := context.Background()
+	tweets := NewTweets()
+	ana, beto, otro := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	hidden := base
+
+	var batch []models.Tweet
+	for i, author := range []primitive.ObjectID{ana, beto, ana, otro, beto, ana} {
+		batch = append(batch, models.Tweet{ID: primitive.NewObjectID(), UserID: author, Content: "hola", CreatedAt: base.Add(time.Duration(i) * time.Minute)})
+	}
+	batch[2].HiddenAt = &hidden
+	require.NoError(t, tweets.ImportMany(ctx, batch))
+
+	page, err := tweets.GetByAuthors(ctx, []primitive.ObjectID{ana, beto, ana}, 0, 3)
+	require.NoError(t, err)
+	require.Len(t, page, 3)
+	assert.Equal(t, []primitive.ObjectID{batch[5].ID, batch[4].ID, batch[1].ID}, []primitive.ObjectID{page[0].ID, page[1].ID, page[2].ID},
+		"del más reciente al más antiguo, sin ocultos ni otros autores")
+
+	page, err = tweets.GetByAuthors(ctx, []primitive.ObjectID{ana, beto}, 3, 3)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, batch[0].ID, page[0].ID)
+
+	page, err = tweets.GetByAuthors(ctx, []primitive.ObjectID{ana, beto}, 10, 3)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+
+	_, err = tweets.GetByID(ctx, batch[2].ID.Hex())
+	assert.ErrorIs(t, err, repository.ErrTweetNotFound)
+}
+
+func TestTweets_GetByAuthorsAfter(t *testing.T) {
+	ctx := context.Background()
+	tweets := NewTweets()
+	ana, beto := primitive.NewObjectID(), primitive.NewObjectID()
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// ana y beto publican a la vez: el ID desempata
+	var batch []models.Tweet
+	for i, author := range []primitive.ObjectID{ana, beto, ana, beto} {
+		batch = append(batch, models.Tweet{ID: primitive.NewObjectID(), UserID: author, Content: "hola", CreatedAt: base.Add(time.Duration(i/2) * time.Minute)})
+	}
+	require.NoError(t, tweets.ImportMany(ctx, batch))
+
+	var seen []primitive.ObjectID
+	var after *models.TweetPosition
+	for {
+		page, err := tweets.GetByAuthorsAfter(ctx, []primitive.ObjectID{ana, beto}, after, 3)
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		for _, tweet := range page {
+			seen = append(seen, tweet.ID)
+		}
+		position := page[len(page)-1].Position()
+		after = &position
+	}
+	require.Len(t, seen, 4, "sin repetidos ni saltos")
+	assert.ElementsMatch(t, []primitive.ObjectID{batch[2].ID, batch[3].ID}, seen[:2])
+	assert.ElementsMatch(t, []primitive.ObjectID{batch[0].ID, batch[1].ID}, seen[2:])
+
+	// Desde una posición se leen los posteriores, del más antiguo al más reciente
+	since, err := tweets.GetByAuthorsSince(ctx, []primitive.ObjectID{ana, beto}, batch[0].Position(), 2)
+	require.NoError(t, err)
+	require.Len(t, since, 2)
+	assert.Equal(t, batch[1].ID, since[0].ID)
+	assert.Contains(t, []primitive.ObjectID{batch[2].ID, batch[3].ID}, since[1].ID)
+
+	found, err := tweets.GetByIDs(ctx, []primitive.ObjectID{batch[1].ID, primitive.NewObjectID()})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, batch[1].ID, found[0].ID)
+}
+
+func TestPolls(t *testing.T) {
+	ctx := context.Background()
+	tweets := NewTweets()
+	polls := NewPolls(tweets)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tweet := models.Tweet{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Content: "¿Sí o no?", CreatedAt: now,
+		Poll: &models.Poll{Options: []models.PollOption{{Text: "sí"}, {Text: "no"}}, ExpiresAt: now.Add(time.Hour)}}
+	require.NoError(t, tweets.ImportMany(ctx, []models.Tweet{tweet}))
+	before, err := tweets.GetByID(ctx, tweet.ID.Hex())
+	require.NoError(t, err)
+
+	voter := primitive.NewObjectID()
+	vote := &models.PollVote{TweetID: tweet.ID, UserID: voter, Option: 1}
+	require.NoError(t, polls.InsertVote(ctx, vote))
+	assert.ErrorIs(t, polls.InsertVote(ctx, &models.PollVote{TweetID: tweet.ID, UserID: voter}), repository.ErrAlreadyVoted)
+
+	counted, err := polls.CountVote(ctx, tweet.ID, 1, now)
+	require.NoError(t, err)
+	assert.True(t, counted)
+	counted, err = polls.CountVote(ctx, tweet.ID, 1, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, counted, "la encuesta ya venció")
+
+	after, err := tweets.GetByID(ctx, tweet.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, 1, *after.Poll.Options[1].Votes)
+	assert.Equal(t, 1, *after.Poll.TotalVotes)
+	assert.Nil(t, before.Poll.Options[1].Votes, "las copias anteriores no cambian")
+
+	votes, err := polls.VotesByUser(ctx, voter, []primitive.ObjectID{tweet.ID, primitive.NewObjectID()})
+	require.NoError(t, err)
+	assert.Equal(t, map[primitive.ObjectID]int{tweet.ID: 1}, votes)
+
+	require.NoError(t, polls.DeleteVote(ctx, vote.ID))
+	votes, err = polls.VotesByUser(ctx, voter, []primitive.ObjectID{tweet.ID})
+	require.NoError(t, err)
+	assert.Empty(t, votes)
+}
@@ -0,0 +1,100 @@
+// internal/memstore/polls.go
+package memstore
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// voteKey identifica el voto de un usuario en una encuesta
+type voteKey struct {
+	tweetID primitive.ObjectID
+	userID  primitive.ObjectID
+}
+
+// Polls guarda los votos en memoria con la misma semántica que
+// repository.PollRepository; los recuentos viven en los tweets de Tweets
+type Polls struct {
+	mu     sync.Mutex
+	tweets *Tweets
+	votes  map[voteKey]models.PollVote
+}
+
+func NewPolls(tweets *Tweets) *Polls {
+	return &Polls{tweets: tweets, votes: make(map[voteKey]models.PollVote)}
+}
+
+// InsertVote guarda un voto; un segundo voto del mismo usuario es ErrAlreadyVoted
+func (s *Polls) InsertVote(ctx context.Context, vote *models.PollVote) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := voteKey{vote.TweetID, vote.UserID}
+	if _, ok := s.votes[key]; ok {
+		return repository.ErrAlreadyVoted
+	}
+	if vote.ID.IsZero() {
+		vote.ID = primitive.NewObjectID()
+	}
+	s.votes[key] = *vote
+	return nil
+}
+
+// DeleteVote elimina un voto que no se pudo contabilizar
+func (s *Polls) DeleteVote(ctx context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, vote := range s.votes {
+		if vote.ID == id {
+			delete(s.votes, key)
+		}
+	}
+	return nil
+}
+
+// CountVote incrementa los conteos de la opción solo si la encuesta sigue abierta en now
+func (s *Polls) CountVote(ctx context.Context, tweetID primitive.ObjectID, option int, now time.Time) (bool, error) {
+	return s.tweets.countVote(tweetID, option, now), nil
+}
+
+// VotesByUser devuelve la opción votada por el usuario en cada uno de los tweets indicados
+func (s *Polls) VotesByUser(ctx context.Context, userID primitive.ObjectID, tweetIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make(map[primitive.ObjectID]int)
+	for _, tweetID := range tweetIDs {
+		if vote, ok := s.votes[voteKey{tweetID, userID}]; ok {
+			result[tweetID] = vote.Option
+		}
+	}
+	return result, nil
+}
+
+// countVote suma un voto a la opción de una encuesta abierta. Los conteos se
+// reemplazan en lugar de modificarse porque las copias entregadas comparten los
+// punteros de las opciones.
+func (s *Tweets) countVote(tweetID primitive.ObjectID, option int, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tweet := s.tweets[tweetID]
+	if tweet == nil || tweet.Poll == nil || !tweet.Poll.IsOpen(now) || option < 0 || option >= len(tweet.Poll.Options) {
+		return false
+	}
+	tweet.Poll.Options[option].Votes = increment(tweet.Poll.Options[option].Votes)
+	tweet.Poll.TotalVotes = increment(tweet.Poll.TotalVotes)
+	return true
+}
+
+func increment(n *int) *int {
+	v := 1
+	if n != nil {
+		v = *n + 1
+	}
+	return &v
+}
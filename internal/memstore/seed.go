@@ -0,0 +1,24 @@
+// internal/memstore/seed.go
+package memstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+)
+
+// SeedUsers crea n usuarios userN con email userN@example.com y devuelve sus
+// IDs en orden. Es el conjunto mínimo con el que las pruebas levantan las APIs
+// sobre memoria.
+func SeedUsers(ctx context.Context, users *Users, n int) ([]string, error) {
+	ids := make([]string, 0, n)
+	for i := range n {
+		user := &models.User{Username: fmt.Sprintf("user%d", i), Email: fmt.Sprintf("user%d@example.com", i)}
+		if err := users.Create(ctx, user); err != nil {
+			return nil, err
+		}
+		ids = append(ids, user.ID.Hex())
+	}
+	return ids, nil
+}
@@ -0,0 +1,295 @@
+// internal/memstore/tweets.go
+package memstore
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tweets guarda los tweets en memoria con la misma semántica que
+// repository.TweetRepository: los ocultos por moderación y los retenidos por la
+// política de contenido no aparecen en las lecturas de la API. Cada autor tiene
+// sus tweets ordenados por fecha para que armar un timeline no recorra todos.
+type Tweets struct {
+	mu       sync.RWMutex
+	tweets   map[primitive.ObjectID]*models.Tweet
+	byAuthor map[primitive.ObjectID][]*models.Tweet
+	now      func() time.Time
+}
+
+func NewTweets() *Tweets {
+	return &Tweets{
+		tweets:   make(map[primitive.ObjectID]*models.Tweet),
+		byAuthor: make(map[primitive.ObjectID][]*models.Tweet),
+		now:      time.Now,
+	}
+}
+
+// Create guarda un tweet ya validado. Si tweet.ID viene fijado se respeta.
+func (s *Tweets) Create(ctx context.Context, tweet *models.Tweet) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tweet.ID.IsZero() {
+		tweet.ID = primitive.NewObjectID()
+	}
+	if s.tweets[tweet.ID] != nil {
+		return repository.ErrTweetExists
+	}
+	tweet.CreatedAt = s.now()
+	s.insert(tweet)
+	return nil
+}
+
+// insert guarda una copia del tweet en su posición dentro de los del autor; hay
+// que tener el lock
+func (s *Tweets) insert(tweet *models.Tweet) {
+	stored := cloneTweet(tweet)
+	s.tweets[stored.ID] = stored
+	authored := s.byAuthor[stored.UserID]
+	i, _ := slices.BinarySearchFunc(authored, stored, compareTweets)
+	s.byAuthor[stored.UserID] = slices.Insert(authored, i, stored)
+}
+
+// GetByID obtiene un tweet; devuelve ErrTweetNotFound si no existe o está oculto
+func (s *Tweets) GetByID(ctx context.Context, id string) (*models.Tweet, error) {
+	objectID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tweet := s.tweets[objectID]
+	if tweet == nil || !isVisible(tweet) {
+		return nil, repository.ErrTweetNotFound
+	}
+	return cloneTweet(tweet), nil
+}
+
+// GetByUserID devuelve los tweets visibles del usuario, del más reciente al más antiguo
+func (s *Tweets) GetByUserID(ctx context.Context, userID string) ([]models.Tweet, error) {
+	objectID, err := parseID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.newest(s.byAuthor[objectID], -1), nil
+}
+
+// AllByUser devuelve todos los tweets del usuario, ocultos y retenidos
+// incluidos, del más antiguo al más reciente
+func (s *Tweets) AllByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Tweet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tweets := make([]models.Tweet, 0, len(s.byAuthor[userID]))
+	for _, tweet := range s.byAuthor[userID] {
+		tweets = append(tweets, *cloneTweet(tweet))
+	}
+	return tweets, nil
+}
+
+// GetByHashtag obtiene los tweets más recientes que contienen el hashtag, sin
+// los de los autores de excludeAuthors
+func (s *Tweets) GetByHashtag(ctx context.Context, tag string, excludeAuthors []primitive.ObjectID, limit int) ([]models.Tweet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var matches []*models.Tweet
+	for author, authored := range s.byAuthor {
+		if slices.Contains(excludeAuthors, author) {
+			continue
+		}
+		for _, tweet := range authored {
+			if slices.Contains(tweet.Hashtags, tag) {
+				matches = append(matches, tweet)
+			}
+		}
+	}
+	slices.SortFunc(matches, compareTweets)
+	return s.newest(matches, limit), nil
+}
+
+// GetByAuthors obtiene una página de los tweets de los autores indicados, del
+// más reciente al más antiguo
+func (s *Tweets) GetByAuthors(ctx context.Context, authorIDs []primitive.ObjectID, skip, limit int) ([]models.Tweet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Cada autor aporta como mucho sus skip+limit tweets visibles más recientes
+	var candidates []*models.Tweet
+	for _, author := range slices.Compact(slices.SortedFunc(slices.Values(authorIDs), compareIDs)) {
+		authored := s.byAuthor[author]
+		taken := 0
+		for i := len(authored) - 1; i >= 0 && taken < skip+limit; i-- {
+			if isVisible(authored[i]) {
+				candidates = append(candidates, authored[i])
+				taken++
+			}
+		}
+	}
+	slices.SortFunc(candidates, compareTweets)
+	page := s.newest(candidates, skip+limit)
+	if skip >= len(page) {
+		return []models.Tweet{}, nil
+	}
+	return page[skip:], nil
+}
+
+// GetByAuthorsAfter obtiene hasta limit tweets de los autores indicados que van
+// después de la posición after, del más reciente al más antiguo
+func (s *Tweets) GetByAuthorsAfter(ctx context.Context, authorIDs []primitive.ObjectID, after *models.TweetPosition, limit int) ([]models.Tweet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var candidates []*models.Tweet
+	for _, author := range slices.Compact(slices.SortedFunc(slices.Values(authorIDs), compareIDs)) {
+		authored := s.byAuthor[author]
+		end := len(authored)
+		if after != nil {
+			// Los tweets del autor están ordenados: los anteriores a after son un prefijo
+			end, _ = slices.BinarySearchFunc(authored, after, func(t *models.Tweet, p *models.TweetPosition) int {
+				return compareTweets(t, &models.Tweet{CreatedAt: p.CreatedAt, ID: p.ID})
+			})
+		}
+		taken := 0
+		for i := end - 1; i >= 0 && taken < limit; i-- {
+			if isVisible(authored[i]) {
+				candidates = append(candidates, authored[i])
+				taken++
+			}
+		}
+	}
+	slices.SortFunc(candidates, compareTweets)
+	return s.newest(candidates, limit), nil
+}
+
+// GetByAuthorsSince obtiene hasta limit tweets de los autores indicados que son
+// posteriores a la posición since, del más antiguo al más reciente
+func (s *Tweets) GetByAuthorsSince(ctx context.Context, authorIDs []primitive.ObjectID, since models.TweetPosition, limit int) ([]models.Tweet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var candidates []*models.Tweet
+	for _, author := range slices.Compact(slices.SortedFunc(slices.Values(authorIDs), compareIDs)) {
+		authored := s.byAuthor[author]
+		// Los posteriores a since son un sufijo de los tweets del autor
+		start, found := slices.BinarySearchFunc(authored, since, func(t *models.Tweet, p models.TweetPosition) int {
+			return t.Position().Compare(p)
+		})
+		if found {
+			start++
+		}
+		taken := 0
+		for i := start; i < len(authored) && taken < limit; i++ {
+			if isVisible(authored[i]) {
+				candidates = append(candidates, authored[i])
+				taken++
+			}
+		}
+	}
+	slices.SortFunc(candidates, compareTweets)
+	out := []models.Tweet{}
+	for _, tweet := range candidates[:min(limit, len(candidates))] {
+		out = append(out, *cloneTweet(tweet))
+	}
+	return out, nil
+}
+
+// GetByIDs obtiene los tweets visibles con los IDs indicados
+func (s *Tweets) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Tweet, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tweets := []models.Tweet{}
+	for _, id := range ids {
+		if tweet := s.tweets[id]; tweet != nil && isVisible(tweet) {
+			tweets = append(tweets, *cloneTweet(tweet))
+		}
+	}
+	return tweets, nil
+}
+
+// Walk recorre todos los tweets, ocultos y retenidos incluidos, del más antiguo
+// al más reciente
+func (s *Tweets) Walk(ctx context.Context, fn func(tweet *models.Tweet) error) error {
+	s.mu.RLock()
+	tweets := make([]*models.Tweet, 0, len(s.tweets))
+	for _, tweet := range s.tweets {
+		tweets = append(tweets, cloneTweet(tweet))
+	}
+	s.mu.RUnlock()
+
+	slices.SortFunc(tweets, compareTweets)
+	for _, tweet := range tweets {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(tweet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportMany guarda tweets conservando sus IDs y fechas; los que ya existen se
+// dan por importados
+func (s *Tweets) ImportMany(ctx context.Context, tweets []models.Tweet) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range tweets {
+		if s.tweets[tweets[i].ID] == nil {
+			s.insert(&tweets[i])
+		}
+	}
+	return nil
+}
+
+// Len devuelve cuántos tweets hay guardados, ocultos incluidos
+func (s *Tweets) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.tweets)
+}
+
+// newest copia hasta limit tweets visibles de una lista ordenada del más antiguo
+// al más reciente, empezando por el más reciente; limit < 0 no limita
+func (s *Tweets) newest(tweets []*models.Tweet, limit int) []models.Tweet {
+	out := []models.Tweet{}
+	for i := len(tweets) - 1; i >= 0 && (limit < 0 || len(out) < limit); i-- {
+		if isVisible(tweets[i]) {
+			out = append(out, *cloneTweet(tweets[i]))
+		}
+	}
+	return out
+}
+
+// isVisible aplica la regla del filtro de tweets visibles del repositorio
+func isVisible(tweet *models.Tweet) bool {
+	return tweet.HiddenAt == nil && !tweet.Held()
+}
+
+// compareTweets ordena por fecha de creación y, a igual fecha, por ID
+func compareTweets(a, b *models.Tweet) int {
+	return a.Position().Compare(b.Position())
+}
+
+// cloneTweet copia el tweet con su encuesta: los servicios completan el estado
+// de la encuesta para quien consulta sobre el tweet devuelto
+func cloneTweet(tweet *models.Tweet) *models.Tweet {
+	clone := *tweet
+	clone.Hashtags = slices.Clone(tweet.Hashtags)
+	clone.Media = slices.Clone(tweet.Media)
+	if tweet.Poll != nil {
+		poll := *tweet.Poll
+		poll.Options = slices.Clone(tweet.Poll.Options)
+		clone.Poll = &poll
+	}
+	return &clone
+}
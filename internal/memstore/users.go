@@ -0,0 +1,334 @@
+// internal/memstore/users.go
+package memstore
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Users guarda los usuarios en memoria con la misma semántica que
+// repository.UserRepository: nombres y emails únicos, follows idempotentes y
+// listados que ocultan las cuentas inactivas.
+type Users struct {
+	mu         sync.RWMutex
+	users      map[primitive.ObjectID]*models.User
+	byUsername map[string]primitive.ObjectID
+	byEmail    map[string]primitive.ObjectID
+	now        func() time.Time
+}
+
+func NewUsers() *Users {
+	return &Users{
+		users:      make(map[primitive.ObjectID]*models.User),
+		byUsername: make(map[string]primitive.ObjectID),
+		byEmail:    make(map[string]primitive.ObjectID),
+		now:        time.Now,
+	}
+}
+
+// Create guarda un usuario nuevo; si no trae ID se le asigna uno
+func (s *Users) Create(ctx context.Context, user *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	if s.users[user.ID] != nil || s.conflicts(user) {
+		return repository.ErrUserExists
+	}
+	now := s.now()
+	user.CreatedAt, user.UpdatedAt = now, now
+	user.Following = make([]string, 0)
+	user.FollowersCount = 0
+	s.insert(user)
+	return nil
+}
+
+// conflicts indica si otra cuenta ya usa el nombre o el email de user, como
+// los índices únicos de MongoDB
+func (s *Users) conflicts(user *models.User) bool {
+	if _, ok := s.byUsername[user.Username]; ok {
+		return true
+	}
+	if _, ok := s.byEmail[user.Email]; ok && user.Email != "" {
+		return true
+	}
+	return false
+}
+
+func (s *Users) insert(user *models.User) {
+	s.users[user.ID] = cloneUser(user)
+	s.byUsername[user.Username] = user.ID
+	if user.Email != "" {
+		s.byEmail[user.Email] = user.ID
+	}
+}
+
+func (s *Users) GetByID(ctx context.Context, id string) (*models.User, error) {
+	objectID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	user := s.users[objectID]
+	if user == nil {
+		return nil, repository.ErrUserNotFound
+	}
+	return cloneUser(user), nil
+}
+
+// GetByIDs obtiene los usuarios con los IDs indicados; los que no existen no aparecen
+func (s *Users) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	users := []models.User{}
+	for _, id := range ids {
+		if user := s.users[id]; user != nil {
+			users = append(users, *cloneUser(user))
+		}
+	}
+	return users, nil
+}
+
+func (s *Users) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, ok := s.byUsername[username]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	return cloneUser(s.users[id]), nil
+}
+
+// FollowUser agrega targetID a los seguidos de userID; solo cuenta el seguidor
+// si la relación no existía
+func (s *Users) FollowUser(ctx context.Context, userID, targetID string) error {
+	userObjID, err := parseID(userID)
+	if err != nil {
+		return err
+	}
+	targetObjID, err := parseID(targetID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := s.users[userObjID]
+	if user == nil || slices.Contains(user.Following, targetID) {
+		return nil
+	}
+	user.Following = append(user.Following, targetID)
+	if target := s.users[targetObjID]; target != nil {
+		target.FollowersCount++
+	}
+	return nil
+}
+
+// UnfollowUser quita targetID de los seguidos de userID; solo descuenta el
+// seguidor si la relación existía
+func (s *Users) UnfollowUser(ctx context.Context, userID, targetID string) error {
+	userObjID, err := parseID(userID)
+	if err != nil {
+		return err
+	}
+	targetObjID, err := parseID(targetID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := s.users[userObjID]
+	if user == nil {
+		return nil
+	}
+	i := slices.Index(user.Following, targetID)
+	if i < 0 {
+		return nil
+	}
+	user.Following = slices.Delete(user.Following, i, i+1)
+	if target := s.users[targetObjID]; target != nil && target.FollowersCount > 0 {
+		target.FollowersCount--
+	}
+	return nil
+}
+
+// GetFollowing devuelve las cuentas activas que sigue userID
+func (s *Users) GetFollowing(ctx context.Context, userID string) ([]models.User, error) {
+	objectID, err := parseID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	user := s.users[objectID]
+	if user == nil {
+		return nil, repository.ErrUserNotFound
+	}
+	following := []models.User{}
+	for _, id := range user.Following {
+		targetID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		if target := s.users[targetID]; target != nil && isActive(target) {
+			following = append(following, *cloneUser(target))
+		}
+	}
+	return following, nil
+}
+
+// GetFollowers devuelve las cuentas activas que siguen a userID, por orden de ID
+func (s *Users) GetFollowers(ctx context.Context, userID string) ([]models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var followers []models.User
+	for _, user := range s.sorted() {
+		if isActive(user) && slices.Contains(user.Following, userID) {
+			followers = append(followers, *cloneUser(user))
+		}
+	}
+	return followers, nil
+}
+
+// InactiveIDs devuelve los IDs de las cuentas suspendidas, desactivadas o
+// pendientes de borrado
+func (s *Users) InactiveIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ids []primitive.ObjectID
+	for _, user := range s.sorted() {
+		if !isActive(user) {
+			ids = append(ids, user.ID)
+		}
+	}
+	return ids, nil
+}
+
+// Walk recorre todos los usuarios en orden de ID
+func (s *Users) Walk(ctx context.Context, fn func(user *models.User) error) error {
+	s.mu.RLock()
+	users := s.sorted()
+	for i, user := range users {
+		users[i] = cloneUser(user)
+	}
+	s.mu.RUnlock()
+
+	for _, user := range users {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportMany guarda usuarios conservando sus IDs y fechas. Los que ya existen con
+// el mismo ID se dan por importados; devuelve las posiciones de los que chocan
+// con el nombre o el email de otra cuenta.
+func (s *Users) ImportMany(ctx context.Context, users []models.User) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var conflicts []int
+	for i := range users {
+		user := &users[i]
+		if s.users[user.ID] != nil {
+			continue
+		}
+		if s.conflicts(user) {
+			conflicts = append(conflicts, i)
+			continue
+		}
+		if user.Following == nil {
+			user.Following = make([]string, 0)
+		}
+		s.insert(user)
+	}
+	return conflicts, nil
+}
+
+// AddFollowing agrega targetIDs a los seguidos de id sin tocar los contadores;
+// se recalculan con RecountFollowers
+func (s *Users) AddFollowing(ctx context.Context, id primitive.ObjectID, targetIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := s.users[id]
+	if user == nil {
+		return repository.ErrUserNotFound
+	}
+	for _, targetID := range targetIDs {
+		if !slices.Contains(user.Following, targetID) {
+			user.Following = append(user.Following, targetID)
+		}
+	}
+	return nil
+}
+
+// RecountFollowers recalcula followers_count de las cuentas indicadas
+func (s *Users) RecountFollowers(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[string]int)
+	for _, user := range s.users {
+		for _, id := range user.Following {
+			counts[id]++
+		}
+	}
+	for _, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		if user := s.users[objectID]; user != nil {
+			user.FollowersCount = counts[id]
+		}
+	}
+	return nil
+}
+
+// Len devuelve cuántos usuarios hay guardados
+func (s *Users) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.users)
+}
+
+// sorted devuelve los usuarios por orden de ID; hay que tener el lock
+func (s *Users) sorted() []*models.User {
+	users := make([]*models.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	slices.SortFunc(users, func(a, b *models.User) int {
+		return compareIDs(a.ID, b.ID)
+	})
+	return users
+}
+
+// isActive aplica la regla del filtro de cuentas activas del repositorio: una
+// suspensión cuenta hasta que se levanta, aunque haya vencido
+func isActive(user *models.User) bool {
+	return user.Suspension == nil && !user.IsDeactivated() && !user.IsPendingDeletion()
+}
+
+func cloneUser(user *models.User) *models.User {
+	clone := *user
+	clone.Following = slices.Clone(user.Following)
+	clone.Roles = slices.Clone(user.Roles)
+	clone.Permissions = slices.Clone(user.Permissions)
+	clone.MutedWords = slices.Clone(user.MutedWords)
+	return &clone
+}
@@ -0,0 +1,170 @@
+// internal/metrics/metrics.go
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace antecede a todas las métricas propias de la aplicación
+const namespace = "microblog"
+
+// UnmatchedRoute es la etiqueta de las peticiones que no coinciden con ninguna
+// ruta; usar la URL cruda haría crecer la cardinalidad sin límite
+const UnmatchedRoute = "unmatched"
+
+// Metrics agrupa las métricas de la API en un registro propio
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpDuration *prometheus.HistogramVec
+	httpInFlight prometheus.Gauge
+
+	mongoDuration *prometheus.HistogramVec
+	mongoFailures *prometheus.CounterVec
+
+	tweetsCreated    *prometheus.CounterVec
+	follows          *prometheus.CounterVec
+	timelineRequests *prometheus.CounterVec
+}
+
+// New crea las métricas y las registra junto con las del runtime de Go y del proceso
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duración de las peticiones HTTP por ruta, método y estado.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		httpInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "Peticiones HTTP en curso.",
+		}),
+		mongoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "mongo",
+			Name:      "command_duration_seconds",
+			Help:      "Duración de los comandos enviados a MongoDB.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"command"}),
+		mongoFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "mongo",
+			Name:      "command_failures_total",
+			Help:      "Comandos de MongoDB que fallaron.",
+		}, []string{"command"}),
+		tweetsCreated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tweets_created_total",
+			Help:      "Tweets publicados, por tipo de contenido.",
+		}, []string{"kind"}),
+		follows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "follows_total",
+			Help:      "Follows y unfollows completados.",
+		}, []string{"action"}),
+		timelineRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "timeline_requests_total",
+			Help:      "Consultas del timeline por profundidad de página.",
+		}, []string{"page"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration, m.httpInFlight,
+		m.mongoDuration, m.mongoFailures,
+		m.tweetsCreated, m.follows, m.timelineRequests,
+	)
+	return m
+}
+
+// Registry devuelve el registro, para agregar métricas de otros componentes
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler expone las métricas en el formato de texto de Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RequestStarted cuenta una petición en curso; la devolución la da por terminada
+func (m *Metrics) RequestStarted() func() {
+	m.httpInFlight.Inc()
+	return m.httpInFlight.Dec
+}
+
+// ObserveRequest registra una petición terminada. route debe ser la plantilla de
+// la ruta (/api/v1/users/:id), nunca la URL con los parámetros.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = UnmatchedRoute
+	}
+	m.httpDuration.WithLabelValues(httpMethod(method), route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// TweetCreated implementa service.Metrics
+func (m *Metrics) TweetCreated(tweet *models.Tweet) {
+	kind := "text"
+	switch {
+	case tweet.Poll != nil:
+		kind = "poll"
+	case len(tweet.Media) > 0:
+		kind = "media"
+	}
+	m.tweetsCreated.WithLabelValues(kind).Inc()
+}
+
+// FollowChanged implementa service.Metrics
+func (m *Metrics) FollowChanged(follow bool) {
+	action := "unfollow"
+	if follow {
+		action = "follow"
+	}
+	m.follows.WithLabelValues(action).Inc()
+}
+
+// TimelineServed implementa service.Metrics. Las páginas se agrupan en rangos
+// para que la etiqueta tenga pocos valores posibles.
+func (m *Metrics) TimelineServed(page int) {
+	m.timelineRequests.WithLabelValues(pageBucket(page)).Inc()
+}
+
+func pageBucket(page int) string {
+	switch {
+	case page <= 1:
+		return "1"
+	case page == 2:
+		return "2"
+	case page <= 5:
+		return "3-5"
+	case page <= 10:
+		return "6-10"
+	default:
+		return "11+"
+	}
+}
+
+// httpMethod limita la etiqueta a los métodos estándar
+func httpMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	default:
+		return "OTHER"
+	}
+}
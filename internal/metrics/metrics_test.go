@@ -0,0 +1,103 @@
+// internal/metrics/metrics_test.go
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestMetrics_ObserveRequest(t *testing.T) {
+	m := New()
+
+	m.ObserveRequest(http.MethodGet, "/api/v1/users/:id", http.StatusOK, 10*time.Millisecond)
+	m.ObserveRequest(http.MethodGet, "/api/v1/users/:id", http.StatusOK, 20*time.Millisecond)
+	m.ObserveRequest("PROPFIND", "", http.StatusNotFound, time.Millisecond)
+
+	// Una serie por combinación de etiquetas; método y ruta desconocidos se agrupan
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpDuration))
+	err := testutil.GatherAndCompare(m.registry, strings.NewReader(`
+# HELP microblog_http_requests_in_flight Peticiones HTTP en curso.
+# TYPE microblog_http_requests_in_flight gauge
+microblog_http_requests_in_flight 0
+`), "microblog_http_requests_in_flight")
+	assert.NoError(t, err)
+}
+
+func TestMetrics_RequestStarted(t *testing.T) {
+	m := New()
+
+	done := m.RequestStarted()
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.httpInFlight))
+	done()
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.httpInFlight))
+}
+
+func TestMetrics_DomainCounters(t *testing.T) {
+	m := New()
+
+	m.TweetCreated(&models.Tweet{})
+	m.TweetCreated(&models.Tweet{Poll: &models.Poll{}})
+	m.TweetCreated(&models.Tweet{Media: []primitive.ObjectID{primitive.NewObjectID()}})
+	m.FollowChanged(true)
+	m.FollowChanged(false)
+	for _, page := range []int{1, 1, 2, 4, 7, 50} {
+		m.TimelineServed(page)
+	}
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.tweetsCreated.WithLabelValues("text")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.tweetsCreated.WithLabelValues("poll")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.tweetsCreated.WithLabelValues("media")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.follows.WithLabelValues("follow")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.timelineRequests.WithLabelValues("1")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.timelineRequests.WithLabelValues("11+")))
+	assert.Equal(t, 5, testutil.CollectAndCount(m.timelineRequests))
+}
+
+func TestMetrics_CommandMonitor(t *testing.T) {
+	m := New()
+	monitor := m.CommandMonitor()
+	ctx := context.Background()
+
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", Duration: time.Millisecond}})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "insert", Duration: time.Millisecond}, Failure: errors.New("E11000").Error()})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "comandoRaro"}})
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.mongoFailures.WithLabelValues("insert")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.mongoFailures.WithLabelValues("other")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.mongoDuration))
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := New()
+	m.ObserveRequest(http.MethodPost, "/api/v1/tweets", http.StatusCreated, time.Millisecond)
+	m.ObserveRequest("PROPFIND", "", http.StatusNotFound, time.Millisecond)
+	m.TweetCreated(&models.Tweet{})
+
+	srv := httptest.NewServer(m.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `microblog_http_request_duration_seconds_count{method="POST",route="/api/v1/tweets",status="201"} 1`)
+	assert.Contains(t, string(body), `microblog_tweets_created_total{kind="text"} 1`)
+	assert.Contains(t, string(body), `microblog_http_request_duration_seconds_count{method="OTHER",route="unmatched",status="404"} 1`)
+	assert.Contains(t, string(body), "go_goroutines")
+}
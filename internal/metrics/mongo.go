@@ -0,0 +1,39 @@
+// internal/metrics/mongo.go
+package metrics
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// mongoCommands son los comandos que usan los repositorios y el driver; el resto
+// se agrupa como "other" para acotar la cardinalidad
+var mongoCommands = map[string]bool{
+	"find": true, "insert": true, "update": true, "delete": true,
+	"findAndModify": true, "aggregate": true, "count": true, "distinct": true,
+	"getMore": true, "killCursors": true, "createIndexes": true,
+	"ping": true, "hello": true, "isMaster": true, "endSessions": true,
+	"listDatabases": true, "listCollections": true,
+}
+
+// CommandMonitor mide la latencia y los fallos de los comandos enviados a MongoDB
+func (m *Metrics) CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			m.mongoDuration.WithLabelValues(commandLabel(e.CommandName)).Observe(e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			command := commandLabel(e.CommandName)
+			m.mongoDuration.WithLabelValues(command).Observe(e.Duration.Seconds())
+			m.mongoFailures.WithLabelValues(command).Inc()
+		},
+	}
+}
+
+func commandLabel(name string) string {
+	if mongoCommands[name] {
+		return name
+	}
+	return "other"
+}
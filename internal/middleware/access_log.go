@@ -0,0 +1,73 @@
+// internal/middleware/access_log.go
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userIDKey es la clave del contexto de Gin con el usuario que hace la petición
+const userIDKey = "user_id"
+
+// SetUserID registra el usuario que hace la petición para el log de acceso. Lo
+// hace Identity con X-User-ID y los handlers que reciben el autor en el cuerpo.
+func SetUserID(c *gin.Context, id string) {
+	c.Set(userIDKey, id)
+}
+
+// AccessLog registra cada petición con su estado, latencia y usuario. El usuario
+// de la ruta (/users/:id) es el consultado y va aparte, en target_user_id. Los
+// 5xx van en error, los 4xx en warn y el resto en info.
+func AccessLog(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if userID := c.GetString(userIDKey); userID != "" {
+			attrs = append(attrs, slog.String("user_id", userID))
+		}
+		if targetID := targetUserID(c); targetID != "" {
+			attrs = append(attrs, slog.String("target_user_id", targetID))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.Last().Error()))
+		}
+
+		logger.LogAttrs(c.Request.Context(), level, "petición HTTP", attrs...)
+	}
+}
+
+// targetUserID devuelve el usuario de la ruta de las rutas /users/:id
+func targetUserID(c *gin.Context) string {
+	if strings.HasPrefix(c.FullPath(), "/api/v1/users/:id") {
+		return c.Param("id")
+	}
+	return ""
+}
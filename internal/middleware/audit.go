@@ -0,0 +1,24 @@
+// internal/middleware/audit.go
+package middleware
+
+import (
+	"github.com/ffelixf/microblog-platform/internal/audit"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditSource guarda en el contexto de la petición el origen que la auditoría
+// registra con cada acción: el usuario identificado, la IP y el user agent. Va
+// después de Identity.
+func AuditSource() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		source := audit.Source{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		if p := Principal(c); p != nil {
+			source.ActorID = p.UserID
+		}
+		c.Request = c.Request.WithContext(audit.WithSource(c.Request.Context(), source))
+		c.Next()
+	}
+}
@@ -0,0 +1,37 @@
+// internal/middleware/audit_test.go
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ffelixf/microblog-platform/internal/audit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditSource(t *testing.T) {
+	r := newIdentityRouter()
+	var got audit.Source
+	r.GET("/audited", AuditSource(), func(c *gin.Context) {
+		got = audit.SourceFrom(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/audited", nil)
+	req.Header.Set(UserIDHeader, "mod")
+	req.Header.Set("User-Agent", "curl/8")
+	req.RemoteAddr = "10.0.0.7:4321"
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, audit.Source{ActorID: "mod", IP: "10.0.0.7", UserAgent: "curl/8"}, got)
+
+	t.Run("anonymous", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/audited", nil)
+		req.RemoteAddr = "10.0.0.8:4321"
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Empty(t, got.ActorID)
+		assert.Equal(t, "10.0.0.8", got.IP)
+	})
+}
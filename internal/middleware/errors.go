@@ -0,0 +1,122 @@
+// internal/middleware/errors.go
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/i18n"
+	"github.com/ffelixf/microblog-platform/internal/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType es el tipo de las respuestas de error (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix antecede al código del error en el campo type del problema
+const problemTypePrefix = "urn:microblog:problem:"
+
+// Problem es el cuerpo de una respuesta de error según RFC 7807, con el código
+// estable del error, el detalle de los campos inválidos y el ID de la traza como extensiones
+type Problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Code     string              `json:"code"`
+	Errors   []apperr.FieldError `json:"errors,omitempty"`
+	TraceID  string              `json:"trace_id,omitempty"`
+}
+
+// internalError es lo único que ve el cliente de un error sin tipo
+var internalError = apperr.New(apperr.KindInternal, "internal_error", "error interno del servidor")
+
+// StatusFor devuelve el estado HTTP que corresponde a cada tipo de error
+func StatusFor(kind apperr.Kind) int {
+	switch kind {
+	case apperr.KindValidation:
+		return http.StatusBadRequest
+	case apperr.KindUnauthorized:
+		return http.StatusUnauthorized
+	case apperr.KindForbidden:
+		return http.StatusForbidden
+	case apperr.KindNotFound:
+		return http.StatusNotFound
+	case apperr.KindConflict:
+		return http.StatusConflict
+	case apperr.KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case apperr.KindUnsupportedMedia:
+		return http.StatusUnsupportedMediaType
+	case apperr.KindUnavailable:
+		return http.StatusServiceUnavailable
+	case apperr.KindTooManyRequests:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// NewProblem arma el problema para err. Los errores sin tipo se reducen a un
+// error interno genérico: su texto puede contener detalles de la base de datos.
+// Con loc el detalle y los campos inválidos se traducen al idioma de la petición.
+func NewProblem(err error, instance string, loc *i18n.Localizer) *Problem {
+	e, ok := apperr.As(err)
+	if !ok || e.Kind == apperr.KindInternal {
+		e = internalError
+	}
+	if loc != nil {
+		e = loc.Localize(e)
+	}
+
+	status := StatusFor(e.Kind)
+	return &Problem{
+		Type:     problemTypePrefix + e.Code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   e.Message,
+		Instance: instance,
+		Code:     e.Code,
+		Errors:   e.Fields,
+	}
+}
+
+// WriteProblem responde con el problema correspondiente a err y aborta la cadena.
+// La causa completa no se envía al cliente: queda en el log de acceso.
+func WriteProblem(c *gin.Context, err error) {
+	loc := LocalizerFrom(c)
+	problem := NewProblem(err, c.Request.URL.Path, loc)
+	problem.TraceID = tracing.TraceID(c.Request.Context())
+
+	body, marshalErr := json.Marshal(problem)
+	if marshalErr != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if loc != nil {
+		c.Header("Content-Language", loc.Language())
+		c.Header("Vary", "Accept-Language")
+	}
+	c.Abort()
+	c.Data(problem.Status, ProblemContentType, body)
+}
+
+// Errors renderiza como application/problem+json el último error que los handlers
+// registraron con c.Error, siempre que todavía no hayan escrito una respuesta
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		WriteProblem(c, c.Errors.Last().Err)
+	}
+}
+
+// NoRoute responde con un problema 404 a las rutas que no existen
+func NoRoute(c *gin.Context) {
+	c.Error(apperr.NotFound("route_not_found", "ruta no encontrada"))
+}
@@ -0,0 +1,143 @@
+// internal/middleware/errors_test.go
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/i18n"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(err error) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Errors())
+	r.NoRoute(NoRoute)
+	r.GET("/fail", func(c *gin.Context) {
+		c.Error(err)
+	})
+	r.GET("/ok", func(c *gin.Context) {
+		c.Error(errors.New("ignorado"))
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	return r
+}
+
+func doRequest(t *testing.T, r *gin.Engine, path string) (*httptest.ResponseRecorder, Problem) {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var p Problem
+	if w.Header().Get("Content-Type") == ProblemContentType {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	}
+	return w, p
+}
+
+func TestErrors_TypedError(t *testing.T) {
+	err := fmt.Errorf("buscar: %w", apperr.NotFound("user_not_found", "usuario no encontrado").Wrap(errors.New("detalle interno")))
+	w, p := doRequest(t, newTestRouter(err), "/fail")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "urn:microblog:problem:user_not_found", p.Type)
+	assert.Equal(t, "user_not_found", p.Code)
+	assert.Equal(t, "usuario no encontrado", p.Detail)
+	assert.Equal(t, "/fail", p.Instance)
+	assert.NotContains(t, w.Body.String(), "detalle interno")
+}
+
+func TestErrors_ValidationFields(t *testing.T) {
+	err := apperr.InvalidField("invalid_id", "id", "ID inválido")
+	w, p := doRequest(t, newTestRouter(err), "/fail")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	require.Len(t, p.Errors, 1)
+	assert.Equal(t, "id", p.Errors[0].Field)
+	assert.Equal(t, "invalid_id", p.Errors[0].Code)
+}
+
+func TestErrors_UntypedErrorIsNotLeaked(t *testing.T) {
+	err := errors.New("connection refused mongodb://admin:secret@db:27017")
+	w, p := doRequest(t, newTestRouter(err), "/fail")
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "internal_error", p.Code)
+	assert.Equal(t, "error interno del servidor", p.Detail)
+	assert.NotContains(t, w.Body.String(), "secret")
+}
+
+func TestErrors_Statuses(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{apperr.Unauthorized("invalid_signature", "firma inválida"), http.StatusUnauthorized},
+		{apperr.Forbidden("forbidden", "prohibido"), http.StatusForbidden},
+		{apperr.Conflict("user_exists", "ya existe"), http.StatusConflict},
+		{apperr.New(apperr.KindTooLarge, "media_too_large", "muy grande"), http.StatusRequestEntityTooLarge},
+		{apperr.New(apperr.KindUnsupportedMedia, "media_unsupported_type", "tipo"), http.StatusUnsupportedMediaType},
+		{apperr.Unavailable("database_unavailable", "caída"), http.StatusServiceUnavailable},
+		{apperr.New(apperr.KindTooManyRequests, "rate_limited", "demasiadas peticiones"), http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		w, _ := doRequest(t, newTestRouter(tt.err), "/fail")
+		assert.Equal(t, tt.status, w.Code, tt.err.Error())
+	}
+}
+
+func TestErrors_WrittenResponseIsKept(t *testing.T) {
+	w, _ := doRequest(t, newTestRouter(nil), "/ok")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"ok":true}`, w.Body.String())
+}
+
+func TestNoRoute(t *testing.T) {
+	w, p := doRequest(t, newTestRouter(nil), "/no-existe")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "route_not_found", p.Code)
+}
+
+func TestErrors_Localized(t *testing.T) {
+	bundle, err := i18n.NewBundle(i18n.DefaultLanguage)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Locale(bundle), Errors())
+	r.GET("/fail", func(c *gin.Context) {
+		c.Error(apperr.NotFound("user_not_found", "usuario no encontrado"))
+	})
+
+	tests := []struct {
+		acceptLanguage string
+		language       string
+		detail         string
+	}{
+		{"en-US,en;q=0.9", "en", "user not found"},
+		{"pt-BR", "pt", "usuário não encontrado"},
+		{"fr", "es", "usuario no encontrado"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+		req.Header.Set("Accept-Language", tt.acceptLanguage)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var p Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		assert.Equal(t, tt.detail, p.Detail)
+		assert.Equal(t, "user_not_found", p.Code)
+		assert.Equal(t, tt.language, w.Header().Get("Content-Language"))
+	}
+}
@@ -0,0 +1,102 @@
+// internal/middleware/identity_test.go
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdentityRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	principals := map[string]*rbac.Principal{
+		"mod":  {UserID: "mod", Roles: []string{rbac.RoleModerator}},
+		"user": {UserID: "user"},
+	}
+	resolve := func(ctx context.Context, id string) (*rbac.Principal, error) {
+		if p, ok := principals[id]; ok {
+			return p, nil
+		}
+		return nil, apperr.Unauthorized("invalid_identity", "el usuario que hace la petición no existe")
+	}
+
+	r := gin.New()
+	r.Use(Errors(), Identity(resolve))
+	r.GET("/whoami", func(c *gin.Context) {
+		id := ""
+		if p := Principal(c); p != nil {
+			id = p.UserID
+		}
+		c.JSON(http.StatusOK, gin.H{"user_id": id})
+	})
+	r.GET("/reports", RequirePermission(rbac.ReportsRead), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	r.GET("/roles", RequirePermission(rbac.RolesManage), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return r
+}
+
+func identityRequest(r *gin.Engine, path, userID string) (*httptest.ResponseRecorder, Problem) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if userID != "" {
+		req.Header.Set(UserIDHeader, userID)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var p Problem
+	if w.Header().Get("Content-Type") == ProblemContentType {
+		_ = json.Unmarshal(w.Body.Bytes(), &p)
+	}
+	return w, p
+}
+
+func TestIdentity(t *testing.T) {
+	r := newIdentityRouter()
+
+	w, _ := identityRequest(r, "/whoami", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id":""}`, w.Body.String(), "sin cabecera la petición es anónima")
+
+	w, _ = identityRequest(r, "/whoami", "user")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id":"user"}`, w.Body.String())
+
+	w, p := identityRequest(r, "/whoami", "nadie")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "invalid_identity", p.Code)
+}
+
+func TestRequirePermission(t *testing.T) {
+	r := newIdentityRouter()
+
+	tests := []struct {
+		name   string
+		path   string
+		user   string
+		status int
+		code   string
+	}{
+		{"anonymous", "/reports", "", http.StatusUnauthorized, "authentication_required"},
+		{"missing permission", "/reports", "user", http.StatusForbidden, "permission_denied"},
+		{"granted by role", "/reports", "mod", http.StatusNoContent, ""},
+		{"moderator cannot manage roles", "/roles", "mod", http.StatusForbidden, "permission_denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, p := identityRequest(r, tt.path, tt.user)
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.code, p.Code)
+		})
+	}
+}
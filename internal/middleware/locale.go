@@ -0,0 +1,29 @@
+// internal/middleware/locale.go
+package middleware
+
+import (
+	"github.com/ffelixf/microblog-platform/internal/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// localizerKey es la clave del contexto de Gin donde queda el idioma de la petición
+const localizerKey = "localizer"
+
+// Locale negocia el idioma de la petición según Accept-Language y lo deja en el
+// contexto para que las respuestas de error se traduzcan
+func Locale(bundle *i18n.Bundle) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(localizerKey, bundle.Negotiate(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+// LocalizerFrom devuelve el idioma negociado por Locale, o nil si no se usó el middleware
+func LocalizerFrom(c *gin.Context) *i18n.Localizer {
+	if v, ok := c.Get(localizerKey); ok {
+		if loc, ok := v.(*i18n.Localizer); ok {
+			return loc
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// internal/middleware/logging_test.go
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ffelixf/microblog-platform/internal/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLoggedRouter(t *testing.T, buf *bytes.Buffer) *gin.Engine {
+	logger, err := logging.New(buf, "info")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID(), AccessLog(logger), Recovery(logger), Errors())
+	r.GET("/api/v1/users/:id/timeline", func(c *gin.Context) {
+		c.String(http.StatusOK, logging.RequestID(c.Request.Context()))
+	})
+	r.POST("/api/v1/tweets", func(c *gin.Context) {
+		SetUserID(c, "64b7f0c2e4b0a1a2b3c4d5e6")
+		c.Status(http.StatusCreated)
+	})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("algo salió mal")
+	})
+	return r
+}
+
+func lastLogEntry(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &entry))
+	return entry
+}
+
+func TestRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	r := newLoggedRouter(t, &buf)
+
+	t.Run("propagates received id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/abc/timeline", nil)
+		req.Header.Set(RequestIDHeader, "client-id-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, "client-id-1", w.Header().Get(RequestIDHeader))
+		assert.Equal(t, "client-id-1", w.Body.String())
+		assert.Equal(t, "client-id-1", lastLogEntry(t, &buf)["request_id"])
+	})
+
+	t.Run("generates id when missing or invalid", func(t *testing.T) {
+		for _, header := range []string{"", "tiene espacios", strings.Repeat("a", 200)} {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users/abc/timeline", nil)
+			req.Header.Set(RequestIDHeader, header)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			assert.Len(t, id, 32)
+			assert.Equal(t, id, w.Body.String())
+		}
+	})
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	r := newLoggedRouter(t, &buf)
+
+	t.Run("route user is the target, not the caller", func(t *testing.T) {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users/abc/timeline", nil))
+		entry := lastLogEntry(t, &buf)
+		assert.Equal(t, "INFO", entry["level"])
+		assert.Equal(t, "GET", entry["method"])
+		assert.Equal(t, "/api/v1/users/:id/timeline", entry["route"])
+		assert.Equal(t, float64(http.StatusOK), entry["status"])
+		assert.Equal(t, "abc", entry["target_user_id"])
+		assert.NotContains(t, entry, "user_id", "la petición es anónima")
+		assert.Contains(t, entry, "latency_ms")
+	})
+
+	t.Run("user set by handler", func(t *testing.T) {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/tweets", nil))
+		entry := lastLogEntry(t, &buf)
+		assert.Equal(t, float64(http.StatusCreated), entry["status"])
+		assert.Equal(t, "64b7f0c2e4b0a1a2b3c4d5e6", entry["user_id"])
+	})
+
+	t.Run("unmatched route", func(t *testing.T) {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/no-existe", nil))
+		entry := lastLogEntry(t, &buf)
+		assert.Equal(t, "WARN", entry["level"])
+		assert.Equal(t, "unmatched", entry["route"])
+	})
+}
+
+func TestRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	r := newLoggedRouter(t, &buf)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	assert.NotContains(t, w.Body.String(), "algo salió mal")
+
+	entry := lastLogEntry(t, &buf)
+	assert.Equal(t, "ERROR", entry["level"])
+	assert.Equal(t, float64(http.StatusInternalServerError), entry["status"])
+	assert.Contains(t, buf.String(), "panic en handler")
+}
@@ -0,0 +1,23 @@
+// internal/middleware/metrics.go
+package middleware
+
+import (
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics mide cada petición por plantilla de ruta (c.FullPath), de modo que los
+// parámetros como :id no generan una serie por valor
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		done := m.RequestStarted()
+		defer done()
+
+		c.Next()
+
+		m.ObserveRequest(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
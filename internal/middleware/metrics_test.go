@@ -0,0 +1,42 @@
+// internal/middleware/metrics_test.go
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ffelixf/microblog-platform/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_RouteTemplateLabels(t *testing.T) {
+	m := metrics.New()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Metrics(m))
+	r.GET("/api/v1/users/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+
+	for _, path := range []string{"/api/v1/users/1", "/api/v1/users/2", "/api/v1/users/3", "/no-existe/4"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+
+	out := string(body)
+	assert.Contains(t, out, `microblog_http_request_duration_seconds_count{method="GET",route="/api/v1/users/:id",status="200"} 3`)
+	assert.Contains(t, out, `microblog_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, out, "/api/v1/users/1")
+	// La petición a /metrics sigue en curso mientras se genera la respuesta
+	assert.Contains(t, out, "microblog_http_requests_in_flight 1")
+}
@@ -0,0 +1,77 @@
+// internal/middleware/rate_limit.go
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// Cabeceras con el estado del límite de la petición
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+var ErrRateLimited = apperr.New(apperr.KindTooManyRequests, "rate_limited", "demasiadas peticiones")
+
+// RateLimitKey identifica al cliente por su IP. El usuario de X-User-ID no sirve
+// de clave: no está autenticado, así que cambiarlo daría un bucket nuevo en cada
+// petición y poner el de otro agotaría el suyo. Tampoco sale nunca de la ruta.
+func RateLimitKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimit aplica el límite de la regla que corresponde a cada ruta. Si el
+// almacén de buckets falla la petición se admite: un límite caído no debe
+// tumbar la API.
+func RateLimit(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !CheckRateLimit(c, limiter, c.Request.Method) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// CheckRateLimit consume un token de la regla que corresponde a method y a la
+// ruta de la petición. Sirve a los handlers que solo saben qué límite aplicar
+// después de leer el cuerpo, como GraphQL. Devuelve false si la petición se
+// rechazó; el error ya quedó registrado en c.
+func CheckRateLimit(c *gin.Context, limiter *ratelimit.Limiter, method string) bool {
+	rule := limiter.Rule(method, c.FullPath())
+	if rule == nil {
+		return true
+	}
+
+	res, err := limiter.Allow(c.Request.Context(), rule, RateLimitKey(c))
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "rate limit no disponible, se admite la petición",
+			slog.String("rule", rule.Name), slog.Any("error", err))
+		return true
+	}
+
+	c.Header(RateLimitLimitHeader, strconv.Itoa(res.Limit))
+	c.Header(RateLimitRemainingHeader, strconv.Itoa(res.Remaining))
+	c.Header(RateLimitResetHeader, strconv.Itoa(ceilSeconds(res.Reset)))
+	if !res.Allowed {
+		retryAfter := ceilSeconds(res.RetryAfter)
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.Error(ErrRateLimited.WithParam("retry_after", retryAfter))
+		return false
+	}
+	return true
+}
+
+// ceilSeconds redondea hacia arriba: un cliente que espera Retry-After segundos
+// debe encontrar el token disponible
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
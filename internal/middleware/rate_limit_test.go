@@ -0,0 +1,122 @@
+// internal/middleware/rate_limit_test.go
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/i18n"
+	"github.com/ffelixf/microblog-platform/internal/ratelimit"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit, time.Time) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("mongo caído")
+}
+
+func newRateLimitRouter(t *testing.T, store ratelimit.Store) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	bundle, err := i18n.NewBundle(i18n.DefaultLanguage)
+	require.NoError(t, err)
+
+	limiter := ratelimit.NewLimiter(store, ratelimit.Rule{
+		Name:   "api",
+		Routes: []string{"/api/v1/*"},
+		Limit:  ratelimit.Limit{Requests: 2, Period: time.Minute},
+	})
+	resolve := func(ctx context.Context, userID string) (*rbac.Principal, error) {
+		return &rbac.Principal{UserID: userID}, nil
+	}
+	r := gin.New()
+	r.Use(Locale(bundle), Errors(), Identity(resolve), RateLimit(limiter))
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	r.GET("/api/v1/users/:id", ok)
+	r.GET("/api/v1/tweets", ok)
+	r.GET("/livez", ok)
+	return r
+}
+
+func rateLimitRequest(r *gin.Engine, path, ip string) *httptest.ResponseRecorder {
+	return rateLimitRequestAs(r, path, ip, "")
+}
+
+// rateLimitRequestAs hace la petición como el usuario indicado; vacío es anónima
+func rateLimitRequestAs(r *gin.Engine, path, ip, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = ip + ":1234"
+	if userID != "" {
+		req.Header.Set(UserIDHeader, userID)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit_HeadersAndTooManyRequests(t *testing.T) {
+	r := newRateLimitRouter(t, ratelimit.NewMemoryStore())
+
+	w := rateLimitRequest(r, "/api/v1/tweets", "10.0.0.1")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "2", w.Header().Get(RateLimitLimitHeader))
+	assert.Equal(t, "1", w.Header().Get(RateLimitRemainingHeader))
+	assert.Equal(t, "30", w.Header().Get(RateLimitResetHeader))
+
+	rateLimitRequest(r, "/api/v1/tweets", "10.0.0.1")
+	w = rateLimitRequest(r, "/api/v1/tweets", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get(RateLimitRemainingHeader))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	var p Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, "rate_limited", p.Code)
+	assert.Contains(t, p.Detail, w.Header().Get("Retry-After")+" segundos")
+
+	// Otra IP no comparte el límite
+	w = rateLimitRequest(r, "/api/v1/tweets", "10.0.0.2")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestRateLimit_KeysByIP(t *testing.T) {
+	r := newRateLimitRouter(t, ratelimit.NewMemoryStore())
+
+	// El usuario de la ruta es el consultado: cambiarlo no da un bucket nuevo
+	rateLimitRequestAs(r, "/api/v1/users/u1", "10.0.0.1", "caller")
+	rateLimitRequestAs(r, "/api/v1/users/u2", "10.0.0.1", "caller")
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitRequestAs(r, "/api/v1/users/u3", "10.0.0.1", "caller").Code)
+
+	// X-User-ID no está autenticado: cambiarlo tampoco da un bucket nuevo
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitRequestAs(r, "/api/v1/users/u1", "10.0.0.1", "other").Code)
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitRequest(r, "/api/v1/users/u1", "10.0.0.1").Code)
+
+	// Y poner el usuario de otro no agota su límite desde otra IP
+	assert.Equal(t, http.StatusNoContent, rateLimitRequestAs(r, "/api/v1/users/u1", "10.0.0.2", "caller").Code)
+}
+
+func TestRateLimit_UnlimitedRoute(t *testing.T) {
+	r := newRateLimitRouter(t, ratelimit.NewMemoryStore())
+
+	for i := 0; i < 5; i++ {
+		w := rateLimitRequest(r, "/livez", "10.0.0.1")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Header().Get(RateLimitLimitHeader))
+	}
+}
+
+func TestRateLimit_FailsOpen(t *testing.T) {
+	r := newRateLimitRouter(t, failingStore{})
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusNoContent, rateLimitRequest(r, "/api/v1/tweets", "10.0.0.1").Code)
+	}
+}
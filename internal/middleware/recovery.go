@@ -0,0 +1,29 @@
+// internal/middleware/recovery.go
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery convierte un panic en un error interno con formato de problema y lo
+// registra con el stack en el logger
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				logger.ErrorContext(c.Request.Context(), "panic en handler",
+					slog.Any("panic", rec),
+					slog.String("stack", string(debug.Stack())),
+				)
+				err := fmt.Errorf("panic: %v", rec)
+				c.Error(err)
+				WriteProblem(c, err)
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,55 @@
+// internal/middleware/request_id.go
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/ffelixf/microblog-platform/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader es la cabecera con la que se recibe y se devuelve el ID de la petición
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength limita los IDs recibidos para que no inflen los logs
+const maxRequestIDLength = 128
+
+// RequestID usa el X-Request-ID recibido o genera uno nuevo, lo devuelve en la
+// respuesta y lo guarda en el contexto de la petición, que llega hasta los repositorios
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// validRequestID acepta solo IDs cortos de caracteres imprimibles seguros
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
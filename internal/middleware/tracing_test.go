@@ -0,0 +1,92 @@
+// internal/middleware/tracing_test.go
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const (
+	incomingTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	incomingParentID    = "00f067aa0ba902b7"
+	incomingTraceparent = "00-" + incomingTraceID + "-" + incomingParentID + "-01"
+)
+
+func newTracedRouter(t *testing.T, buf *bytes.Buffer) (*gin.Engine, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	logger, err := logging.New(buf, "info")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID(), Tracing(), AccessLog(logger), Errors())
+	r.GET("/api/v1/tweets/:id", func(c *gin.Context) {
+		c.Error(apperr.NotFound("tweet_not_found", "tweet no encontrado"))
+	})
+	r.GET("/fail", func(c *gin.Context) {
+		c.Error(assert.AnError)
+	})
+	return r, recorder
+}
+
+func TestTracing_ContinuesIncomingTrace(t *testing.T) {
+	var buf bytes.Buffer
+	r, recorder := newTracedRouter(t, &buf)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tweets/abc", nil)
+	req.Header.Set("traceparent", incomingTraceparent)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /api/v1/tweets/:id", span.Name())
+	assert.Equal(t, incomingTraceID, span.SpanContext().TraceID().String())
+	assert.Equal(t, incomingParentID, span.Parent().SpanID().String())
+	assert.NotEqual(t, codes.Error, span.Status().Code, "un 404 no es un error del servidor")
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, incomingTraceID, problem.TraceID)
+
+	entry := lastLogEntry(t, &buf)
+	assert.Equal(t, incomingTraceID, entry["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), entry["span_id"])
+}
+
+func TestTracing_ServerErrors(t *testing.T) {
+	var buf bytes.Buffer
+	r, recorder := newTracedRouter(t, &buf)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.NotEmpty(t, spans[0].Events(), "el error queda registrado en el span")
+	assert.Equal(t, "GET unmatched", spans[1].Name())
+}
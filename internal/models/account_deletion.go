@@ -0,0 +1,43 @@
+// internal/models/account_deletion.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DeletionStatusPending = "pending"
+	DeletionStatusDone    = "done"
+)
+
+// Motivos del borrado de una cuenta
+const (
+	DeletionReasonAdmin       = "admin"
+	DeletionReasonDeactivated = "deactivation_expired"
+)
+
+// AccountDeletion es el job que borra definitivamente una cuenta. Avanza por
+// pasos y guarda el último completado, así que si la instancia cae otra lo
+// retoma donde quedó; cada paso se puede repetir sin efectos dobles.
+type AccountDeletion struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID   primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Username string             `bson:"username" json:"username"`
+	Reason   string             `bson:"reason" json:"reason"`
+	Status   string             `bson:"status" json:"status"`
+	// Step es la cantidad de pasos ya completados
+	Step int `bson:"step" json:"step"`
+	// Following es la copia de los seguidos al pedir el borrado, para corregir su
+	// followers_count aunque el usuario ya no exista
+	Following   []string   `bson:"following" json:"-"`
+	Attempts    int        `bson:"attempts" json:"attempts"`
+	LastError   string     `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	RequestedAt time.Time  `bson:"requested_at" json:"requested_at"`
+	CompletedAt *time.Time `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+
+	// Lease de la instancia que lo está procesando
+	LeaseOwner string     `bson:"lease_owner,omitempty" json:"-"`
+	LeaseUntil *time.Time `bson:"lease_until,omitempty" json:"-"`
+}
@@ -0,0 +1,16 @@
+// internal/models/actor_key.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ActorKey es el par de claves RSA con el que un usuario firma sus actividades ActivityPub
+type ActorKey struct {
+	UserID        primitive.ObjectID `bson:"_id"`
+	PublicKeyPEM  string             `bson:"public_key_pem"`
+	PrivateKeyPEM string             `bson:"private_key_pem"`
+	CreatedAt     time.Time          `bson:"created_at"`
+}
@@ -0,0 +1,149 @@
+// internal/models/audit.go
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Acciones registradas en la auditoría
+const (
+	AuditRolesChanged      = "user.roles_changed"
+	AuditUserSuspended     = "user.suspended"
+	AuditSuspensionLifted  = "user.suspension_lifted"
+	AuditUserDeactivated   = "user.deactivated"
+	AuditUserReactivated   = "user.reactivated"
+	AuditDeletionRequested = "user.deletion_requested"
+	AuditUserDeleted       = "user.deleted"
+	AuditExportRequested   = "user.export_requested"
+	AuditReportResolved    = "report.resolved"
+	AuditTweetDeleted      = "tweet.deleted"
+	AuditTweetApproved     = "tweet.approved"
+	AuditPolicyRuleCreated = "policy.rule_created"
+	AuditPolicyRuleDeleted = "policy.rule_deleted"
+)
+
+// Tipos de objetivo de la auditoría
+const (
+	AuditTargetUser   = "user"
+	AuditTargetTweet  = "tweet"
+	AuditTargetReport = "report"
+	AuditTargetRule   = "policy_rule"
+)
+
+// AuditActorSystem es el actor de las acciones que no hace un usuario: workers y
+// comandos de administración
+const AuditActorSystem = "system"
+
+// AuditEntry es un registro de la auditoría. Los registros no se modifican: cada
+// uno guarda el hash del anterior (PrevHash) y el suyo (Hash), calculado sobre
+// todos sus campos, así que cambiar, borrar o reordenar uno rompe la cadena.
+type AuditEntry struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	// Seq es la posición en la cadena, empezando en 1 y sin huecos
+	Seq        int64             `bson:"seq" json:"seq"`
+	Action     string            `bson:"action" json:"action"`
+	ActorID    string            `bson:"actor_id,omitempty" json:"actor_id,omitempty"`
+	IP         string            `bson:"ip,omitempty" json:"ip,omitempty"`
+	UserAgent  string            `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	RequestID  string            `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	TargetType string            `bson:"target_type" json:"target_type"`
+	TargetID   string            `bson:"target_id" json:"target_id"`
+	Before     AuditState        `bson:"before,omitempty" json:"before,omitempty"`
+	After      AuditState        `bson:"after,omitempty" json:"after,omitempty"`
+	Metadata   map[string]string `bson:"metadata,omitempty" json:"metadata,omitempty"`
+	CreatedAt  time.Time         `bson:"created_at" json:"created_at"`
+	PrevHash   string            `bson:"prev_hash" json:"prev_hash"`
+	Hash       string            `bson:"hash" json:"hash"`
+}
+
+// ComputeHash calcula el hash del registro a partir de sus campos y del hash
+// del anterior. CreatedAt debe estar truncado a milisegundos, la precisión con
+// que MongoDB guarda las fechas.
+func (e *AuditEntry) ComputeHash() string {
+	// El orden y los nombres de este struct forman parte del formato: cambiarlos
+	// invalida todas las cadenas existentes
+	payload, _ := json.Marshal(struct {
+		Seq        int64             `json:"seq"`
+		PrevHash   string            `json:"prev_hash"`
+		Action     string            `json:"action"`
+		ActorID    string            `json:"actor_id"`
+		IP         string            `json:"ip"`
+		UserAgent  string            `json:"user_agent"`
+		RequestID  string            `json:"request_id"`
+		TargetType string            `json:"target_type"`
+		TargetID   string            `json:"target_id"`
+		Before     AuditState        `json:"before"`
+		After      AuditState        `json:"after"`
+		Metadata   map[string]string `json:"metadata"`
+		CreatedAt  string            `json:"created_at"`
+	}{
+		e.Seq, e.PrevHash, e.Action, e.ActorID, e.IP, e.UserAgent, e.RequestID,
+		e.TargetType, e.TargetID, e.Before, e.After, e.Metadata,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditState es un estado en JSON (los campos que cambiaron, antes o después).
+// En MongoDB se guarda como texto para que el hash no dependa de cómo el
+// driver decodifica números y documentos anidados.
+type AuditState json.RawMessage
+
+func (s AuditState) MarshalJSON() ([]byte, error) {
+	if len(s) == 0 {
+		return []byte("null"), nil
+	}
+	return s, nil
+}
+
+func (s *AuditState) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*s = nil
+		return nil
+	}
+	*s = append((*s)[:0], data...)
+	return nil
+}
+
+func (s AuditState) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return bson.MarshalValue(string(s))
+}
+
+func (s *AuditState) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	var str string
+	if err := bson.UnmarshalValue(t, data, &str); err != nil {
+		return err
+	}
+	*s = AuditState(str)
+	return nil
+}
+
+// AuditCheckpoint es la cabeza de la cadena, guardada fuera de la colección de
+// auditoría: si se borran los últimos registros, la cadena que queda sigue siendo
+// válida pero ya no llega hasta el punto de control
+type AuditCheckpoint struct {
+	Seq       int64     `bson:"seq" json:"seq"`
+	Hash      string    `bson:"hash" json:"hash"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// AuditQuery son los filtros de la consulta de auditoría. Los resultados van del
+// más reciente al más antiguo; Before pagina devolviendo los de Seq menor.
+type AuditQuery struct {
+	Action     string     `form:"action" json:"action"`
+	ActorID    string     `form:"actor_id" json:"actor_id"`
+	TargetType string     `form:"target_type" json:"target_type"`
+	TargetID   string     `form:"target_id" json:"target_id"`
+	From       *time.Time `form:"from" json:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" json:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Before     int64      `form:"before" json:"before" binding:"min=0"`
+	Limit      int        `form:"limit" json:"limit" binding:"min=0,max=100"`
+}
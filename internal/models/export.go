@@ -0,0 +1,42 @@
+// internal/models/export.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ExportStatusPending = "pending"
+	ExportStatusDone    = "done"
+	ExportStatusFailed  = "failed"
+	// ExportStatusExpired indica que el archivo ya se borró del almacenamiento
+	ExportStatusExpired = "expired"
+)
+
+// ExportJob es una exportación de los datos personales de un usuario. La
+// genera worker.Exporter en segundo plano; al terminar, el ZIP queda en el
+// almacenamiento de archivos hasta ExpiresAt y se descarga con un enlace firmado.
+type ExportJob struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID      primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Status      string             `bson:"status" json:"status"`
+	Attempts    int                `bson:"attempts" json:"attempts"`
+	LastError   string             `bson:"last_error,omitempty" json:"-"`
+	RequestedAt time.Time          `bson:"requested_at" json:"requested_at"`
+	CompletedAt *time.Time         `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	// Key es la clave del ZIP en el almacenamiento
+	Key  string `bson:"key,omitempty" json:"-"`
+	Size int64  `bson:"size,omitempty" json:"size,omitempty"`
+	// ExpiresAt es cuándo se borra el archivo
+	ExpiresAt *time.Time `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+
+	// Enlace de descarga; se firma en cada consulta y no se guarda
+	DownloadURL       string     `bson:"-" json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `bson:"-" json:"download_expires_at,omitempty"`
+
+	// Lease de la instancia que lo está procesando
+	LeaseOwner string     `bson:"lease_owner,omitempty" json:"-"`
+	LeaseUntil *time.Time `bson:"lease_until,omitempty" json:"-"`
+}
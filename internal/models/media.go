@@ -0,0 +1,31 @@
+// internal/models/media.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxTweetMedia es la cantidad máxima de archivos adjuntos por tweet
+const MaxTweetMedia = 4
+
+// Media representa una imagen subida por un usuario para adjuntar a sus tweets
+type Media struct {
+	ID          primitive.ObjectID      `bson:"_id,omitempty" json:"id"`
+	UserID      primitive.ObjectID      `bson:"user_id" json:"user_id"`
+	ContentType string                  `bson:"content_type" json:"content_type"`
+	Size        int64                   `bson:"size" json:"size"`
+	Variants    map[string]MediaVariant `bson:"variants" json:"variants"`
+	CreatedAt   time.Time               `bson:"created_at" json:"created_at"`
+}
+
+// MediaVariant es una versión procesada (original sin metadatos, redimensionada, miniatura)
+type MediaVariant struct {
+	Key         string `bson:"key" json:"-"`
+	URL         string `bson:"-" json:"url,omitempty"`
+	ContentType string `bson:"content_type" json:"content_type"`
+	Width       int    `bson:"width" json:"width"`
+	Height      int    `bson:"height" json:"height"`
+	Size        int64  `bson:"size" json:"size"`
+}
@@ -0,0 +1,23 @@
+// internal/models/notification.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// NotificationPollClosed se envía a los votantes y al autor cuando cierra una encuesta
+	NotificationPollClosed = "poll_closed"
+)
+
+// Notification es un aviso dirigido a un usuario
+type Notification struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Type      string             `bson:"type" json:"type"`
+	TweetID   primitive.ObjectID `bson:"tweet_id,omitempty" json:"tweet_id,omitempty"`
+	Read      bool               `bson:"read" json:"read"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
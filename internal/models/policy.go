@@ -0,0 +1,58 @@
+// internal/models/policy.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Estados de un tweet ante la política de contenido
+const (
+	// PolicyStatusHeld es un tweet retenido: no se publica hasta que se aprueba
+	PolicyStatusHeld = "held"
+	// PolicyStatusFlagged es un tweet publicado que queda marcado para revisión
+	PolicyStatusFlagged = "flagged"
+	// PolicyStatusApproved es un tweet retenido o marcado que un moderador aprobó
+	PolicyStatusApproved = "approved"
+)
+
+// MaxMutedWords es cuántas palabras puede silenciar un usuario
+const MaxMutedWords = 100
+
+// MaxMutedWordLength es la longitud máxima de una palabra silenciada
+const MaxMutedWordLength = 50
+
+// PolicyRule es una entrada de la lista de bloqueo. Kind es exact, wildcard o
+// regex y Action es flag, hold o reject.
+type PolicyRule struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Kind      string             `bson:"kind" json:"kind" binding:"required,oneof=exact wildcard regex"`
+	Pattern   string             `bson:"pattern" json:"pattern" binding:"required"`
+	Action    string             `bson:"action" json:"action" binding:"required,oneof=flag hold reject"`
+	CreatedBy string             `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// TweetPolicy es el resultado de la política de contenido sobre un tweet, cuando
+// cumplió alguna regla
+type TweetPolicy struct {
+	Status string `bson:"status" json:"status"`
+	// Rules son las reglas que cumplió: "blocklist:<id>", duplicate, links o burst
+	Rules      []string   `bson:"rules" json:"rules"`
+	ReviewedBy string     `bson:"reviewed_by,omitempty" json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+}
+
+// PolicyReviewItem es un tweet de la cola de revisión con el resultado de la
+// política, que no se muestra en el resto de la API
+type PolicyReviewItem struct {
+	Tweet
+	Policy *TweetPolicy `json:"policy"`
+}
+
+// MutedWords son las palabras que un usuario no quiere ver en su timeline; cada
+// una es una palabra o frase exacta, o un patrón con comodines * y ?
+type MutedWords struct {
+	Words []string `json:"words"`
+}
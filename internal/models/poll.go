@@ -0,0 +1,80 @@
+// internal/models/poll.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	MinPollOptions      = 2
+	MaxPollOptions      = 4
+	MaxPollOptionLength = 25
+	MinPollDuration     = 5 * time.Minute
+	MaxPollDuration     = 7 * 24 * time.Hour
+)
+
+// Poll es una encuesta embebida en un tweet. Los conteos se guardan en el propio
+// tweet y se incrementan atómicamente; los votos individuales viven en poll_votes.
+type Poll struct {
+	Options         []PollOption `bson:"options" json:"options"`
+	DurationMinutes int          `bson:"-" json:"duration_minutes,omitempty"`
+	ExpiresAt       time.Time    `bson:"expires_at" json:"expires_at"`
+	Closed          bool         `bson:"closed" json:"closed"`
+	TotalVotes      *int         `bson:"total_votes" json:"total_votes,omitempty"`
+	// ResultsNotified indica si ya se avisó del cierre a votantes y autor; el job
+	// de cierre lo pone en false al cerrar y en true cuando crea los avisos. Se
+	// guarda siempre, también en false, porque los avisos pendientes se buscan
+	// por ese valor.
+	ResultsNotified bool `bson:"results_notified" json:"-"`
+	// NotifyLeaseUntil reserva el aviso del cierre para una instancia del job
+	NotifyLeaseUntil *time.Time `bson:"notify_lease_until,omitempty" json:"-"`
+
+	// Estado relativo a quien consulta; no se persiste
+	Voted          bool `bson:"-" json:"voted"`
+	VotedOption    *int `bson:"-" json:"voted_option,omitempty"`
+	ResultsVisible bool `bson:"-" json:"results_visible"`
+}
+
+// PollOption es una opción de la encuesta; Votes es nil mientras los resultados estén ocultos
+type PollOption struct {
+	Text  string `bson:"text" json:"text"`
+	Votes *int   `bson:"votes" json:"votes,omitempty"`
+}
+
+// PollVote registra el voto de un usuario; hay un índice único por (tweet_id, user_id)
+type PollVote struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TweetID   primitive.ObjectID `bson:"tweet_id" json:"tweet_id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Option    int                `bson:"option" json:"option"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// IsOpen indica si la encuesta todavía acepta votos
+func (p *Poll) IsOpen(now time.Time) bool {
+	return !p.Closed && now.Before(p.ExpiresAt)
+}
+
+// ApplyViewer completa el estado para quien consulta (votedOption < 0 si no votó)
+// y oculta los conteos hasta que haya votado o la encuesta haya terminado.
+func (p *Poll) ApplyViewer(votedOption int, now time.Time) {
+	p.Voted = votedOption >= 0
+	if p.Voted {
+		option := votedOption
+		p.VotedOption = &option
+	}
+
+	p.ResultsVisible = p.Voted || !p.IsOpen(now)
+	if p.ResultsVisible {
+		return
+	}
+
+	p.TotalVotes = nil
+	options := make([]PollOption, len(p.Options))
+	for i, o := range p.Options {
+		options[i] = PollOption{Text: o.Text}
+	}
+	p.Options = options
+}
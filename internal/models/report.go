@@ -0,0 +1,99 @@
+// internal/models/report.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ReportTargetTweet = "tweet"
+	ReportTargetUser  = "user"
+
+	ReportStatusOpen     = "open"
+	ReportStatusResolved = "resolved"
+
+	ModerationDismiss     = "dismiss"
+	ModerationHideTweet   = "hide_tweet"
+	ModerationSuspendUser = "suspend_user"
+
+	// MaxReportCommentLength es la longitud máxima del texto libre de una denuncia
+	MaxReportCommentLength = 1000
+)
+
+// ReportReasons son las categorías de denuncia con su gravedad. La cola de
+// moderación atiende primero los casos más graves.
+var ReportReasons = map[string]int{
+	"spam":           1,
+	"other":          1,
+	"misinformation": 2,
+	"harassment":     3,
+	"hate":           3,
+	"sexual":         3,
+	"violence":       4,
+	"self_harm":      4,
+}
+
+// ReportRequest es la denuncia que envía un usuario sobre un tweet o una cuenta
+type ReportRequest struct {
+	// ReporterID es el usuario identificado de la petición, no viene en el cuerpo
+	ReporterID primitive.ObjectID `json:"-"`
+	TargetType string             `json:"target_type" binding:"required,oneof=tweet user"`
+	TargetID   primitive.ObjectID `json:"target_id"`
+	Reason     string             `json:"reason" binding:"required"`
+	Comment    string             `json:"comment" binding:"max=1000"`
+}
+
+// Report es un caso de la cola de moderación. Todas las denuncias abiertas sobre
+// un mismo objetivo se acumulan en un único caso, que guarda la gravedad máxima
+// y cuántas veces se denunció.
+type Report struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TargetType string             `bson:"target_type" json:"target_type"`
+	TargetID   primitive.ObjectID `bson:"target_id" json:"target_id"`
+	// TargetUserID es el autor del tweet denunciado, o el propio usuario denunciado
+	TargetUserID primitive.ObjectID `bson:"target_user_id" json:"target_user_id"`
+	Status       string             `bson:"status" json:"status"`
+	Severity     int                `bson:"severity" json:"severity"`
+	ReportCount  int                `bson:"report_count" json:"report_count"`
+	// Reasons cuenta las denuncias por categoría
+	Reasons    map[string]int    `bson:"reasons" json:"reasons"`
+	Entries    []ReportEntry     `bson:"entries" json:"entries"`
+	Resolution *ReportResolution `bson:"resolution,omitempty" json:"resolution,omitempty"`
+	CreatedAt  time.Time         `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time         `bson:"updated_at" json:"updated_at"`
+}
+
+// ReportEntry es una denuncia individual dentro de un caso
+type ReportEntry struct {
+	ReporterID primitive.ObjectID `bson:"reporter_id" json:"reporter_id"`
+	Reason     string             `bson:"reason" json:"reason"`
+	Comment    string             `bson:"comment,omitempty" json:"comment,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// ReportResolution es la decisión del moderador que cerró el caso
+type ReportResolution struct {
+	Action      string             `bson:"action" json:"action" binding:"required,oneof=dismiss hide_tweet suspend_user"`
+	ModeratorID primitive.ObjectID `bson:"moderator_id" json:"moderator_id"`
+	Note        string             `bson:"note,omitempty" json:"note,omitempty" binding:"max=1000"`
+	ResolvedAt  time.Time          `bson:"resolved_at" json:"resolved_at"`
+}
+
+// TopReason devuelve la categoría más grave del caso; a igual gravedad, la más denunciada
+func (r *Report) TopReason() string {
+	top := ""
+	for reason, count := range r.Reasons {
+		if top == "" {
+			top = reason
+			continue
+		}
+		severity, topSeverity := ReportReasons[reason], ReportReasons[top]
+		if severity > topSeverity ||
+			(severity == topSeverity && (count > r.Reasons[top] || (count == r.Reasons[top] && reason < top))) {
+			top = reason
+		}
+	}
+	return top
+}
@@ -0,0 +1,77 @@
+// internal/models/scheduled_tweet.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ScheduledStatusDraft     = "draft"
+	ScheduledStatusScheduled = "scheduled"
+	ScheduledStatusPublished = "published"
+	ScheduledStatusCanceled  = "canceled"
+	ScheduledStatusFailed    = "failed"
+)
+
+// ScheduledTweet es un borrador o un tweet programado. Vive en su propia colección,
+// así que no aparece en ningún timeline ni feed hasta que se publica.
+// Al publicarse, el tweet se crea con el mismo ID para que un reintento no lo duplique.
+type ScheduledTweet struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	UserID      primitive.ObjectID   `bson:"user_id" json:"user_id"`
+	Content     string               `bson:"content" json:"content" binding:"required,max=280"`
+	Media       []primitive.ObjectID `bson:"media,omitempty" json:"media,omitempty" binding:"omitempty,max=4"`
+	Poll        *PollDraft           `bson:"poll,omitempty" json:"poll,omitempty"`
+	Status      string               `bson:"status" json:"status"`
+	PublishAt   *time.Time           `bson:"publish_at,omitempty" json:"publish_at,omitempty"`
+	PublishedAt *time.Time           `bson:"published_at,omitempty" json:"published_at,omitempty"`
+	TweetID     primitive.ObjectID   `bson:"tweet_id,omitempty" json:"tweet_id,omitempty"`
+	Attempts    int                  `bson:"attempts" json:"attempts"`
+	LastError   string               `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	CreatedAt   time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time            `bson:"updated_at" json:"updated_at"`
+
+	// Lease del worker que lo está publicando; evita publicaciones dobles entre instancias
+	LeaseOwner string     `bson:"lease_owner,omitempty" json:"-"`
+	LeaseUntil *time.Time `bson:"lease_until,omitempty" json:"-"`
+}
+
+// PollDraft es la encuesta de un tweet todavía no publicado
+type PollDraft struct {
+	Options         []string `bson:"options" json:"options"`
+	DurationMinutes int      `bson:"duration_minutes" json:"duration_minutes"`
+}
+
+// ScheduledTweetUpdate son los cambios admitidos sobre un borrador o tweet programado;
+// los campos nil no se modifican. Fijar PublishAt programa también un borrador.
+type ScheduledTweetUpdate struct {
+	Content   *string               `json:"content" binding:"omitempty,max=280"`
+	Media     *[]primitive.ObjectID `json:"media" binding:"omitempty,max=4"`
+	Poll      *PollDraft            `json:"poll"`
+	PublishAt *time.Time            `json:"publish_at"`
+}
+
+// ToPoll convierte el borrador en la encuesta que se adjunta al tweet publicado
+func (d *PollDraft) ToPoll() *Poll {
+	options := make([]PollOption, len(d.Options))
+	for i, text := range d.Options {
+		options[i] = PollOption{Text: text}
+	}
+	return &Poll{Options: options, DurationMinutes: d.DurationMinutes}
+}
+
+// ToTweet construye el tweet que se publicará
+func (s *ScheduledTweet) ToTweet() *Tweet {
+	tweet := &Tweet{
+		ID:      s.ID,
+		UserID:  s.UserID,
+		Content: s.Content,
+		Media:   s.Media,
+	}
+	if s.Poll != nil {
+		tweet.Poll = s.Poll.ToPoll()
+	}
+	return tweet
+}
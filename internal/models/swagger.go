@@ -1,9 +1,24 @@
 // internal/models/swagger.go
 package models
 
-// Error representa un error de la API
-type Error struct {
-	Message string `json:"error" example:"Descripción del error"`
+// Problem representa un error de la API (application/problem+json, RFC 7807)
+type Problem struct {
+	Type     string         `json:"type" example:"urn:microblog:problem:user_not_found"`
+	Title    string         `json:"title" example:"Not Found"`
+	Status   int            `json:"status" example:"404"`
+	Detail   string         `json:"detail" example:"usuario no encontrado"`
+	Instance string         `json:"instance" example:"/api/v1/users/123"`
+	Code     string         `json:"code" example:"user_not_found"`
+	Errors   []ProblemField `json:"errors,omitempty"`
+	TraceID  string         `json:"trace_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+}
+
+// ProblemField representa un campo inválido dentro de un Problem
+type ProblemField struct {
+	Field   string `json:"field" example:"email"`
+	Code    string `json:"code" example:"required"`
+	Param   string `json:"param,omitempty" example:""`
+	Message string `json:"message" example:"el campo es requerido"`
 }
 
 // FollowResponse representa la respuesta al seguir a un usuario
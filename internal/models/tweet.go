@@ -2,14 +2,51 @@
 package models
 
 import (
+	"bytes"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Tweet struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	UserID    primitive.ObjectID `bson:"user_id" json:"user_id" binding:"required"`
-	Content   string             `bson:"content" json:"content" binding:"required,max=280"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	ID        primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID   `bson:"user_id" json:"user_id" binding:"required"`
+	Content   string               `bson:"content" json:"content" binding:"required,max=280"`
+	Hashtags  []string             `bson:"hashtags,omitempty" json:"hashtags,omitempty"`
+	Media     []primitive.ObjectID `bson:"media,omitempty" json:"media,omitempty" binding:"omitempty,max=4"`
+	Poll      *Poll                `bson:"poll,omitempty" json:"poll,omitempty"`
+	CreatedAt time.Time            `bson:"created_at" json:"created_at"`
+	// HiddenAt indica que un moderador ocultó el tweet; deja de aparecer en la API
+	HiddenAt *time.Time `bson:"hidden_at,omitempty" json:"-"`
+	// ContentHash identifica el contenido normalizado para detectar duplicados
+	ContentHash string `bson:"content_hash,omitempty" json:"-"`
+	// Policy es el resultado de la política de contenido; un tweet retenido no
+	// aparece en la API hasta que se aprueba
+	Policy *TweetPolicy `bson:"policy,omitempty" json:"-"`
+}
+
+// Held indica que el tweet está retenido por la política de contenido
+func (t *Tweet) Held() bool {
+	return t.Policy != nil && t.Policy.Status == PolicyStatusHeld
+}
+
+// TweetPosition es la posición de un tweet en los listados, que van del más
+// reciente al más antiguo: la fecha de creación y, a igual fecha, el ID
+type TweetPosition struct {
+	CreatedAt time.Time
+	ID        primitive.ObjectID
+}
+
+// Position devuelve la posición del tweet en los listados
+func (t *Tweet) Position() TweetPosition {
+	return TweetPosition{CreatedAt: t.CreatedAt, ID: t.ID}
+}
+
+// Compare ordena las posiciones cronológicamente: devuelve -1 si p es anterior
+// a q, 1 si es posterior y 0 si son la misma
+func (p TweetPosition) Compare(q TweetPosition) int {
+	if c := p.CreatedAt.Compare(q.CreatedAt); c != 0 {
+		return c
+	}
+	return bytes.Compare(p.ID[:], q.ID[:])
 }
@@ -10,9 +10,87 @@ import (
 type User struct {
 	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Username       string             `bson:"username" json:"username" binding:"required"`
-	Email          string             `bson:"email" json:"email" binding:"required,email"`
+	Email          string             `bson:"email,omitempty" json:"email" binding:"required,email"`
 	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
 	Following      []string           `bson:"following" json:"following"`
 	FollowersCount int                `bson:"followers_count" json:"followers_count"`
+	Remote         *RemoteActor       `bson:"remote,omitempty" json:"remote,omitempty"`
+	Suspension     *Suspension        `bson:"suspension,omitempty" json:"suspension,omitempty"`
+	Roles          []string           `bson:"roles,omitempty" json:"roles,omitempty"`
+	Permissions    []string           `bson:"permissions,omitempty" json:"permissions,omitempty"`
+	// DeactivatedAt es cuándo el usuario desactivó su cuenta; puede reactivarla
+	// durante DeactivationGracePeriod y después se borra
+	DeactivatedAt *time.Time `bson:"deactivated_at,omitempty" json:"deactivated_at,omitempty"`
+	// DeletionRequestedAt marca la cuenta como pendiente del borrado definitivo
+	DeletionRequestedAt *time.Time `bson:"deletion_requested_at,omitempty" json:"-"`
+	// MutedWords son las palabras silenciadas; solo las ve el propio usuario
+	MutedWords []string `bson:"muted_words,omitempty" json:"-"`
+}
+
+// DeactivationGracePeriod es el plazo para reactivar una cuenta desactivada
+const DeactivationGracePeriod = 30 * 24 * time.Hour
+
+// Suspension es la suspensión de una cuenta por moderación. El moderador y el
+// caso quedan registrados pero no se muestran en el perfil. Sin ExpiresAt la
+// suspensión es indefinida.
+type Suspension struct {
+	Reason      string             `bson:"reason" json:"reason"`
+	ModeratorID primitive.ObjectID `bson:"moderator_id" json:"-"`
+	ReportID    primitive.ObjectID `bson:"report_id,omitempty" json:"-"`
+	Since       time.Time          `bson:"since" json:"since"`
+	ExpiresAt   *time.Time         `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+}
+
+// SuspensionRequest es la suspensión que aplica un administrador
+type SuspensionRequest struct {
+	Reason    string     `json:"reason" binding:"required,max=500"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// RoleAssignment son los roles y permisos que la administración asigna a un
+// usuario; reemplazan a los anteriores
+type RoleAssignment struct {
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
+
+// RemoteActor contiene los datos de una cuenta federada (ActivityPub).
+// Los usuarios remotos se guardan en la misma colección que los locales para
+// que sus follows formen parte del mismo grafo.
+type RemoteActor struct {
+	ActorID     string `bson:"actor_id" json:"actor_id"`
+	Inbox       string `bson:"inbox" json:"inbox"`
+	SharedInbox string `bson:"shared_inbox,omitempty" json:"shared_inbox,omitempty"`
+}
+
+// IsSuspended indica si la cuenta está suspendida ahora
+func (u *User) IsSuspended() bool {
+	return u.IsSuspendedAt(time.Now())
+}
+
+// IsSuspendedAt indica si la cuenta está suspendida en el instante now; una
+// suspensión vencida ya no cuenta aunque el job todavía no la haya levantado
+func (u *User) IsSuspendedAt(now time.Time) bool {
+	return u.Suspension != nil && (u.Suspension.ExpiresAt == nil || now.Before(*u.Suspension.ExpiresAt))
+}
+
+// IsDeactivated indica si el usuario desactivó su cuenta
+func (u *User) IsDeactivated() bool {
+	return u.DeactivatedAt != nil
+}
+
+// IsPendingDeletion indica si la cuenta está en cola para el borrado definitivo
+func (u *User) IsPendingDeletion() bool {
+	return u.DeletionRequestedAt != nil
+}
+
+// IsActiveAt indica si la cuenta se muestra en timelines, seguidores y búsquedas
+func (u *User) IsActiveAt(now time.Time) bool {
+	return !u.IsSuspendedAt(now) && !u.IsDeactivated() && !u.IsPendingDeletion()
+}
+
+// IsRemote indica si el usuario pertenece a otra instancia del fediverso
+func (u *User) IsRemote() bool {
+	return u.Remote != nil
 }
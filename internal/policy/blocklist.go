@@ -0,0 +1,101 @@
+// internal/policy/blocklist.go
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Rule es una entrada de la lista de bloqueo que gestiona la administración
+type Rule struct {
+	ID      string
+	Kind    string
+	Pattern string
+	Action  Action
+}
+
+type compiledRule struct {
+	id      string
+	action  Action
+	matcher Matcher
+}
+
+// Blocklist evalúa las reglas de la lista de bloqueo. Es inmutable y se puede
+// usar desde varias goroutines.
+type Blocklist struct {
+	rules []compiledRule
+}
+
+// NewBlocklist compila las reglas. Las inválidas se descartan y se devuelven
+// en el error, junto con la lista formada por las demás: una regla rota no debe
+// desactivar toda la lista.
+func NewBlocklist(rules []Rule) (*Blocklist, error) {
+	b := &Blocklist{rules: make([]compiledRule, 0, len(rules))}
+	var errs []error
+	for _, r := range rules {
+		m, err := Compile(r.Kind, r.Pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("regla %s: %w", r.ID, err))
+			continue
+		}
+		b.rules = append(b.rules, compiledRule{id: r.ID, action: r.Action, matcher: m})
+	}
+	return b, errors.Join(errs...)
+}
+
+// Match devuelve las reglas que cumple el contenido
+func (b *Blocklist) Match(t Text) []Match {
+	if b == nil {
+		return nil
+	}
+	var matches []Match
+	for _, r := range b.rules {
+		if r.matcher.Match(t) {
+			matches = append(matches, Match{Rule: "blocklist:" + r.id, Action: r.action})
+		}
+	}
+	return matches
+}
+
+// MuteList son las palabras silenciadas de un usuario. Cada una es una palabra o
+// frase exacta, o un patrón con comodines si contiene * o ?.
+type MuteList struct {
+	matchers []Matcher
+}
+
+// CompileMuted valida una palabra silenciada
+func CompileMuted(word string) (Matcher, error) {
+	kind := KindExact
+	if strings.ContainsAny(word, "*?") {
+		kind = KindWildcard
+	}
+	return Compile(kind, word)
+}
+
+// NewMuteList prepara las palabras silenciadas; las inválidas se ignoran porque
+// ya se validaron al guardarlas
+func NewMuteList(words []string) *MuteList {
+	l := &MuteList{}
+	for _, w := range words {
+		if m, err := CompileMuted(w); err == nil {
+			l.matchers = append(l.matchers, m)
+		}
+	}
+	return l
+}
+
+// Empty indica que no hay palabras silenciadas
+func (l *MuteList) Empty() bool {
+	return len(l.matchers) == 0
+}
+
+// Match indica si el contenido tiene alguna palabra silenciada
+func (l *MuteList) Match(t Text) bool {
+	for _, m := range l.matchers {
+		if m.Match(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+// internal/policy/heuristics.go
+package policy
+
+import "time"
+
+// Nombres de las heurísticas de spam, usados como identificador de la regla
+const (
+	RuleDuplicate = "duplicate"
+	RuleLinks     = "links"
+	RuleBurst     = "burst"
+)
+
+// Heuristics son las heurísticas de spam. Cada una se desactiva dejando su
+// acción vacía.
+type Heuristics struct {
+	Duplicate Duplicate
+	Links     Links
+	Burst     Burst
+}
+
+// Duplicate detecta el mismo contenido publicado muchas veces, por la misma
+// cuenta o por varias
+type Duplicate struct {
+	Action Action
+	// Threshold es cuántos tweets iguales previos dentro de Window disparan la regla
+	Threshold int
+	Window    time.Duration
+}
+
+// Links detecta tweets con demasiados enlaces
+type Links struct {
+	Action Action
+	// Max es el número de enlaces permitido; más dispara la regla
+	Max int
+}
+
+// Burst detecta cuentas nuevas que publican muchos tweets seguidos
+type Burst struct {
+	Action Action
+	// AccountAge es la antigüedad por debajo de la cual una cuenta es nueva
+	AccountAge time.Duration
+	// MaxTweets es cuántos tweets previos dentro de Window disparan la regla
+	MaxTweets int
+	Window    time.Duration
+}
+
+// Signals son los datos sobre el tweet y su autor que usan las heurísticas. Los
+// conteos los obtiene quien evalúa, solo para las heurísticas activas.
+type Signals struct {
+	Links int
+	// Duplicates es cuántos tweets con el mismo contenido hay dentro de la ventana
+	Duplicates int64
+	// AccountAge es la antigüedad de la cuenta del autor
+	AccountAge time.Duration
+	// RecentTweets es cuántos tweets publicó el autor dentro de la ventana
+	RecentTweets int64
+}
+
+// DuplicateEnabled indica si hay que contar duplicados
+func (h Heuristics) DuplicateEnabled() bool {
+	return h.Duplicate.Action.Enabled() && h.Duplicate.Threshold > 0 && h.Duplicate.Window > 0
+}
+
+// BurstApplies indica si hay que contar los tweets recientes de una cuenta con
+// esa antigüedad
+func (h Heuristics) BurstApplies(accountAge time.Duration) bool {
+	return h.Burst.Action.Enabled() && h.Burst.MaxTweets > 0 && h.Burst.Window > 0 &&
+		accountAge < h.Burst.AccountAge
+}
+
+// Match devuelve las heurísticas que cumple el tweet
+func (h Heuristics) Match(s Signals) []Match {
+	var matches []Match
+	if h.DuplicateEnabled() && s.Duplicates >= int64(h.Duplicate.Threshold) {
+		matches = append(matches, Match{Rule: RuleDuplicate, Action: h.Duplicate.Action})
+	}
+	if h.Links.Action.Enabled() && s.Links > h.Links.Max {
+		matches = append(matches, Match{Rule: RuleLinks, Action: h.Links.Action})
+	}
+	if h.BurstApplies(s.AccountAge) && s.RecentTweets >= int64(h.Burst.MaxTweets) {
+		matches = append(matches, Match{Rule: RuleBurst, Action: h.Burst.Action})
+	}
+	return matches
+}
@@ -0,0 +1,244 @@
+// internal/policy/policy.go
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Action es lo que se hace con un tweet que cumple una regla. Las acciones se
+// ordenan de menor a mayor gravedad: sin acción, marcar, retener y rechazar.
+type Action string
+
+const (
+	// ActionNone desactiva la regla
+	ActionNone Action = ""
+	// ActionFlag publica el tweet y lo deja marcado para revisión
+	ActionFlag Action = "flag"
+	// ActionHold retiene el tweet sin publicarlo hasta que un moderador lo apruebe
+	ActionHold Action = "hold"
+	// ActionReject rechaza el tweet
+	ActionReject Action = "reject"
+)
+
+// ParseAction interpreta una acción; "" y "none" desactivan la regla
+func ParseAction(s string) (Action, error) {
+	switch a := Action(strings.ToLower(strings.TrimSpace(s))); a {
+	case ActionNone, "none":
+		return ActionNone, nil
+	case ActionFlag, ActionHold, ActionReject:
+		return a, nil
+	default:
+		return ActionNone, fmt.Errorf("acción inválida %q (none, flag, hold o reject)", s)
+	}
+}
+
+// UnmarshalText permite leer la acción de la configuración
+func (a *Action) UnmarshalText(text []byte) error {
+	parsed, err := ParseAction(string(text))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Enabled indica si la acción hace algo
+func (a Action) Enabled() bool {
+	return a.severity() > 0
+}
+
+func (a Action) severity() int {
+	switch a {
+	case ActionFlag:
+		return 1
+	case ActionHold:
+		return 2
+	case ActionReject:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Tipos de patrón de la lista de bloqueo
+const (
+	// KindExact coincide con una palabra o frase completa
+	KindExact = "exact"
+	// KindWildcard coincide palabra a palabra con comodines * y ?
+	KindWildcard = "wildcard"
+	// KindRegex es una expresión regular (RE2) sobre el contenido original
+	KindRegex = "regex"
+)
+
+// MaxPatternLength es la longitud máxima de un patrón
+const MaxPatternLength = 200
+
+// minHashLength es la longitud mínima del contenido normalizado para buscar
+// duplicados; los mensajes cortos ("gracias", "jaja") se repiten sin ser spam
+const minHashLength = 20
+
+// linkPattern reconoce los enlaces del contenido
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// Text es un contenido preparado para evaluar las reglas: el original y sus
+// palabras en minúsculas
+type Text struct {
+	raw   string
+	words []string
+}
+
+// NewText prepara un contenido para evaluarlo
+func NewText(content string) Text {
+	return Text{raw: content, words: words(content, false)}
+}
+
+// Hash identifica el contenido normalizado para detectar duplicados, sin
+// distinguir mayúsculas, espacios ni puntuación. Devuelve "" si el contenido es
+// demasiado corto para considerarlo.
+func (t Text) Hash() string {
+	normalized := strings.Join(t.words, " ")
+	if utf8.RuneCountInString(normalized) < minHashLength {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// Links cuenta los enlaces del contenido
+func (t Text) Links() int {
+	return len(linkPattern.FindAllStringIndex(t.raw, -1))
+}
+
+// words separa el contenido en palabras en minúsculas: letras, dígitos y _. Con
+// wildcard también se conservan los comodines * y ?.
+func words(s string, wildcard bool) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		if wildcard && (r == '*' || r == '?') {
+			return false
+		}
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
+
+// Matcher decide si un contenido cumple un patrón
+type Matcher interface {
+	Match(t Text) bool
+}
+
+// Compile valida un patrón y lo prepara para evaluarlo
+func Compile(kind, pattern string) (Matcher, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, fmt.Errorf("el patrón está vacío")
+	}
+	if len(pattern) > MaxPatternLength {
+		return nil, fmt.Errorf("el patrón supera los %d caracteres", MaxPatternLength)
+	}
+
+	switch kind {
+	case KindExact:
+		seq := words(pattern, false)
+		if len(seq) == 0 {
+			return nil, fmt.Errorf("el patrón no tiene palabras")
+		}
+		return sequence{words: seq, match: func(p, w string) bool { return p == w }}, nil
+	case KindWildcard:
+		seq := words(pattern, true)
+		if len(seq) == 0 {
+			return nil, fmt.Errorf("el patrón no tiene palabras")
+		}
+		for _, p := range seq {
+			if strings.Trim(p, "*?") == "" {
+				return nil, fmt.Errorf("%q coincide con cualquier palabra", p)
+			}
+		}
+		return sequence{words: seq, match: func(p, w string) bool {
+			ok, _ := path.Match(p, w)
+			return ok
+		}}, nil
+	case KindRegex:
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("expresión regular inválida: %w", err)
+		}
+		return regex{re}, nil
+	default:
+		return nil, fmt.Errorf("tipo de patrón desconocido %q", kind)
+	}
+}
+
+// sequence coincide con una secuencia de palabras consecutivas
+type sequence struct {
+	words []string
+	match func(pattern, word string) bool
+}
+
+func (s sequence) Match(t Text) bool {
+	for i := 0; i+len(s.words) <= len(t.words); i++ {
+		matched := true
+		for j, p := range s.words {
+			if !s.match(p, t.words[i+j]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+type regex struct {
+	re *regexp.Regexp
+}
+
+func (r regex) Match(t Text) bool {
+	return r.re.MatchString(t.raw)
+}
+
+// Match es una regla que cumplió el contenido
+type Match struct {
+	// Rule identifica la regla: "blocklist:<id>" o el nombre de la heurística
+	Rule   string
+	Action Action
+}
+
+// Decision es el resultado de evaluar un contenido: la acción más grave de las
+// reglas que cumplió
+type Decision struct {
+	Action  Action
+	Matches []Match
+}
+
+// Decide combina las reglas cumplidas en una decisión
+func Decide(matches ...[]Match) Decision {
+	var d Decision
+	for _, group := range matches {
+		for _, m := range group {
+			if !m.Action.Enabled() {
+				continue
+			}
+			d.Matches = append(d.Matches, m)
+			if m.Action.severity() > d.Action.severity() {
+				d.Action = m.Action
+			}
+		}
+	}
+	return d
+}
+
+// Rules devuelve los identificadores de las reglas cumplidas
+func (d Decision) Rules() []string {
+	rules := make([]string, len(d.Matches))
+	for i, m := range d.Matches {
+		rules[i] = m.Rule
+	}
+	return rules
+}
@@ -0,0 +1,122 @@
+// internal/policy/policy_test.go
+package policy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	cases := []struct {
+		name    string
+		kind    string
+		pattern string
+		content string
+		want    bool
+	}{
+		{"exact word", KindExact, "casino", "Gana en el CASINO hoy", true},
+		{"exact ignores substrings", KindExact, "casino", "casinos online", false},
+		{"exact phrase", KindExact, "dinero fácil", "¡Dinero   fácil! ya", true},
+		{"exact phrase in order", KindExact, "dinero fácil", "fácil dinero", false},
+		{"wildcard suffix", KindWildcard, "casin*", "los casinos online", true},
+		{"wildcard single char", KindWildcard, "v?agra", "compra viagra", true},
+		{"wildcard phrase", KindWildcard, "gana* dinero", "ganar dinero", true},
+		{"wildcard no match", KindWildcard, "casin*", "casa", false},
+		{"regex case insensitive", KindRegex, `bit\.ly/\w+`, "mira BIT.LY/abc", true},
+		{"regex no match", KindRegex, `^spam`, "no es spam", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := Compile(tc.kind, tc.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, m.Match(NewText(tc.content)))
+		})
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	for _, tc := range []struct{ kind, pattern string }{
+		{KindExact, ""},
+		{KindExact, "!!!"},
+		{KindWildcard, "*"},
+		{KindWildcard, "foo ??"},
+		{KindRegex, "(abc"},
+		{"prefix", "abc"},
+	} {
+		_, err := Compile(tc.kind, tc.pattern)
+		assert.Error(t, err, "%s %q", tc.kind, tc.pattern)
+	}
+}
+
+func TestText(t *testing.T) {
+	a := NewText("Compra YA en nuestra tienda online!!")
+	b := NewText("compra ya en   nuestra tienda online")
+	assert.NotEmpty(t, a.Hash())
+	assert.Equal(t, a.Hash(), b.Hash(), "ignora mayúsculas, espacios y puntuación")
+	assert.Empty(t, NewText("gracias!").Hash(), "los mensajes cortos no se comparan")
+
+	assert.Equal(t, 3, NewText("https://a.com http://b.com y www.c.com").Links())
+	assert.Equal(t, 0, NewText("sin enlaces").Links())
+}
+
+func TestBlocklist(t *testing.T) {
+	b, err := NewBlocklist([]Rule{
+		{ID: "1", Kind: KindExact, Pattern: "casino", Action: ActionFlag},
+		{ID: "2", Kind: KindRegex, Pattern: `gana \d+ euros`, Action: ActionReject},
+	})
+	require.NoError(t, err)
+
+	d := Decide(b.Match(NewText("casino: gana 100 euros")))
+	assert.Equal(t, ActionReject, d.Action, "gana la acción más grave")
+	assert.Equal(t, []string{"blocklist:1", "blocklist:2"}, d.Rules())
+
+	assert.Equal(t, ActionNone, Decide(b.Match(NewText("hola"))).Action)
+
+	partial, err := NewBlocklist([]Rule{
+		{ID: "3", Kind: KindRegex, Pattern: "("},
+		{ID: "4", Kind: KindExact, Pattern: "casino", Action: ActionHold},
+	})
+	assert.ErrorContains(t, err, "regla 3")
+	assert.Equal(t, ActionHold, Decide(partial.Match(NewText("casino"))).Action, "las reglas válidas se aplican")
+}
+
+func TestMuteList(t *testing.T) {
+	l := NewMuteList([]string{"spoiler", "final*"})
+	assert.True(t, l.Match(NewText("¡SPOILER del capítulo!")))
+	assert.True(t, l.Match(NewText("la finalísima")))
+	assert.False(t, l.Match(NewText("sin nada")))
+	assert.True(t, NewMuteList(nil).Empty())
+}
+
+func TestHeuristics(t *testing.T) {
+	h := Heuristics{
+		Duplicate: Duplicate{Action: ActionHold, Threshold: 3, Window: time.Hour},
+		Links:     Links{Action: ActionFlag, Max: 2},
+		Burst:     Burst{Action: ActionHold, AccountAge: 24 * time.Hour, MaxTweets: 5, Window: 10 * time.Minute},
+	}
+
+	assert.Empty(t, h.Match(Signals{Links: 2, Duplicates: 2, AccountAge: time.Hour, RecentTweets: 4}))
+
+	d := Decide(h.Match(Signals{Links: 3, AccountAge: time.Hour, RecentTweets: 5}))
+	assert.Equal(t, ActionHold, d.Action)
+	assert.Equal(t, []string{RuleLinks, RuleBurst}, d.Rules())
+
+	assert.False(t, h.BurstApplies(48*time.Hour), "solo cuentas nuevas")
+	assert.Equal(t, []string{RuleDuplicate}, Decide(h.Match(Signals{Duplicates: 3, AccountAge: 48 * time.Hour, RecentTweets: 50})).Rules())
+
+	h.Links.Action = ActionNone
+	assert.Empty(t, h.Match(Signals{Links: 10, AccountAge: 48 * time.Hour}), "una acción vacía desactiva la regla")
+}
+
+func TestParseAction(t *testing.T) {
+	for in, want := range map[string]Action{"": ActionNone, "none": ActionNone, "Hold": ActionHold, "reject": ActionReject} {
+		got, err := ParseAction(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	_, err := ParseAction("delete")
+	assert.Error(t, err)
+}
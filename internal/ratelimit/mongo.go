@@ -0,0 +1,74 @@
+// internal/ratelimit/mongo.go
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoStore guarda los buckets en MongoDB para que todas las réplicas compartan
+// la cuenta. Cada Take es una única actualización atómica con upsert.
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoStore(client *mongo.Client, dbName string) *MongoStore {
+	return &MongoStore{collection: client.Database(dbName).Collection("rate_limits")}
+}
+
+// EnsureIndexes crea el índice TTL que borra los buckets que ya se rellenaron
+func (s *MongoStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return fmt.Errorf("error al crear índice de rate limits: %w", err)
+	}
+	return nil
+}
+
+type mongoBucket struct {
+	Tokens  float64 `bson:"tokens"`
+	Allowed bool    `bson:"allowed"`
+}
+
+func (s *MongoStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	capacity := float64(limit.Requests)
+	perMilli := limit.rate() / 1000
+
+	// El pipeline rellena el bucket según el tiempo transcurrido, decide y
+	// descuenta en el servidor, así dos réplicas nunca consumen el mismo token
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"tokens": bson.M{"$min": bson.A{capacity, bson.M{"$add": bson.A{
+				bson.M{"$ifNull": bson.A{"$tokens", capacity}},
+				bson.M{"$multiply": bson.A{
+					bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$updated_at", now}}}}}},
+					perMilli,
+				}},
+			}}}},
+			"updated_at": now,
+		}}},
+		{{Key: "$set", Value: bson.M{"allowed": bson.M{"$gte": bson.A{"$tokens", 1}}}}},
+		{{Key: "$set", Value: bson.M{
+			"tokens": bson.M{"$cond": bson.A{"$allowed", bson.M{"$subtract": bson.A{"$tokens", 1}}, "$tokens"}},
+			// Pasado este momento el bucket estaría lleno y equivale a uno nuevo
+			"expires_at": now.Add(limit.Period),
+		}}},
+	}
+
+	var b mongoBucket
+	err := s.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, pipeline,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&b)
+	if err != nil {
+		return Result{}, fmt.Errorf("error al consumir rate limit: %w", err)
+	}
+	return result(limit, b.Tokens, b.Allowed), nil
+}
@@ -0,0 +1,188 @@
+// internal/ratelimit/ratelimit.go
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit es un token bucket: admite ráfagas de hasta Requests peticiones y se
+// rellena a razón de Requests por Period. El valor cero desactiva el límite.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+var periods = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// ParseLimit interpreta límites como "30/m", "5/s" o "1000/h"; "" y "0" desactivan el límite
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return Limit{}, nil
+	}
+	n, unit, ok := strings.Cut(s, "/")
+	requests, err := strconv.Atoi(n)
+	period, known := periods[unit]
+	if !ok || err != nil || requests <= 0 || !known {
+		return Limit{}, fmt.Errorf("límite inválido %q (ejemplos: 30/m, 5/s, 1000/h)", s)
+	}
+	return Limit{Requests: requests, Period: period}, nil
+}
+
+// Enabled indica si el límite se aplica
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "0"
+	}
+	for unit, d := range periods {
+		if d == l.Period {
+			return fmt.Sprintf("%d/%s", l.Requests, unit)
+		}
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// MarshalText permite escribir el límite en la configuración como "30/m"
+func (l Limit) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText permite leer el límite de la configuración
+func (l *Limit) UnmarshalText(text []byte) error {
+	parsed, err := ParseLimit(string(text))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
+// rate devuelve los tokens que se recuperan por segundo
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result es la decisión sobre una petición
+type Result struct {
+	Allowed bool
+	// Limit es el tamaño del bucket
+	Limit int
+	// Remaining son las peticiones que quedan disponibles ahora
+	Remaining int
+	// Reset es cuánto falta para que el bucket esté lleno de nuevo
+	Reset time.Duration
+	// RetryAfter es cuánto esperar hasta la próxima petición admitida; cero si se admitió
+	RetryAfter time.Duration
+}
+
+// Store guarda los buckets. Take consume un token de key si hay disponible.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// result calcula la decisión a partir de los tokens que quedan en el bucket
+func result(limit Limit, tokens float64, allowed bool) Result {
+	rate := limit.rate()
+	r := Result{
+		Allowed:   allowed,
+		Limit:     limit.Requests,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     secondsToDuration((float64(limit.Requests) - tokens) / rate),
+	}
+	if !allowed {
+		r.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+	return r
+}
+
+// refill devuelve los tokens del bucket tras el tiempo transcurrido
+func refill(limit Limit, tokens float64, elapsed time.Duration) float64 {
+	if elapsed > 0 {
+		tokens += elapsed.Seconds() * limit.rate()
+	}
+	return math.Min(tokens, float64(limit.Requests))
+}
+
+func secondsToDuration(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return time.Duration(s * float64(time.Second))
+}
+
+// Rule asigna un límite a un grupo de rutas. Routes son plantillas de Gin
+// (/api/v1/tweets); las terminadas en * cubren todo lo que empieza así.
+type Rule struct {
+	// Name identifica al grupo; cada grupo tiene sus propios buckets
+	Name    string
+	Methods []string
+	Routes  []string
+	Limit   Limit
+}
+
+func (r Rule) matches(method, route string) bool {
+	if len(r.Methods) > 0 && !contains(r.Methods, method) {
+		return false
+	}
+	for _, pattern := range r.Routes {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(route, prefix) {
+				return true
+			}
+		} else if route == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Limiter aplica la primera regla que coincide con cada petición
+type Limiter struct {
+	store Store
+	rules []Rule
+	now   func() time.Time
+}
+
+func NewLimiter(store Store, rules ...Rule) *Limiter {
+	return &Limiter{store: store, rules: rules, now: time.Now}
+}
+
+// Rule devuelve la regla que se aplica a la ruta, o nil si ninguna la limita
+func (l *Limiter) Rule(method, route string) *Rule {
+	for i := range l.rules {
+		if l.rules[i].matches(method, route) {
+			if !l.rules[i].Limit.Enabled() {
+				return nil
+			}
+			return &l.rules[i]
+		}
+	}
+	return nil
+}
+
+// Allow consume un token del bucket de client en el grupo de la regla
+func (l *Limiter) Allow(ctx context.Context, rule *Rule, client string) (Result, error) {
+	return l.store.Take(ctx, rule.Name+":"+client, rule.Limit, l.now())
+}
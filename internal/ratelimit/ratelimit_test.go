@@ -0,0 +1,117 @@
+// internal/ratelimit/ratelimit_test.go
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Limit
+	}{
+		{"30/m", Limit{Requests: 30, Period: time.Minute}},
+		{"5/s", Limit{Requests: 5, Period: time.Second}},
+		{" 1000/h ", Limit{Requests: 1000, Period: time.Hour}},
+		{"0", Limit{}},
+		{"", Limit{}},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, in := range []string{"30", "30/d", "-1/m", "x/m", "0/m"} {
+		_, err := ParseLimit(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestLimit_String(t *testing.T) {
+	assert.Equal(t, "30/m", Limit{Requests: 30, Period: time.Minute}.String())
+	assert.Equal(t, "0", Limit{}.String())
+	assert.False(t, Limit{}.Enabled())
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	limit := Limit{Requests: 2, Period: time.Minute}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	r, err := store.Take(ctx, "k", limit, now)
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 2, r.Limit)
+	assert.Equal(t, 1, r.Remaining)
+	assert.Equal(t, 30*time.Second, r.Reset)
+
+	r, _ = store.Take(ctx, "k", limit, now)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+
+	r, _ = store.Take(ctx, "k", limit, now.Add(10*time.Second))
+	assert.False(t, r.Allowed, "el bucket está vacío")
+	assert.Equal(t, 20*time.Second, r.RetryAfter)
+
+	// Otra clave tiene su propio bucket
+	r, _ = store.Take(ctx, "otra", limit, now)
+	assert.True(t, r.Allowed)
+
+	// Pasados 30s se recupera un token
+	r, _ = store.Take(ctx, "k", limit, now.Add(30*time.Second))
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+}
+
+func TestMemoryStore_SweepsFullBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	limit := Limit{Requests: 10, Period: time.Minute}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	_, _ = store.Take(ctx, "a", limit, now)
+	_, _ = store.Take(ctx, "b", limit, now)
+	require.Equal(t, 2, store.Len())
+
+	// En el siguiente barrido los dos buckets ya se rellenaron
+	_, _ = store.Take(ctx, "c", limit, now.Add(2*time.Minute))
+	assert.Equal(t, 1, store.Len())
+}
+
+func TestLimiter_Rule(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(),
+		Rule{Name: "tweets", Methods: []string{"POST"}, Routes: []string{"/api/v1/tweets"}, Limit: Limit{Requests: 1, Period: time.Minute}},
+		Rule{Name: "media", Methods: []string{"POST"}, Routes: []string{"/api/v1/media"}},
+		Rule{Name: "write", Methods: []string{"POST", "DELETE"}, Routes: []string{"/api/v1/*"}, Limit: Limit{Requests: 10, Period: time.Minute}},
+	)
+
+	assert.Equal(t, "tweets", limiter.Rule("POST", "/api/v1/tweets").Name)
+	assert.Equal(t, "write", limiter.Rule("POST", "/api/v1/users").Name)
+	assert.Equal(t, "write", limiter.Rule("DELETE", "/api/v1/users/:id/scheduled-tweets/:scheduled_id").Name)
+	assert.Nil(t, limiter.Rule("POST", "/api/v1/media"), "la primera regla que coincide está desactivada")
+	assert.Nil(t, limiter.Rule("GET", "/api/v1/tweets"))
+	assert.Nil(t, limiter.Rule("POST", "/inbox"))
+}
+
+func TestLimiter_AllowSeparatesRules(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore(),
+		Rule{Name: "a", Routes: []string{"/a"}, Limit: Limit{Requests: 1, Period: time.Minute}},
+		Rule{Name: "b", Routes: []string{"/b"}, Limit: Limit{Requests: 1, Period: time.Minute}},
+	)
+	ctx := context.Background()
+
+	r, err := limiter.Allow(ctx, limiter.Rule("GET", "/a"), "ip:1.2.3.4")
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+	r, _ = limiter.Allow(ctx, limiter.Rule("GET", "/a"), "ip:1.2.3.4")
+	assert.False(t, r.Allowed)
+	r, _ = limiter.Allow(ctx, limiter.Rule("GET", "/b"), "ip:1.2.3.4")
+	assert.True(t, r.Allowed, "cada regla tiene sus propios buckets")
+}
@@ -0,0 +1,189 @@
+// internal/rbac/rbac.go
+package rbac
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+)
+
+// Permission es un permiso con la forma recurso:acción[:alcance]. Un permiso
+// concedido como "recurso:*" cubre todas las acciones del recurso y "*" cubre todo.
+type Permission string
+
+const (
+	All Permission = "*"
+
+	TweetsDeleteAny Permission = "tweets:delete:any"
+	TweetsHide      Permission = "tweets:hide"
+	UsersSuspend    Permission = "users:suspend"
+	UsersDelete     Permission = "users:delete"
+	ReportsRead     Permission = "reports:read"
+	ReportsResolve  Permission = "reports:resolve"
+	RolesManage     Permission = "roles:manage"
+	AuditRead       Permission = "audit:read"
+	PolicyManage    Permission = "policy:manage"
+	PolicyReview    Permission = "policy:review"
+)
+
+// Roles predefinidos. Los usuarios sin roles no tienen permisos especiales.
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+)
+
+var (
+	ErrUnauthenticated  = apperr.Unauthorized("authentication_required", "esta operación requiere identificarse")
+	ErrPermissionDenied = apperr.Forbidden("permission_denied", "no tienes permiso para esta operación")
+	ErrUnknownRole      = apperr.InvalidField("unknown_role", "roles", "rol desconocido")
+	ErrUnknownPerm      = apperr.InvalidField("unknown_permission", "permissions", "permiso desconocido")
+)
+
+// permissions son los permisos que existen; los concedidos deben ser uno de
+// estos, un comodín de recurso o All
+var permissions = []Permission{TweetsDeleteAny, TweetsHide, UsersSuspend, UsersDelete, ReportsRead, ReportsResolve, RolesManage, AuditRead, PolicyManage, PolicyReview}
+
+// roles son los permisos de cada rol
+var roles = map[string][]Permission{
+	RoleAdmin:     {All},
+	RoleModerator: {ReportsRead, ReportsResolve, TweetsHide, TweetsDeleteAny, UsersSuspend, PolicyReview},
+}
+
+// Roles devuelve los roles definidos con sus permisos
+func Roles() map[string][]Permission {
+	out := make(map[string][]Permission, len(roles))
+	for name, perms := range roles {
+		out[name] = append([]Permission(nil), perms...)
+	}
+	return out
+}
+
+// Permissions devuelve los permisos definidos, ordenados
+func Permissions() []Permission {
+	out := append([]Permission(nil), permissions...)
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// ValidateRoles comprueba que todos los roles existan
+func ValidateRoles(names []string) error {
+	for _, name := range names {
+		if _, ok := roles[name]; !ok {
+			return ErrUnknownRole.WithParam("role", name)
+		}
+	}
+	return nil
+}
+
+// ValidatePermissions comprueba que los permisos concedidos directamente existan
+func ValidatePermissions(granted []string) error {
+	for _, g := range granted {
+		if !knownGrant(Permission(g)) {
+			return ErrUnknownPerm.WithParam("permission", g)
+		}
+	}
+	return nil
+}
+
+func knownGrant(g Permission) bool {
+	if g == All {
+		return true
+	}
+	for _, p := range permissions {
+		if covers(g, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// covers indica si el permiso concedido g incluye a p
+func covers(g, p Permission) bool {
+	if g == All || g == p {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(string(g), "*")
+	return ok && strings.HasSuffix(prefix, ":") && strings.HasPrefix(string(p), prefix)
+}
+
+// Principal es quien hace la petición, con sus roles y los permisos que tiene
+// concedidos además de los de sus roles
+type Principal struct {
+	UserID      string
+	Roles       []string
+	Permissions []string
+}
+
+// Can indica si el principal tiene el permiso p por alguno de sus roles o por
+// una concesión directa
+func (p *Principal) Can(perm Permission) bool {
+	if p == nil {
+		return false
+	}
+	for _, role := range p.Roles {
+		for _, g := range roles[role] {
+			if covers(g, perm) {
+				return true
+			}
+		}
+	}
+	for _, g := range p.Permissions {
+		if covers(Permission(g), perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole indica si el principal tiene el rol
+func (p *Principal) HasRole(role string) bool {
+	if p == nil {
+		return false
+	}
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Require devuelve ErrUnauthenticated sin principal y ErrPermissionDenied si le
+// falta alguno de los permisos
+func Require(p *Principal, perms ...Permission) error {
+	if p == nil {
+		return ErrUnauthenticated
+	}
+	for _, perm := range perms {
+		if !p.Can(perm) {
+			return ErrPermissionDenied.WithParam("permission", perm)
+		}
+	}
+	return nil
+}
+
+// CheckGrant comprueba que actor tenga todos los permisos que otorgan los roles y
+// permisos a conceder, para que nadie pueda dar más de lo que tiene. Conceder All
+// (el rol admin) exige tener All, que incluye también los permisos futuros.
+func CheckGrant(actor *Principal, roleNames, granted []string) error {
+	grants := make([]Permission, 0, len(granted))
+	for _, name := range roleNames {
+		grants = append(grants, roles[name]...)
+	}
+	for _, g := range granted {
+		grants = append(grants, Permission(g))
+	}
+
+	for _, g := range grants {
+		if g == All && !actor.Can(All) {
+			return ErrPermissionDenied.WithParam("permission", All)
+		}
+		for _, p := range permissions {
+			if covers(g, p) && !actor.Can(p) {
+				return ErrPermissionDenied.WithParam("permission", p)
+			}
+		}
+	}
+	return nil
+}
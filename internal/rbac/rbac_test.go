@@ -0,0 +1,58 @@
+// internal/rbac/rbac_test.go
+package rbac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipal_Can(t *testing.T) {
+	admin := &Principal{UserID: "a", Roles: []string{RoleAdmin}}
+	moderator := &Principal{UserID: "m", Roles: []string{RoleModerator}}
+	helper := &Principal{UserID: "h", Permissions: []string{"reports:*"}}
+	user := &Principal{UserID: "u"}
+
+	for _, p := range Permissions() {
+		assert.True(t, admin.Can(p), "admin tiene %s", p)
+	}
+
+	assert.True(t, moderator.Can(ReportsResolve))
+	assert.True(t, moderator.Can(TweetsDeleteAny))
+	assert.False(t, moderator.Can(RolesManage))
+
+	assert.True(t, helper.Can(ReportsRead))
+	assert.True(t, helper.Can(ReportsResolve))
+	assert.False(t, helper.Can(TweetsHide))
+
+	assert.False(t, user.Can(ReportsRead))
+	assert.False(t, (*Principal)(nil).Can(ReportsRead))
+}
+
+func TestRequire(t *testing.T) {
+	assert.ErrorIs(t, Require(nil, ReportsRead), ErrUnauthenticated)
+
+	err := Require(&Principal{UserID: "m", Roles: []string{RoleModerator}}, ReportsRead, RolesManage)
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+
+	assert.NoError(t, Require(&Principal{UserID: "a", Roles: []string{RoleAdmin}}, RolesManage))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, ValidateRoles([]string{RoleAdmin, RoleModerator}))
+	assert.ErrorIs(t, ValidateRoles([]string{"root"}), ErrUnknownRole)
+
+	assert.NoError(t, ValidatePermissions([]string{"tweets:delete:any", "reports:*", "*"}))
+	assert.ErrorIs(t, ValidatePermissions([]string{"tweets:fly"}), ErrUnknownPerm)
+	assert.ErrorIs(t, ValidatePermissions([]string{"planets:*"}), ErrUnknownPerm)
+}
+
+func TestCheckGrant(t *testing.T) {
+	admin := &Principal{UserID: "a", Roles: []string{RoleAdmin}}
+	manager := &Principal{UserID: "r", Roles: []string{RoleModerator}, Permissions: []string{string(RolesManage)}}
+
+	assert.NoError(t, CheckGrant(admin, []string{RoleAdmin}, []string{"*"}))
+	assert.NoError(t, CheckGrant(manager, []string{RoleModerator}, []string{"reports:*"}))
+	assert.ErrorIs(t, CheckGrant(manager, []string{RoleAdmin}, nil), ErrPermissionDenied, "no puede conceder más de lo que tiene")
+	assert.ErrorIs(t, CheckGrant(manager, nil, []string{"*"}), ErrPermissionDenied)
+}
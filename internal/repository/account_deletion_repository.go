@@ -0,0 +1,161 @@
+// internal/repository/account_deletion_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrDeletionNotFound = apperr.NotFound("deletion_not_found", "no hay un borrado pedido para este usuario")
+	// ErrDeletionLeaseLost indica que otra instancia tomó el borrado
+	ErrDeletionLeaseLost = apperr.Conflict("deletion_lease_lost", "se perdió el lease del borrado de cuenta")
+)
+
+type AccountDeletionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAccountDeletionRepository(client *mongo.Client, dbName string) *AccountDeletionRepository {
+	collection := client.Database(dbName).Collection("account_deletions")
+	return &AccountDeletionRepository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes crea el índice único que deja un solo borrado por usuario y el
+// que usa el worker para encontrar los pendientes
+func (r *AccountDeletionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "requested_at", Value: 1}}},
+	})
+	if err != nil {
+		return dbError("error al crear índices de borrados de cuentas", err)
+	}
+	return nil
+}
+
+// Create registra el borrado si el usuario no tiene uno y devuelve el del
+// usuario, nuevo o existente; pedir dos veces el borrado no crea otro job
+func (r *AccountDeletionRepository) Create(ctx context.Context, deletion *models.AccountDeletion) (*models.AccountDeletion, error) {
+	existing, err := r.upsert(ctx, deletion)
+	if mongo.IsDuplicateKeyError(err) {
+		// Otro pedido simultáneo insertó el job; el segundo intento lo encuentra
+		existing, err = r.upsert(ctx, deletion)
+	}
+	if err != nil {
+		return nil, dbError("error al registrar borrado de cuenta", err)
+	}
+	return existing, nil
+}
+
+func (r *AccountDeletionRepository) upsert(ctx context.Context, deletion *models.AccountDeletion) (*models.AccountDeletion, error) {
+	var existing models.AccountDeletion
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"user_id": deletion.UserID},
+		bson.M{"$setOnInsert": bson.M{
+			"username":     deletion.Username,
+			"reason":       deletion.Reason,
+			"status":       models.DeletionStatusPending,
+			"step":         0,
+			"following":    deletion.Following,
+			"attempts":     0,
+			"requested_at": deletion.RequestedAt,
+		}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&existing)
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// GetByUserID obtiene el borrado de un usuario
+func (r *AccountDeletionRepository) GetByUserID(ctx context.Context, userID string) (*models.AccountDeletion, error) {
+	objectID, err := parseID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var deletion models.AccountDeletion
+	if err := r.collection.FindOne(ctx, bson.M{"user_id": objectID}).Decode(&deletion); err != nil {
+		return nil, findError("error al obtener borrado de cuenta", err, ErrDeletionNotFound)
+	}
+	return &deletion, nil
+}
+
+// ClaimPending toma el borrado pendiente más antiguo sin lease vigente y lo
+// reserva para owner hasta now+lease. Devuelve nil si no hay ninguno.
+func (r *AccountDeletionRepository) ClaimPending(ctx context.Context, owner string, now time.Time, lease time.Duration) (*models.AccountDeletion, error) {
+	var deletion models.AccountDeletion
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"status": models.DeletionStatusPending,
+			"$or": bson.A{
+				bson.M{"lease_until": bson.M{"$exists": false}},
+				bson.M{"lease_until": bson.M{"$lt": now}},
+			},
+		},
+		bson.M{
+			"$set": bson.M{"lease_owner": owner, "lease_until": now.Add(lease)},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "requested_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&deletion)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, dbError("error al reservar borrado de cuenta", err)
+	}
+	return &deletion, nil
+}
+
+// SaveStep guarda el último paso completado y renueva el lease hasta leaseUntil
+func (r *AccountDeletionRepository) SaveStep(ctx context.Context, id primitive.ObjectID, owner string, step int, leaseUntil time.Time) error {
+	return r.finish(ctx, id, owner, bson.M{"step": step, "lease_until": leaseUntil}, nil)
+}
+
+// Complete marca el borrado como terminado
+func (r *AccountDeletionRepository) Complete(ctx context.Context, id primitive.ObjectID, owner string, at time.Time) error {
+	return r.finish(ctx, id, owner, bson.M{
+		"status":       models.DeletionStatusDone,
+		"completed_at": at,
+	}, bson.M{"lease_owner": "", "lease_until": "", "last_error": "", "following": ""})
+}
+
+// Release libera el lease tras un fallo; no se reintenta antes de retryAt
+func (r *AccountDeletionRepository) Release(ctx context.Context, id primitive.ObjectID, owner string, reason string, retryAt time.Time) error {
+	return r.finish(ctx, id, owner, bson.M{
+		"last_error":  reason,
+		"lease_until": retryAt,
+	}, bson.M{"lease_owner": ""})
+}
+
+func (r *AccountDeletionRepository) finish(ctx context.Context, id primitive.ObjectID, owner string, set, unset bson.M) error {
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "lease_owner": owner}, update)
+	if err != nil {
+		return dbError("error al actualizar borrado de cuenta", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrDeletionLeaseLost
+	}
+	return nil
+}
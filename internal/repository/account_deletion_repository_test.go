@@ -0,0 +1,66 @@
+// internal/repository/account_deletion_repository_test.go
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAccountDeletionRepository(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+	defer client.Database("test_db").Collection("account_deletions").Drop(context.Background())
+
+	ctx := context.Background()
+	repo := NewAccountDeletionRepository(client, "test_db")
+	require.NoError(t, repo.EnsureIndexes(ctx))
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	userID := primitive.NewObjectID()
+
+	t.Run("one deletion per user", func(t *testing.T) {
+		first, err := repo.Create(ctx, &models.AccountDeletion{UserID: userID, Username: "ana", Reason: models.DeletionReasonAdmin, RequestedAt: now})
+		require.NoError(t, err)
+		assert.Equal(t, models.DeletionStatusPending, first.Status)
+
+		again, err := repo.Create(ctx, &models.AccountDeletion{UserID: userID, Username: "ana", Reason: models.DeletionReasonDeactivated, RequestedAt: now.Add(time.Hour)})
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, again.ID)
+		assert.Equal(t, models.DeletionReasonAdmin, again.Reason)
+
+		_, err = repo.GetByUserID(ctx, primitive.NewObjectID().Hex())
+		assert.ErrorIs(t, err, ErrDeletionNotFound)
+	})
+
+	t.Run("lease", func(t *testing.T) {
+		claimed, err := repo.ClaimPending(ctx, "a", now, time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		assert.Equal(t, 1, claimed.Attempts)
+
+		other, err := repo.ClaimPending(ctx, "b", now, time.Minute)
+		require.NoError(t, err)
+		assert.Nil(t, other, "el lease vigente impide que otra instancia lo tome")
+
+		assert.ErrorIs(t, repo.SaveStep(ctx, claimed.ID, "b", 1, now), ErrDeletionLeaseLost)
+		require.NoError(t, repo.SaveStep(ctx, claimed.ID, "a", 2, now.Add(time.Minute)))
+		require.NoError(t, repo.Release(ctx, claimed.ID, "a", "falló", now.Add(time.Hour)))
+
+		other, err = repo.ClaimPending(ctx, "b", now.Add(2*time.Hour), time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, other)
+		assert.Equal(t, 2, other.Step, "se retoma desde el último paso guardado")
+
+		require.NoError(t, repo.Complete(ctx, other.ID, "b", now))
+		done, err := repo.GetByUserID(ctx, userID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, models.DeletionStatusDone, done.Status)
+		assert.Empty(t, done.LastError)
+	})
+}
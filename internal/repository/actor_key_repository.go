@@ -0,0 +1,70 @@
+// internal/repository/actor_key_repository.go
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// KeyGenerator genera un par de claves en formato PEM (pública, privada)
+type KeyGenerator func() (publicPEM, privatePEM string, err error)
+
+type ActorKeyRepository struct {
+	collection *mongo.Collection
+	generate   KeyGenerator
+}
+
+func NewActorKeyRepository(client *mongo.Client, dbName string, generate KeyGenerator) *ActorKeyRepository {
+	collection := client.Database(dbName).Collection("actor_keys")
+	return &ActorKeyRepository{
+		collection: collection,
+		generate:   generate,
+	}
+}
+
+// GetOrCreate obtiene las claves del usuario, generándolas la primera vez que se piden
+func (r *ActorKeyRepository) GetOrCreate(ctx context.Context, userID string) (*models.ActorKey, error) {
+	objectID, err := parseID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var key models.ActorKey
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&key)
+	if err == nil {
+		return &key, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, dbError("error al obtener claves", err)
+	}
+
+	publicPEM, privatePEM, err := r.generate()
+	if err != nil {
+		return nil, fmt.Errorf("error al generar claves: %v", err)
+	}
+
+	key = models.ActorKey{
+		UserID:        objectID,
+		PublicKeyPEM:  publicPEM,
+		PrivateKeyPEM: privatePEM,
+		CreatedAt:     time.Now(),
+	}
+	if _, err := r.collection.InsertOne(ctx, key); err != nil {
+		// Otra petición generó las claves en paralelo: usar las ya guardadas
+		if mongo.IsDuplicateKeyError(err) {
+			var existing models.ActorKey
+			if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&existing); err != nil {
+				return nil, dbError("error al obtener claves", err)
+			}
+			return &existing, nil
+		}
+		return nil, dbError("error al guardar claves", err)
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,57 @@
+// internal/repository/actor_key_repository_test.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestActorKeyRepository_GetOrCreate(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+	defer client.Database("test_db").Collection("actor_keys").Drop(context.Background())
+
+	generated := 0
+	repo := NewActorKeyRepository(client, "test_db", func() (string, string, error) {
+		generated++
+		return "public-pem", "private-pem", nil
+	})
+	ctx := context.Background()
+	userID := primitive.NewObjectID().Hex()
+
+	t.Run("generates keys on first access", func(t *testing.T) {
+		key, err := repo.GetOrCreate(ctx, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, "public-pem", key.PublicKeyPEM)
+		assert.Equal(t, "private-pem", key.PrivateKeyPEM)
+		assert.Equal(t, 1, generated)
+	})
+
+	t.Run("reuses stored keys", func(t *testing.T) {
+		key, err := repo.GetOrCreate(ctx, userID)
+		assert.NoError(t, err)
+		assert.Equal(t, "public-pem", key.PublicKeyPEM)
+		assert.Equal(t, 1, generated)
+	})
+
+	t.Run("generator error", func(t *testing.T) {
+		failing := NewActorKeyRepository(client, "test_db", func() (string, string, error) {
+			return "", "", errors.New("sin entropía")
+		})
+		key, err := failing.GetOrCreate(ctx, primitive.NewObjectID().Hex())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "error al generar claves")
+		assert.Nil(t, key)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		key, err := repo.GetOrCreate(ctx, "invalid-id")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ID de usuario inválido")
+		assert.Nil(t, key)
+	})
+}
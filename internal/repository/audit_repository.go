@@ -0,0 +1,253 @@
+// internal/repository/audit_repository.go
+package repository
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrAuditContention indica que no se pudo encadenar el registro porque otras
+// escrituras ocupaban la misma posición
+var ErrAuditContention = apperr.Unavailable("audit_contention", "el registro de auditoría está ocupado, inténtalo de nuevo")
+
+// ErrAuditNotReady indica que aún no existe el índice único de la posición en la
+// cadena; sin él dos registros podrían ocupar la misma posición
+var ErrAuditNotReady = apperr.Unavailable("audit_not_ready", "la auditoría aún no está lista, inténtalo de nuevo")
+
+const (
+	// maxAuditAppendAttempts es cuántas veces se reintenta encadenar un registro
+	maxAuditAppendAttempts = 10
+	// defaultAuditLimit es el tamaño de página de la consulta de auditoría
+	defaultAuditLimit = 50
+	// auditCheckpointID es el documento del punto de control de la cadena
+	auditCheckpointID = "head"
+)
+
+// AuditRepository guarda la auditoría. Solo añade registros: no tiene
+// operaciones para modificarlos ni borrarlos. La cabeza de la cadena se guarda
+// además en otra colección para detectar que se borraron los últimos registros.
+type AuditRepository struct {
+	collection  *mongo.Collection
+	checkpoints *mongo.Collection
+	// seqIndexReady indica que ya se confirmó el índice único de seq
+	seqIndexReady atomic.Bool
+}
+
+func NewAuditRepository(client *mongo.Client, dbName string) *AuditRepository {
+	db := client.Database(dbName)
+	return &AuditRepository{
+		collection:  db.Collection("audit_log"),
+		checkpoints: db.Collection("audit_checkpoints"),
+	}
+}
+
+// EnsureIndexes crea el índice único de la posición en la cadena, que impide
+// que dos registros la compartan, y los de los filtros de la consulta
+func (r *AuditRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "seq", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "action", Value: 1}, {Key: "seq", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "seq", Value: -1}}},
+		{Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "seq", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: 1}}},
+	})
+	if err != nil {
+		return dbError("error al crear índices de auditoría", err)
+	}
+	r.seqIndexReady.Store(true)
+	return nil
+}
+
+// requireSeqIndex comprueba que exista el índice único de seq, del que depende
+// Append para no repetir posiciones. Una vez confirmado no se vuelve a consultar.
+func (r *AuditRepository) requireSeqIndex(ctx context.Context) error {
+	if r.seqIndexReady.Load() {
+		return nil
+	}
+	cursor, err := r.collection.Indexes().List(ctx)
+	if err != nil {
+		return dbError("error al consultar índices de auditoría", err)
+	}
+	defer cursor.Close(ctx)
+
+	var specs []struct {
+		Key    bson.D `bson:"key"`
+		Unique bool   `bson:"unique"`
+	}
+	if err := cursor.All(ctx, &specs); err != nil {
+		return dbError("error al decodificar índices de auditoría", err)
+	}
+	for _, spec := range specs {
+		if spec.Unique && len(spec.Key) == 1 && spec.Key[0].Key == "seq" {
+			r.seqIndexReady.Store(true)
+			return nil
+		}
+	}
+	return ErrAuditNotReady
+}
+
+// Append encadena el registro al último: le asigna la posición siguiente, el hash
+// del anterior y el suyo. Si otra instancia ocupa la posición a la vez, el índice
+// único rechaza la inserción y se vuelve a encadenar sobre el nuevo último; por
+// eso Append se niega a escribir mientras ese índice no exista. Después avanza
+// el punto de control hasta el registro.
+func (r *AuditRepository) Append(ctx context.Context, entry *models.AuditEntry) error {
+	if err := r.requireSeqIndex(ctx); err != nil {
+		return err
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	entry.CreatedAt = entry.CreatedAt.UTC().Truncate(time.Millisecond)
+	// Un mapa vacío no se guarda y se lee como nil; el hash debe ser el mismo
+	if len(entry.Metadata) == 0 {
+		entry.Metadata = nil
+	}
+
+	for attempt := 0; attempt < maxAuditAppendAttempts; attempt++ {
+		last, err := r.last(ctx)
+		if err != nil {
+			return err
+		}
+		entry.Seq, entry.PrevHash = 1, ""
+		if last != nil {
+			entry.Seq, entry.PrevHash = last.Seq+1, last.Hash
+		}
+		entry.Hash = entry.ComputeHash()
+
+		result, err := r.collection.InsertOne(ctx, entry)
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		if err != nil {
+			return dbError("error al guardar registro de auditoría", err)
+		}
+		entry.ID = result.InsertedID.(primitive.ObjectID)
+		return r.advanceCheckpoint(ctx, entry)
+	}
+	return ErrAuditContention
+}
+
+// advanceCheckpoint mueve el punto de control hasta entry si está por detrás.
+// Con escrituras concurrentes puede haber avanzado ya más allá: el filtro no
+// coincide, el upsert choca con el documento existente y no hay nada que hacer.
+func (r *AuditRepository) advanceCheckpoint(ctx context.Context, entry *models.AuditEntry) error {
+	_, err := r.checkpoints.UpdateOne(ctx,
+		bson.M{"_id": auditCheckpointID, "seq": bson.M{"$lt": entry.Seq}},
+		bson.M{"$set": bson.M{"seq": entry.Seq, "hash": entry.Hash, "updated_at": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return dbError("error al actualizar punto de control de auditoría", err)
+	}
+	return nil
+}
+
+// Checkpoint devuelve el punto de control de la cadena, nil si aún no hay
+func (r *AuditRepository) Checkpoint(ctx context.Context) (*models.AuditCheckpoint, error) {
+	var checkpoint models.AuditCheckpoint
+	err := r.checkpoints.FindOne(ctx, bson.M{"_id": auditCheckpointID}).Decode(&checkpoint)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, dbError("error al obtener punto de control de auditoría", err)
+	}
+	return &checkpoint, nil
+}
+
+func (r *AuditRepository) last(ctx context.Context) (*models.AuditEntry, error) {
+	var entry models.AuditEntry
+	err := r.collection.FindOne(ctx, bson.M{},
+		options.FindOne().SetSort(bson.D{{Key: "seq", Value: -1}}),
+	).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, dbError("error al obtener último registro de auditoría", err)
+	}
+	return &entry, nil
+}
+
+// List devuelve los registros que cumplen los filtros, del más reciente al más
+// antiguo
+func (r *AuditRepository) List(ctx context.Context, query models.AuditQuery) ([]models.AuditEntry, error) {
+	filter := bson.M{}
+	for field, value := range map[string]string{
+		"action":      query.Action,
+		"actor_id":    query.ActorID,
+		"target_type": query.TargetType,
+		"target_id":   query.TargetID,
+	} {
+		if value != "" {
+			filter[field] = value
+		}
+	}
+	if query.Before > 0 {
+		filter["seq"] = bson.M{"$lt": query.Before}
+	}
+	if query.From != nil || query.To != nil {
+		createdAt := bson.M{}
+		if query.From != nil {
+			createdAt["$gte"] = *query.From
+		}
+		if query.To != nil {
+			createdAt["$lt"] = *query.To
+		}
+		filter["created_at"] = createdAt
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+
+	cursor, err := r.collection.Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "seq", Value: -1}}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, dbError("error al consultar auditoría", err)
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.AuditEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, dbError("error al decodificar auditoría", err)
+	}
+	return entries, nil
+}
+
+// Walk recorre todos los registros en orden de la cadena y llama a fn con cada
+// uno; se detiene en el primer error de fn
+func (r *AuditRepository) Walk(ctx context.Context, fn func(entry *models.AuditEntry) error) error {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "seq", Value: 1}}))
+	if err != nil {
+		return dbError("error al recorrer auditoría", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var entry models.AuditEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return dbError("error al decodificar auditoría", err)
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return dbError("error al recorrer auditoría", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// internal/repository/audit_repository_test.go
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRepository(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	for _, name := range []string{"audit_log", "audit_checkpoints"} {
+		collection := client.Database("test_db").Collection(name)
+		require.NoError(t, collection.Drop(ctx))
+		defer collection.Drop(context.Background())
+	}
+
+	repo := NewAuditRepository(client, "test_db")
+	t.Run("append refuses to write without the seq index", func(t *testing.T) {
+		err := repo.Append(ctx, &models.AuditEntry{Action: models.AuditUserDeleted, TargetType: models.AuditTargetUser})
+		assert.ErrorIs(t, err, ErrAuditNotReady)
+		checkpoint, err := repo.Checkpoint(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, checkpoint)
+	})
+	require.NoError(t, repo.EnsureIndexes(ctx))
+
+	t.Run("another instance confirms the existing index", func(t *testing.T) {
+		other := NewAuditRepository(client, "test_db")
+		assert.NoError(t, other.requireSeqIndex(ctx))
+	})
+
+	t.Run("concurrent appends form a single chain", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, repo.Append(ctx, &models.AuditEntry{
+					Action:     models.AuditUserSuspended,
+					ActorID:    "admin",
+					TargetType: models.AuditTargetUser,
+					TargetID:   "u1",
+					After:      models.AuditState(`{"suspension":{"reason":"spam"}}`),
+					Metadata:   map[string]string{},
+				}))
+			}()
+		}
+		wg.Wait()
+
+		var prev string
+		var seq int64
+		require.NoError(t, repo.Walk(ctx, func(entry *models.AuditEntry) error {
+			seq++
+			assert.Equal(t, seq, entry.Seq)
+			assert.Equal(t, prev, entry.PrevHash)
+			assert.Equal(t, entry.ComputeHash(), entry.Hash, "el hash se recalcula igual tras leer de MongoDB")
+			prev = entry.Hash
+			return nil
+		}))
+		assert.Equal(t, int64(8), seq)
+
+		checkpoint, err := repo.Checkpoint(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, checkpoint)
+		assert.Equal(t, int64(8), checkpoint.Seq, "el punto de control nunca retrocede")
+		assert.Equal(t, prev, checkpoint.Hash)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		require.NoError(t, repo.Append(ctx, &models.AuditEntry{
+			Action:     models.AuditRolesChanged,
+			ActorID:    "root",
+			TargetType: models.AuditTargetUser,
+			TargetID:   "u2",
+		}))
+
+		entries, err := repo.List(ctx, models.AuditQuery{ActorID: "root"})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, int64(9), entries[0].Seq)
+
+		entries, err = repo.List(ctx, models.AuditQuery{Action: models.AuditUserSuspended, Before: 5, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, []int64{4, 3}, []int64{entries[0].Seq, entries[1].Seq})
+
+		future := time.Now().Add(time.Hour)
+		entries, err = repo.List(ctx, models.AuditQuery{From: &future})
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
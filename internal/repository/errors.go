@@ -0,0 +1,53 @@
+// internal/repository/errors.go
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Errores comunes de los repositorios. Los "no encontrado" envuelven
+// mongo.ErrNoDocuments, de modo que errors.Is sigue reconociendo ambos.
+var (
+	ErrInvalidID     = apperr.InvalidField("invalid_id", "id", "ID inválido")
+	ErrUserNotFound  = apperr.NotFound("user_not_found", "usuario no encontrado")
+	ErrUserExists    = apperr.Conflict("user_exists", "el nombre de usuario o el email ya están registrados")
+	ErrTweetNotFound = apperr.NotFound("tweet_not_found", "tweet no encontrado")
+	ErrMediaNotFound = apperr.NotFound("media_not_found", "archivo no encontrado")
+	ErrDuplicate     = apperr.Conflict("duplicate", "el recurso ya existe")
+	ErrUnavailable   = apperr.Unavailable("database_unavailable", "la base de datos no está disponible")
+)
+
+// dbError clasifica un error del driver: caídas y timeouts son ErrUnavailable y
+// claves duplicadas ErrDuplicate. El resto queda como error interno con el contexto op.
+func dbError(op string, err error) error {
+	wrapped := fmt.Errorf("%s: %w", op, err)
+	switch {
+	case mongo.IsTimeout(err), mongo.IsNetworkError(err), errors.Is(err, mongo.ErrClientDisconnected):
+		return ErrUnavailable.Wrap(wrapped)
+	case mongo.IsDuplicateKeyError(err):
+		return ErrDuplicate.Wrap(wrapped)
+	}
+	return wrapped
+}
+
+// findError es dbError para lecturas de un único documento: si no existe devuelve notFound
+func findError(op string, err error, notFound *apperr.Error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return notFound.Wrap(err)
+	}
+	return dbError(op, err)
+}
+
+// parseID convierte un ID hexadecimal; los IDs mal formados son ErrInvalidID
+func parseID(id string) (primitive.ObjectID, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, ErrInvalidID.Wrap(err)
+	}
+	return oid, nil
+}
@@ -0,0 +1,211 @@
+// internal/repository/export_job_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrExportNotFound = apperr.NotFound("export_not_found", "exportación no encontrada")
+	// ErrExportLeaseLost indica que otra instancia tomó la exportación o que se borró
+	ErrExportLeaseLost = apperr.Conflict("export_lease_lost", "se perdió el lease de la exportación")
+)
+
+type ExportJobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewExportJobRepository(client *mongo.Client, dbName string) *ExportJobRepository {
+	collection := client.Database(dbName).Collection("export_jobs")
+	return &ExportJobRepository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes crea el índice único que deja una sola exportación pendiente
+// por usuario y los que usa el worker para encontrar pendientes y vencidas
+func (r *ExportJobRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"status": models.ExportStatusPending,
+			}),
+		},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "requested_at", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}}},
+	})
+	if err != nil {
+		return dbError("error al crear índices de exportaciones", err)
+	}
+	return nil
+}
+
+// Create registra la exportación si el usuario no tiene una pendiente y
+// devuelve la pendiente, nueva o existente
+func (r *ExportJobRepository) Create(ctx context.Context, job *models.ExportJob) (*models.ExportJob, error) {
+	existing, err := r.upsert(ctx, job)
+	if mongo.IsDuplicateKeyError(err) {
+		// Otro pedido simultáneo insertó el job; el segundo intento lo encuentra
+		existing, err = r.upsert(ctx, job)
+	}
+	if err != nil {
+		return nil, dbError("error al registrar exportación", err)
+	}
+	return existing, nil
+}
+
+func (r *ExportJobRepository) upsert(ctx context.Context, job *models.ExportJob) (*models.ExportJob, error) {
+	var existing models.ExportJob
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"user_id": job.UserID, "status": models.ExportStatusPending},
+		bson.M{"$setOnInsert": bson.M{
+			"attempts":     0,
+			"requested_at": job.RequestedAt,
+		}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&existing)
+	if err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// GetByID obtiene una exportación
+func (r *ExportJobRepository) GetByID(ctx context.Context, id string) (*models.ExportJob, error) {
+	objectID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var job models.ExportJob
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&job); err != nil {
+		return nil, findError("error al obtener exportación", err, ErrExportNotFound)
+	}
+	return &job, nil
+}
+
+// ListByUser devuelve las exportaciones del usuario, de la más antigua a la más reciente
+func (r *ExportJobRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.ExportJob, error) {
+	return r.find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.D{{Key: "requested_at", Value: 1}}))
+}
+
+// ExpiredBefore devuelve hasta limit exportaciones terminadas cuyo archivo
+// venció antes de now
+func (r *ExportJobRepository) ExpiredBefore(ctx context.Context, now time.Time, limit int) ([]models.ExportJob, error) {
+	return r.find(ctx,
+		bson.M{"status": models.ExportStatusDone, "expires_at": bson.M{"$lt": now}},
+		options.Find().SetSort(bson.D{{Key: "expires_at", Value: 1}}).SetLimit(int64(limit)),
+	)
+}
+
+func (r *ExportJobRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.ExportJob, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, dbError("error al obtener exportaciones", err)
+	}
+	defer cursor.Close(ctx)
+
+	jobs := []models.ExportJob{}
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, dbError("error al decodificar exportaciones", err)
+	}
+	return jobs, nil
+}
+
+// ClaimPending toma la exportación pendiente más antigua sin lease vigente y
+// la reserva para owner hasta now+lease. Devuelve nil si no hay ninguna.
+func (r *ExportJobRepository) ClaimPending(ctx context.Context, owner string, now time.Time, lease time.Duration) (*models.ExportJob, error) {
+	var job models.ExportJob
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"status": models.ExportStatusPending,
+			"$or": bson.A{
+				bson.M{"lease_until": bson.M{"$exists": false}},
+				bson.M{"lease_until": bson.M{"$lt": now}},
+			},
+		},
+		bson.M{
+			"$set": bson.M{"lease_owner": owner, "lease_until": now.Add(lease)},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "requested_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, dbError("error al reservar exportación", err)
+	}
+	return &job, nil
+}
+
+// Complete marca la exportación como terminada con el archivo guardado en key
+func (r *ExportJobRepository) Complete(ctx context.Context, id primitive.ObjectID, owner, key string, size int64, at, expiresAt time.Time) error {
+	return r.finish(ctx, id, owner, bson.M{
+		"status":       models.ExportStatusDone,
+		"key":          key,
+		"size":         size,
+		"completed_at": at,
+		"expires_at":   expiresAt,
+	}, bson.M{"lease_owner": "", "lease_until": "", "last_error": ""})
+}
+
+// Release libera el lease tras un fallo; no se reintenta antes de retryAt
+func (r *ExportJobRepository) Release(ctx context.Context, id primitive.ObjectID, owner string, reason string, retryAt time.Time) error {
+	return r.finish(ctx, id, owner, bson.M{
+		"last_error":  reason,
+		"lease_until": retryAt,
+	}, bson.M{"lease_owner": ""})
+}
+
+// Fail marca la exportación como fallida; el usuario puede pedir otra
+func (r *ExportJobRepository) Fail(ctx context.Context, id primitive.ObjectID, owner string, reason string, at time.Time) error {
+	return r.finish(ctx, id, owner, bson.M{
+		"status":       models.ExportStatusFailed,
+		"last_error":   reason,
+		"completed_at": at,
+	}, bson.M{"lease_owner": "", "lease_until": ""})
+}
+
+func (r *ExportJobRepository) finish(ctx context.Context, id primitive.ObjectID, owner string, set, unset bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "lease_owner": owner}, bson.M{"$set": set, "$unset": unset})
+	if err != nil {
+		return dbError("error al actualizar exportación", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrExportLeaseLost
+	}
+	return nil
+}
+
+// MarkExpired marca la exportación como vencida una vez borrado su archivo
+func (r *ExportJobRepository) MarkExpired(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.ExportStatusDone},
+		bson.M{"$set": bson.M{"status": models.ExportStatusExpired}, "$unset": bson.M{"key": ""}},
+	)
+	if err != nil {
+		return dbError("error al vencer exportación", err)
+	}
+	return nil
+}
+
+// DeleteByUser borra todas las exportaciones del usuario; los archivos los
+// borra antes quien llama
+func (r *ExportJobRepository) DeleteByUser(ctx context.Context, userID primitive.ObjectID) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+		return dbError("error al borrar exportaciones del usuario", err)
+	}
+	return nil
+}
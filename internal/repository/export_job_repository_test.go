@@ -0,0 +1,103 @@
+// internal/repository/export_job_repository_test.go
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestExportJobRepository(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+	defer client.Database("test_db").Collection("export_jobs").Drop(context.Background())
+
+	ctx := context.Background()
+	repo := NewExportJobRepository(client, "test_db")
+	require.NoError(t, repo.EnsureIndexes(ctx))
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	userID := primitive.NewObjectID()
+
+	first, err := repo.Create(ctx, &models.ExportJob{UserID: userID, RequestedAt: now})
+	require.NoError(t, err)
+
+	t.Run("one pending export per user", func(t *testing.T) {
+		assert.Equal(t, models.ExportStatusPending, first.Status)
+		again, err := repo.Create(ctx, &models.ExportJob{UserID: userID, RequestedAt: now.Add(time.Minute)})
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, again.ID)
+		assert.Equal(t, now, again.RequestedAt)
+
+		_, err = repo.GetByID(ctx, primitive.NewObjectID().Hex())
+		assert.ErrorIs(t, err, ErrExportNotFound)
+	})
+
+	t.Run("lease", func(t *testing.T) {
+		claimed, err := repo.ClaimPending(ctx, "a", now, time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, claimed)
+		assert.Equal(t, 1, claimed.Attempts)
+
+		other, err := repo.ClaimPending(ctx, "b", now, time.Minute)
+		require.NoError(t, err)
+		assert.Nil(t, other, "el lease vigente impide que otra instancia la tome")
+
+		require.NoError(t, repo.Release(ctx, claimed.ID, "a", "falló", now.Add(time.Hour)))
+		other, err = repo.ClaimPending(ctx, "b", now.Add(2*time.Hour), time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, other)
+		assert.Equal(t, 2, other.Attempts)
+
+		assert.ErrorIs(t, repo.Complete(ctx, other.ID, "a", "k", 1, now, now), ErrExportLeaseLost)
+		require.NoError(t, repo.Complete(ctx, other.ID, "b", "exports/u/j.zip", 42, now, now.Add(time.Hour)))
+		done, err := repo.GetByID(ctx, other.ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, models.ExportStatusDone, done.Status)
+		assert.Equal(t, "exports/u/j.zip", done.Key)
+		assert.Equal(t, int64(42), done.Size)
+		assert.Empty(t, done.LastError)
+	})
+
+	t.Run("a finished export allows a new one", func(t *testing.T) {
+		next, err := repo.Create(ctx, &models.ExportJob{UserID: userID, RequestedAt: now})
+		require.NoError(t, err)
+		assert.NotEqual(t, first.ID, next.ID)
+
+		claimed, err := repo.ClaimPending(ctx, "a", now, time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, repo.Fail(ctx, claimed.ID, "a", "sin espacio", now))
+		failed, err := repo.GetByID(ctx, next.ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, models.ExportStatusFailed, failed.Status)
+
+		jobs, err := repo.ListByUser(ctx, userID)
+		require.NoError(t, err)
+		assert.Len(t, jobs, 2)
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		expired, err := repo.ExpiredBefore(ctx, now.Add(2*time.Hour), 10)
+		require.NoError(t, err)
+		require.Len(t, expired, 1)
+		assert.Equal(t, first.ID, expired[0].ID)
+
+		require.NoError(t, repo.MarkExpired(ctx, first.ID))
+		job, err := repo.GetByID(ctx, first.ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, models.ExportStatusExpired, job.Status)
+		assert.Empty(t, job.Key)
+	})
+
+	t.Run("delete by user", func(t *testing.T) {
+		require.NoError(t, repo.DeleteByUser(ctx, userID))
+		jobs, err := repo.ListByUser(ctx, userID)
+		require.NoError(t, err)
+		assert.Empty(t, jobs)
+	})
+}
@@ -0,0 +1,72 @@
+// internal/repository/import.go
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// insertNew inserta en un solo lote los documentos cuyos IDs (ids[i] es el de
+// docs[i]) todavía no existen, así que repetir un lote tras un fallo no duplica
+// nada. Devuelve las posiciones de los documentos que chocaron con un índice único.
+func insertNew(ctx context.Context, collection *mongo.Collection, op string, ids []primitive.ObjectID, docs []any) ([]int, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, dbError(op, err)
+	}
+	existing := make(map[primitive.ObjectID]bool)
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			cursor.Close(ctx)
+			return nil, dbError(op, err)
+		}
+		existing[doc.ID] = true
+	}
+	err = cursor.Err()
+	cursor.Close(ctx)
+	if err != nil {
+		return nil, dbError(op, err)
+	}
+
+	pending := make([]any, 0, len(docs))
+	positions := make([]int, 0, len(docs))
+	for i, doc := range docs {
+		if !existing[ids[i]] {
+			pending = append(pending, doc)
+			positions = append(positions, i)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	// Sin orden, un documento rechazado no impide insertar el resto del lote
+	_, err = collection.InsertMany(ctx, pending, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return nil, nil
+	}
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return nil, dbError(op, err)
+	}
+	var conflicts []int
+	for _, we := range bulkErr.WriteErrors {
+		if !mongo.IsDuplicateKeyError(we.WriteError) {
+			return nil, dbError(op, err)
+		}
+		conflicts = append(conflicts, positions[we.Index])
+	}
+	return conflicts, nil
+}
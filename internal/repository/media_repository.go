@@ -0,0 +1,90 @@
+// internal/repository/media_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MediaRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMediaRepository(client *mongo.Client, dbName string) *MediaRepository {
+	collection := client.Database(dbName).Collection("media")
+	return &MediaRepository{
+		collection: collection,
+	}
+}
+
+// Create guarda los metadatos de un archivo ya almacenado. Si media.ID está vacío se genera uno.
+func (r *MediaRepository) Create(ctx context.Context, media *models.Media) error {
+	if media.UserID.IsZero() {
+		return apperr.InvalidField("user_id_required", "user_id", "el ID de usuario es requerido")
+	}
+	if len(media.Variants) == 0 {
+		return apperr.InvalidField("media_variants_required", "variants", "el archivo no tiene variantes")
+	}
+
+	if media.ID.IsZero() {
+		media.ID = primitive.NewObjectID()
+	}
+	media.CreatedAt = time.Now()
+
+	if _, err := r.collection.InsertOne(ctx, media); err != nil {
+		return dbError("error al guardar archivo", err)
+	}
+	return nil
+}
+
+// GetByID obtiene los metadatos de un archivo
+func (r *MediaRepository) GetByID(ctx context.Context, id string) (*models.Media, error) {
+	objectID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var media models.Media
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&media)
+	if err != nil {
+		return nil, findError("error al obtener archivo", err, ErrMediaNotFound)
+	}
+
+	return &media, nil
+}
+
+// CountOwned cuenta cuántos de los archivos indicados existen y pertenecen al usuario
+func (r *MediaRepository) CountOwned(ctx context.Context, ids []primitive.ObjectID, userID primitive.ObjectID) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"_id":     bson.M{"$in": ids},
+		"user_id": userID,
+	})
+	if err != nil {
+		return 0, dbError("error al verificar archivos adjuntos", err)
+	}
+	return count, nil
+}
+
+// ListByUser devuelve los metadatos de todos los archivos del usuario, del más
+// antiguo al más reciente
+func (r *MediaRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Media, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, dbError("error al obtener archivos", err)
+	}
+	defer cursor.Close(ctx)
+
+	media := []models.Media{}
+	if err := cursor.All(ctx, &media); err != nil {
+		return nil, dbError("error al decodificar archivos", err)
+	}
+	return media, nil
+}
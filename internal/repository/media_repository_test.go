@@ -0,0 +1,84 @@
+// internal/repository/media_repository_test.go
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMediaRepository(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+	defer client.Database("test_db").Collection("media").Drop(context.Background())
+
+	repo := NewMediaRepository(client, "test_db")
+	ctx := context.Background()
+
+	t.Run("create and get", func(t *testing.T) {
+		m := &models.Media{
+			UserID:      primitive.NewObjectID(),
+			ContentType: "image/jpeg",
+			Size:        1234,
+			Variants: map[string]models.MediaVariant{
+				"original":  {Key: "media/u/m/original.jpg", ContentType: "image/jpeg", Width: 800, Height: 600},
+				"thumbnail": {Key: "media/u/m/thumbnail.jpg", ContentType: "image/jpeg", Width: 320, Height: 240},
+			},
+		}
+		err := repo.Create(ctx, m)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, m.ID)
+		assert.NotZero(t, m.CreatedAt)
+
+		found, err := repo.GetByID(ctx, m.ID.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, m.UserID, found.UserID)
+		assert.Equal(t, "media/u/m/thumbnail.jpg", found.Variants["thumbnail"].Key)
+	})
+
+	t.Run("missing user", func(t *testing.T) {
+		err := repo.Create(ctx, &models.Media{Variants: map[string]models.MediaVariant{"original": {}}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ID de usuario es requerido")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := repo.GetByID(ctx, primitive.NewObjectID().Hex())
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	})
+
+	t.Run("count owned", func(t *testing.T) {
+		owner := primitive.NewObjectID()
+		mine := &models.Media{UserID: owner, Variants: map[string]models.MediaVariant{"original": {}}}
+		other := &models.Media{UserID: primitive.NewObjectID(), Variants: map[string]models.MediaVariant{"original": {}}}
+		assert.NoError(t, repo.Create(ctx, mine))
+		assert.NoError(t, repo.Create(ctx, other))
+
+		count, err := repo.CountOwned(ctx, []primitive.ObjectID{mine.ID, other.ID, primitive.NewObjectID()}, owner)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("list by user", func(t *testing.T) {
+		owner := primitive.NewObjectID()
+		first := &models.Media{UserID: owner, Variants: map[string]models.MediaVariant{"original": {}}}
+		second := &models.Media{UserID: owner, Variants: map[string]models.MediaVariant{"original": {}}}
+		assert.NoError(t, repo.Create(ctx, first))
+		assert.NoError(t, repo.Create(ctx, second))
+		assert.NoError(t, repo.Create(ctx, &models.Media{UserID: primitive.NewObjectID(), Variants: map[string]models.MediaVariant{"original": {}}}))
+
+		media, err := repo.ListByUser(ctx, owner)
+		assert.NoError(t, err)
+		if assert.Len(t, media, 2) {
+			assert.Equal(t, first.ID, media[0].ID)
+		}
+
+		media, err = repo.ListByUser(ctx, primitive.NewObjectID())
+		assert.NoError(t, err)
+		assert.Empty(t, media)
+	})
+}
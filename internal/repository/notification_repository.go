@@ -0,0 +1,82 @@
+// internal/repository/notification_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewNotificationRepository(client *mongo.Client, dbName string) *NotificationRepository {
+	collection := client.Database(dbName).Collection("notifications")
+	return &NotificationRepository{
+		collection: collection,
+	}
+}
+
+// CreateMany guarda varias notificaciones en una sola operación
+func (r *NotificationRepository) CreateMany(ctx context.Context, notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	docs := make([]interface{}, len(notifications))
+	for i := range notifications {
+		if notifications[i].ID.IsZero() {
+			notifications[i].ID = primitive.NewObjectID()
+		}
+		notifications[i].CreatedAt = now
+		docs[i] = notifications[i]
+	}
+
+	if _, err := r.collection.InsertMany(ctx, docs); err != nil {
+		return dbError("error al crear notificaciones", err)
+	}
+	return nil
+}
+
+// GetByUserID obtiene las notificaciones más recientes de un usuario
+func (r *NotificationRepository) GetByUserID(ctx context.Context, userID string, limit int) ([]models.Notification, error) {
+	objectID, err := parseID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if limit < 1 {
+		return nil, apperr.InvalidField("invalid_limit", "limit", "tamaño de página inválido: debe ser mayor a 0")
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": objectID}, opts)
+	if err != nil {
+		return nil, dbError("error al buscar notificaciones", err)
+	}
+	defer cursor.Close(ctx)
+
+	notifications := []models.Notification{}
+	if err = cursor.All(ctx, &notifications); err != nil {
+		return nil, dbError("error al decodificar notificaciones", err)
+	}
+
+	return notifications, nil
+}
+
+// DeleteByUser borra todas las notificaciones del usuario
+func (r *NotificationRepository) DeleteByUser(ctx context.Context, userID primitive.ObjectID) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+		return dbError("error al borrar notificaciones del usuario", err)
+	}
+	return nil
+}
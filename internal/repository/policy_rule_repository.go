@@ -0,0 +1,87 @@
+// internal/repository/policy_rule_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrPolicyRuleNotFound = apperr.NotFound("policy_rule_not_found", "regla no encontrada")
+	ErrPolicyRuleExists   = apperr.Conflict("policy_rule_exists", "ya existe una regla con ese tipo y patrón")
+)
+
+// PolicyRuleRepository guarda la lista de bloqueo de la política de contenido
+type PolicyRuleRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPolicyRuleRepository(client *mongo.Client, dbName string) *PolicyRuleRepository {
+	collection := client.Database(dbName).Collection("policy_rules")
+	return &PolicyRuleRepository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes crea el índice único que impide repetir un patrón
+func (r *PolicyRuleRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "kind", Value: 1}, {Key: "pattern", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return dbError("error al crear índices de reglas", err)
+	}
+	return nil
+}
+
+// Create guarda una regla ya validada; devuelve ErrPolicyRuleExists si el patrón
+// ya está en la lista
+func (r *PolicyRuleRepository) Create(ctx context.Context, rule *models.PolicyRule) error {
+	rule.CreatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, rule)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrPolicyRuleExists.Wrap(err)
+		}
+		return dbError("error al crear regla", err)
+	}
+	rule.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// List devuelve todas las reglas, de la más antigua a la más reciente
+func (r *PolicyRuleRepository) List(ctx context.Context) ([]models.PolicyRule, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, dbError("error al obtener reglas", err)
+	}
+	defer cursor.Close(ctx)
+
+	rules := []models.PolicyRule{}
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, dbError("error al decodificar reglas", err)
+	}
+	return rules, nil
+}
+
+// Delete borra una regla y la devuelve
+func (r *PolicyRuleRepository) Delete(ctx context.Context, id string) (*models.PolicyRule, error) {
+	objectID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var rule models.PolicyRule
+	if err := r.collection.FindOneAndDelete(ctx, bson.M{"_id": objectID}).Decode(&rule); err != nil {
+		return nil, findError("error al borrar regla", err, ErrPolicyRuleNotFound)
+	}
+	return &rule, nil
+}
@@ -0,0 +1,44 @@
+// internal/repository/policy_rule_repository_test.go
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPolicyRuleRepository(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+	defer client.Database("test_db").Collection("policy_rules").Drop(context.Background())
+
+	ctx := context.Background()
+	repo := NewPolicyRuleRepository(client, "test_db")
+	require.NoError(t, repo.EnsureIndexes(ctx))
+
+	first := &models.PolicyRule{Kind: "exact", Pattern: "casino", Action: "flag"}
+	require.NoError(t, repo.Create(ctx, first))
+	assert.False(t, first.ID.IsZero())
+	require.NoError(t, repo.Create(ctx, &models.PolicyRule{Kind: "regex", Pattern: `bit\.ly`, Action: "reject"}))
+
+	err := repo.Create(ctx, &models.PolicyRule{Kind: "exact", Pattern: "casino", Action: "hold"})
+	assert.ErrorIs(t, err, ErrPolicyRuleExists)
+
+	rules, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, first.ID, rules[0].ID)
+
+	deleted, err := repo.Delete(ctx, first.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, "casino", deleted.Pattern)
+
+	_, err = repo.Delete(ctx, first.ID.Hex())
+	assert.ErrorIs(t, err, ErrPolicyRuleNotFound)
+	_, err = repo.Delete(ctx, primitive.NewObjectID().Hex())
+	assert.ErrorIs(t, err, ErrPolicyRuleNotFound)
+}
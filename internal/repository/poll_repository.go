@@ -0,0 +1,250 @@
+// internal/repository/poll_repository.go
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// closeBatchSize limita cuántos avisos de cierre reserva cada pasada del job
+const closeBatchSize = 100
+
+// ErrAlreadyVoted indica que el usuario ya tiene un voto registrado en la encuesta
+var ErrAlreadyVoted = apperr.Conflict("already_voted", "el usuario ya votó en esta encuesta")
+
+type PollRepository struct {
+	votes  *mongo.Collection
+	tweets *mongo.Collection
+}
+
+func NewPollRepository(client *mongo.Client, dbName string) *PollRepository {
+	db := client.Database(dbName)
+	return &PollRepository{
+		votes:  db.Collection("poll_votes"),
+		tweets: db.Collection("tweets"),
+	}
+}
+
+// EnsureIndexes crea el índice único que garantiza un voto por usuario y encuesta,
+// y los índices que usa el job de cierre para encontrar encuestas vencidas y
+// cierres sin avisar. También marca como pendientes los avisos de las encuestas
+// cerradas sin results_notified, para que el job avise de ellas.
+func (r *PollRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.votes.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "tweet_id", Value: 1}, {Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return dbError("error al crear índice de votos", err)
+	}
+
+	_, err = r.tweets.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "poll.closed", Value: 1}, {Key: "poll.expires_at", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{
+			"poll": bson.M{"$exists": true},
+		}),
+	})
+	if err != nil {
+		return dbError("error al crear índice de encuestas", err)
+	}
+
+	_, err = r.tweets.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "poll.notify_lease_until", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{
+			"poll.results_notified": false,
+		}),
+	})
+	if err != nil {
+		return dbError("error al crear índice de avisos de encuestas", err)
+	}
+
+	// Las encuestas cerradas antes de que se guardara results_notified no tienen
+	// el campo y ClaimUnnotified no las encontraría. Es idempotente: después de
+	// la primera vez no hay documentos sin el campo.
+	_, err = r.tweets.UpdateMany(ctx,
+		bson.M{"poll.closed": true, "poll.results_notified": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"poll.results_notified": false}},
+	)
+	if err != nil {
+		return dbError("error al marcar avisos de encuestas pendientes", err)
+	}
+	return nil
+}
+
+// InsertVote guarda un voto. El índice único (tweet_id, user_id) rechaza votos
+// repetidos aunque lleguen en paralelo.
+func (r *PollRepository) InsertVote(ctx context.Context, vote *models.PollVote) error {
+	if vote.ID.IsZero() {
+		vote.ID = primitive.NewObjectID()
+	}
+	if _, err := r.votes.InsertOne(ctx, vote); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrAlreadyVoted.Wrap(err)
+		}
+		return dbError("error al registrar voto", err)
+	}
+	return nil
+}
+
+// DeleteVote elimina un voto que no se pudo contabilizar
+func (r *PollRepository) DeleteVote(ctx context.Context, id primitive.ObjectID) error {
+	if _, err := r.votes.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return dbError("error al revertir voto", err)
+	}
+	return nil
+}
+
+// CountVote incrementa de forma atómica los conteos de la opción, solo si la encuesta
+// sigue abierta en now. Devuelve false si la encuesta ya estaba cerrada.
+func (r *PollRepository) CountVote(ctx context.Context, tweetID primitive.ObjectID, option int, now time.Time) (bool, error) {
+	result, err := r.tweets.UpdateOne(ctx,
+		bson.M{
+			"_id":             tweetID,
+			"poll.closed":     false,
+			"poll.expires_at": bson.M{"$gt": now},
+		},
+		bson.M{"$inc": bson.M{
+			fmt.Sprintf("poll.options.%d.votes", option): 1,
+			"poll.total_votes":                           1,
+		}},
+	)
+	if err != nil {
+		return false, dbError("error al contabilizar voto", err)
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// VotesByUser devuelve la opción votada por el usuario en cada uno de los tweets indicados
+func (r *PollRepository) VotesByUser(ctx context.Context, userID primitive.ObjectID, tweetIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	result := make(map[primitive.ObjectID]int)
+	if len(tweetIDs) == 0 {
+		return result, nil
+	}
+
+	cursor, err := r.votes.Find(ctx, bson.M{"user_id": userID, "tweet_id": bson.M{"$in": tweetIDs}})
+	if err != nil {
+		return nil, dbError("error al obtener votos", err)
+	}
+	defer cursor.Close(ctx)
+
+	var found []models.PollVote
+	if err = cursor.All(ctx, &found); err != nil {
+		return nil, dbError("error al decodificar votos", err)
+	}
+
+	for _, v := range found {
+		result[v.TweetID] = v.Option
+	}
+	return result, nil
+}
+
+// CloseExpired marca como cerradas las encuestas vencidas, con el aviso a
+// votantes y autor pendiente, y devuelve cuántas cerró
+func (r *PollRepository) CloseExpired(ctx context.Context, now time.Time) (int, error) {
+	result, err := r.tweets.UpdateMany(ctx,
+		bson.M{
+			"poll.closed":     false,
+			"poll.expires_at": bson.M{"$lte": now},
+		},
+		bson.M{"$set": bson.M{"poll.closed": true, "poll.results_notified": false}},
+	)
+	if err != nil {
+		return 0, dbError("error al cerrar encuestas", err)
+	}
+	return int(result.ModifiedCount), nil
+}
+
+// ClaimUnnotified reserva por lease los tweets con encuestas cerradas cuyo aviso
+// está pendiente y los devuelve. Una reserva vencida se vuelve a tomar, así que
+// un aviso que falló o quedó a medias se reintenta; con varias instancias
+// solo una tiene la reserva a la vez.
+func (r *PollRepository) ClaimUnnotified(ctx context.Context, now time.Time, lease time.Duration) ([]models.Tweet, error) {
+	pending := bson.M{
+		"poll.closed":           true,
+		"poll.results_notified": false,
+		"$or": bson.A{
+			bson.M{"poll.notify_lease_until": bson.M{"$exists": false}},
+			bson.M{"poll.notify_lease_until": bson.M{"$lte": now}},
+		},
+	}
+	cursor, err := r.tweets.Find(ctx, pending, options.Find().SetLimit(closeBatchSize))
+	if err != nil {
+		return nil, dbError("error al buscar encuestas sin avisar", err)
+	}
+	defer cursor.Close(ctx)
+
+	var candidates []models.Tweet
+	if err = cursor.All(ctx, &candidates); err != nil {
+		return nil, dbError("error al decodificar tweets", err)
+	}
+
+	claimed := make([]models.Tweet, 0, len(candidates))
+	for _, t := range candidates {
+		filter := bson.M{"_id": t.ID}
+		for key, value := range pending {
+			filter[key] = value
+		}
+		var tweet models.Tweet
+		err := r.tweets.FindOneAndUpdate(ctx, filter,
+			bson.M{"$set": bson.M{"poll.notify_lease_until": now.Add(lease)}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&tweet)
+		if err == mongo.ErrNoDocuments {
+			// Otra instancia la reservó primero
+			continue
+		}
+		if err != nil {
+			return claimed, dbError("error al reservar aviso de encuesta", err)
+		}
+		claimed = append(claimed, tweet)
+	}
+	return claimed, nil
+}
+
+// MarkNotified registra que ya se avisó del cierre de la encuesta del tweet
+func (r *PollRepository) MarkNotified(ctx context.Context, tweetID primitive.ObjectID) error {
+	_, err := r.tweets.UpdateOne(ctx,
+		bson.M{"_id": tweetID},
+		bson.M{
+			"$set":   bson.M{"poll.results_notified": true},
+			"$unset": bson.M{"poll.notify_lease_until": ""},
+		},
+	)
+	if err != nil {
+		return dbError("error al marcar aviso de encuesta", err)
+	}
+	return nil
+}
+
+// Voters devuelve los IDs de los usuarios que votaron en la encuesta del tweet
+func (r *PollRepository) Voters(ctx context.Context, tweetID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	values, err := r.votes.Distinct(ctx, "user_id", bson.M{"tweet_id": tweetID})
+	if err != nil {
+		return nil, dbError("error al obtener votantes", err)
+	}
+
+	voters := make([]primitive.ObjectID, 0, len(values))
+	for _, v := range values {
+		if id, ok := v.(primitive.ObjectID); ok {
+			voters = append(voters, id)
+		}
+	}
+	return voters, nil
+}
+
+// DeleteByUser borra los votos del usuario. Los recuentos de las encuestas están
+// en los tweets, así que los resultados no cambian.
+func (r *PollRepository) DeleteByUser(ctx context.Context, userID primitive.ObjectID) error {
+	if _, err := r.votes.DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+		return dbError("error al borrar votos del usuario", err)
+	}
+	return nil
+}
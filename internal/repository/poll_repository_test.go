@@ -0,0 +1,162 @@
+// internal/repository/poll_repository_test.go
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPollRepository(t *testing.T) {
+	client, cleanup := setupTweetTestDB(t)
+	defer cleanup()
+	defer client.Database("test_db").Collection("poll_votes").Drop(context.Background())
+
+	ctx := context.Background()
+	tweetRepo := NewTweetRepository(client, "test_db")
+	pollRepo := NewPollRepository(client, "test_db")
+	assert.NoError(t, pollRepo.EnsureIndexes(ctx))
+
+	author := createTestUserForTweets(t, client)
+
+	newPollTweet := func(t *testing.T, expiresAt time.Time) *models.Tweet {
+		zero := func() *int { n := 0; return &n }
+		tweet := &models.Tweet{
+			UserID:  author,
+			Content: "¿Qué lenguaje prefieres?",
+			Poll: &models.Poll{
+				Options:         []models.PollOption{{Text: "Go", Votes: zero()}, {Text: "Rust", Votes: zero()}, {Text: "Zig", Votes: zero()}},
+				DurationMinutes: 60,
+				ExpiresAt:       expiresAt,
+				TotalVotes:      zero(),
+			},
+		}
+		assert.NoError(t, tweetRepo.Create(ctx, tweet))
+		return tweet
+	}
+
+	storedPoll := func(t *testing.T, id primitive.ObjectID) *models.Poll {
+		var stored models.Tweet
+		err := client.Database("test_db").Collection("tweets").FindOne(ctx, bson.M{"_id": id}).Decode(&stored)
+		assert.NoError(t, err)
+		return stored.Poll
+	}
+
+	t.Run("insert and count vote", func(t *testing.T) {
+		tweet := newPollTweet(t, time.Now().Add(time.Hour))
+		voter := primitive.NewObjectID()
+
+		vote := &models.PollVote{TweetID: tweet.ID, UserID: voter, Option: 1, CreatedAt: time.Now()}
+		assert.NoError(t, pollRepo.InsertVote(ctx, vote))
+		assert.NotEmpty(t, vote.ID)
+
+		counted, err := pollRepo.CountVote(ctx, tweet.ID, 1, time.Now())
+		assert.NoError(t, err)
+		assert.True(t, counted)
+
+		poll := storedPoll(t, tweet.ID)
+		assert.Equal(t, 1, *poll.TotalVotes)
+		assert.Equal(t, 1, *poll.Options[1].Votes)
+		assert.Equal(t, 0, *poll.Options[0].Votes)
+
+		votes, err := pollRepo.VotesByUser(ctx, voter, []primitive.ObjectID{tweet.ID, primitive.NewObjectID()})
+		assert.NoError(t, err)
+		assert.Equal(t, map[primitive.ObjectID]int{tweet.ID: 1}, votes)
+	})
+
+	t.Run("one vote per user", func(t *testing.T) {
+		tweet := newPollTweet(t, time.Now().Add(time.Hour))
+		voter := primitive.NewObjectID()
+
+		assert.NoError(t, pollRepo.InsertVote(ctx, &models.PollVote{TweetID: tweet.ID, UserID: voter, Option: 0}))
+		err := pollRepo.InsertVote(ctx, &models.PollVote{TweetID: tweet.ID, UserID: voter, Option: 2})
+		assert.ErrorIs(t, err, ErrAlreadyVoted)
+	})
+
+	t.Run("concurrent votes are stored once", func(t *testing.T) {
+		tweet := newPollTweet(t, time.Now().Add(time.Hour))
+		voter := primitive.NewObjectID()
+
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			accepted int
+		)
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if pollRepo.InsertVote(ctx, &models.PollVote{TweetID: tweet.ID, UserID: voter, Option: 0}) == nil {
+					mu.Lock()
+					accepted++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1, accepted)
+	})
+
+	t.Run("closed poll is not counted", func(t *testing.T) {
+		tweet := newPollTweet(t, time.Now().Add(-time.Minute))
+
+		counted, err := pollRepo.CountVote(ctx, tweet.ID, 0, time.Now())
+		assert.NoError(t, err)
+		assert.False(t, counted)
+		assert.Equal(t, 0, *storedPoll(t, tweet.ID).TotalVotes)
+
+		// El voto rechazado se puede revertir
+		vote := &models.PollVote{TweetID: tweet.ID, UserID: primitive.NewObjectID(), Option: 0}
+		assert.NoError(t, pollRepo.InsertVote(ctx, vote))
+		assert.NoError(t, pollRepo.DeleteVote(ctx, vote.ID))
+		assert.NoError(t, pollRepo.InsertVote(ctx, &models.PollVote{TweetID: tweet.ID, UserID: vote.UserID, Option: 1}))
+	})
+
+	t.Run("close expired polls", func(t *testing.T) {
+		tweet := newPollTweet(t, time.Now().Add(time.Hour))
+		voter := primitive.NewObjectID()
+		assert.NoError(t, pollRepo.InsertVote(ctx, &models.PollVote{TweetID: tweet.ID, UserID: voter, Option: 2}))
+
+		later := time.Now().Add(2 * time.Hour)
+		closed, err := pollRepo.CloseExpired(ctx, later)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, closed, 1)
+
+		// Una segunda pasada no vuelve a cerrar las ya cerradas
+		closed, err = pollRepo.CloseExpired(ctx, later)
+		assert.NoError(t, err)
+		assert.Zero(t, closed)
+
+		claimedIDs := func(now time.Time) []primitive.ObjectID {
+			claimed, err := pollRepo.ClaimUnnotified(ctx, now, time.Minute)
+			assert.NoError(t, err)
+			ids := []primitive.ObjectID{}
+			for _, tw := range claimed {
+				assert.True(t, tw.Poll.Closed)
+				ids = append(ids, tw.ID)
+			}
+			return ids
+		}
+		assert.Contains(t, claimedIDs(later), tweet.ID)
+		// La reserva vigente impide tomarla otra vez; al vencer se retoma
+		assert.NotContains(t, claimedIDs(later), tweet.ID)
+		assert.Contains(t, claimedIDs(later.Add(time.Minute)), tweet.ID)
+
+		assert.NoError(t, pollRepo.MarkNotified(ctx, tweet.ID))
+		assert.NotContains(t, claimedIDs(later.Add(time.Hour)), tweet.ID)
+
+		voters, err := pollRepo.Voters(ctx, tweet.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []primitive.ObjectID{voter}, voters)
+
+		counted, err := pollRepo.CountVote(ctx, tweet.ID, 0, time.Now())
+		assert.NoError(t, err)
+		assert.False(t, counted)
+	})
+}
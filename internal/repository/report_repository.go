@@ -0,0 +1,187 @@
+// internal/repository/report_repository.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrReportNotFound = apperr.NotFound("report_not_found", "denuncia no encontrada")
+	// ErrAlreadyReported indica que el usuario ya denunció el objetivo y el caso sigue abierto
+	ErrAlreadyReported = apperr.Conflict("already_reported", "ya denunciaste este contenido")
+	ErrReportResolved  = apperr.Conflict("report_resolved", "la denuncia ya fue resuelta")
+)
+
+type ReportRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReportRepository(client *mongo.Client, dbName string) *ReportRepository {
+	collection := client.Database(dbName).Collection("reports")
+	return &ReportRepository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes crea el índice único que deja un solo caso abierto por objetivo y
+// el que ordena la cola de moderación
+func (r *ReportRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"status": models.ReportStatusOpen,
+			}),
+		},
+		{Keys: bson.D{
+			{Key: "status", Value: 1},
+			{Key: "severity", Value: -1},
+			{Key: "report_count", Value: -1},
+			{Key: "created_at", Value: 1},
+		}},
+	})
+	if err != nil {
+		return dbError("error al crear índices de denuncias", err)
+	}
+	return nil
+}
+
+// Add suma la denuncia entry al caso abierto del objetivo de report, o abre uno
+// nuevo si no hay. Devuelve ErrAlreadyReported si el mismo usuario ya lo denunció.
+func (r *ReportRepository) Add(ctx context.Context, report *models.Report, entry models.ReportEntry) (*models.Report, error) {
+	now := time.Now()
+	entry.CreatedAt = now
+
+	// Si el usuario ya está en el caso abierto el filtro no coincide y el upsert
+	// choca con el índice único. También choca si otro usuario abre el caso a la
+	// vez: en ese caso el segundo intento ya encuentra el documento.
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var saved models.Report
+		err = r.collection.FindOneAndUpdate(ctx,
+			bson.M{
+				"target_type":         report.TargetType,
+				"target_id":           report.TargetID,
+				"status":              models.ReportStatusOpen,
+				"entries.reporter_id": bson.M{"$ne": entry.ReporterID},
+			},
+			bson.M{
+				"$setOnInsert": bson.M{
+					"target_user_id": report.TargetUserID,
+					"created_at":     now,
+				},
+				"$push": bson.M{"entries": entry},
+				"$inc": bson.M{
+					"report_count":            1,
+					"reasons." + entry.Reason: 1,
+				},
+				"$max": bson.M{"severity": models.ReportReasons[entry.Reason]},
+				"$set": bson.M{"updated_at": now},
+			},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+		).Decode(&saved)
+		if err == nil {
+			return &saved, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, dbError("error al guardar denuncia", err)
+		}
+	}
+	return nil, ErrAlreadyReported.Wrap(err)
+}
+
+// GetByID obtiene un caso de moderación
+func (r *ReportRepository) GetByID(ctx context.Context, id string) (*models.Report, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrReportNotFound.Wrap(err)
+	}
+
+	var report models.Report
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&report); err != nil {
+		return nil, findError("error al obtener denuncia", err, ErrReportNotFound)
+	}
+	return &report, nil
+}
+
+// ListByStatus devuelve una página de la cola: primero los casos más graves, a
+// igual gravedad los más denunciados y después los más antiguos
+func (r *ReportRepository) ListByStatus(ctx context.Context, status string, skip, limit int) ([]models.Report, error) {
+	opts := options.Find().
+		SetSort(bson.D{
+			{Key: "severity", Value: -1},
+			{Key: "report_count", Value: -1},
+			{Key: "created_at", Value: 1},
+		}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{"status": status}, opts)
+	if err != nil {
+		return nil, dbError("error al buscar denuncias", err)
+	}
+	defer cursor.Close(ctx)
+
+	reports := []models.Report{}
+	if err = cursor.All(ctx, &reports); err != nil {
+		return nil, dbError("error al decodificar denuncias", err)
+	}
+	return reports, nil
+}
+
+// Resolve cierra un caso abierto con la decisión del moderador. Devuelve
+// ErrReportResolved si otro moderador lo cerró antes.
+func (r *ReportRepository) Resolve(ctx context.Context, id primitive.ObjectID, resolution models.ReportResolution) (*models.Report, error) {
+	var report models.Report
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "status": models.ReportStatusOpen},
+		bson.M{"$set": bson.M{
+			"status":     models.ReportStatusResolved,
+			"resolution": resolution,
+			"updated_at": resolution.ResolvedAt,
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&report)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, dbError("error al resolver denuncia", err)
+		}
+		if _, getErr := r.GetByID(ctx, id.Hex()); getErr != nil {
+			return nil, getErr
+		}
+		return nil, ErrReportResolved
+	}
+	return &report, nil
+}
+
+// AnonymizeUser reemplaza al usuario por un ID vacío en las denuncias que hizo y
+// en los casos que resolvió como moderador
+func (r *ReportRepository) AnonymizeUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"entries.reporter_id": userID},
+		bson.M{"$set": bson.M{"entries.$[e].reporter_id": primitive.NilObjectID}},
+		options.Update().SetArrayFilters(options.ArrayFilters{
+			Filters: []interface{}{bson.M{"e.reporter_id": userID}},
+		}),
+	)
+	if err != nil {
+		return dbError("error al anonimizar denuncias", err)
+	}
+
+	_, err = r.collection.UpdateMany(ctx,
+		bson.M{"resolution.moderator_id": userID},
+		bson.M{"$set": bson.M{"resolution.moderator_id": primitive.NilObjectID}},
+	)
+	if err != nil {
+		return dbError("error al anonimizar resoluciones", err)
+	}
+	return nil
+}
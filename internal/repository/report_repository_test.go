@@ -0,0 +1,83 @@
+// internal/repository/report_repository_test.go
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReportRepository(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+	defer client.Database("test_db").Collection("reports").Drop(context.Background())
+
+	ctx := context.Background()
+	repo := NewReportRepository(client, "test_db")
+	require.NoError(t, repo.EnsureIndexes(ctx))
+
+	target := &models.Report{
+		TargetType:   models.ReportTargetTweet,
+		TargetID:     primitive.NewObjectID(),
+		TargetUserID: primitive.NewObjectID(),
+	}
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+
+	t.Run("reports on the same target share a case", func(t *testing.T) {
+		first, err := repo.Add(ctx, target, models.ReportEntry{ReporterID: alice, Reason: "spam"})
+		require.NoError(t, err)
+		assert.Equal(t, models.ReportStatusOpen, first.Status)
+		assert.Equal(t, 1, first.ReportCount)
+		assert.Equal(t, target.TargetUserID, first.TargetUserID)
+
+		second, err := repo.Add(ctx, target, models.ReportEntry{ReporterID: bob, Reason: "violence", Comment: "amenazas"})
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, second.ID)
+		assert.Equal(t, 2, second.ReportCount)
+		assert.Equal(t, 4, second.Severity)
+		assert.Equal(t, map[string]int{"spam": 1, "violence": 1}, second.Reasons)
+		assert.Len(t, second.Entries, 2)
+
+		_, err = repo.Add(ctx, target, models.ReportEntry{ReporterID: alice, Reason: "hate"})
+		assert.ErrorIs(t, err, ErrAlreadyReported)
+	})
+
+	t.Run("queue order", func(t *testing.T) {
+		mild := &models.Report{TargetType: models.ReportTargetUser, TargetID: primitive.NewObjectID(), TargetUserID: primitive.NewObjectID()}
+		_, err := repo.Add(ctx, mild, models.ReportEntry{ReporterID: alice, Reason: "spam"})
+		require.NoError(t, err)
+
+		queue, err := repo.ListByStatus(ctx, models.ReportStatusOpen, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, queue, 2)
+		assert.Equal(t, target.TargetID, queue[0].TargetID)
+		assert.Equal(t, mild.TargetID, queue[1].TargetID)
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		open, err := repo.ListByStatus(ctx, models.ReportStatusOpen, 0, 1)
+		require.NoError(t, err)
+		moderator := primitive.NewObjectID()
+
+		resolution := models.ReportResolution{Action: models.ModerationHideTweet, ModeratorID: moderator, ResolvedAt: time.Now()}
+		resolved, err := repo.Resolve(ctx, open[0].ID, resolution)
+		require.NoError(t, err)
+		assert.Equal(t, models.ReportStatusResolved, resolved.Status)
+		assert.Equal(t, moderator, resolved.Resolution.ModeratorID)
+
+		_, err = repo.Resolve(ctx, open[0].ID, resolution)
+		assert.ErrorIs(t, err, ErrReportResolved)
+		_, err = repo.Resolve(ctx, primitive.NewObjectID(), resolution)
+		assert.ErrorIs(t, err, ErrReportNotFound)
+
+		// Resuelto el caso, una denuncia nueva abre otro
+		reopened, err := repo.Add(ctx, target, models.ReportEntry{ReporterID: alice, Reason: "spam"})
+		require.NoError(t, err)
+		assert.NotEqual(t, open[0].ID, reopened.ID)
+	})
+}
@@ -0,0 +1,259 @@
+// internal/repository/scheduled_tweet_repository.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrScheduledNotFound = apperr.NotFound("scheduled_tweet_not_found", "tweet programado no encontrado")
+	// ErrScheduledLocked indica que ya se publicó, se canceló o se está publicando
+	ErrScheduledLocked = apperr.Conflict("scheduled_tweet_locked", "el tweet programado ya no se puede modificar")
+	// ErrLeaseLost indica que otra instancia tomó el tweet programado
+	ErrLeaseLost = apperr.Conflict("lease_lost", "se perdió el lease del tweet programado")
+)
+
+// editableStatuses son los estados en los que se puede editar, reprogramar o cancelar
+var editableStatuses = []string{models.ScheduledStatusDraft, models.ScheduledStatusScheduled, models.ScheduledStatusFailed}
+
+type ScheduledTweetRepository struct {
+	collection *mongo.Collection
+}
+
+func NewScheduledTweetRepository(client *mongo.Client, dbName string) *ScheduledTweetRepository {
+	collection := client.Database(dbName).Collection("scheduled_tweets")
+	return &ScheduledTweetRepository{
+		collection: collection,
+	}
+}
+
+// EnsureIndexes crea los índices que usan el scheduler y los listados por usuario
+func (r *ScheduledTweetRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "publish_at", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}, {Key: "publish_at", Value: 1}}},
+	})
+	if err != nil {
+		return dbError("error al crear índices de tweets programados", err)
+	}
+	return nil
+}
+
+// Create guarda un borrador o tweet programado ya validado
+func (r *ScheduledTweetRepository) Create(ctx context.Context, st *models.ScheduledTweet) error {
+	now := time.Now()
+	st.ID = primitive.NewObjectID()
+	st.CreatedAt = now
+	st.UpdatedAt = now
+
+	if _, err := r.collection.InsertOne(ctx, st); err != nil {
+		return dbError("error al crear tweet programado", err)
+	}
+	return nil
+}
+
+// GetByID obtiene un borrador o tweet programado de un usuario
+func (r *ScheduledTweetRepository) GetByID(ctx context.Context, userID, id string) (*models.ScheduledTweet, error) {
+	filter, err := ownedFilter(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var st models.ScheduledTweet
+	if err := r.collection.FindOne(ctx, filter).Decode(&st); err != nil {
+		return nil, findError("error al obtener tweet programado", err, ErrScheduledNotFound)
+	}
+	return &st, nil
+}
+
+// ListByUser devuelve los borradores y tweets programados de un usuario. Sin estados
+// indicados devuelve los pendientes (borradores y programados).
+func (r *ScheduledTweetRepository) ListByUser(ctx context.Context, userID string, statuses []string) ([]models.ScheduledTweet, error) {
+	objectID, err := parseID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(statuses) == 0 {
+		statuses = []string{models.ScheduledStatusDraft, models.ScheduledStatusScheduled}
+	}
+
+	opts := options.Find().SetSort(bson.D{
+		{Key: "publish_at", Value: 1},
+		{Key: "created_at", Value: -1},
+	})
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"user_id": objectID,
+		"status":  bson.M{"$in": statuses},
+	}, opts)
+	if err != nil {
+		return nil, dbError("error al buscar tweets programados", err)
+	}
+	defer cursor.Close(ctx)
+
+	scheduled := []models.ScheduledTweet{}
+	if err = cursor.All(ctx, &scheduled); err != nil {
+		return nil, dbError("error al decodificar tweets programados", err)
+	}
+	return scheduled, nil
+}
+
+// Update guarda el contenido, la fecha y el estado de st. Falla con ErrScheduledLocked
+// si ya se publicó, se canceló o un worker lo está publicando en este momento.
+func (r *ScheduledTweetRepository) Update(ctx context.Context, st *models.ScheduledTweet) error {
+	st.UpdatedAt = time.Now()
+
+	var updated models.ScheduledTweet
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"_id":         st.ID,
+			"user_id":     st.UserID,
+			"status":      bson.M{"$in": editableStatuses},
+			"lease_owner": bson.M{"$exists": false},
+		},
+		bson.M{
+			"$set": bson.M{
+				"content":    st.Content,
+				"media":      st.Media,
+				"poll":       st.Poll,
+				"publish_at": st.PublishAt,
+				"status":     st.Status,
+				"attempts":   0,
+				"updated_at": st.UpdatedAt,
+			},
+			// Reprogramar descarta el estado de reintentos anterior
+			"$unset": bson.M{"lease_until": "", "last_error": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		return findError("error al actualizar tweet programado", err, ErrScheduledLocked)
+	}
+
+	*st = updated
+	return nil
+}
+
+// Cancel cancela un borrador o tweet programado que todavía no se publicó
+func (r *ScheduledTweetRepository) Cancel(ctx context.Context, userID, id string) error {
+	if _, err := r.GetByID(ctx, userID, id); err != nil {
+		return err
+	}
+
+	filter, _ := ownedFilter(userID, id)
+	filter["status"] = bson.M{"$in": editableStatuses}
+	filter["lease_owner"] = bson.M{"$exists": false}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{
+		"$set":   bson.M{"status": models.ScheduledStatusCanceled, "updated_at": time.Now()},
+		"$unset": bson.M{"lease_until": ""},
+	})
+	if err != nil {
+		return dbError("error al cancelar tweet programado", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrScheduledLocked
+	}
+	return nil
+}
+
+// ClaimDue toma el tweet programado vencido más antiguo que no tenga un lease vigente
+// y lo reserva para owner hasta now+lease. Devuelve nil si no hay ninguno pendiente.
+func (r *ScheduledTweetRepository) ClaimDue(ctx context.Context, owner string, now time.Time, lease time.Duration) (*models.ScheduledTweet, error) {
+	leaseUntil := now.Add(lease)
+
+	var st models.ScheduledTweet
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"status":     models.ScheduledStatusScheduled,
+			"publish_at": bson.M{"$lte": now},
+			"$or": bson.A{
+				bson.M{"lease_until": bson.M{"$exists": false}},
+				bson.M{"lease_until": bson.M{"$lt": now}},
+			},
+		},
+		bson.M{
+			"$set": bson.M{"lease_owner": owner, "lease_until": leaseUntil},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "publish_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&st)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, dbError("error al reservar tweet programado", err)
+	}
+	return &st, nil
+}
+
+// MarkPublished marca el tweet programado como publicado si owner conserva el lease
+func (r *ScheduledTweetRepository) MarkPublished(ctx context.Context, id primitive.ObjectID, owner string, tweetID primitive.ObjectID, at time.Time) error {
+	return r.finish(ctx, id, owner, bson.M{
+		"status":       models.ScheduledStatusPublished,
+		"tweet_id":     tweetID,
+		"published_at": at,
+		"updated_at":   at,
+	}, bson.M{"lease_owner": "", "lease_until": "", "last_error": ""})
+}
+
+// MarkFailed marca el tweet programado como fallido de forma definitiva
+func (r *ScheduledTweetRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, owner string, reason string) error {
+	return r.finish(ctx, id, owner, bson.M{
+		"status":     models.ScheduledStatusFailed,
+		"last_error": reason,
+		"updated_at": time.Now(),
+	}, bson.M{"lease_owner": "", "lease_until": ""})
+}
+
+// Release libera el lease tras un fallo transitorio; no se reintenta antes de retryAt
+func (r *ScheduledTweetRepository) Release(ctx context.Context, id primitive.ObjectID, owner string, reason string, retryAt time.Time) error {
+	return r.finish(ctx, id, owner, bson.M{
+		"last_error":  reason,
+		"lease_until": retryAt,
+		"updated_at":  time.Now(),
+	}, bson.M{"lease_owner": ""})
+}
+
+func (r *ScheduledTweetRepository) finish(ctx context.Context, id primitive.ObjectID, owner string, set, unset bson.M) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "lease_owner": owner},
+		bson.M{"$set": set, "$unset": unset},
+	)
+	if err != nil {
+		return dbError("error al actualizar tweet programado", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func ownedFilter(userID, id string) (bson.M, error) {
+	userOID, err := parseID(userID)
+	if err != nil {
+		return nil, err
+	}
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrScheduledNotFound
+	}
+	return bson.M{"_id": oid, "user_id": userOID}, nil
+}
+
+// DeleteByUser borra todos los borradores y tweets programados del usuario
+func (r *ScheduledTweetRepository) DeleteByUser(ctx context.Context, userID primitive.ObjectID) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+		return dbError("error al borrar tweets programados del usuario", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// internal/repository/scheduled_tweet_repository_test.go
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestScheduledTweetRepository(t *testing.T) {
+	client, cleanup := setupTweetTestDB(t)
+	defer cleanup()
+	defer client.Database("test_db").Collection("scheduled_tweets").Drop(context.Background())
+
+	ctx := context.Background()
+	repo := NewScheduledTweetRepository(client, "test_db")
+	assert.NoError(t, repo.EnsureIndexes(ctx))
+
+	userID := createTestUserForTweets(t, client)
+	user := userID.Hex()
+
+	t.Run("create and edit draft", func(t *testing.T) {
+		draft := &models.ScheduledTweet{UserID: userID, Content: "borrador secreto", Status: models.ScheduledStatusDraft}
+		assert.NoError(t, repo.Create(ctx, draft))
+		assert.NotEmpty(t, draft.ID)
+		assert.NotZero(t, draft.CreatedAt)
+
+		draft.Content = "borrador editado"
+		assert.NoError(t, repo.Update(ctx, draft))
+		assert.Equal(t, "borrador editado", draft.Content)
+		assert.Equal(t, models.ScheduledStatusDraft, draft.Status)
+
+		found, err := repo.GetByID(ctx, user, draft.ID.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, "borrador editado", found.Content)
+
+		// Un usuario distinto no puede editarlo
+		foreign := *draft
+		foreign.UserID = primitive.NewObjectID()
+		assert.ErrorIs(t, repo.Update(ctx, &foreign), ErrScheduledLocked)
+	})
+
+	t.Run("claim, publish and lock", func(t *testing.T) {
+		publishAt := time.Now().Add(time.Minute)
+		st := &models.ScheduledTweet{UserID: userID, Content: "programado", Status: models.ScheduledStatusScheduled, PublishAt: &publishAt}
+		assert.NoError(t, repo.Create(ctx, st))
+
+		// Todavía no vence
+		claimed, err := repo.ClaimDue(ctx, "a", time.Now(), time.Minute)
+		assert.NoError(t, err)
+		assert.Nil(t, claimed)
+
+		now := publishAt.Add(time.Second)
+		claimed, err = repo.ClaimDue(ctx, "a", now, time.Minute)
+		assert.NoError(t, err)
+		if assert.NotNil(t, claimed) {
+			assert.Equal(t, st.ID, claimed.ID)
+			assert.Equal(t, 1, claimed.Attempts)
+		}
+
+		// Otra instancia no lo puede tomar mientras el lease esté vigente
+		other, err := repo.ClaimDue(ctx, "b", now, time.Minute)
+		assert.NoError(t, err)
+		assert.Nil(t, other)
+
+		// Ni se puede editar o cancelar mientras se publica
+		err = repo.Cancel(ctx, user, st.ID.Hex())
+		assert.ErrorIs(t, err, ErrScheduledLocked)
+		assert.ErrorIs(t, repo.Update(ctx, st), ErrScheduledLocked)
+
+		assert.ErrorIs(t, repo.MarkPublished(ctx, st.ID, "b", st.ID, now), ErrLeaseLost)
+		assert.NoError(t, repo.MarkPublished(ctx, st.ID, "a", st.ID, now))
+
+		published, err := repo.GetByID(ctx, user, st.ID.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, models.ScheduledStatusPublished, published.Status)
+		assert.Equal(t, st.ID, published.TweetID)
+	})
+
+	t.Run("expired lease can be reclaimed", func(t *testing.T) {
+		publishAt := time.Now().Add(time.Minute)
+		st := &models.ScheduledTweet{UserID: userID, Content: "reintento", Status: models.ScheduledStatusScheduled, PublishAt: &publishAt}
+		assert.NoError(t, repo.Create(ctx, st))
+
+		now := publishAt.Add(time.Second)
+		_, err := repo.ClaimDue(ctx, "a", now, time.Minute)
+		assert.NoError(t, err)
+
+		claimed, err := repo.ClaimDue(ctx, "b", now.Add(2*time.Minute), time.Minute)
+		assert.NoError(t, err)
+		if assert.NotNil(t, claimed) {
+			assert.Equal(t, st.ID, claimed.ID)
+			assert.Equal(t, "b", claimed.LeaseOwner)
+			assert.Equal(t, 2, claimed.Attempts)
+		}
+		assert.NoError(t, repo.MarkFailed(ctx, st.ID, "b", "error"))
+	})
+
+	t.Run("reschedule and cancel", func(t *testing.T) {
+		publishAt := time.Now().Add(time.Hour)
+		st := &models.ScheduledTweet{UserID: userID, Content: "cambiar hora", Status: models.ScheduledStatusScheduled, PublishAt: &publishAt}
+		assert.NoError(t, repo.Create(ctx, st))
+
+		later := publishAt.Add(24 * time.Hour)
+		st.PublishAt = &later
+		assert.NoError(t, repo.Update(ctx, st))
+		assert.WithinDuration(t, later, *st.PublishAt, time.Second)
+
+		pending, err := repo.ListByUser(ctx, user, nil)
+		assert.NoError(t, err)
+		ids := []primitive.ObjectID{}
+		for _, p := range pending {
+			ids = append(ids, p.ID)
+		}
+		assert.Contains(t, ids, st.ID)
+
+		assert.NoError(t, repo.Cancel(ctx, user, st.ID.Hex()))
+		assert.ErrorIs(t, repo.Cancel(ctx, user, st.ID.Hex()), ErrScheduledLocked)
+
+		canceled, err := repo.ListByUser(ctx, user, []string{models.ScheduledStatusCanceled})
+		assert.NoError(t, err)
+		assert.Len(t, canceled, 1)
+
+		_, err = repo.GetByID(ctx, primitive.NewObjectID().Hex(), st.ID.Hex())
+		assert.ErrorIs(t, err, ErrScheduledNotFound)
+	})
+}
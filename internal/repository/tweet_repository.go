@@ -3,9 +3,9 @@ package repository
 
 import (
 	"context"
-	"fmt"
 	"time"
 
+	"github.com/ffelixf/microblog-platform/internal/apperr"
 	"github.com/ffelixf/microblog-platform/internal/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -13,49 +13,64 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrTweetExists indica que ya hay un tweet con el ID indicado (p. ej. un tweet programado ya publicado)
+var ErrTweetExists = apperr.Conflict("tweet_exists", "el tweet ya existe")
+
+// ErrTweetNotInReview indica que el tweet no está retenido ni marcado por la política de contenido
+var ErrTweetNotInReview = apperr.Conflict("tweet_not_in_review", "el tweet no está pendiente de revisión")
+
+// visible restringe un filtro a los tweets que no ocultó la moderación ni
+// retuvo la política de contenido
+func visible(filter bson.M) bson.M {
+	filter["hidden_at"] = bson.M{"$exists": false}
+	filter["policy.status"] = bson.M{"$ne": models.PolicyStatusHeld}
+	return filter
+}
+
 type TweetRepository struct {
 	collection *mongo.Collection
-	db         *mongo.Database
 }
 
 func NewTweetRepository(client *mongo.Client, dbName string) *TweetRepository {
-	db := client.Database(dbName)
-	collection := db.Collection("tweets")
+	collection := client.Database(dbName).Collection("tweets")
 	return &TweetRepository{
 		collection: collection,
-		db:         db,
 	}
 }
 
-func (r *TweetRepository) Create(ctx context.Context, tweet *models.Tweet) error {
-	// Validar que existe el usuario
-	if tweet.UserID.IsZero() {
-		return fmt.Errorf("el ID de usuario es requerido")
-	}
-
-	// Validar contenido del tweet
-	if tweet.Content == "" {
-		return fmt.Errorf("el contenido del tweet no puede estar vacío")
-	}
-
-	// Validar longitud máxima
-	if len(tweet.Content) > 280 {
-		return fmt.Errorf("el contenido del tweet no puede exceder los 280 caracteres")
-	}
-
-	// Validar que el usuario existe
-	err := r.db.Collection("users").FindOne(ctx, bson.M{"_id": tweet.UserID}).Err()
+// EnsureIndexes crea los índices de las heurísticas de spam, que cuentan tweets
+// recientes por contenido y por autor, y el de la cola de revisión
+func (r *TweetRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{
+			Keys: bson.D{{Key: "content_hash", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{
+				"content_hash": bson.M{"$exists": true},
+			}),
+		},
+		{
+			Keys: bson.D{{Key: "policy.status", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{
+				"policy.status": bson.M{"$exists": true},
+			}),
+		},
+	})
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return fmt.Errorf("el usuario especificado no existe")
-		}
-		return fmt.Errorf("error al verificar usuario: %v", err)
+		return dbError("error al crear índices de tweets", err)
 	}
+	return nil
+}
 
+// Create guarda un tweet ya validado. Si tweet.ID viene fijado se respeta.
+func (r *TweetRepository) Create(ctx context.Context, tweet *models.Tweet) error {
 	tweet.CreatedAt = time.Now()
 	result, err := r.collection.InsertOne(ctx, tweet)
 	if err != nil {
-		return fmt.Errorf("error al crear tweet: %v", err)
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrTweetExists.Wrap(err)
+		}
+		return dbError("error al crear tweet", err)
 	}
 
 	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
@@ -64,83 +79,350 @@ func (r *TweetRepository) Create(ctx context.Context, tweet *models.Tweet) error
 	return nil
 }
 
+// GetByID obtiene un tweet; devuelve ErrTweetNotFound si no existe o está oculto
+func (r *TweetRepository) GetByID(ctx context.Context, id string) (*models.Tweet, error) {
+	objectID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var tweet models.Tweet
+	if err := r.collection.FindOne(ctx, visible(bson.M{"_id": objectID})).Decode(&tweet); err != nil {
+		return nil, findError("error al obtener tweet", err, ErrTweetNotFound)
+	}
+	return &tweet, nil
+}
+
 func (r *TweetRepository) GetByUserID(ctx context.Context, userID string) ([]models.Tweet, error) {
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := parseID(userID)
 	if err != nil {
-		return nil, fmt.Errorf("ID de usuario inválido: %v", err)
+		return nil, err
 	}
 
 	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
-	cursor, err := r.collection.Find(ctx, bson.M{"user_id": objectID}, opts)
+	cursor, err := r.collection.Find(ctx, visible(bson.M{"user_id": objectID}), opts)
 	if err != nil {
-		return nil, fmt.Errorf("error al buscar tweets: %v", err)
+		return nil, dbError("error al buscar tweets", err)
 	}
 	defer cursor.Close(ctx)
 
 	var tweets []models.Tweet
 	if err = cursor.All(ctx, &tweets); err != nil {
-		return nil, fmt.Errorf("error al decodificar tweets: %v", err)
+		return nil, dbError("error al decodificar tweets", err)
 	}
 
 	return tweets, nil
 }
 
-func (r *TweetRepository) GetTimeline(ctx context.Context, userID string, page, limit int) ([]models.Tweet, error) {
-	// Validar parámetros de paginación
-	if page < 1 {
-		return nil, fmt.Errorf("número de página inválido: debe ser mayor a 0")
-	}
-	if limit < 1 {
-		return nil, fmt.Errorf("tamaño de página inválido: debe ser mayor a 0")
-	}
-	if limit > 100 {
-		return nil, fmt.Errorf("tamaño de página máximo excedido: máximo 100")
+// AllByUser devuelve todos los tweets del usuario, ocultos y retenidos
+// incluidos, del más antiguo al más reciente; lo usa la exportación de datos
+func (r *TweetRepository) AllByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Tweet, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, dbError("error al buscar tweets", err)
 	}
+	defer cursor.Close(ctx)
 
-	objectID, err := primitive.ObjectIDFromHex(userID)
-	if err != nil {
-		return nil, fmt.Errorf("ID de usuario inválido: %v", err)
+	tweets := []models.Tweet{}
+	if err := cursor.All(ctx, &tweets); err != nil {
+		return nil, dbError("error al decodificar tweets", err)
 	}
+	return tweets, nil
+}
 
-	// Obtener la lista de usuarios seguidos
-	var user models.User
-	err = r.db.Collection("users").FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+// GetByHashtag obtiene los tweets más recientes que contienen el hashtag (ya
+// normalizado), sin los de los autores de excludeAuthors
+func (r *TweetRepository) GetByHashtag(ctx context.Context, tag string, excludeAuthors []primitive.ObjectID, limit int) ([]models.Tweet, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+	filter := bson.M{"hashtags": tag}
+	if len(excludeAuthors) > 0 {
+		filter["user_id"] = bson.M{"$nin": excludeAuthors}
+	}
+	cursor, err := r.collection.Find(ctx, visible(filter), opts)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return []models.Tweet{}, nil
-		}
-		return nil, fmt.Errorf("error al obtener usuario: %v", err)
+		return nil, dbError("error al buscar tweets", err)
 	}
+	defer cursor.Close(ctx)
 
-	// Preparar lista de IDs para la consulta
-	followingIDs := []primitive.ObjectID{objectID} // Incluir tweets propios
-	for _, id := range user.Following {
-		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
-			followingIDs = append(followingIDs, objID)
-		}
+	var tweets []models.Tweet
+	if err = cursor.All(ctx, &tweets); err != nil {
+		return nil, dbError("error al decodificar tweets", err)
 	}
 
-	// Configurar opciones de búsqueda
-	skip := (page - 1) * limit
+	return tweets, nil
+}
+
+// GetByAuthors obtiene una página de los tweets de los autores indicados, del más reciente al más antiguo
+func (r *TweetRepository) GetByAuthors(ctx context.Context, authorIDs []primitive.ObjectID, skip, limit int) ([]models.Tweet, error) {
 	opts := options.Find().
 		SetSort(bson.D{{Key: "created_at", Value: -1}}).
 		SetSkip(int64(skip)).
 		SetLimit(int64(limit))
 
-	// Buscar tweets
 	cursor, err := r.collection.Find(ctx,
-		bson.M{"user_id": bson.M{"$in": followingIDs}},
+		visible(bson.M{"user_id": bson.M{"$in": authorIDs}}),
 		opts,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("error al obtener tweets: %v", err)
+		return nil, dbError("error al obtener tweets", err)
 	}
 	defer cursor.Close(ctx)
 
 	var tweets []models.Tweet
 	if err = cursor.All(ctx, &tweets); err != nil {
-		return nil, fmt.Errorf("error al decodificar tweets: %v", err)
+		return nil, dbError("error al decodificar tweets", err)
 	}
 
 	return tweets, nil
 }
+
+// GetByAuthorsAfter obtiene hasta limit tweets de los autores indicados que van
+// después de la posición after, del más reciente al más antiguo; sin after
+// empieza por el más reciente. A diferencia de GetByAuthors, los tweets nuevos
+// no desplazan los siguientes.
+func (r *TweetRepository) GetByAuthorsAfter(ctx context.Context, authorIDs []primitive.ObjectID, after *models.TweetPosition, limit int) ([]models.Tweet, error) {
+	filter := bson.M{"user_id": bson.M{"$in": authorIDs}}
+	if after != nil {
+		filter["$or"] = bson.A{
+			bson.M{"created_at": bson.M{"$lt": after.CreatedAt}},
+			bson.M{"created_at": after.CreatedAt, "_id": bson.M{"$lt": after.ID}},
+		}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, visible(filter), opts)
+	if err != nil {
+		return nil, dbError("error al obtener tweets", err)
+	}
+	defer cursor.Close(ctx)
+
+	tweets := []models.Tweet{}
+	if err = cursor.All(ctx, &tweets); err != nil {
+		return nil, dbError("error al decodificar tweets", err)
+	}
+	return tweets, nil
+}
+
+// GetByAuthorsSince obtiene hasta limit tweets de los autores indicados que son
+// posteriores a la posición since, del más antiguo al más reciente
+func (r *TweetRepository) GetByAuthorsSince(ctx context.Context, authorIDs []primitive.ObjectID, since models.TweetPosition, limit int) ([]models.Tweet, error) {
+	filter := bson.M{
+		"user_id": bson.M{"$in": authorIDs},
+		"$or": bson.A{
+			bson.M{"created_at": bson.M{"$gt": since.CreatedAt}},
+			bson.M{"created_at": since.CreatedAt, "_id": bson.M{"$gt": since.ID}},
+		},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, visible(filter), opts)
+	if err != nil {
+		return nil, dbError("error al obtener tweets", err)
+	}
+	defer cursor.Close(ctx)
+
+	tweets := []models.Tweet{}
+	if err = cursor.All(ctx, &tweets); err != nil {
+		return nil, dbError("error al decodificar tweets", err)
+	}
+	return tweets, nil
+}
+
+// GetByIDs obtiene los tweets visibles con los IDs indicados, en cualquier orden;
+// los que no existen o están ocultos no aparecen
+func (r *TweetRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Tweet, error) {
+	tweets := []models.Tweet{}
+	if len(ids) == 0 {
+		return tweets, nil
+	}
+	cursor, err := r.collection.Find(ctx, visible(bson.M{"_id": bson.M{"$in": ids}}))
+	if err != nil {
+		return nil, dbError("error al obtener tweets", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &tweets); err != nil {
+		return nil, dbError("error al decodificar tweets", err)
+	}
+	return tweets, nil
+}
+
+// Hide oculta un tweet por moderación. Ocultar un tweet ya oculto conserva la
+// fecha original.
+func (r *TweetRepository) Hide(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$min": bson.M{"hidden_at": at}},
+	)
+	if err != nil {
+		return dbError("error al ocultar tweet", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrTweetNotFound
+	}
+	return nil
+}
+
+// Delete borra un tweet, esté oculto o no; devuelve ErrTweetNotFound si no existe
+func (r *TweetRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return dbError("error al borrar tweet", err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrTweetNotFound
+	}
+	return nil
+}
+
+// DeleteByUser borra todos los tweets del usuario, ocultos incluidos
+func (r *TweetRepository) DeleteByUser(ctx context.Context, userID primitive.ObjectID) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+		return dbError("error al borrar tweets del usuario", err)
+	}
+	return nil
+}
+
+// Walk recorre todos los tweets, ocultos y retenidos incluidos, del más antiguo
+// al más reciente; lo usa la exportación del conjunto de datos
+func (r *TweetRepository) Walk(ctx context.Context, fn func(tweet *models.Tweet) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return dbError("error al recorrer tweets", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var tweet models.Tweet
+		if err := cursor.Decode(&tweet); err != nil {
+			return dbError("error al decodificar tweet", err)
+		}
+		if err := fn(&tweet); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return dbError("error al recorrer tweets", err)
+	}
+	return nil
+}
+
+// SetHashtags reemplaza los hashtags guardados de un tweet; sin hashtags quita
+// el campo, como al crearlo
+func (r *TweetRepository) SetHashtags(ctx context.Context, id primitive.ObjectID, tags []string) error {
+	update := bson.M{"$set": bson.M{"hashtags": tags}}
+	if len(tags) == 0 {
+		update = bson.M{"$unset": bson.M{"hashtags": ""}}
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return dbError("error al guardar hashtags", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrTweetNotFound
+	}
+	return nil
+}
+
+// ImportMany inserta tweets ya validados conservando sus IDs y fechas, a
+// diferencia de Create. Los que ya existen se dan por importados, así que
+// repetir un lote no falla.
+func (r *TweetRepository) ImportMany(ctx context.Context, tweets []models.Tweet) error {
+	ids := make([]primitive.ObjectID, len(tweets))
+	docs := make([]any, len(tweets))
+	for i := range tweets {
+		ids[i], docs[i] = tweets[i].ID, tweets[i]
+	}
+	// Los tweets no tienen índices únicos aparte del ID: un choque es un tweet
+	// que otra importación insertó mientras tanto
+	_, err := insertNew(ctx, r.collection, "error al importar tweets", ids, docs)
+	return err
+}
+
+// CountByContentHash cuenta los tweets con el mismo contenido normalizado
+// publicados desde since, ocultos y retenidos incluidos
+func (r *TweetRepository) CountByContentHash(ctx context.Context, hash string, since time.Time) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"content_hash": hash,
+		"created_at":   bson.M{"$gte": since},
+	})
+	if err != nil {
+		return 0, dbError("error al contar tweets duplicados", err)
+	}
+	return count, nil
+}
+
+// CountByUserSince cuenta los tweets del usuario publicados desde since, ocultos
+// y retenidos incluidos
+func (r *TweetRepository) CountByUserSince(ctx context.Context, userID primitive.ObjectID, since time.Time) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"user_id":    userID,
+		"created_at": bson.M{"$gte": since},
+	})
+	if err != nil {
+		return 0, dbError("error al contar tweets recientes", err)
+	}
+	return count, nil
+}
+
+// ListByPolicyStatus devuelve una página de la cola de revisión de la política
+// de contenido, del tweet más reciente al más antiguo
+func (r *TweetRepository) ListByPolicyStatus(ctx context.Context, status string, skip, limit int) ([]models.Tweet, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"policy.status": status}, opts)
+	if err != nil {
+		return nil, dbError("error al obtener la cola de revisión", err)
+	}
+	defer cursor.Close(ctx)
+
+	tweets := []models.Tweet{}
+	if err = cursor.All(ctx, &tweets); err != nil {
+		return nil, dbError("error al decodificar tweets", err)
+	}
+	return tweets, nil
+}
+
+// ApprovePolicy aprueba un tweet retenido o marcado y lo devuelve como estaba
+// antes, para saber si hay que publicarlo. Devuelve ErrTweetNotInReview si no
+// está pendiente de revisión.
+func (r *TweetRepository) ApprovePolicy(ctx context.Context, id primitive.ObjectID, reviewer string, at time.Time) (*models.Tweet, error) {
+	var tweet models.Tweet
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"_id":           id,
+			"policy.status": bson.M{"$in": []string{models.PolicyStatusHeld, models.PolicyStatusFlagged}},
+		},
+		bson.M{"$set": bson.M{
+			"policy.status":      models.PolicyStatusApproved,
+			"policy.reviewed_by": reviewer,
+			"policy.reviewed_at": at,
+		}},
+	).Decode(&tweet)
+	if err == mongo.ErrNoDocuments {
+		if _, err := r.collection.FindOne(ctx, bson.M{"_id": id}).Raw(); err == mongo.ErrNoDocuments {
+			return nil, ErrTweetNotFound
+		}
+		return nil, ErrTweetNotInReview
+	}
+	if err != nil {
+		return nil, dbError("error al aprobar tweet", err)
+	}
+	return &tweet, nil
+}
@@ -4,12 +4,12 @@ package repository
 import (
 	"context"
 	"fmt"
-	"strings"
 	"testing"
 	"time"
 
 	"github.com/ffelixf/microblog-platform/internal/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -99,62 +99,68 @@ func TestTweetRepository_Create(t *testing.T) {
 		assert.Equal(t, userID, tweet.UserID)
 	})
 
-	t.Run("tweet without content", func(t *testing.T) {
+	t.Run("tweet with given ID", func(t *testing.T) {
+		id := primitive.NewObjectID()
 		tweet := &models.Tweet{
+			ID:      id,
 			UserID:  userID,
-			Content: "",
+			Content: "Tweet programado",
 		}
 
 		err := repo.Create(ctx, tweet)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "no puede estar vacío")
+		assert.NoError(t, err)
+		assert.Equal(t, id, tweet.ID)
+
+		// Un segundo intento con el mismo ID no duplica el tweet
+		err = repo.Create(ctx, &models.Tweet{ID: id, UserID: userID, Content: "Tweet programado"})
+		assert.ErrorIs(t, err, ErrTweetExists)
 	})
+}
 
-	t.Run("tweet exceeding max length", func(t *testing.T) {
-		longContent := strings.Repeat("a", 281)
-		tweet := &models.Tweet{
-			UserID:  userID,
-			Content: longContent,
-		}
+func TestTweetRepository_GetByID(t *testing.T) {
+	client, cleanup := setupTweetTestDB(t)
+	defer cleanup()
 
-		err := repo.Create(ctx, tweet)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "no puede exceder los 280 caracteres")
-	})
+	repo := NewTweetRepository(client, "test_db")
+	ctx := context.Background()
+	userID := createTestUserForTweets(t, client)
 
-	t.Run("tweet with valid max length", func(t *testing.T) {
-		content := strings.Repeat("a", 280)
-		tweet := &models.Tweet{
-			UserID:  userID,
-			Content: content,
-		}
+	t.Run("get existing tweet", func(t *testing.T) {
+		tweet := &models.Tweet{UserID: userID, Content: "Buscado"}
+		assert.NoError(t, repo.Create(ctx, tweet))
 
-		err := repo.Create(ctx, tweet)
+		found, err := repo.GetByID(ctx, tweet.ID.Hex())
 		assert.NoError(t, err)
-		assert.NotEmpty(t, tweet.ID)
+		assert.Equal(t, "Buscado", found.Content)
 	})
 
-	t.Run("tweet with non-existent user", func(t *testing.T) {
-		nonExistentUserID := primitive.NewObjectID()
-		tweet := &models.Tweet{
-			UserID:  nonExistentUserID,
-			Content: "Test content",
-		}
+	t.Run("tweet not found", func(t *testing.T) {
+		_, err := repo.GetByID(ctx, primitive.NewObjectID().Hex())
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	})
 
-		err := repo.Create(ctx, tweet)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "usuario especificado no existe")
+	t.Run("hidden tweet", func(t *testing.T) {
+		tweet := &models.Tweet{UserID: userID, Content: "Oculto #moderado", Hashtags: []string{"moderado"}}
+		assert.NoError(t, repo.Create(ctx, tweet))
+		assert.NoError(t, repo.Hide(ctx, tweet.ID, time.Now()))
+
+		_, err := repo.GetByID(ctx, tweet.ID.Hex())
+		assert.ErrorIs(t, err, ErrTweetNotFound)
+		tagged, err := repo.GetByHashtag(ctx, "moderado", nil, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, tagged)
+
+		assert.ErrorIs(t, repo.Hide(ctx, primitive.NewObjectID(), time.Now()), ErrTweetNotFound)
 	})
 
-	t.Run("tweet with zero user ID", func(t *testing.T) {
-		tweet := &models.Tweet{
-			UserID:  primitive.ObjectID{},
-			Content: "Test content",
-		}
+	t.Run("deleted tweet", func(t *testing.T) {
+		tweet := &models.Tweet{UserID: userID, Content: "Borrado"}
+		assert.NoError(t, repo.Create(ctx, tweet))
+		assert.NoError(t, repo.Delete(ctx, tweet.ID.Hex()))
 
-		err := repo.Create(ctx, tweet)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "ID de usuario es requerido")
+		_, err := repo.GetByID(ctx, tweet.ID.Hex())
+		assert.ErrorIs(t, err, ErrTweetNotFound)
+		assert.ErrorIs(t, repo.Delete(ctx, tweet.ID.Hex()), ErrTweetNotFound)
 	})
 }
 
@@ -228,83 +234,51 @@ func TestTweetRepository_GetByUserID(t *testing.T) {
 	})
 }
 
-func TestTweetRepository_GetTimeline(t *testing.T) {
+func TestTweetRepository_GetByAuthors(t *testing.T) {
 	client, cleanup := setupTweetTestDB(t)
 	defer cleanup()
 
 	repo := NewTweetRepository(client, "test_db")
 	ctx := context.Background()
 
-	t.Run("get timeline with tweets", func(t *testing.T) {
-		// Crear usuarios
-		userID := createTestUserForTweets(t, client)
-		followedID1 := createTestUserForTweets(t, client)
-		followedID2 := createTestUserForTweets(t, client)
-
-		// Establecer relaciones de following
-		_, err := client.Database("test_db").Collection("users").UpdateOne(
-			ctx,
-			bson.M{"_id": userID},
-			bson.M{"$set": bson.M{"following": []string{followedID1.Hex(), followedID2.Hex()}}},
-		)
-		assert.NoError(t, err)
+	t.Run("only tweets from given authors", func(t *testing.T) {
+		author1 := createTestUserForTweets(t, client)
+		author2 := createTestUserForTweets(t, client)
+		other := createTestUserForTweets(t, client)
 
-		// Crear tweets para los usuarios seguidos
-		tweet1 := &models.Tweet{
-			UserID:  followedID1,
-			Content: "Tweet from followed user 1",
+		for _, id := range []primitive.ObjectID{author1, author2, other} {
+			err := repo.Create(ctx, &models.Tweet{UserID: id, Content: "Tweet de " + id.Hex()})
+			assert.NoError(t, err)
+			time.Sleep(time.Millisecond * 10) // Asegurar diferentes timestamps
 		}
-		err = repo.Create(ctx, tweet1)
-		assert.NoError(t, err)
 
-		tweet2 := &models.Tweet{
-			UserID:  followedID2,
-			Content: "Tweet from followed user 2",
-		}
-		err = repo.Create(ctx, tweet2)
-		assert.NoError(t, err)
-
-		// Obtener timeline
-		tweets, err := repo.GetTimeline(ctx, userID.Hex(), 1, 10)
+		tweets, err := repo.GetByAuthors(ctx, []primitive.ObjectID{author1, author2}, 0, 10)
 		assert.NoError(t, err)
 		assert.Len(t, tweets, 2)
 
 		// Verificar orden cronológico inverso
-		assert.True(t, tweets[0].CreatedAt.After(tweets[1].CreatedAt) ||
-			tweets[0].CreatedAt.Equal(tweets[1].CreatedAt))
+		assert.Equal(t, author2, tweets[0].UserID)
+		assert.Equal(t, author1, tweets[1].UserID)
 	})
 
-	t.Run("timeline pagination", func(t *testing.T) {
-		// Crear usuario y seguidor
-		userID := createTestUserForTweets(t, client)
-		followedID := createTestUserForTweets(t, client)
-
-		// Establecer relación de following
-		_, err := client.Database("test_db").Collection("users").UpdateOne(
-			ctx,
-			bson.M{"_id": userID},
-			bson.M{"$set": bson.M{"following": []string{followedID.Hex()}}},
-		)
-		assert.NoError(t, err)
+	t.Run("skip and limit", func(t *testing.T) {
+		author := createTestUserForTweets(t, client)
 
 		// Crear varios tweets
 		for i := 0; i < 15; i++ {
-			tweet := &models.Tweet{
-				UserID:  followedID,
+			err := repo.Create(ctx, &models.Tweet{
+				UserID:  author,
 				Content: fmt.Sprintf("Paginated tweet %d", i),
-			}
-			err := repo.Create(ctx, tweet)
+			})
 			assert.NoError(t, err)
 			time.Sleep(time.Millisecond * 10) // Asegurar diferentes timestamps
 		}
 
-		// Probar primera página
-		page1, err := repo.GetTimeline(ctx, userID.Hex(), 1, 10)
+		page1, err := repo.GetByAuthors(ctx, []primitive.ObjectID{author}, 0, 10)
 		assert.NoError(t, err)
 		assert.Len(t, page1, 10)
 
-		// Probar segunda página
-		page2, err := repo.GetTimeline(ctx, userID.Hex(), 2, 10)
+		page2, err := repo.GetByAuthors(ctx, []primitive.ObjectID{author}, 10, 10)
 		assert.NoError(t, err)
 		assert.Len(t, page2, 5)
 
@@ -316,49 +290,194 @@ func TestTweetRepository_GetTimeline(t *testing.T) {
 		}
 	})
 
-	t.Run("empty timeline", func(t *testing.T) {
-		userID := createTestUserForTweets(t, client)
-		tweets, err := repo.GetTimeline(ctx, userID.Hex(), 1, 10)
+	t.Run("no authors", func(t *testing.T) {
+		tweets, err := repo.GetByAuthors(ctx, []primitive.ObjectID{primitive.NewObjectID()}, 0, 10)
 		assert.NoError(t, err)
 		assert.Empty(t, tweets)
 	})
+}
+
+func TestTweetRepository_GetByAuthorsAfter(t *testing.T) {
+	client, cleanup := setupTweetTestDB(t)
+	defer cleanup()
+
+	repo := NewTweetRepository(client, "test_db")
+	ctx := context.Background()
+	author := createTestUserForTweets(t, client)
+
+	// Dos tweets con la misma fecha: el ID desempata
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	hiddenAt := createdAt
+	tweets := []models.Tweet{
+		{ID: primitive.NewObjectID(), UserID: author, Content: "primero", CreatedAt: createdAt},
+		{ID: primitive.NewObjectID(), UserID: author, Content: "segundo", CreatedAt: createdAt.Add(time.Minute)},
+		{ID: primitive.NewObjectID(), UserID: author, Content: "tercero", CreatedAt: createdAt.Add(time.Minute)},
+		{ID: primitive.NewObjectID(), UserID: author, Content: "oculto", CreatedAt: createdAt.Add(time.Hour), HiddenAt: &hiddenAt},
+	}
+	require.NoError(t, repo.ImportMany(ctx, tweets))
+
+	first, err := repo.GetByAuthorsAfter(ctx, []primitive.ObjectID{author}, nil, 2)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.Equal(t, tweets[2].ID, first[0].ID)
+	assert.Equal(t, tweets[1].ID, first[1].ID)
+
+	// Un tweet nuevo no cambia lo que sigue a la posición
+	require.NoError(t, repo.Create(ctx, &models.Tweet{UserID: author, Content: "nuevo"}))
+	position := first[1].Position()
+	rest, err := repo.GetByAuthorsAfter(ctx, []primitive.ObjectID{author}, &position, 2)
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.Equal(t, tweets[0].ID, rest[0].ID)
+
+	// Desde una posición se leen los posteriores, del más antiguo al más reciente
+	since, err := repo.GetByAuthorsSince(ctx, []primitive.ObjectID{author}, tweets[0].Position(), 2)
+	require.NoError(t, err)
+	require.Len(t, since, 2)
+	assert.Equal(t, tweets[1].ID, since[0].ID)
+	assert.Equal(t, tweets[2].ID, since[1].ID)
+
+	found, err := repo.GetByIDs(ctx, []primitive.ObjectID{tweets[0].ID, tweets[3].ID, primitive.NewObjectID()})
+	require.NoError(t, err)
+	require.Len(t, found, 1, "sin ocultos ni inexistentes")
+	assert.Equal(t, tweets[0].ID, found[0].ID)
+}
+
+func TestTweetRepository_GetByHashtag(t *testing.T) {
+	client, cleanup := setupTweetTestDB(t)
+	defer cleanup()
+
+	repo := NewTweetRepository(client, "test_db")
+	ctx := context.Background()
 
-	// Enhanced Timeline Tests
-	t.Run("invalid page number", func(t *testing.T) {
+	t.Run("get tweets by hashtag", func(t *testing.T) {
 		userID := createTestUserForTweets(t, client)
-		tweets, err := repo.GetTimeline(ctx, userID.Hex(), 0, 10)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "número de página inválido")
-		assert.Nil(t, tweets)
+		for i := 0; i < 3; i++ {
+			err := repo.Create(ctx, &models.Tweet{
+				UserID:   userID,
+				Content:  fmt.Sprintf("Tweet %d sobre #feeds", i),
+				Hashtags: []string{"feeds"},
+			})
+			assert.NoError(t, err)
+		}
+		err := repo.Create(ctx, &models.Tweet{UserID: userID, Content: "Sin hashtag"})
+		assert.NoError(t, err)
+
+		tweets, err := repo.GetByHashtag(ctx, "feeds", nil, 10)
+		assert.NoError(t, err)
+		assert.Len(t, tweets, 3)
+
+		limited, err := repo.GetByHashtag(ctx, "feeds", nil, 2)
+		assert.NoError(t, err)
+		assert.Len(t, limited, 2)
+
+		excluded, err := repo.GetByHashtag(ctx, "feeds", []primitive.ObjectID{userID}, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, excluded)
 	})
+}
 
-	t.Run("invalid page size", func(t *testing.T) {
-		userID := createTestUserForTweets(t, client)
-		tweets, err := repo.GetTimeline(ctx, userID.Hex(), 1, 0)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "tamaño de página inválido")
-		assert.Nil(t, tweets)
+func TestTweetRepository_Policy(t *testing.T) {
+	client, cleanup := setupTweetTestDB(t)
+	defer cleanup()
+
+	repo := NewTweetRepository(client, "test_db")
+	ctx := context.Background()
+	assert.NoError(t, repo.EnsureIndexes(ctx))
+	userID := createTestUserForTweets(t, client)
+	since := time.Now().Add(-time.Minute)
+
+	held := &models.Tweet{UserID: userID, Content: "oferta", ContentHash: "abc",
+		Policy: &models.TweetPolicy{Status: models.PolicyStatusHeld, Rules: []string{"duplicate"}}}
+	flagged := &models.Tweet{UserID: userID, Content: "oferta", ContentHash: "abc",
+		Policy: &models.TweetPolicy{Status: models.PolicyStatusFlagged, Rules: []string{"links"}}}
+	plain := &models.Tweet{UserID: userID, Content: "hola"}
+	for _, tweet := range []*models.Tweet{held, flagged, plain} {
+		assert.NoError(t, repo.Create(ctx, tweet))
+	}
+
+	t.Run("held tweets are not visible", func(t *testing.T) {
+		_, err := repo.GetByID(ctx, held.ID.Hex())
+		assert.ErrorIs(t, err, ErrTweetNotFound)
+		tweets, err := repo.GetByUserID(ctx, userID.Hex())
+		assert.NoError(t, err)
+		assert.Len(t, tweets, 2)
 	})
 
-	t.Run("max page size exceeded", func(t *testing.T) {
-		userID := createTestUserForTweets(t, client)
-		tweets, err := repo.GetTimeline(ctx, userID.Hex(), 1, 101)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "tamaño de página máximo excedido")
-		assert.Nil(t, tweets)
+	t.Run("export includes held tweets", func(t *testing.T) {
+		tweets, err := repo.AllByUser(ctx, userID)
+		assert.NoError(t, err)
+		if assert.Len(t, tweets, 3) {
+			assert.Equal(t, held.ID, tweets[0].ID, "del más antiguo al más reciente")
+		}
 	})
 
-	t.Run("invalid user ID format", func(t *testing.T) {
-		tweets, err := repo.GetTimeline(ctx, "invalid-id", 1, 10)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "ID de usuario inválido")
-		assert.Nil(t, tweets)
+	t.Run("counts include held tweets", func(t *testing.T) {
+		count, err := repo.CountByContentHash(ctx, "abc", since)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+		count, err = repo.CountByUserSince(ctx, userID, since)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+		count, err = repo.CountByContentHash(ctx, "abc", time.Now().Add(time.Minute))
+		assert.NoError(t, err)
+		assert.Zero(t, count)
 	})
 
-	t.Run("non-existent user", func(t *testing.T) {
-		nonExistentID := primitive.NewObjectID()
-		tweets, err := repo.GetTimeline(ctx, nonExistentID.Hex(), 1, 10)
+	t.Run("review queue", func(t *testing.T) {
+		queue, err := repo.ListByPolicyStatus(ctx, models.PolicyStatusHeld, 0, 10)
 		assert.NoError(t, err)
-		assert.Empty(t, tweets)
+		if assert.Len(t, queue, 1) {
+			assert.Equal(t, held.ID, queue[0].ID)
+		}
+	})
+
+	t.Run("approve", func(t *testing.T) {
+		before, err := repo.ApprovePolicy(ctx, held.ID, "moderador", time.Now())
+		assert.NoError(t, err)
+		assert.True(t, before.Held(), "devuelve el estado anterior")
+
+		approved, err := repo.GetByID(ctx, held.ID.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, models.PolicyStatusApproved, approved.Policy.Status)
+		assert.Equal(t, "moderador", approved.Policy.ReviewedBy)
+
+		_, err = repo.ApprovePolicy(ctx, held.ID, "moderador", time.Now())
+		assert.ErrorIs(t, err, ErrTweetNotInReview)
+		_, err = repo.ApprovePolicy(ctx, plain.ID, "moderador", time.Now())
+		assert.ErrorIs(t, err, ErrTweetNotInReview)
+		_, err = repo.ApprovePolicy(ctx, primitive.NewObjectID(), "moderador", time.Now())
+		assert.ErrorIs(t, err, ErrTweetNotFound)
 	})
 }
+
+func TestTweetRepository_Import(t *testing.T) {
+	client, cleanup := setupTweetTestDB(t)
+	defer cleanup()
+
+	repo := NewTweetRepository(client, "test_db")
+	ctx := context.Background()
+	userID := createTestUserForTweets(t, client)
+
+	createdAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	hiddenAt := createdAt.Add(time.Hour)
+	tweets := []models.Tweet{
+		{ID: primitive.NewObjectID(), UserID: userID, Content: "primero", CreatedAt: createdAt},
+		{ID: primitive.NewObjectID(), UserID: userID, Content: "oculto", CreatedAt: createdAt.Add(time.Minute), HiddenAt: &hiddenAt},
+	}
+	assert.NoError(t, repo.ImportMany(ctx, tweets))
+	// Repetir el lote tras un fallo no duplica nada
+	assert.NoError(t, repo.ImportMany(ctx, tweets))
+
+	var walked []models.Tweet
+	err := repo.Walk(ctx, func(tweet *models.Tweet) error {
+		walked = append(walked, *tweet)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, walked, 2) {
+		assert.Equal(t, tweets[0].ID, walked[0].ID)
+		assert.True(t, createdAt.Equal(walked[0].CreatedAt), "conserva la fecha original")
+		assert.NotNil(t, walked[1].HiddenAt)
+	}
+}
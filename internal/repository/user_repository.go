@@ -3,16 +3,33 @@ package repository
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"time"
 
+	"github.com/ffelixf/microblog-platform/internal/apperr"
 	"github.com/ffelixf/microblog-platform/internal/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// active restringe un filtro a las cuentas que no están suspendidas, desactivadas
+// ni pendientes de borrado. Las suspensiones vencidas cuentan hasta que el job de
+// cuentas las levanta.
+func active(filter bson.M) bson.M {
+	filter["suspension"] = bson.M{"$exists": false}
+	filter["deactivated_at"] = bson.M{"$exists": false}
+	filter["deletion_requested_at"] = bson.M{"$exists": false}
+	return filter
+}
+
+// inactiveFilter es el complemento de active
+var inactiveFilter = bson.M{"$or": bson.A{
+	bson.M{"suspension": bson.M{"$exists": true}},
+	bson.M{"deactivated_at": bson.M{"$exists": true}},
+	bson.M{"deletion_requested_at": bson.M{"$exists": true}},
+}}
+
 type UserRepository struct {
 	collection *mongo.Collection
 }
@@ -24,6 +41,41 @@ func NewUserRepository(client *mongo.Client, dbName string) *UserRepository {
 	}
 }
 
+// EnsureIndexes crea los índices únicos de username y email en los que se apoya
+// ErrUserExists. Los usuarios remotos no tienen email, por eso el índice de email
+// es parcial y solo cubre los documentos donde es un string.
+func (r *UserRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "username", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"email": bson.M{"$type": "string"},
+			}),
+		},
+		{
+			// Solo unos pocos usuarios tienen roles; CountByRole protege al último admin
+			Keys:    bson.D{{Key: "roles", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		// Índices dispersos para las cuentas inactivas, que son pocas; con ellos
+		// InactiveIDs no recorre toda la colección. Las suspensiones sin
+		// vencimiento no tienen expires_at, así que suspension tiene el suyo.
+		{Keys: bson.D{{Key: "suspension", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "suspension.expires_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "deactivated_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "deletion_requested_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "following", Value: 1}}},
+	})
+	if err != nil {
+		return dbError("error al crear índices de usuarios", err)
+	}
+	return nil
+}
+
 // Método existente Create
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	user.CreatedAt = time.Now()
@@ -33,7 +85,10 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 
 	result, err := r.collection.InsertOne(ctx, user)
 	if err != nil {
-		return err
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrUserExists.Wrap(err)
+		}
+		return dbError("error al crear usuario", err)
 	}
 
 	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
@@ -44,7 +99,7 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 
 // Método existente GetByID
 func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := parseID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -52,46 +107,377 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User,
 	var user models.User
 	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
 	if err != nil {
-		return nil, err
+		return nil, findError("error al obtener usuario", err, ErrUserNotFound)
 	}
 
 	return &user, nil
 }
 
-// Nuevo método FollowUser
-func (r *UserRepository) FollowUser(ctx context.Context, userID, targetID string) error {
-	userObjID, err := primitive.ObjectIDFromHex(userID)
+// GetByIDs obtiene los usuarios con los IDs indicados en una sola consulta, en
+// cualquier orden; los que no existen no aparecen
+func (r *UserRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.User, error) {
+	users := []models.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
 	if err != nil {
-		return err
+		return nil, dbError("error al obtener usuarios", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, dbError("error al decodificar usuarios", err)
 	}
-	targetObjID, err := primitive.ObjectIDFromHex(targetID)
+	return users, nil
+}
+
+// GetByUsername obtiene un usuario por su nombre de usuario
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	err := r.collection.FindOne(ctx, bson.M{"username": username}).Decode(&user)
 	if err != nil {
-		return err
+		return nil, findError("error al obtener usuario", err, ErrUserNotFound)
+	}
+
+	return &user, nil
+}
+
+// GetByActorID obtiene un usuario remoto por el ID de su actor ActivityPub
+func (r *UserRepository) GetByActorID(ctx context.Context, actorID string) (*models.User, error) {
+	var user models.User
+	err := r.collection.FindOne(ctx, bson.M{"remote.actor_id": actorID}).Decode(&user)
+	if err != nil {
+		return nil, findError("error al obtener usuario remoto", err, ErrUserNotFound)
+	}
+
+	return &user, nil
+}
+
+// UpsertRemoteUser crea o actualiza el usuario que representa a un actor remoto.
+// username debe incluir el dominio (usuario@instancia) para no colisionar con cuentas locales.
+func (r *UserRepository) UpsertRemoteUser(ctx context.Context, username string, remote *models.RemoteActor) (*models.User, error) {
+	if remote == nil || remote.ActorID == "" {
+		return nil, apperr.InvalidField("remote_actor_required", "actor", "el actor remoto es requerido")
+	}
+
+	now := time.Now()
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var user models.User
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"remote.actor_id": remote.ActorID},
+		bson.M{
+			"$set": bson.M{
+				"username":   username,
+				"remote":     remote,
+				"updated_at": now,
+			},
+			"$setOnInsert": bson.M{
+				"created_at":      now,
+				"following":       []string{},
+				"followers_count": 0,
+			},
+		},
+		opts,
+	).Decode(&user)
+	if err != nil {
+		return nil, dbError("error al guardar usuario remoto", err)
+	}
+
+	return &user, nil
+}
+
+// Suspend suspende la cuenta; una suspensión previa se reemplaza
+func (r *UserRepository) Suspend(ctx context.Context, id primitive.ObjectID, suspension models.Suspension) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"suspension": suspension, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return dbError("error al suspender usuario", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// SetRoles reemplaza los roles y permisos del usuario y devuelve el usuario actualizado
+func (r *UserRepository) SetRoles(ctx context.Context, id primitive.ObjectID, roles, permissions []string) (*models.User, error) {
+	var user models.User
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"roles": roles, "permissions": permissions, "updated_at": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&user)
+	if err != nil {
+		return nil, findError("error al asignar roles", err, ErrUserNotFound)
 	}
+	return &user, nil
+}
 
-	// Verificar que el usuario objetivo existe
-	var targetUser models.User
-	err = r.collection.FindOne(ctx, bson.M{"_id": targetObjID}).Decode(&targetUser)
+// SetMutedWords reemplaza las palabras silenciadas del usuario
+func (r *UserRepository) SetMutedWords(ctx context.Context, id primitive.ObjectID, words []string) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"muted_words": words, "updated_at": time.Now()}},
+	)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return errors.New("usuario objetivo no encontrado")
+		return dbError("error al guardar palabras silenciadas", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// CountByRole cuenta los usuarios con el rol indicado
+func (r *UserRepository) CountByRole(ctx context.Context, role string) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"roles": role})
+	if err != nil {
+		return 0, dbError("error al contar usuarios por rol", err)
+	}
+	return count, nil
+}
+
+// LiftSuspension levanta la suspensión de la cuenta
+func (r *UserRepository) LiftSuspension(ctx context.Context, id primitive.ObjectID) error {
+	return r.updateOne(ctx, "error al levantar suspensión", bson.M{"_id": id},
+		bson.M{"$unset": bson.M{"suspension": ""}, "$set": bson.M{"updated_at": time.Now()}})
+}
+
+// LiftExpiredSuspensions levanta las suspensiones vencidas en now y devuelve cuántas
+func (r *UserRepository) LiftExpiredSuspensions(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{"suspension.expires_at": bson.M{"$lte": now}},
+		bson.M{"$unset": bson.M{"suspension": ""}, "$set": bson.M{"updated_at": now}},
+	)
+	if err != nil {
+		return 0, dbError("error al levantar suspensiones vencidas", err)
+	}
+	return result.ModifiedCount, nil
+}
+
+// Deactivate desactiva la cuenta; desactivar dos veces conserva la fecha original
+func (r *UserRepository) Deactivate(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	return r.updateOne(ctx, "error al desactivar usuario", bson.M{"_id": id},
+		bson.M{"$min": bson.M{"deactivated_at": at}, "$set": bson.M{"updated_at": at}})
+}
+
+// Reactivate reactiva una cuenta desactivada que no está pendiente de borrado
+func (r *UserRepository) Reactivate(ctx context.Context, id primitive.ObjectID) error {
+	return r.updateOne(ctx, "error al reactivar usuario",
+		bson.M{"_id": id, "deletion_requested_at": bson.M{"$exists": false}},
+		bson.M{"$unset": bson.M{"deactivated_at": ""}, "$set": bson.M{"updated_at": time.Now()}})
+}
+
+// DeactivatedBefore devuelve hasta limit cuentas desactivadas antes de cutoff que
+// todavía no están pendientes de borrado
+func (r *UserRepository) DeactivatedBefore(ctx context.Context, cutoff time.Time, limit int) ([]models.User, error) {
+	cursor, err := r.collection.Find(ctx,
+		bson.M{
+			"deactivated_at":        bson.M{"$lt": cutoff},
+			"deletion_requested_at": bson.M{"$exists": false},
+		},
+		options.Find().SetSort(bson.D{{Key: "deactivated_at", Value: 1}}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, dbError("error al obtener cuentas desactivadas", err)
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, dbError("error al decodificar cuentas desactivadas", err)
+	}
+	return users, nil
+}
+
+// InactiveIDs devuelve los IDs de las cuentas suspendidas, desactivadas o
+// pendientes de borrado, que no deben aparecer en timelines ni búsquedas
+func (r *UserRepository) InactiveIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	cursor, err := r.collection.Find(ctx, inactiveFilter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, dbError("error al obtener cuentas inactivas", err)
+	}
+	defer cursor.Close(ctx)
+
+	var ids []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
 		}
-		return err
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, dbError("error al decodificar cuentas inactivas", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, dbError("error al obtener cuentas inactivas", err)
+	}
+	return ids, nil
+}
+
+// MarkForDeletion marca la cuenta como pendiente de borrado; desde ese momento
+// deja de verse y no se puede reactivar
+func (r *UserRepository) MarkForDeletion(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id},
+		bson.M{"$min": bson.M{"deletion_requested_at": at}, "$set": bson.M{"updated_at": at}})
+	if err != nil {
+		return dbError("error al marcar usuario para borrado", err)
+	}
+	return nil
+}
+
+// RemoveFollower quita userID de los seguidos de todas las cuentas
+func (r *UserRepository) RemoveFollower(ctx context.Context, userID string) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"following": userID},
+		bson.M{"$pull": bson.M{"following": userID}},
+	)
+	if err != nil {
+		return dbError("error al quitar seguidor", err)
+	}
+	return nil
+}
+
+// ClearFollowing vacía los seguidos de la cuenta sin tocar los contadores; quien
+// la llama los corrige después con RecountFollowers
+func (r *UserRepository) ClearFollowing(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"following": bson.A{}}})
+	if err != nil {
+		return dbError("error al vaciar seguidos", err)
+	}
+	return nil
+}
+
+// RecountFollowers recalcula followers_count de las cuentas indicadas contando
+// quién las sigue. A diferencia de un decremento, se puede repetir sin error.
+func (r *UserRepository) RecountFollowers(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		count, err := r.collection.CountDocuments(ctx, bson.M{"following": id})
+		if err != nil {
+			return dbError("error al contar seguidores", err)
+		}
+		if _, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID},
+			bson.M{"$set": bson.M{"followers_count": count}}); err != nil {
+			return dbError("error al actualizar seguidores", err)
+		}
+	}
+	return nil
+}
+
+// Walk recorre todos los usuarios en orden de ID; lo usa la exportación del
+// conjunto de datos
+func (r *UserRepository) Walk(ctx context.Context, fn func(user *models.User) error) error {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return dbError("error al recorrer usuarios", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var user models.User
+		if err := cursor.Decode(&user); err != nil {
+			return dbError("error al decodificar usuario", err)
+		}
+		if err := fn(&user); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return dbError("error al recorrer usuarios", err)
+	}
+	return nil
+}
+
+// ImportMany inserta usuarios ya validados conservando sus IDs y fechas. Los que
+// ya existen con el mismo ID se dan por importados, así que repetir un lote no
+// falla. Devuelve las posiciones de los que chocan con el nombre o el email de
+// otra cuenta.
+func (r *UserRepository) ImportMany(ctx context.Context, users []models.User) ([]int, error) {
+	ids := make([]primitive.ObjectID, len(users))
+	docs := make([]any, len(users))
+	for i := range users {
+		if users[i].Following == nil {
+			users[i].Following = make([]string, 0)
+		}
+		ids[i], docs[i] = users[i].ID, users[i]
+	}
+	return insertNew(ctx, r.collection, "error al importar usuarios", ids, docs)
+}
+
+// AddFollowing agrega targetIDs a los seguidos de id sin tocar los contadores;
+// quien importa los recalcula al final con RecountFollowers
+func (r *UserRepository) AddFollowing(ctx context.Context, id primitive.ObjectID, targetIDs []string) error {
+	return r.updateOne(ctx, "error al importar seguidos",
+		bson.M{"_id": id},
+		bson.M{"$addToSet": bson.M{"following": bson.M{"$each": targetIDs}}},
+	)
+}
+
+// AnonymizeModerator quita al moderador id de las suspensiones que aplicó
+func (r *UserRepository) AnonymizeModerator(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"suspension.moderator_id": id},
+		bson.M{"$set": bson.M{"suspension.moderator_id": primitive.NilObjectID}},
+	)
+	if err != nil {
+		return dbError("error al anonimizar moderador", err)
 	}
+	return nil
+}
 
-	// Verificar que no se está siguiendo a sí mismo
-	if userID == targetID {
-		return errors.New("no puedes seguirte a ti mismo")
+// Delete borra la cuenta; borrar una cuenta que ya no existe no es un error
+func (r *UserRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return dbError("error al borrar usuario", err)
+	}
+	return nil
+}
+
+func (r *UserRepository) updateOne(ctx context.Context, op string, filter, update bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return dbError(op, err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// FollowUser agrega targetID a los seguidos de userID. Solo incrementa el contador
+// del seguido si la relación no existía, así que repetir la operación no lo altera.
+func (r *UserRepository) FollowUser(ctx context.Context, userID, targetID string) error {
+	userObjID, err := parseID(userID)
+	if err != nil {
+		return err
+	}
+	targetObjID, err := parseID(targetID)
+	if err != nil {
+		return err
 	}
 
 	// Actualizar following del usuario
-	_, err = r.collection.UpdateOne(
+	result, err := r.collection.UpdateOne(
 		ctx,
-		bson.M{"_id": userObjID},
+		bson.M{"_id": userObjID, "following": bson.M{"$ne": targetID}},
 		bson.M{"$addToSet": bson.M{"following": targetID}},
 	)
 	if err != nil {
-		return err
+		return dbError("error al seguir usuario", err)
+	}
+	if result.ModifiedCount == 0 {
+		return nil
 	}
 
 	// Incrementar followers_count del usuario objetivo
@@ -100,28 +486,35 @@ func (r *UserRepository) FollowUser(ctx context.Context, userID, targetID string
 		bson.M{"_id": targetObjID},
 		bson.M{"$inc": bson.M{"followers_count": 1}},
 	)
-	return err
+	if err != nil {
+		return dbError("error al actualizar seguidores", err)
+	}
+	return nil
 }
 
-// Nuevo método UnfollowUser
+// UnfollowUser quita targetID de los seguidos de userID y decrementa el contador
+// del seguido solo si la relación existía
 func (r *UserRepository) UnfollowUser(ctx context.Context, userID, targetID string) error {
-	userObjID, err := primitive.ObjectIDFromHex(userID)
+	userObjID, err := parseID(userID)
 	if err != nil {
 		return err
 	}
-	targetObjID, err := primitive.ObjectIDFromHex(targetID)
+	targetObjID, err := parseID(targetID)
 	if err != nil {
 		return err
 	}
 
 	// Remover de la lista de following
-	_, err = r.collection.UpdateOne(
+	result, err := r.collection.UpdateOne(
 		ctx,
-		bson.M{"_id": userObjID},
+		bson.M{"_id": userObjID, "following": targetID},
 		bson.M{"$pull": bson.M{"following": targetID}},
 	)
 	if err != nil {
-		return err
+		return dbError("error al dejar de seguir usuario", err)
+	}
+	if result.ModifiedCount == 0 {
+		return nil
 	}
 
 	// Decrementar followers_count
@@ -130,25 +523,25 @@ func (r *UserRepository) UnfollowUser(ctx context.Context, userID, targetID stri
 		bson.M{"_id": targetObjID},
 		bson.M{"$inc": bson.M{"followers_count": -1}},
 	)
-	return err
+	if err != nil {
+		return dbError("error al actualizar seguidores", err)
+	}
+	return nil
 }
 
 // Nuevo método GetFollowing
 func (r *UserRepository) GetFollowing(ctx context.Context, userID string) ([]models.User, error) {
 	// Convertir el ID a ObjectID
-	objectID, err := primitive.ObjectIDFromHex(userID)
+	objectID, err := parseID(userID)
 	if err != nil {
-		return nil, fmt.Errorf("ID de usuario inválido: %v", err)
+		return nil, err
 	}
 
 	// Buscar el usuario primero para verificar que existe
 	var user models.User
 	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("usuario no encontrado")
-		}
-		return nil, err
+		return nil, findError("error al obtener usuario", err, ErrUserNotFound)
 	}
 
 	// Si el usuario no sigue a nadie, retornar lista vacía
@@ -172,17 +565,17 @@ func (r *UserRepository) GetFollowing(ctx context.Context, userID string) ([]mod
 	}
 
 	// Buscar los usuarios que está siguiendo
-	cursor, err := r.collection.Find(ctx, bson.M{
+	cursor, err := r.collection.Find(ctx, active(bson.M{
 		"_id": bson.M{"$in": followingObjIDs},
-	})
+	}))
 	if err != nil {
-		return nil, err
+		return nil, dbError("error al obtener seguidos", err)
 	}
 	defer cursor.Close(ctx)
 
 	var following []models.User
 	if err = cursor.All(ctx, &following); err != nil {
-		return nil, err
+		return nil, dbError("error al decodificar seguidos", err)
 	}
 
 	return following, nil
@@ -190,17 +583,17 @@ func (r *UserRepository) GetFollowing(ctx context.Context, userID string) ([]mod
 
 func (r *UserRepository) GetFollowers(ctx context.Context, userID string) ([]models.User, error) {
 	// Buscar usuarios que tienen este userID en su array "following"
-	cursor, err := r.collection.Find(ctx, bson.M{
+	cursor, err := r.collection.Find(ctx, active(bson.M{
 		"following": userID,
-	})
+	}))
 	if err != nil {
-		return nil, err
+		return nil, dbError("error al obtener seguidores", err)
 	}
 	defer cursor.Close(ctx)
 
 	var followers []models.User
 	if err = cursor.All(ctx, &followers); err != nil {
-		return nil, err
+		return nil, dbError("error al decodificar seguidores", err)
 	}
 
 	return followers, nil
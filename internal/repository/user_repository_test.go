@@ -4,10 +4,11 @@ package repository
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/ffelixf/microblog-platform/internal/models"
 	"github.com/stretchr/testify/assert"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -38,17 +39,7 @@ func setupTestDB(t *testing.T) (*mongo.Client, func()) {
 	t.Log("Successfully connected to MongoDB")
 
 	// Crear índices únicos
-	collection := client.Database("test_db").Collection("users")
-	_, err = collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
-		{
-			Keys:    bson.D{{Key: "username", Value: 1}},
-			Options: options.Index().SetUnique(true),
-		},
-		{
-			Keys:    bson.D{{Key: "email", Value: 1}},
-			Options: options.Index().SetUnique(true),
-		},
-	})
+	err = NewUserRepository(client, "test_db").EnsureIndexes(ctx)
 	if err != nil {
 		t.Fatalf("Error creating indexes: %v", err)
 		return nil, nil
@@ -108,18 +99,7 @@ func TestUserRepository_Create(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Crear índices nuevamente
-		collection := client.Database("test_db").Collection("users")
-		_, err = collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
-			{
-				Keys:    bson.D{{Key: "username", Value: 1}},
-				Options: options.Index().SetUnique(true),
-			},
-			{
-				Keys:    bson.D{{Key: "email", Value: 1}},
-				Options: options.Index().SetUnique(true),
-			},
-		})
-		assert.NoError(t, err)
+		assert.NoError(t, repo.EnsureIndexes(ctx))
 
 		// Crear el primer usuario
 		user1 := &models.User{
@@ -166,6 +146,210 @@ func TestUserRepository_GetByID(t *testing.T) {
 	})
 }
 
+func TestUserRepository_GetByIDs(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewUserRepository(client, "test_db")
+	ctx := context.Background()
+	ana := createTestUser(t, repo, "ana", "ana@example.com")
+	beto := createTestUser(t, repo, "beto", "beto@example.com")
+
+	users, err := repo.GetByIDs(ctx, []primitive.ObjectID{ana.ID, beto.ID, primitive.NewObjectID()})
+	require.NoError(t, err)
+	var names []string
+	for _, user := range users {
+		names = append(names, user.Username)
+	}
+	assert.ElementsMatch(t, []string{"ana", "beto"}, names)
+
+	users, err = repo.GetByIDs(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestUserRepository_GetByUsername(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewUserRepository(client, "test_db")
+	ctx := context.Background()
+
+	t.Run("get existing user", func(t *testing.T) {
+		createdUser := createTestUser(t, repo, "byusername", "byusername@example.com")
+
+		foundUser, err := repo.GetByUsername(ctx, "byusername")
+		assert.NoError(t, err)
+		assert.Equal(t, createdUser.ID, foundUser.ID)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		user, err := repo.GetByUsername(ctx, "nobody")
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+		assert.Nil(t, user)
+	})
+}
+
+func TestUserRepository_SetRoles(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewUserRepository(client, "test_db")
+	ctx := context.Background()
+
+	admin := createTestUser(t, repo, "admin", "admin@example.com")
+	createTestUser(t, repo, "plain", "plain@example.com")
+
+	updated, err := repo.SetRoles(ctx, admin.ID, []string{"admin"}, []string{"reports:read"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admin"}, updated.Roles)
+	assert.Equal(t, []string{"reports:read"}, updated.Permissions)
+
+	count, err := repo.CountByRole(ctx, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	_, err = repo.SetRoles(ctx, primitive.NewObjectID(), nil, nil)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestUserRepository_SetMutedWords(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewUserRepository(client, "test_db")
+	ctx := context.Background()
+	user := createTestUser(t, repo, "muter", "muter@example.com")
+
+	require.NoError(t, repo.SetMutedWords(ctx, user.ID, []string{"spoiler", "final*"}))
+	found, err := repo.GetByID(ctx, user.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"spoiler", "final*"}, found.MutedWords)
+
+	assert.ErrorIs(t, repo.SetMutedWords(ctx, primitive.NewObjectID(), nil), ErrUserNotFound)
+}
+
+func TestUserRepository_Lifecycle(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewUserRepository(client, "test_db")
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	gone := createTestUser(t, repo, "gone", "gone@example.com")
+	star := createTestUser(t, repo, "star", "star@example.com")
+	fan := createTestUser(t, repo, "fan", "fan@example.com")
+	require.NoError(t, repo.FollowUser(ctx, gone.ID.Hex(), star.ID.Hex()))
+	require.NoError(t, repo.FollowUser(ctx, fan.ID.Hex(), star.ID.Hex()))
+	require.NoError(t, repo.FollowUser(ctx, star.ID.Hex(), gone.ID.Hex()))
+
+	t.Run("inactive accounts are hidden from followers", func(t *testing.T) {
+		require.NoError(t, repo.Deactivate(ctx, gone.ID, now.Add(-31*24*time.Hour)))
+		followers, err := repo.GetFollowers(ctx, star.ID.Hex())
+		require.NoError(t, err)
+		require.Len(t, followers, 1)
+		assert.Equal(t, fan.ID, followers[0].ID)
+
+		ids, err := repo.InactiveIDs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []primitive.ObjectID{gone.ID}, ids)
+
+		expired, err := repo.DeactivatedBefore(ctx, now.Add(-models.DeactivationGracePeriod), 10)
+		require.NoError(t, err)
+		require.Len(t, expired, 1)
+		assert.Equal(t, gone.ID, expired[0].ID)
+	})
+
+	t.Run("expired suspensions are lifted", func(t *testing.T) {
+		expires := now.Add(-time.Minute)
+		require.NoError(t, repo.Suspend(ctx, fan.ID, models.Suspension{Reason: "spam", Since: now, ExpiresAt: &expires}))
+		n, err := repo.LiftExpiredSuspensions(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		user, err := repo.GetByID(ctx, fan.ID.Hex())
+		require.NoError(t, err)
+		assert.Nil(t, user.Suspension)
+	})
+
+	t.Run("pending deletion cannot be reactivated", func(t *testing.T) {
+		require.NoError(t, repo.MarkForDeletion(ctx, gone.ID, now))
+		assert.ErrorIs(t, repo.Reactivate(ctx, gone.ID), ErrUserNotFound)
+	})
+
+	t.Run("removing an account fixes references", func(t *testing.T) {
+		require.NoError(t, repo.RemoveFollower(ctx, gone.ID.Hex()))
+		require.NoError(t, repo.ClearFollowing(ctx, gone.ID))
+		require.NoError(t, repo.RecountFollowers(ctx, []string{star.ID.Hex()}))
+		require.NoError(t, repo.Delete(ctx, gone.ID))
+
+		updated, err := repo.GetByID(ctx, star.ID.Hex())
+		require.NoError(t, err)
+		assert.Empty(t, updated.Following)
+		assert.Equal(t, 1, updated.FollowersCount)
+
+		_, err = repo.GetByID(ctx, gone.ID.Hex())
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestUserRepository_UpsertRemoteUser(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewUserRepository(client, "test_db")
+	ctx := context.Background()
+
+	remote := &models.RemoteActor{
+		ActorID: "https://remote.example/users/bob",
+		Inbox:   "https://remote.example/users/bob/inbox",
+	}
+
+	t.Run("creates remote user", func(t *testing.T) {
+		user, err := repo.UpsertRemoteUser(ctx, "bob@remote.example", remote)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, user.ID)
+		assert.True(t, user.IsRemote())
+		assert.Empty(t, user.Following)
+		assert.Equal(t, 0, user.FollowersCount)
+	})
+
+	t.Run("updates existing remote user", func(t *testing.T) {
+		first, err := repo.GetByActorID(ctx, remote.ActorID)
+		assert.NoError(t, err)
+
+		updated := *remote
+		updated.SharedInbox = "https://remote.example/inbox"
+		user, err := repo.UpsertRemoteUser(ctx, "bob@remote.example", &updated)
+		assert.NoError(t, err)
+		assert.Equal(t, first.ID, user.ID)
+		assert.Equal(t, "https://remote.example/inbox", user.Remote.SharedInbox)
+	})
+
+	t.Run("remote users without email do not collide", func(t *testing.T) {
+		other := &models.RemoteActor{
+			ActorID: "https://other.example/users/carol",
+			Inbox:   "https://other.example/users/carol/inbox",
+		}
+		_, err := repo.UpsertRemoteUser(ctx, "carol@other.example", other)
+		assert.NoError(t, err)
+	})
+
+	t.Run("remote followers appear in GetFollowers", func(t *testing.T) {
+		local := createTestUser(t, repo, "federated", "federated@example.com")
+		follower, err := repo.GetByActorID(ctx, remote.ActorID)
+		assert.NoError(t, err)
+
+		err = repo.FollowUser(ctx, follower.ID.Hex(), local.ID.Hex())
+		assert.NoError(t, err)
+
+		followers, err := repo.GetFollowers(ctx, local.ID.Hex())
+		assert.NoError(t, err)
+		assert.Len(t, followers, 1)
+		assert.Equal(t, remote.ActorID, followers[0].Remote.ActorID)
+	})
+}
+
 func TestUserRepository_FollowUser(t *testing.T) {
 	client, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -193,19 +377,16 @@ func TestUserRepository_FollowUser(t *testing.T) {
 		assert.Equal(t, 1, updatedFollowee.FollowersCount)
 	})
 
-	t.Run("cannot follow self", func(t *testing.T) {
-		user := createTestUser(t, repo, "selffollow", "self@example.com")
-		err := repo.FollowUser(ctx, user.ID.Hex(), user.ID.Hex())
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "no puedes seguirte a ti mismo")
-	})
+	t.Run("repeated follow counts once", func(t *testing.T) {
+		follower := createTestUser(t, repo, "follower2", "follower2@example.com")
+		followee := createTestUser(t, repo, "followee2", "followee2@example.com")
 
-	t.Run("follow non-existent user", func(t *testing.T) {
-		user := createTestUser(t, repo, "follower2", "follower2@example.com")
-		nonExistentID := primitive.NewObjectID()
-		err := repo.FollowUser(ctx, user.ID.Hex(), nonExistentID.Hex())
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "usuario objetivo no encontrado")
+		assert.NoError(t, repo.FollowUser(ctx, follower.ID.Hex(), followee.ID.Hex()))
+		assert.NoError(t, repo.FollowUser(ctx, follower.ID.Hex(), followee.ID.Hex()))
+
+		updatedFollowee, err := repo.GetByID(ctx, followee.ID.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, updatedFollowee.FollowersCount)
 	})
 }
 
@@ -251,6 +432,11 @@ func TestUserRepository_UnfollowUser(t *testing.T) {
 
 		err := repo.UnfollowUser(ctx, user1.ID.Hex(), user2.ID.Hex())
 		assert.NoError(t, err) // No debería dar error, simplemente no hace nada
+
+		// El contador no se decrementa por un unfollow sin efecto
+		updated, err := repo.GetByID(ctx, user2.ID.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, updated.FollowersCount)
 	})
 }
 
@@ -301,3 +487,63 @@ func TestUserRepository_GetFollowing(t *testing.T) {
 			"El mensaje de error debería indicar que el usuario no fue encontrado")
 	})
 }
+
+func TestUserRepository_Import(t *testing.T) {
+	client, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewUserRepository(client, "test_db")
+	ctx := context.Background()
+	existing := createTestUser(t, repo, "existente", "existente@example.com")
+
+	createdAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	users := []models.User{
+		{ID: primitive.NewObjectID(), Username: "ana", Email: "ana@example.com", CreatedAt: createdAt, UpdatedAt: createdAt},
+		{ID: primitive.NewObjectID(), Username: "existente", Email: "otro@example.com"},
+		{ID: primitive.NewObjectID(), Username: "beto", Email: "beto@example.com", CreatedAt: createdAt, UpdatedAt: createdAt},
+	}
+
+	t.Run("keeps IDs and dates and reports conflicts", func(t *testing.T) {
+		conflicts, err := repo.ImportMany(ctx, users)
+		require.NoError(t, err)
+		assert.Equal(t, []int{1}, conflicts)
+
+		ana, err := repo.GetByID(ctx, users[0].ID.Hex())
+		require.NoError(t, err)
+		assert.True(t, createdAt.Equal(ana.CreatedAt))
+		assert.NotNil(t, ana.Following)
+	})
+
+	t.Run("repeating a batch is not an error", func(t *testing.T) {
+		conflicts, err := repo.ImportMany(ctx, []models.User{users[0], users[2]})
+		require.NoError(t, err)
+		assert.Empty(t, conflicts)
+	})
+
+	t.Run("follows and recount", func(t *testing.T) {
+		targets := []string{users[2].ID.Hex(), existing.ID.Hex()}
+		require.NoError(t, repo.AddFollowing(ctx, users[0].ID, targets))
+		require.NoError(t, repo.AddFollowing(ctx, users[0].ID, targets))
+		require.NoError(t, repo.RecountFollowers(ctx, targets))
+
+		ana, err := repo.GetByID(ctx, users[0].ID.Hex())
+		require.NoError(t, err)
+		assert.ElementsMatch(t, targets, ana.Following)
+		beto, err := repo.GetByID(ctx, users[2].ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, 1, beto.FollowersCount)
+
+		err = repo.AddFollowing(ctx, primitive.NewObjectID(), targets)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("walk", func(t *testing.T) {
+		var names []string
+		err := repo.Walk(ctx, func(user *models.User) error {
+			names = append(names, user.Username)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"existente", "ana", "beto"}, names)
+	})
+}
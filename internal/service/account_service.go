@@ -0,0 +1,226 @@
+// internal/service/account_service.go
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/audit"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrDeletionNotFound       = repository.ErrDeletionNotFound
+	ErrAccountDeactivated     = apperr.Forbidden("account_deactivated", "la cuenta está desactivada")
+	ErrAccountPendingDeletion = apperr.Conflict("account_pending_deletion", "la cuenta está pendiente de borrado")
+	ErrAccountNotDeactivated  = apperr.Conflict("account_not_deactivated", "la cuenta no está desactivada")
+	ErrReactivationExpired    = apperr.Conflict("reactivation_expired", "pasó el plazo para reactivar la cuenta")
+	ErrAccountNotSuspended    = apperr.Conflict("account_not_suspended", "la cuenta no está suspendida")
+	ErrSelfSuspension         = apperr.Validation("self_suspension", "no puedes suspender tu propia cuenta")
+	ErrSuspensionReason       = apperr.InvalidField("suspension_reason_required", "reason", "el motivo de la suspensión es requerido")
+	ErrSuspensionExpiry       = apperr.InvalidField("invalid_suspension_expiry", "expires_at", "la suspensión debe vencer en el futuro")
+)
+
+// AccountService gestiona el ciclo de vida de las cuentas: desactivación por el
+// propio usuario, suspensión por un administrador y borrado definitivo. El
+// borrado solo se registra aquí; lo ejecuta worker.AccountPurger.
+type AccountService struct {
+	users     AccountUsers
+	deletions DeletionStore
+	audit     AuditLog
+	now       func() time.Time
+}
+
+func NewAccountService(users AccountUsers, deletions DeletionStore, auditLog AuditLog) *AccountService {
+	return &AccountService{
+		users:     users,
+		deletions: deletions,
+		audit:     auditOrNoop(auditLog),
+		now:       time.Now,
+	}
+}
+
+// Deactivate desactiva la cuenta: deja de aparecer en timelines, seguidores y
+// búsquedas y no puede publicar. Se puede reactivar durante
+// models.DeactivationGracePeriod; después se borra.
+func (s *AccountService) Deactivate(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.liveUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsDeactivated() {
+		return user, nil
+	}
+	now := s.now()
+	if err := s.users.Deactivate(ctx, user.ID, now); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, models.AuditEntry{
+		Action:     models.AuditUserDeactivated,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		After:      audit.State(map[string]any{"deactivated_at": now}),
+	})
+	return getUser(ctx, s.users, userID, ErrUserNotFound)
+}
+
+// Reactivate deshace la desactivación si no pasó el plazo
+func (s *AccountService) Reactivate(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.liveUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsDeactivated() {
+		return nil, ErrAccountNotDeactivated
+	}
+	if s.now().Sub(*user.DeactivatedAt) > models.DeactivationGracePeriod {
+		return nil, ErrReactivationExpired
+	}
+	if err := s.users.Reactivate(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, models.AuditEntry{
+		Action:     models.AuditUserReactivated,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Before:     audit.State(map[string]any{"deactivated_at": user.DeactivatedAt}),
+	})
+	return getUser(ctx, s.users, userID, ErrUserNotFound)
+}
+
+// Suspend suspende la cuenta con un motivo y, opcionalmente, una fecha de
+// vencimiento; una suspensión previa se reemplaza
+func (s *AccountService) Suspend(ctx context.Context, moderator *rbac.Principal, userID string, req models.SuspensionRequest) (*models.User, error) {
+	if err := rbac.Require(moderator, rbac.UsersSuspend); err != nil {
+		return nil, err
+	}
+	if moderator.UserID == userID {
+		return nil, ErrSelfSuspension
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrSuspensionReason
+	}
+	now := s.now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, ErrSuspensionExpiry
+	}
+	user, err := s.liveUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	moderatorID, _ := primitive.ObjectIDFromHex(moderator.UserID)
+	suspension := models.Suspension{
+		Reason:      reason,
+		ModeratorID: moderatorID,
+		Since:       now,
+		ExpiresAt:   req.ExpiresAt,
+	}
+	if err := s.users.Suspend(ctx, user.ID, suspension); err != nil {
+		return nil, err
+	}
+	before, after := audit.Diff(
+		map[string]any{"suspension": user.Suspension},
+		map[string]any{"suspension": suspension},
+	)
+	s.audit.Record(ctx, models.AuditEntry{
+		Action:     models.AuditUserSuspended,
+		ActorID:    moderator.UserID,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Before:     before,
+		After:      after,
+	})
+	return getUser(ctx, s.users, userID, ErrUserNotFound)
+}
+
+// LiftSuspension levanta la suspensión antes de que venza
+func (s *AccountService) LiftSuspension(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.liveUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsSuspendedAt(s.now()) {
+		return nil, ErrAccountNotSuspended
+	}
+	if err := s.users.LiftSuspension(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, models.AuditEntry{
+		Action:     models.AuditSuspensionLifted,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Before:     audit.State(map[string]any{"suspension": user.Suspension}),
+	})
+	return getUser(ctx, s.users, userID, ErrUserNotFound)
+}
+
+// RequestDeletion registra el borrado definitivo de la cuenta, que desde ese
+// momento deja de verse y no se puede reactivar. Pedirlo dos veces devuelve el
+// mismo borrado.
+func (s *AccountService) RequestDeletion(ctx context.Context, userID, reason string) (*models.AccountDeletion, error) {
+	user, err := getUser(ctx, s.users, userID, ErrUserNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	// Primero el job y después la marca: si la marca falla, el worker la repite
+	// como primer paso del borrado
+	now := s.now()
+	deletion, err := s.deletions.Create(ctx, &models.AccountDeletion{
+		UserID:      user.ID,
+		Username:    user.Username,
+		Reason:      reason,
+		Following:   user.Following,
+		RequestedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.MarkForDeletion(ctx, user.ID, now); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, models.AuditEntry{
+		Action:     models.AuditDeletionRequested,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Metadata:   map[string]string{"reason": reason, "username": user.Username},
+	})
+	return deletion, nil
+}
+
+// Deletion devuelve el estado del borrado de una cuenta
+func (s *AccountService) Deletion(ctx context.Context, userID string) (*models.AccountDeletion, error) {
+	return s.deletions.GetByUserID(ctx, userID)
+}
+
+// liveUser obtiene un usuario que no está pendiente de borrado
+func (s *AccountService) liveUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := getUser(ctx, s.users, userID, ErrUserNotFound)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsPendingDeletion() {
+		return nil, ErrAccountPendingDeletion
+	}
+	return user, nil
+}
+
+// checkCanPost devuelve el error correspondiente si la cuenta no puede publicar
+func checkCanPost(user *models.User) error {
+	switch {
+	case user.IsPendingDeletion():
+		return ErrAccountPendingDeletion
+	case user.IsSuspended():
+		return ErrUserSuspended
+	case user.IsDeactivated():
+		return ErrAccountDeactivated
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// internal/service/account_service_test.go
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type accountFixture struct {
+	service   *AccountService
+	users     *fakeUsers
+	deletions *fakeDeletions
+	audit     *fakeAudit
+	user      *models.User
+	admin     *models.User
+	now       time.Time
+}
+
+func newAccountFixture() *accountFixture {
+	f := &accountFixture{
+		user:      &models.User{Username: "ana", Following: []string{primitive.NewObjectID().Hex()}},
+		admin:     &models.User{Username: "admin", Roles: []string{rbac.RoleAdmin}},
+		deletions: newFakeDeletions(),
+		audit:     &fakeAudit{},
+		now:       time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC),
+	}
+	f.users = newFakeUsers(f.user, f.admin)
+	f.service = NewAccountService(f.users, f.deletions, f.audit)
+	f.service.now = func() time.Time { return f.now }
+	return f
+}
+
+func TestAccountService_DeactivateAndReactivate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("within grace period", func(t *testing.T) {
+		f := newAccountFixture()
+		user, err := f.service.Deactivate(ctx, f.user.ID.Hex())
+		require.NoError(t, err)
+		require.NotNil(t, user.DeactivatedAt)
+		assert.Equal(t, f.now, *user.DeactivatedAt)
+
+		// Desactivar dos veces conserva la fecha original
+		f.now = f.now.Add(time.Hour)
+		again, err := f.service.Deactivate(ctx, f.user.ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, *user.DeactivatedAt, *again.DeactivatedAt)
+
+		f.now = f.now.Add(29 * 24 * time.Hour)
+		user, err = f.service.Reactivate(ctx, f.user.ID.Hex())
+		require.NoError(t, err)
+		assert.False(t, user.IsDeactivated())
+
+		_, err = f.service.Reactivate(ctx, f.user.ID.Hex())
+		assert.ErrorIs(t, err, ErrAccountNotDeactivated)
+
+		assert.Equal(t, []string{models.AuditUserDeactivated, models.AuditUserReactivated}, f.audit.actions())
+	})
+
+	t.Run("after grace period", func(t *testing.T) {
+		f := newAccountFixture()
+		_, err := f.service.Deactivate(ctx, f.user.ID.Hex())
+		require.NoError(t, err)
+
+		f.now = f.now.Add(models.DeactivationGracePeriod + time.Minute)
+		_, err = f.service.Reactivate(ctx, f.user.ID.Hex())
+		assert.ErrorIs(t, err, ErrReactivationExpired)
+	})
+
+	t.Run("deactivated users cannot post", func(t *testing.T) {
+		f := newAccountFixture()
+		_, err := f.service.Deactivate(ctx, f.user.ID.Hex())
+		require.NoError(t, err)
+		user, _ := f.users.GetByID(ctx, f.user.ID.Hex())
+		assert.ErrorIs(t, checkCanPost(user), ErrAccountDeactivated)
+	})
+}
+
+func TestAccountService_Suspend(t *testing.T) {
+	ctx := context.Background()
+	f := newAccountFixture()
+	admin := principalOf(f.admin)
+	expires := f.now.Add(7 * 24 * time.Hour)
+
+	user, err := f.service.Suspend(ctx, admin, f.user.ID.Hex(), models.SuspensionRequest{Reason: " spam ", ExpiresAt: &expires})
+	require.NoError(t, err)
+	require.NotNil(t, user.Suspension)
+	assert.Equal(t, "spam", user.Suspension.Reason)
+	assert.Equal(t, f.admin.ID, user.Suspension.ModeratorID)
+	assert.True(t, user.IsSuspendedAt(f.now))
+	assert.False(t, user.IsSuspendedAt(expires), "la suspensión vence sola")
+
+	require.Len(t, f.audit.entries, 1)
+	entry := f.audit.entries[0]
+	assert.Equal(t, models.AuditUserSuspended, entry.Action)
+	assert.Equal(t, f.admin.ID.Hex(), entry.ActorID)
+	assert.Equal(t, f.user.ID.Hex(), entry.TargetID)
+	assert.JSONEq(t, `{"suspension":null}`, string(entry.Before))
+	assert.Contains(t, string(entry.After), `"reason":"spam"`)
+
+	user, err = f.service.LiftSuspension(ctx, f.user.ID.Hex())
+	require.NoError(t, err)
+	assert.Nil(t, user.Suspension)
+	_, err = f.service.LiftSuspension(ctx, f.user.ID.Hex())
+	assert.ErrorIs(t, err, ErrAccountNotSuspended)
+
+	t.Run("validation", func(t *testing.T) {
+		past := f.now.Add(-time.Hour)
+		_, err := f.service.Suspend(ctx, admin, f.user.ID.Hex(), models.SuspensionRequest{Reason: "spam", ExpiresAt: &past})
+		assert.ErrorIs(t, err, ErrSuspensionExpiry)
+
+		_, err = f.service.Suspend(ctx, admin, f.user.ID.Hex(), models.SuspensionRequest{Reason: "  "})
+		assert.ErrorIs(t, err, ErrSuspensionReason)
+
+		_, err = f.service.Suspend(ctx, admin, f.admin.ID.Hex(), models.SuspensionRequest{Reason: "spam"})
+		assert.ErrorIs(t, err, ErrSelfSuspension)
+
+		_, err = f.service.Suspend(ctx, principalOf(f.user), f.admin.ID.Hex(), models.SuspensionRequest{Reason: "spam"})
+		assert.ErrorIs(t, err, rbac.ErrPermissionDenied)
+
+		_, err = f.service.Suspend(ctx, admin, primitive.NewObjectID().Hex(), models.SuspensionRequest{Reason: "spam"})
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestAccountService_RequestDeletion(t *testing.T) {
+	ctx := context.Background()
+	f := newAccountFixture()
+
+	deletion, err := f.service.RequestDeletion(ctx, f.user.ID.Hex(), models.DeletionReasonAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeletionStatusPending, deletion.Status)
+	assert.Equal(t, f.user.Following, deletion.Following, "guarda los seguidos para corregir sus contadores")
+
+	again, err := f.service.RequestDeletion(ctx, f.user.ID.Hex(), models.DeletionReasonAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, deletion.ID, again.ID)
+
+	user, _ := f.users.GetByID(ctx, f.user.ID.Hex())
+	assert.True(t, user.IsPendingDeletion())
+	_, err = f.service.Reactivate(ctx, f.user.ID.Hex())
+	assert.ErrorIs(t, err, ErrAccountPendingDeletion)
+
+	status, err := f.service.Deletion(ctx, f.user.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, deletion.ID, status.ID)
+	_, err = f.service.Deletion(ctx, f.admin.ID.Hex())
+	assert.ErrorIs(t, err, ErrDeletionNotFound)
+}
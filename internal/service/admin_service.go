@@ -0,0 +1,104 @@
+// internal/service/admin_service.go
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/audit"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+)
+
+var ErrLastAdmin = apperr.Conflict("last_admin", "no se puede quitar el rol admin al último administrador")
+
+// AdminService reúne las operaciones de administración: roles de los usuarios y
+// borrado de contenido ajeno. Los permisos de la ruta los comprueba el middleware;
+// aquí solo las reglas que dependen de los datos.
+type AdminService struct {
+	users  AdminUsers
+	tweets AdminTweets
+	audit  AuditLog
+}
+
+func NewAdminService(users AdminUsers, tweets AdminTweets, auditLog AuditLog) *AdminService {
+	return &AdminService{
+		users:  users,
+		tweets: tweets,
+		audit:  auditOrNoop(auditLog),
+	}
+}
+
+// SetRoles reemplaza los roles y permisos de un usuario. actor solo puede conceder
+// permisos que ya tiene, y la instancia nunca se queda sin administradores.
+func (s *AdminService) SetRoles(ctx context.Context, actor *rbac.Principal, userID string, assignment models.RoleAssignment) (*models.User, error) {
+	roles := normalizeGrants(assignment.Roles)
+	permissions := normalizeGrants(assignment.Permissions)
+	if err := rbac.ValidateRoles(roles); err != nil {
+		return nil, err
+	}
+	if err := rbac.ValidatePermissions(permissions); err != nil {
+		return nil, err
+	}
+	if err := rbac.CheckGrant(actor, roles, permissions); err != nil {
+		return nil, err
+	}
+
+	user, err := getUser(ctx, s.users, userID, ErrUserNotFound)
+	if err != nil {
+		return nil, err
+	}
+	// La cuenta no es atómica con la actualización: dos bajas simultáneas de los
+	// dos últimos admins podrían pasar. El comando bootstrap permite recuperarse.
+	if slices.Contains(user.Roles, rbac.RoleAdmin) && !slices.Contains(roles, rbac.RoleAdmin) {
+		admins, err := s.users.CountByRole(ctx, rbac.RoleAdmin)
+		if err != nil {
+			return nil, err
+		}
+		if admins <= 1 {
+			return nil, ErrLastAdmin
+		}
+	}
+
+	updated, err := s.users.SetRoles(ctx, user.ID, roles, permissions)
+	if err != nil {
+		return nil, err
+	}
+	before, after := audit.Diff(
+		map[string]any{"roles": user.Roles, "permissions": user.Permissions},
+		map[string]any{"roles": updated.Roles, "permissions": updated.Permissions},
+	)
+	s.audit.Record(ctx, models.AuditEntry{
+		Action:     models.AuditRolesChanged,
+		ActorID:    actor.UserID,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Before:     before,
+		After:      after,
+	})
+	return updated, nil
+}
+
+// DeleteTweet borra el tweet de cualquier usuario
+func (s *AdminService) DeleteTweet(ctx context.Context, id string) error {
+	if err := s.tweets.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, models.AuditEntry{
+		Action:     models.AuditTweetDeleted,
+		TargetType: models.AuditTargetTweet,
+		TargetID:   id,
+	})
+	return nil
+}
+
+// normalizeGrants ordena y quita duplicados; una lista vacía se guarda como nil
+func normalizeGrants(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	out := slices.Clone(values)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
@@ -0,0 +1,84 @@
+// internal/service/admin_service_test.go
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func principalOf(u *models.User) *rbac.Principal {
+	return &rbac.Principal{UserID: u.ID.Hex(), Roles: u.Roles, Permissions: u.Permissions}
+}
+
+func TestAdminService_SetRoles(t *testing.T) {
+	ctx := context.Background()
+	admin := &models.User{Username: "admin", Roles: []string{rbac.RoleAdmin}}
+	manager := &models.User{Username: "gestora", Roles: []string{rbac.RoleModerator}, Permissions: []string{string(rbac.RolesManage)}}
+	bob := &models.User{Username: "bob"}
+	users := newFakeUsers(admin, manager, bob)
+	audit := &fakeAudit{}
+	s := NewAdminService(users, &fakeTweets{}, audit)
+
+	t.Run("grant", func(t *testing.T) {
+		updated, err := s.SetRoles(ctx, principalOf(admin), bob.ID.Hex(), models.RoleAssignment{
+			Roles:       []string{rbac.RoleModerator, rbac.RoleModerator},
+			Permissions: []string{"reports:*"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{rbac.RoleModerator}, updated.Roles)
+		assert.Equal(t, []string{"reports:*"}, updated.Permissions)
+
+		require.Len(t, audit.entries, 1)
+		entry := audit.entries[0]
+		assert.Equal(t, models.AuditRolesChanged, entry.Action)
+		assert.Equal(t, admin.ID.Hex(), entry.ActorID)
+		assert.JSONEq(t, `{"roles":null,"permissions":null}`, string(entry.Before))
+		assert.JSONEq(t, `{"roles":["moderator"],"permissions":["reports:*"]}`, string(entry.After))
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := s.SetRoles(ctx, principalOf(admin), bob.ID.Hex(), models.RoleAssignment{Roles: []string{"root"}})
+		assert.ErrorIs(t, err, rbac.ErrUnknownRole)
+
+		_, err = s.SetRoles(ctx, principalOf(admin), bob.ID.Hex(), models.RoleAssignment{Permissions: []string{"tweets:fly"}})
+		assert.ErrorIs(t, err, rbac.ErrUnknownPerm)
+
+		_, err = s.SetRoles(ctx, principalOf(admin), primitive.NewObjectID().Hex(), models.RoleAssignment{})
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("cannot grant more than own permissions", func(t *testing.T) {
+		_, err := s.SetRoles(ctx, principalOf(manager), bob.ID.Hex(), models.RoleAssignment{Roles: []string{rbac.RoleAdmin}})
+		assert.ErrorIs(t, err, rbac.ErrPermissionDenied)
+	})
+
+	t.Run("last admin", func(t *testing.T) {
+		_, err := s.SetRoles(ctx, principalOf(admin), admin.ID.Hex(), models.RoleAssignment{})
+		assert.ErrorIs(t, err, ErrLastAdmin)
+
+		_, err = s.SetRoles(ctx, principalOf(admin), bob.ID.Hex(), models.RoleAssignment{Roles: []string{rbac.RoleAdmin}})
+		require.NoError(t, err)
+		updated, err := s.SetRoles(ctx, principalOf(admin), admin.ID.Hex(), models.RoleAssignment{})
+		require.NoError(t, err, "con otro admin sí se puede quitar el rol")
+		assert.Empty(t, updated.Roles)
+	})
+}
+
+func TestAdminService_DeleteTweet(t *testing.T) {
+	ctx := context.Background()
+	tweets := &fakeTweets{}
+	tweet := &models.Tweet{UserID: primitive.NewObjectID(), Content: "spam"}
+	require.NoError(t, tweets.Create(ctx, tweet))
+	s := NewAdminService(newFakeUsers(), tweets, nil)
+
+	require.NoError(t, s.DeleteTweet(ctx, tweet.ID.Hex()))
+	_, err := tweets.GetByID(ctx, tweet.ID.Hex())
+	assert.ErrorIs(t, err, ErrTweetNotFound)
+	assert.ErrorIs(t, s.DeleteTweet(ctx, tweet.ID.Hex()), ErrTweetNotFound)
+}
@@ -0,0 +1,66 @@
+// internal/service/audit_service.go
+package service
+
+import (
+	"context"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+)
+
+var ErrAuditRange = apperr.InvalidField("invalid_audit_range", "to", "el final del rango debe ser posterior al inicio")
+
+// AuditLog registra las acciones sensibles en la auditoría (ver audit.Logger). Los
+// servicios aceptan nil y en ese caso no registran nada.
+type AuditLog interface {
+	Record(ctx context.Context, entry models.AuditEntry)
+}
+
+type noopAudit struct{}
+
+func (noopAudit) Record(context.Context, models.AuditEntry) {}
+
+func auditOrNoop(a AuditLog) AuditLog {
+	if a == nil {
+		return noopAudit{}
+	}
+	return a
+}
+
+// AuditPage es una página de la consulta de auditoría. NextBefore es el valor de
+// before para la página siguiente, 0 si no hay más.
+type AuditPage struct {
+	Entries    []models.AuditEntry `json:"entries"`
+	NextBefore int64               `json:"next_before,omitempty"`
+}
+
+// AuditService consulta la auditoría; los registros los crea cada servicio con AuditLog
+type AuditService struct {
+	entries AuditStore
+}
+
+func NewAuditService(entries AuditStore) *AuditService {
+	return &AuditService{
+		entries: entries,
+	}
+}
+
+// Query devuelve los registros que cumplen los filtros, del más reciente al más antiguo
+func (s *AuditService) Query(ctx context.Context, query models.AuditQuery) (*AuditPage, error) {
+	if query.From != nil && query.To != nil && !query.To.After(*query.From) {
+		return nil, ErrAuditRange
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultPageSize
+	}
+
+	entries, err := s.entries.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	page := &AuditPage{Entries: entries}
+	if len(entries) == query.Limit {
+		page.NextBefore = entries[len(entries)-1].Seq
+	}
+	return page, nil
+}
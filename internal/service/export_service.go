@@ -0,0 +1,145 @@
+// internal/service/export_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/export"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"github.com/ffelixf/microblog-platform/pkg/storage"
+)
+
+// DefaultExportLinkTTL es cuánto dura un enlace de descarga
+const DefaultExportLinkTTL = 15 * time.Minute
+
+var (
+	ErrExportNotFound    = repository.ErrExportNotFound
+	ErrExportLinkInvalid = apperr.Forbidden("invalid_export_link", "enlace de descarga inválido")
+	ErrExportLinkExpired = apperr.Forbidden("export_link_expired", "el enlace de descarga venció")
+	ErrExportExpired     = apperr.NotFound("export_expired", "el archivo de la exportación ya no está disponible")
+)
+
+// ExportService gestiona las exportaciones de datos personales. Aquí solo se
+// registran y se consultan; las genera worker.Exporter. El archivo se
+// descarga con un enlace firmado que vence a los linkTTL.
+type ExportService struct {
+	jobs    ExportStore
+	users   UserReader
+	store   storage.BlobStore
+	signer  *export.Signer
+	baseURL string
+	linkTTL time.Duration
+	audit   AuditLog
+	now     func() time.Time
+}
+
+func NewExportService(jobs ExportStore, users UserReader, store storage.BlobStore, signer *export.Signer, baseURL string, linkTTL time.Duration, auditLog AuditLog) *ExportService {
+	if linkTTL <= 0 {
+		linkTTL = DefaultExportLinkTTL
+	}
+	return &ExportService{
+		jobs:    jobs,
+		users:   users,
+		store:   store,
+		signer:  signer,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		linkTTL: linkTTL,
+		audit:   auditOrNoop(auditLog),
+		now:     time.Now,
+	}
+}
+
+// Request pide una exportación. Si el usuario ya tiene una pendiente devuelve
+// esa en lugar de crear otra.
+func (s *ExportService) Request(ctx context.Context, userID string) (*models.ExportJob, error) {
+	user, err := getUser(ctx, s.users, userID, ErrUserNotFound)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsPendingDeletion() {
+		return nil, ErrAccountPendingDeletion
+	}
+
+	job, err := s.jobs.Create(ctx, &models.ExportJob{UserID: user.ID, RequestedAt: s.now()})
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, models.AuditEntry{
+		Action:     models.AuditExportRequested,
+		ActorID:    userID,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Metadata:   map[string]string{"export_id": job.ID.Hex()},
+	})
+	return job, nil
+}
+
+// Get devuelve el estado de una exportación del usuario y, si está lista, un
+// enlace de descarga recién firmado
+func (s *ExportService) Get(ctx context.Context, userID, id string) (*models.ExportJob, error) {
+	job, err := s.jobs.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// La exportación de otro usuario no se distingue de una inexistente
+	if job.UserID.Hex() != userID {
+		return nil, ErrExportNotFound
+	}
+	if job.Status == models.ExportStatusDone {
+		expires := s.now().Add(s.linkTTL).Truncate(time.Second)
+		// El enlace no dura más que el archivo
+		if job.ExpiresAt != nil && job.ExpiresAt.Before(expires) {
+			expires = job.ExpiresAt.Truncate(time.Second)
+		}
+		job.DownloadURL = s.downloadURL(job.ID.Hex(), expires)
+		job.DownloadExpiresAt = &expires
+	}
+	return job, nil
+}
+
+func (s *ExportService) downloadURL(id string, expires time.Time) string {
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	query.Set("signature", s.signer.Sign(id, expires))
+	return fmt.Sprintf("%s/exports/%s/download?%s", s.baseURL, id, query.Encode())
+}
+
+// Open verifica un enlace de descarga y devuelve la exportación con su archivo
+// abierto, que el llamador debe cerrar
+func (s *ExportService) Open(ctx context.Context, id, expiresParam, signature string) (*models.ExportJob, *storage.Blob, error) {
+	unix, err := strconv.ParseInt(expiresParam, 10, 64)
+	if err != nil {
+		return nil, nil, ErrExportLinkInvalid.Wrap(err)
+	}
+	expires := time.Unix(unix, 0)
+	if !s.signer.Verify(id, expires, signature) {
+		return nil, nil, ErrExportLinkInvalid
+	}
+	if !s.now().Before(expires) {
+		return nil, nil, ErrExportLinkExpired
+	}
+
+	job, err := s.jobs.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != models.ExportStatusDone {
+		return nil, nil, ErrExportExpired
+	}
+	blob, err := s.store.Get(ctx, job.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, ErrExportExpired.Wrap(err)
+		}
+		return nil, nil, fmt.Errorf("error al leer exportación: %w", err)
+	}
+	return job, blob, nil
+}
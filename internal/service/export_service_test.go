@@ -0,0 +1,142 @@
+// internal/service/export_service_test.go
+package service
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/export"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type exportFixture struct {
+	service *ExportService
+	jobs    *fakeExports
+	users   *fakeUsers
+	store   *storage.LocalStore
+	audit   *fakeAudit
+	user    *models.User
+	now     time.Time
+}
+
+func setupExport(t *testing.T) *exportFixture {
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	f := &exportFixture{
+		jobs:  &fakeExports{},
+		store: store,
+		audit: &fakeAudit{},
+		user:  &models.User{Username: "ana"},
+		now:   time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC),
+	}
+	f.users = newFakeUsers(f.user)
+	f.service = NewExportService(f.jobs, f.users, store, export.NewSigner([]byte("secreto")), "https://example.com/", 15*time.Minute, f.audit)
+	f.service.now = func() time.Time { return f.now }
+	return f
+}
+
+// finish simula al worker: deja la exportación terminada con su archivo
+func (f *exportFixture) finish(t *testing.T, job *models.ExportJob) {
+	key := "exports/" + job.UserID.Hex() + "/" + job.ID.Hex() + ".zip"
+	require.NoError(t, f.store.Put(context.Background(), key, []byte("zip"), export.ContentType))
+	expires := f.now.Add(7 * 24 * time.Hour)
+	for _, stored := range f.jobs.jobs {
+		if stored.ID == job.ID {
+			stored.Status, stored.Key, stored.ExpiresAt = models.ExportStatusDone, key, &expires
+		}
+	}
+}
+
+func TestExportService_Request(t *testing.T) {
+	ctx := context.Background()
+	f := setupExport(t)
+	userID := f.user.ID.Hex()
+
+	job, err := f.service.Request(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExportStatusPending, job.Status)
+	assert.Equal(t, f.now, job.RequestedAt)
+
+	again, err := f.service.Request(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, again.ID, "una sola exportación pendiente por usuario")
+	assert.Equal(t, []string{models.AuditExportRequested, models.AuditExportRequested}, f.audit.actions())
+
+	pending, err := f.service.Get(ctx, userID, job.ID.Hex())
+	require.NoError(t, err)
+	assert.Empty(t, pending.DownloadURL, "sin enlace hasta que termina")
+
+	_, err = f.service.Get(ctx, primitive.NewObjectID().Hex(), job.ID.Hex())
+	assert.ErrorIs(t, err, ErrExportNotFound, "la exportación de otro usuario no se ve")
+	_, err = f.service.Request(ctx, primitive.NewObjectID().Hex())
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	f.user.DeletionRequestedAt = &f.now
+	_, err = f.service.Request(ctx, userID)
+	assert.ErrorIs(t, err, ErrAccountPendingDeletion)
+}
+
+func TestExportService_Download(t *testing.T) {
+	ctx := context.Background()
+	f := setupExport(t)
+	job, err := f.service.Request(ctx, f.user.ID.Hex())
+	require.NoError(t, err)
+	f.finish(t, job)
+
+	done, err := f.service.Get(ctx, f.user.ID.Hex(), job.ID.Hex())
+	require.NoError(t, err)
+	require.NotEmpty(t, done.DownloadURL)
+	assert.Equal(t, f.now.Add(15*time.Minute), *done.DownloadExpiresAt)
+
+	link, err := url.Parse(done.DownloadURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/exports/"+job.ID.Hex()+"/download", link.Scheme+"://"+link.Host+link.Path)
+	expires, signature := link.Query().Get("expires"), link.Query().Get("signature")
+
+	t.Run("valid link", func(t *testing.T) {
+		opened, blob, err := f.service.Open(ctx, job.ID.Hex(), expires, signature)
+		require.NoError(t, err)
+		defer blob.Close()
+		assert.Equal(t, job.ID, opened.ID)
+		data, err := io.ReadAll(blob)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("zip"), data)
+		assert.Equal(t, int64(len(data)), blob.Size)
+	})
+
+	t.Run("tampered link", func(t *testing.T) {
+		_, _, err := f.service.Open(ctx, job.ID.Hex(), expires+"0", signature)
+		assert.ErrorIs(t, err, ErrExportLinkInvalid)
+		_, _, err = f.service.Open(ctx, primitive.NewObjectID().Hex(), expires, signature)
+		assert.ErrorIs(t, err, ErrExportLinkInvalid)
+		_, _, err = f.service.Open(ctx, job.ID.Hex(), "mañana", signature)
+		assert.ErrorIs(t, err, ErrExportLinkInvalid)
+	})
+
+	t.Run("expired link", func(t *testing.T) {
+		defer func(now time.Time) { f.now = now }(f.now)
+		f.now = f.now.Add(16 * time.Minute)
+		_, _, err := f.service.Open(ctx, job.ID.Hex(), expires, signature)
+		assert.ErrorIs(t, err, ErrExportLinkExpired)
+	})
+
+	t.Run("archive removed", func(t *testing.T) {
+		require.NoError(t, f.store.Delete(ctx, f.jobs.jobs[0].Key))
+		_, _, err := f.service.Open(ctx, job.ID.Hex(), expires, signature)
+		assert.ErrorIs(t, err, ErrExportExpired)
+	})
+
+	t.Run("link never outlives the archive", func(t *testing.T) {
+		f.now = f.jobs.jobs[0].ExpiresAt.Add(-time.Minute)
+		done, err := f.service.Get(ctx, f.user.ID.Hex(), job.ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, *f.jobs.jobs[0].ExpiresAt, *done.DownloadExpiresAt)
+	})
+}
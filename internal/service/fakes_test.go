@@ -0,0 +1,854 @@
+// internal/service/fakes_test.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Fakes en memoria de los repositorios con la misma semántica de errores
+
+type fakeUsers struct {
+	mu    sync.Mutex
+	users map[string]*models.User
+}
+
+func newFakeUsers(users ...*models.User) *fakeUsers {
+	f := &fakeUsers{users: make(map[string]*models.User)}
+	for _, u := range users {
+		f.add(u)
+	}
+	return f
+}
+
+func (f *fakeUsers) add(u *models.User) *models.User {
+	if u.ID.IsZero() {
+		u.ID = primitive.NewObjectID()
+	}
+	f.users[u.ID.Hex()] = u
+	return u
+}
+
+func (f *fakeUsers) Create(ctx context.Context, user *models.User) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.add(user)
+	return nil
+}
+
+func (f *fakeUsers) GetByID(ctx context.Context, id string) (*models.User, error) {
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		return nil, repository.ErrInvalidID.Wrap(err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u, ok := f.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound.Wrap(mongo.ErrNoDocuments)
+	}
+	copied := *u
+	return &copied, nil
+}
+
+func (f *fakeUsers) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var users []models.User
+	for _, id := range ids {
+		if u, ok := f.users[id.Hex()]; ok {
+			users = append(users, *u)
+		}
+	}
+	return users, nil
+}
+
+func (f *fakeUsers) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, u := range f.users {
+		if u.Username == username {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound.Wrap(mongo.ErrNoDocuments)
+}
+
+func (f *fakeUsers) Suspend(ctx context.Context, id primitive.ObjectID, suspension models.Suspension) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u, ok := f.users[id.Hex()]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	u.Suspension = &suspension
+	return nil
+}
+
+func (f *fakeUsers) SetRoles(ctx context.Context, id primitive.ObjectID, roles, permissions []string) (*models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u, ok := f.users[id.Hex()]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	u.Roles, u.Permissions = roles, permissions
+	copied := *u
+	return &copied, nil
+}
+
+func (f *fakeUsers) CountByRole(ctx context.Context, role string) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var count int64
+	for _, u := range f.users {
+		for _, r := range u.Roles {
+			if r == role {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeUsers) update(id primitive.ObjectID, apply func(u *models.User)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u, ok := f.users[id.Hex()]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	apply(u)
+	return nil
+}
+
+func (f *fakeUsers) SetMutedWords(ctx context.Context, id primitive.ObjectID, words []string) error {
+	return f.update(id, func(u *models.User) { u.MutedWords = words })
+}
+
+func (f *fakeUsers) LiftSuspension(ctx context.Context, id primitive.ObjectID) error {
+	return f.update(id, func(u *models.User) { u.Suspension = nil })
+}
+
+func (f *fakeUsers) Deactivate(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	return f.update(id, func(u *models.User) {
+		if u.DeactivatedAt == nil {
+			u.DeactivatedAt = &at
+		}
+	})
+}
+
+func (f *fakeUsers) Reactivate(ctx context.Context, id primitive.ObjectID) error {
+	return f.update(id, func(u *models.User) { u.DeactivatedAt = nil })
+}
+
+func (f *fakeUsers) MarkForDeletion(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	return f.update(id, func(u *models.User) {
+		if u.DeletionRequestedAt == nil {
+			u.DeletionRequestedAt = &at
+		}
+	})
+}
+
+// InactiveIDs, como el repositorio, cuenta cualquier suspensión hasta que se levanta
+func (f *fakeUsers) InactiveIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var ids []primitive.ObjectID
+	for _, u := range f.users {
+		if u.Suspension != nil || u.IsDeactivated() || u.IsPendingDeletion() {
+			ids = append(ids, u.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (f *fakeUsers) FollowUser(ctx context.Context, userID, targetID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u := f.users[userID]
+	for _, id := range u.Following {
+		if id == targetID {
+			return nil
+		}
+	}
+	u.Following = append(u.Following, targetID)
+	f.users[targetID].FollowersCount++
+	return nil
+}
+
+func (f *fakeUsers) UnfollowUser(ctx context.Context, userID, targetID string) error {
+	return nil
+}
+
+func (f *fakeUsers) GetFollowing(ctx context.Context, userID string) ([]models.User, error) {
+	return nil, nil
+}
+
+func (f *fakeUsers) GetFollowers(ctx context.Context, userID string) ([]models.User, error) {
+	return nil, nil
+}
+
+type fakeTweets struct {
+	mu     sync.Mutex
+	tweets []models.Tweet
+	clock  time.Time
+}
+
+func (f *fakeTweets) Create(ctx context.Context, tweet *models.Tweet) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if tweet.ID.IsZero() {
+		tweet.ID = primitive.NewObjectID()
+	}
+	for _, t := range f.tweets {
+		if t.ID == tweet.ID {
+			return repository.ErrTweetExists
+		}
+	}
+	// Cada tweet es un segundo posterior al anterior para que el orden sea estable
+	f.clock = f.clock.Add(time.Second)
+	tweet.CreatedAt = f.clock
+	f.tweets = append(f.tweets, cloneTweet(*tweet))
+	return nil
+}
+
+func (f *fakeTweets) GetByID(ctx context.Context, id string) (*models.Tweet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, t := range f.tweets {
+		if t.ID.Hex() == id && t.HiddenAt == nil && !t.Held() {
+			copied := cloneTweet(t)
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrTweetNotFound.Wrap(mongo.ErrNoDocuments)
+}
+
+func (f *fakeTweets) find(match func(t models.Tweet) bool) []models.Tweet {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []models.Tweet
+	for _, t := range f.tweets {
+		if t.HiddenAt == nil && !t.Held() && match(t) {
+			result = append(result, cloneTweet(t))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result
+}
+
+func (f *fakeTweets) GetByUserID(ctx context.Context, userID string) ([]models.Tweet, error) {
+	return f.find(func(t models.Tweet) bool { return t.UserID.Hex() == userID }), nil
+}
+
+func (f *fakeTweets) GetByHashtag(ctx context.Context, tag string, excludeAuthors []primitive.ObjectID, limit int) ([]models.Tweet, error) {
+	result := f.find(func(t models.Tweet) bool {
+		for _, id := range excludeAuthors {
+			if t.UserID == id {
+				return false
+			}
+		}
+		for _, h := range t.Hashtags {
+			if h == tag {
+				return true
+			}
+		}
+		return false
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (f *fakeTweets) GetByAuthors(ctx context.Context, authorIDs []primitive.ObjectID, skip, limit int) ([]models.Tweet, error) {
+	result := f.find(func(t models.Tweet) bool {
+		for _, id := range authorIDs {
+			if t.UserID == id {
+				return true
+			}
+		}
+		return false
+	})
+	if skip >= len(result) {
+		return nil, nil
+	}
+	result = result[skip:]
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (f *fakeTweets) GetByAuthorsAfter(ctx context.Context, authorIDs []primitive.ObjectID, after *models.TweetPosition, limit int) ([]models.Tweet, error) {
+	result := f.find(func(t models.Tweet) bool {
+		if after != nil && !t.CreatedAt.Before(after.CreatedAt) &&
+			(!t.CreatedAt.Equal(after.CreatedAt) || bytes.Compare(t.ID[:], after.ID[:]) >= 0) {
+			return false
+		}
+		return slices.Contains(authorIDs, t.UserID)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (f *fakeTweets) GetByAuthorsSince(ctx context.Context, authorIDs []primitive.ObjectID, since models.TweetPosition, limit int) ([]models.Tweet, error) {
+	result := f.find(func(t models.Tweet) bool {
+		return t.Position().Compare(since) > 0 && slices.Contains(authorIDs, t.UserID)
+	})
+	slices.Reverse(result)
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (f *fakeTweets) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Tweet, error) {
+	return f.find(func(t models.Tweet) bool { return slices.Contains(ids, t.ID) }), nil
+}
+
+func (f *fakeTweets) Hide(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.tweets {
+		if f.tweets[i].ID == id {
+			if f.tweets[i].HiddenAt == nil {
+				f.tweets[i].HiddenAt = &at
+			}
+			return nil
+		}
+	}
+	return repository.ErrTweetNotFound
+}
+
+func (f *fakeTweets) Delete(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.tweets {
+		if f.tweets[i].ID.Hex() == id {
+			f.tweets = append(f.tweets[:i], f.tweets[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrTweetNotFound
+}
+
+// count cuenta los tweets publicados desde since, ocultos y retenidos incluidos
+func (f *fakeTweets) count(since time.Time, match func(t models.Tweet) bool) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var n int64
+	for _, t := range f.tweets {
+		if !t.CreatedAt.Before(since) && match(t) {
+			n++
+		}
+	}
+	return n
+}
+
+func (f *fakeTweets) CountByContentHash(ctx context.Context, hash string, since time.Time) (int64, error) {
+	return f.count(since, func(t models.Tweet) bool { return t.ContentHash == hash }), nil
+}
+
+func (f *fakeTweets) CountByUserSince(ctx context.Context, userID primitive.ObjectID, since time.Time) (int64, error) {
+	return f.count(since, func(t models.Tweet) bool { return t.UserID == userID }), nil
+}
+
+func (f *fakeTweets) ListByPolicyStatus(ctx context.Context, status string, skip, limit int) ([]models.Tweet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := []models.Tweet{}
+	for _, t := range f.tweets {
+		if t.Policy != nil && t.Policy.Status == status {
+			result = append(result, cloneTweet(t))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	if skip >= len(result) {
+		return []models.Tweet{}, nil
+	}
+	result = result[skip:]
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (f *fakeTweets) ApprovePolicy(ctx context.Context, id primitive.ObjectID, reviewer string, at time.Time) (*models.Tweet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.tweets {
+		t := &f.tweets[i]
+		if t.ID != id {
+			continue
+		}
+		if t.Policy == nil || (t.Policy.Status != models.PolicyStatusHeld && t.Policy.Status != models.PolicyStatusFlagged) {
+			return nil, repository.ErrTweetNotInReview
+		}
+		before := cloneTweet(*t)
+		policy := *t.Policy
+		policy.Status, policy.ReviewedBy, policy.ReviewedAt = models.PolicyStatusApproved, reviewer, &at
+		t.Policy = &policy
+		return &before, nil
+	}
+	return nil, repository.ErrTweetNotFound
+}
+
+// cloneTweet copia la encuesta para que los cambios de vista no alteren lo guardado
+func cloneTweet(t models.Tweet) models.Tweet {
+	if t.Poll != nil {
+		poll := *t.Poll
+		poll.Options = make([]models.PollOption, len(t.Poll.Options))
+		for i, o := range t.Poll.Options {
+			if o.Votes != nil {
+				votes := *o.Votes
+				o.Votes = &votes
+			}
+			poll.Options[i] = o
+		}
+		if t.Poll.TotalVotes != nil {
+			total := *t.Poll.TotalVotes
+			poll.TotalVotes = &total
+		}
+		t.Poll = &poll
+	}
+	return t
+}
+
+type fakeMedia struct {
+	owners map[primitive.ObjectID]primitive.ObjectID
+	items  map[primitive.ObjectID]*models.Media
+}
+
+func (f *fakeMedia) Create(ctx context.Context, media *models.Media) error {
+	if f.items == nil {
+		f.items = make(map[primitive.ObjectID]*models.Media)
+	}
+	f.items[media.ID] = media
+	return nil
+}
+
+func (f *fakeMedia) GetByID(ctx context.Context, id string) (*models.Media, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	if media := f.items[objectID]; media != nil {
+		return media, nil
+	}
+	return nil, ErrMediaNotFound
+}
+
+func (f *fakeMedia) CountOwned(ctx context.Context, ids []primitive.ObjectID, userID primitive.ObjectID) (int64, error) {
+	var count int64
+	for _, id := range ids {
+		if owner, ok := f.owners[id]; ok && owner == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+type fakePolls struct {
+	mu     sync.Mutex
+	tweets *fakeTweets
+	votes  map[primitive.ObjectID]models.PollVote
+}
+
+func newFakePolls(tweets *fakeTweets) *fakePolls {
+	return &fakePolls{tweets: tweets, votes: make(map[primitive.ObjectID]models.PollVote)}
+}
+
+func (f *fakePolls) InsertVote(ctx context.Context, vote *models.PollVote) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, v := range f.votes {
+		if v.TweetID == vote.TweetID && v.UserID == vote.UserID {
+			return repository.ErrAlreadyVoted
+		}
+	}
+	vote.ID = primitive.NewObjectID()
+	f.votes[vote.ID] = *vote
+	return nil
+}
+
+func (f *fakePolls) DeleteVote(ctx context.Context, id primitive.ObjectID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.votes, id)
+	return nil
+}
+
+func (f *fakePolls) CountVote(ctx context.Context, tweetID primitive.ObjectID, option int, now time.Time) (bool, error) {
+	f.tweets.mu.Lock()
+	defer f.tweets.mu.Unlock()
+	for i := range f.tweets.tweets {
+		t := &f.tweets.tweets[i]
+		if t.ID != tweetID || t.Poll == nil {
+			continue
+		}
+		if t.Poll.Closed || !t.Poll.ExpiresAt.After(now) {
+			return false, nil
+		}
+		*t.Poll.Options[option].Votes++
+		*t.Poll.TotalVotes++
+		return true, nil
+	}
+	return false, nil
+}
+
+func (f *fakePolls) VotesByUser(ctx context.Context, userID primitive.ObjectID, tweetIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := make(map[primitive.ObjectID]int)
+	for _, v := range f.votes {
+		if v.UserID != userID {
+			continue
+		}
+		for _, id := range tweetIDs {
+			if v.TweetID == id {
+				result[id] = v.Option
+			}
+		}
+	}
+	return result, nil
+}
+
+type fakeScheduled struct {
+	items map[primitive.ObjectID]*models.ScheduledTweet
+}
+
+func newFakeScheduled() *fakeScheduled {
+	return &fakeScheduled{items: make(map[primitive.ObjectID]*models.ScheduledTweet)}
+}
+
+func (f *fakeScheduled) Create(ctx context.Context, st *models.ScheduledTweet) error {
+	st.ID = primitive.NewObjectID()
+	copied := *st
+	f.items[st.ID] = &copied
+	return nil
+}
+
+func (f *fakeScheduled) GetByID(ctx context.Context, userID, id string) (*models.ScheduledTweet, error) {
+	for _, st := range f.items {
+		if st.ID.Hex() == id && st.UserID.Hex() == userID {
+			copied := *st
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrScheduledNotFound
+}
+
+func (f *fakeScheduled) ListByUser(ctx context.Context, userID string, statuses []string) ([]models.ScheduledTweet, error) {
+	return nil, nil
+}
+
+func (f *fakeScheduled) Update(ctx context.Context, st *models.ScheduledTweet) error {
+	current, ok := f.items[st.ID]
+	if !ok || current.LeaseOwner != "" {
+		return repository.ErrScheduledLocked
+	}
+	copied := *st
+	f.items[st.ID] = &copied
+	return nil
+}
+
+func (f *fakeScheduled) Cancel(ctx context.Context, userID, id string) error {
+	return nil
+}
+
+// fakeReports guarda un caso abierto por objetivo, como el índice único de Mongo
+type fakeReports struct {
+	mu      sync.Mutex
+	reports []*models.Report
+	clock   time.Time
+}
+
+func (f *fakeReports) Add(ctx context.Context, report *models.Report, entry models.ReportEntry) (*models.Report, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.clock = f.clock.Add(time.Second)
+	entry.CreatedAt = f.clock
+
+	var open *models.Report
+	for _, r := range f.reports {
+		if r.Status == models.ReportStatusOpen && r.TargetType == report.TargetType && r.TargetID == report.TargetID {
+			open = r
+		}
+	}
+	if open == nil {
+		open = &models.Report{
+			ID:           primitive.NewObjectID(),
+			TargetType:   report.TargetType,
+			TargetID:     report.TargetID,
+			TargetUserID: report.TargetUserID,
+			Status:       models.ReportStatusOpen,
+			Reasons:      map[string]int{},
+			CreatedAt:    f.clock,
+		}
+		f.reports = append(f.reports, open)
+	}
+	for _, e := range open.Entries {
+		if e.ReporterID == entry.ReporterID {
+			return nil, repository.ErrAlreadyReported
+		}
+	}
+	open.Entries = append(open.Entries, entry)
+	open.ReportCount++
+	open.Reasons[entry.Reason]++
+	open.Severity = max(open.Severity, models.ReportReasons[entry.Reason])
+	open.UpdatedAt = f.clock
+	copied := *open
+	return &copied, nil
+}
+
+func (f *fakeReports) GetByID(ctx context.Context, id string) (*models.Report, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range f.reports {
+		if r.ID.Hex() == id {
+			copied := *r
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrReportNotFound
+}
+
+func (f *fakeReports) ListByStatus(ctx context.Context, status string, skip, limit int) ([]models.Report, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []models.Report
+	for _, r := range f.reports {
+		if r.Status == status {
+			result = append(result, *r)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.ReportCount != b.ReportCount {
+			return a.ReportCount > b.ReportCount
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+	if skip >= len(result) {
+		return []models.Report{}, nil
+	}
+	result = result[skip:]
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (f *fakeReports) Resolve(ctx context.Context, id primitive.ObjectID, resolution models.ReportResolution) (*models.Report, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range f.reports {
+		if r.ID != id {
+			continue
+		}
+		if r.Status != models.ReportStatusOpen {
+			return nil, repository.ErrReportResolved
+		}
+		r.Status = models.ReportStatusResolved
+		r.Resolution = &resolution
+		copied := *r
+		return &copied, nil
+	}
+	return nil, repository.ErrReportNotFound
+}
+
+type fakeNotifications struct {
+	lastLimit int
+}
+
+func (f *fakeNotifications) GetByUserID(ctx context.Context, userID string, limit int) ([]models.Notification, error) {
+	f.lastLimit = limit
+	return nil, nil
+}
+
+type recordingPublisher struct {
+	published []primitive.ObjectID
+}
+
+func (p *recordingPublisher) PublishTweet(ctx context.Context, tweet *models.Tweet) {
+	p.published = append(p.published, tweet.ID)
+}
+
+// recordingMetrics guarda los eventos de dominio recibidos
+type recordingMetrics struct {
+	mu        sync.Mutex
+	tweets    int
+	follows   int
+	unfollows int
+	pages     []int
+}
+
+func (m *recordingMetrics) TweetCreated(*models.Tweet) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tweets++
+}
+
+func (m *recordingMetrics) FollowChanged(follow bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if follow {
+		m.follows++
+	} else {
+		m.unfollows++
+	}
+}
+
+func (m *recordingMetrics) TimelineServed(page int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pages = append(m.pages, page)
+}
+
+type fakeDeletions struct {
+	mu        sync.Mutex
+	deletions map[primitive.ObjectID]*models.AccountDeletion
+}
+
+func newFakeDeletions() *fakeDeletions {
+	return &fakeDeletions{deletions: make(map[primitive.ObjectID]*models.AccountDeletion)}
+}
+
+func (f *fakeDeletions) Create(ctx context.Context, deletion *models.AccountDeletion) (*models.AccountDeletion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if existing, ok := f.deletions[deletion.UserID]; ok {
+		copied := *existing
+		return &copied, nil
+	}
+	stored := *deletion
+	stored.ID = primitive.NewObjectID()
+	stored.Status = models.DeletionStatusPending
+	f.deletions[deletion.UserID] = &stored
+	copied := stored
+	return &copied, nil
+}
+
+func (f *fakeDeletions) GetByUserID(ctx context.Context, userID string) (*models.AccountDeletion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range f.deletions {
+		if d.UserID.Hex() == userID {
+			copied := *d
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrDeletionNotFound
+}
+
+// fakeAudit guarda los registros de auditoría en memoria
+type fakeAudit struct {
+	mu      sync.Mutex
+	entries []models.AuditEntry
+}
+
+func (f *fakeAudit) Record(ctx context.Context, entry models.AuditEntry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = append(f.entries, entry)
+}
+
+func (f *fakeAudit) actions() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	actions := make([]string, 0, len(f.entries))
+	for _, entry := range f.entries {
+		actions = append(actions, entry.Action)
+	}
+	return actions
+}
+
+// fakePolicyRules guarda la lista de bloqueo en memoria y cuenta las lecturas
+type fakePolicyRules struct {
+	mu    sync.Mutex
+	rules []models.PolicyRule
+	lists int
+}
+
+func (f *fakePolicyRules) Create(ctx context.Context, rule *models.PolicyRule) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range f.rules {
+		if r.Kind == rule.Kind && r.Pattern == rule.Pattern {
+			return repository.ErrPolicyRuleExists
+		}
+	}
+	rule.ID = primitive.NewObjectID()
+	f.rules = append(f.rules, *rule)
+	return nil
+}
+
+func (f *fakePolicyRules) List(ctx context.Context) ([]models.PolicyRule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lists++
+	return append([]models.PolicyRule{}, f.rules...), nil
+}
+
+func (f *fakePolicyRules) Delete(ctx context.Context, id string) (*models.PolicyRule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, r := range f.rules {
+		if r.ID.Hex() == id {
+			f.rules = append(f.rules[:i], f.rules[i+1:]...)
+			return &r, nil
+		}
+	}
+	return nil, repository.ErrPolicyRuleNotFound
+}
+
+// fakeExports guarda las exportaciones en memoria con una pendiente por usuario
+type fakeExports struct {
+	mu   sync.Mutex
+	jobs []*models.ExportJob
+}
+
+func (f *fakeExports) Create(ctx context.Context, job *models.ExportJob) (*models.ExportJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, existing := range f.jobs {
+		if existing.UserID == job.UserID && existing.Status == models.ExportStatusPending {
+			copied := *existing
+			return &copied, nil
+		}
+	}
+	stored := *job
+	stored.ID = primitive.NewObjectID()
+	stored.Status = models.ExportStatusPending
+	f.jobs = append(f.jobs, &stored)
+	copied := stored
+	return &copied, nil
+}
+
+func (f *fakeExports) GetByID(ctx context.Context, id string) (*models.ExportJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, job := range f.jobs {
+		if job.ID.Hex() == id {
+			copied := *job
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrExportNotFound
+}
@@ -0,0 +1,36 @@
+// internal/service/hashtags.go
+package service
+
+import (
+	"regexp"
+	"strings"
+)
+
+// hashtagPattern reconoce hashtags como #golang o #café dentro del contenido. El
+// # debe ir al principio o después de un carácter que no sea de palabra ni /,
+// para no tomar como hashtag el fragmento de una URL (https://x.com/a#b) ni una
+// entidad HTML (&#39;).
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_/&])#([\p{L}\p{N}_]+)`)
+
+// ExtractHashtags devuelve los hashtags del contenido normalizados y sin duplicados
+func ExtractHashtags(content string) []string {
+	matches := hashtagPattern.FindAllStringSubmatch(content, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(matches))
+	tags := make([]string, 0, len(matches))
+	for _, m := range matches {
+		tag := normalizeHashtag(m[1])
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func normalizeHashtag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
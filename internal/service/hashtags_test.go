@@ -0,0 +1,25 @@
+// internal/service/hashtags_test.go
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"#Go al principio", []string{"go"}},
+		{"Aprendiendo #Go y #café, (#go) otra vez", []string{"go", "café"}},
+		{"Ver https://example.com/docs#install y https://example.com/#top", nil},
+		{"Comillas &#39;escapadas&#39;", nil},
+		{"sin#espacio no cuenta", nil},
+		{"#", nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ExtractHashtags(tt.content), tt.content)
+	}
+}
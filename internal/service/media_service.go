@@ -0,0 +1,131 @@
+// internal/service/media_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/media"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"github.com/ffelixf/microblog-platform/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrMediaNotFound        = repository.ErrMediaNotFound
+	ErrMediaVariantNotFound = apperr.NotFound("media_variant_not_found", "variante no encontrada")
+)
+
+// MediaService procesa y guarda los archivos subidos: las variantes van al
+// almacenamiento de blobs y los metadatos a la base de datos
+type MediaService struct {
+	media     MediaMetadataStore
+	users     UserReader
+	blobs     storage.BlobStore
+	processor *media.Processor
+}
+
+func NewMediaService(mediaStore MediaMetadataStore, users UserReader, blobs storage.BlobStore, processor *media.Processor) *MediaService {
+	return &MediaService{
+		media:     mediaStore,
+		users:     users,
+		blobs:     blobs,
+		processor: processor,
+	}
+}
+
+// MaxBytes es el tamaño máximo de un archivo subido
+func (s *MediaService) MaxBytes() int64 {
+	return s.processor.MaxBytes()
+}
+
+// Upload valida la imagen, elimina sus metadatos y guarda sus variantes. Solo
+// pueden subir archivos las cuentas que pueden publicar. Si algo falla a mitad
+// de camino se borran las variantes ya guardadas.
+func (s *MediaService) Upload(ctx context.Context, userID primitive.ObjectID, data []byte) (*models.Media, error) {
+	user, err := getUser(ctx, s.users, userID.Hex(), ErrUserNotFound)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCanPost(user); err != nil {
+		return nil, err
+	}
+
+	variants, err := s.processor.Process(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &models.Media{
+		ID:          primitive.NewObjectID(),
+		UserID:      userID,
+		ContentType: variants[0].ContentType,
+		Size:        int64(len(variants[0].Data)),
+		Variants:    make(map[string]models.MediaVariant, len(variants)),
+	}
+
+	stored := make([]string, 0, len(variants))
+	for _, v := range variants {
+		key := fmt.Sprintf("media/%s/%s/%s%s", userID.Hex(), m.ID.Hex(), v.Name, v.Extension)
+		if err := s.blobs.Put(ctx, key, v.Data, v.ContentType); err != nil {
+			s.cleanup(ctx, stored)
+			return nil, fmt.Errorf("error al guardar archivo: %w", err)
+		}
+		stored = append(stored, key)
+
+		m.Variants[v.Name] = models.MediaVariant{
+			Key:         key,
+			ContentType: v.ContentType,
+			Width:       v.Width,
+			Height:      v.Height,
+			Size:        int64(len(v.Data)),
+		}
+	}
+
+	if err := s.media.Create(ctx, m); err != nil {
+		s.cleanup(ctx, stored)
+		return nil, err
+	}
+	return m, nil
+}
+
+// Get obtiene los metadatos de un archivo
+func (s *MediaService) Get(ctx context.Context, id string) (*models.Media, error) {
+	return s.media.GetByID(ctx, id)
+}
+
+// OpenVariant devuelve una variante de un archivo con su contenido abierto, que
+// el llamador debe cerrar
+func (s *MediaService) OpenVariant(ctx context.Context, id, name string) (*models.MediaVariant, *storage.Blob, error) {
+	m, err := s.media.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	variant, ok := m.Variants[name]
+	if !ok {
+		return nil, nil, ErrMediaVariantNotFound
+	}
+
+	blob, err := s.blobs.Get(ctx, variant.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, ErrMediaNotFound.Wrap(err)
+		}
+		return nil, nil, fmt.Errorf("error al leer archivo: %w", err)
+	}
+	return &variant, blob, nil
+}
+
+func (s *MediaService) cleanup(ctx context.Context, keys []string) {
+	// La limpieza debe completarse aunque el cliente haya cortado la petición
+	ctx = context.WithoutCancel(ctx)
+	for _, key := range keys {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			slog.ErrorContext(ctx, "error al eliminar blob huérfano", slog.String("key", key), slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// internal/service/media_service_test.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/media"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPNG(t *testing.T) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 64, 48))))
+	return buf.Bytes()
+}
+
+func TestMediaService_Upload(t *testing.T) {
+	ctx := context.Background()
+	blobs, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	now := time.Now()
+	active := &models.User{Username: "ana"}
+	suspended := &models.User{Username: "beto", Suspension: &models.Suspension{Reason: "spam"}}
+	deactivated := &models.User{Username: "carla", DeactivatedAt: &now}
+	mediaStore := &fakeMedia{}
+	s := NewMediaService(mediaStore, newFakeUsers(active, suspended, deactivated), blobs, media.NewProcessor(1<<20))
+
+	m, err := s.Upload(ctx, active.ID, testPNG(t))
+	require.NoError(t, err)
+	assert.Equal(t, active.ID, m.UserID)
+	require.NotEmpty(t, m.Variants)
+
+	got, err := s.Get(ctx, m.ID.Hex())
+	require.NoError(t, err)
+	assert.Equal(t, m.ID, got.ID)
+	for name, v := range m.Variants {
+		variant, blob, err := s.OpenVariant(ctx, m.ID.Hex(), name)
+		require.NoError(t, err)
+		assert.Equal(t, v.ContentType, variant.ContentType)
+		assert.Equal(t, v.Size, blob.Size)
+		require.NoError(t, blob.Close())
+	}
+	_, _, err = s.OpenVariant(ctx, m.ID.Hex(), "gigante")
+	assert.ErrorIs(t, err, ErrMediaVariantNotFound)
+
+	// Las cuentas que no pueden publicar tampoco pueden subir archivos
+	_, err = s.Upload(ctx, suspended.ID, testPNG(t))
+	assert.ErrorIs(t, err, ErrUserSuspended)
+	_, err = s.Upload(ctx, deactivated.ID, testPNG(t))
+	assert.ErrorIs(t, err, ErrAccountDeactivated)
+	assert.Len(t, mediaStore.items, 1)
+}
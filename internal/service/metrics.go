@@ -0,0 +1,28 @@
+// internal/service/metrics.go
+package service
+
+import "github.com/ffelixf/microblog-platform/internal/models"
+
+// Metrics recibe los eventos de dominio que se exponen como métricas. Los servicios
+// aceptan nil y en ese caso no registran nada.
+type Metrics interface {
+	// TweetCreated cuenta un tweet publicado, sea desde la API o por el scheduler
+	TweetCreated(tweet *models.Tweet)
+	// FollowChanged cuenta un follow (follow=true) o unfollow completado
+	FollowChanged(follow bool)
+	// TimelineServed cuenta una consulta del timeline por página
+	TimelineServed(page int)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) TweetCreated(*models.Tweet) {}
+func (noopMetrics) FollowChanged(bool)         {}
+func (noopMetrics) TimelineServed(int)         {}
+
+func metricsOrNoop(m Metrics) Metrics {
+	if m == nil {
+		return noopMetrics{}
+	}
+	return m
+}
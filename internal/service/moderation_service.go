@@ -0,0 +1,228 @@
+// internal/service/moderation_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/audit"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrReportNotFound  = repository.ErrReportNotFound
+	ErrAlreadyReported = repository.ErrAlreadyReported
+	ErrReportResolved  = repository.ErrReportResolved
+	ErrUserSuspended   = apperr.Forbidden("user_suspended", "la cuenta está suspendida")
+)
+
+// Errores de validación de denuncias y resoluciones
+var (
+	ErrReporterRequired     = apperr.InvalidField("reporter_id_required", "reporter_id", "el ID de quien denuncia es requerido")
+	ErrReporterNotFound     = apperr.InvalidField("reporter_not_found", "reporter_id", "el usuario que denuncia no existe")
+	ErrInvalidReportTarget  = apperr.InvalidField("invalid_report_target", "target_type", "el objetivo de la denuncia debe ser tweet o user")
+	ErrReportTargetNotFound = apperr.InvalidField("report_target_not_found", "target_id", "el contenido denunciado no existe")
+	ErrSelfReport           = apperr.Validation("self_report", "no puedes denunciarte a ti mismo")
+	ErrInvalidReportReason  = apperr.InvalidField("invalid_report_reason", "reason", "categoría de denuncia inválida")
+	ErrReportCommentTooLong = apperr.InvalidField("report_comment_too_long", "comment",
+		fmt.Sprintf("el comentario no puede exceder los %d caracteres", models.MaxReportCommentLength)).
+		WithParam("max", models.MaxReportCommentLength)
+	ErrInvalidReportStatus     = apperr.InvalidField("invalid_report_status", "status", "el estado debe ser open o resolved")
+	ErrModeratorNotFound       = apperr.InvalidField("moderator_not_found", "moderator_id", "el moderador no existe")
+	ErrInvalidModerationAction = apperr.InvalidField("invalid_moderation_action", "action", "acción de moderación inválida para esta denuncia")
+)
+
+// ReportPage es una página de la cola de moderación
+type ReportPage struct {
+	Page    int
+	Limit   int
+	Reports []models.Report
+}
+
+// ModerationService recibe las denuncias de los usuarios y aplica las decisiones
+// de los moderadores
+type ModerationService struct {
+	reports ReportStore
+	tweets  ModeratedTweets
+	users   ModeratedUsers
+	audit   AuditLog
+	now     func() time.Time
+}
+
+func NewModerationService(reports ReportStore, tweets ModeratedTweets, users ModeratedUsers, auditLog AuditLog) *ModerationService {
+	return &ModerationService{
+		reports: reports,
+		tweets:  tweets,
+		users:   users,
+		audit:   auditOrNoop(auditLog),
+		now:     time.Now,
+	}
+}
+
+// Report registra una denuncia. Las denuncias sobre un mismo objetivo se suman a
+// su caso abierto; un usuario solo puede denunciar una vez cada caso.
+func (s *ModerationService) Report(ctx context.Context, req *models.ReportRequest) (*models.Report, error) {
+	if req.ReporterID.IsZero() {
+		return nil, ErrReporterRequired
+	}
+	if _, ok := models.ReportReasons[req.Reason]; !ok {
+		return nil, ErrInvalidReportReason
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+	if utf8.RuneCountInString(req.Comment) > models.MaxReportCommentLength {
+		return nil, ErrReportCommentTooLong
+	}
+	if _, err := getUser(ctx, s.users, req.ReporterID.Hex(), ErrReporterNotFound); err != nil {
+		return nil, err
+	}
+
+	report := &models.Report{TargetType: req.TargetType, TargetID: req.TargetID}
+	switch req.TargetType {
+	case models.ReportTargetTweet:
+		tweet, err := s.tweets.GetByID(ctx, req.TargetID.Hex())
+		if err != nil {
+			if errors.Is(err, ErrTweetNotFound) {
+				return nil, ErrReportTargetNotFound
+			}
+			return nil, err
+		}
+		report.TargetUserID = tweet.UserID
+	case models.ReportTargetUser:
+		user, err := getUser(ctx, s.users, req.TargetID.Hex(), ErrReportTargetNotFound)
+		if err != nil {
+			return nil, err
+		}
+		report.TargetUserID = user.ID
+	default:
+		return nil, ErrInvalidReportTarget
+	}
+	if report.TargetUserID == req.ReporterID {
+		return nil, ErrSelfReport
+	}
+
+	return s.reports.Add(ctx, report, models.ReportEntry{
+		ReporterID: req.ReporterID,
+		Reason:     req.Reason,
+		Comment:    req.Comment,
+	})
+}
+
+// Queue devuelve una página de casos en el estado indicado (open por defecto),
+// ordenados por gravedad y cantidad de denuncias
+func (s *ModerationService) Queue(ctx context.Context, status string, page, limit int) (*ReportPage, error) {
+	if status == "" {
+		status = models.ReportStatusOpen
+	}
+	if status != models.ReportStatusOpen && status != models.ReportStatusResolved {
+		return nil, ErrInvalidReportStatus
+	}
+	page, limit = Paginate(page, limit)
+
+	reports, err := s.reports.ListByStatus(ctx, status, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &ReportPage{Page: page, Limit: limit, Reports: reports}, nil
+}
+
+// Get obtiene un caso de moderación
+func (s *ModerationService) Get(ctx context.Context, id string) (*models.Report, error) {
+	return s.reports.GetByID(ctx, id)
+}
+
+// actionPermissions son los permisos que necesita cada acción además de reports:resolve
+var actionPermissions = map[string]rbac.Permission{
+	models.ModerationHideTweet:   rbac.TweetsHide,
+	models.ModerationSuspendUser: rbac.UsersSuspend,
+}
+
+// Resolve aplica la decisión del moderador y cierra el caso. hide_tweet solo se
+// admite sobre tweets; suspend_user suspende al usuario denunciado o al autor del
+// tweet denunciado. La decisión queda registrada en el caso con el moderador.
+func (s *ModerationService) Resolve(ctx context.Context, moderator *rbac.Principal, id string, resolution models.ReportResolution) (*models.Report, error) {
+	if err := rbac.Require(moderator, rbac.ReportsResolve); err != nil {
+		return nil, err
+	}
+	if perm, ok := actionPermissions[resolution.Action]; ok {
+		if err := rbac.Require(moderator, perm); err != nil {
+			return nil, err
+		}
+	}
+	moderatorID, err := primitive.ObjectIDFromHex(moderator.UserID)
+	if err != nil {
+		return nil, ErrModeratorNotFound
+	}
+	report, err := s.reports.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != models.ReportStatusOpen {
+		return nil, ErrReportResolved
+	}
+	if _, err := getUser(ctx, s.users, moderatorID.Hex(), ErrModeratorNotFound); err != nil {
+		return nil, err
+	}
+	resolution.ModeratorID = moderatorID
+
+	now := s.now()
+	resolution.Note = strings.TrimSpace(resolution.Note)
+	resolution.ResolvedAt = now
+
+	switch resolution.Action {
+	case models.ModerationDismiss:
+	case models.ModerationHideTweet:
+		if report.TargetType != models.ReportTargetTweet {
+			return nil, ErrInvalidModerationAction
+		}
+		if err := s.tweets.Hide(ctx, report.TargetID, now); err != nil {
+			return nil, err
+		}
+	case models.ModerationSuspendUser:
+		reason := resolution.Note
+		if reason == "" {
+			reason = report.TopReason()
+		}
+		err := s.users.Suspend(ctx, report.TargetUserID, models.Suspension{
+			Reason:      reason,
+			ModeratorID: resolution.ModeratorID,
+			ReportID:    report.ID,
+			Since:       now,
+		})
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalidModerationAction
+	}
+
+	resolved, err := s.reports.Resolve(ctx, report.ID, resolution)
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, models.AuditEntry{
+		Action:     models.AuditReportResolved,
+		ActorID:    moderator.UserID,
+		TargetType: models.AuditTargetReport,
+		TargetID:   report.ID.Hex(),
+		Before:     audit.State(map[string]any{"status": report.Status}),
+		After: audit.State(map[string]any{
+			"status": resolved.Status,
+			"action": resolution.Action,
+			"note":   resolution.Note,
+		}),
+		Metadata: map[string]string{
+			"target_type":    report.TargetType,
+			"target_id":      report.TargetID.Hex(),
+			"target_user_id": report.TargetUserID.Hex(),
+		},
+	})
+	return resolved, nil
+}
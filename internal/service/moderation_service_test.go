@@ -0,0 +1,223 @@
+// internal/service/moderation_service_test.go
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type moderationFixture struct {
+	service   *ModerationService
+	users     *fakeUsers
+	tweets    *fakeTweets
+	reports   *fakeReports
+	author    *models.User
+	reporters []*models.User
+	moderator *models.User
+	tweet     *models.Tweet
+	now       time.Time
+}
+
+func setupModeration(t *testing.T) *moderationFixture {
+	f := &moderationFixture{
+		author:    &models.User{Username: "autor"},
+		moderator: &models.User{Username: "moderadora", Roles: []string{rbac.RoleModerator}},
+		tweets:    &fakeTweets{},
+		reports:   &fakeReports{},
+		now:       time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC),
+	}
+	f.users = newFakeUsers(f.author, f.moderator)
+	for _, name := range []string{"ana", "bruno", "carla"} {
+		f.reporters = append(f.reporters, f.users.add(&models.User{Username: name}))
+	}
+	f.tweet = &models.Tweet{UserID: f.author.ID, Content: "contenido ofensivo"}
+	require.NoError(t, f.tweets.Create(context.Background(), f.tweet))
+
+	f.service = NewModerationService(f.reports, f.tweets, f.users, nil)
+	f.service.now = func() time.Time { return f.now }
+	return f
+}
+
+func (f *moderationFixture) report(t *testing.T, reporter *models.User, targetType string, targetID primitive.ObjectID, reason string) *models.Report {
+	report, err := f.service.Report(context.Background(), &models.ReportRequest{
+		ReporterID: reporter.ID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+	})
+	require.NoError(t, err)
+	return report
+}
+
+func TestModerationService_ReportDeduplicatesByTarget(t *testing.T) {
+	f := setupModeration(t)
+	ctx := context.Background()
+
+	first := f.report(t, f.reporters[0], models.ReportTargetTweet, f.tweet.ID, "spam")
+	second := f.report(t, f.reporters[1], models.ReportTargetTweet, f.tweet.ID, "harassment")
+
+	assert.Equal(t, first.ID, second.ID, "las denuncias del mismo objetivo comparten caso")
+	assert.Equal(t, 2, second.ReportCount)
+	assert.Equal(t, 3, second.Severity, "el caso toma la gravedad máxima")
+	assert.Equal(t, f.author.ID, second.TargetUserID)
+	assert.Equal(t, "harassment", second.TopReason())
+
+	t.Run("same reporter twice", func(t *testing.T) {
+		_, err := f.service.Report(ctx, &models.ReportRequest{
+			ReporterID: f.reporters[0].ID, TargetType: models.ReportTargetTweet, TargetID: f.tweet.ID, Reason: "spam",
+		})
+		assert.ErrorIs(t, err, ErrAlreadyReported)
+	})
+}
+
+func TestModerationService_ReportValidation(t *testing.T) {
+	f := setupModeration(t)
+	ctx := context.Background()
+	reporter := f.reporters[0].ID
+
+	tests := []struct {
+		name string
+		req  models.ReportRequest
+		want error
+	}{
+		{"missing reporter", models.ReportRequest{TargetType: "tweet", TargetID: f.tweet.ID, Reason: "spam"}, ErrReporterRequired},
+		{"unknown reporter", models.ReportRequest{ReporterID: primitive.NewObjectID(), TargetType: "tweet", TargetID: f.tweet.ID, Reason: "spam"}, ErrReporterNotFound},
+		{"unknown reason", models.ReportRequest{ReporterID: reporter, TargetType: "tweet", TargetID: f.tweet.ID, Reason: "aburrido"}, ErrInvalidReportReason},
+		{"unknown target type", models.ReportRequest{ReporterID: reporter, TargetType: "poll", TargetID: f.tweet.ID, Reason: "spam"}, ErrInvalidReportTarget},
+		{"missing tweet", models.ReportRequest{ReporterID: reporter, TargetType: "tweet", TargetID: primitive.NewObjectID(), Reason: "spam"}, ErrReportTargetNotFound},
+		{"missing user", models.ReportRequest{ReporterID: reporter, TargetType: "user", TargetID: primitive.NewObjectID(), Reason: "spam"}, ErrReportTargetNotFound},
+		{"self report", models.ReportRequest{ReporterID: f.author.ID, TargetType: "tweet", TargetID: f.tweet.ID, Reason: "spam"}, ErrSelfReport},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.service.Report(ctx, &tt.req)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestModerationService_QueueOrder(t *testing.T) {
+	f := setupModeration(t)
+	ctx := context.Background()
+
+	other := &models.Tweet{UserID: f.author.ID, Content: "otro"}
+	require.NoError(t, f.tweets.Create(ctx, other))
+
+	spam := f.report(t, f.reporters[0], models.ReportTargetTweet, f.tweet.ID, "spam")
+	f.report(t, f.reporters[1], models.ReportTargetTweet, f.tweet.ID, "spam")
+	violence := f.report(t, f.reporters[0], models.ReportTargetUser, f.author.ID, "violence")
+	single := f.report(t, f.reporters[2], models.ReportTargetTweet, other.ID, "spam")
+
+	page, err := f.service.Queue(ctx, "", 1, 10)
+	require.NoError(t, err)
+	require.Len(t, page.Reports, 3)
+	assert.Equal(t, violence.ID, page.Reports[0].ID, "primero lo más grave")
+	assert.Equal(t, spam.ID, page.Reports[1].ID, "a igual gravedad, lo más denunciado")
+	assert.Equal(t, single.ID, page.Reports[2].ID)
+
+	_, err = f.service.Queue(ctx, "pending", 1, 10)
+	assert.ErrorIs(t, err, ErrInvalidReportStatus)
+}
+
+func TestModerationService_Resolve(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("hide tweet", func(t *testing.T) {
+		f := setupModeration(t)
+		report := f.report(t, f.reporters[0], models.ReportTargetTweet, f.tweet.ID, "hate")
+
+		resolved, err := f.service.Resolve(ctx, principalOf(f.moderator), report.ID.Hex(), models.ReportResolution{
+			Action: models.ModerationHideTweet, Note: " discurso de odio ",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, models.ReportStatusResolved, resolved.Status)
+		assert.Equal(t, f.moderator.ID, resolved.Resolution.ModeratorID)
+		assert.Equal(t, "discurso de odio", resolved.Resolution.Note)
+		assert.Equal(t, f.now, resolved.Resolution.ResolvedAt)
+
+		_, err = f.tweets.GetByID(ctx, f.tweet.ID.Hex())
+		assert.ErrorIs(t, err, ErrTweetNotFound, "el tweet oculto deja de verse")
+
+		_, err = f.service.Resolve(ctx, principalOf(f.moderator), report.ID.Hex(), models.ReportResolution{
+			Action: models.ModerationDismiss,
+		})
+		assert.ErrorIs(t, err, ErrReportResolved)
+	})
+
+	t.Run("suspend author of tweet", func(t *testing.T) {
+		f := setupModeration(t)
+		report := f.report(t, f.reporters[0], models.ReportTargetTweet, f.tweet.ID, "violence")
+
+		_, err := f.service.Resolve(ctx, principalOf(f.moderator), report.ID.Hex(), models.ReportResolution{
+			Action: models.ModerationSuspendUser,
+		})
+		require.NoError(t, err)
+
+		author, _ := f.users.GetByID(ctx, f.author.ID.Hex())
+		require.True(t, author.IsSuspended())
+		assert.Equal(t, "violence", author.Suspension.Reason)
+		assert.Equal(t, f.moderator.ID, author.Suspension.ModeratorID)
+		assert.Equal(t, report.ID, author.Suspension.ReportID)
+	})
+
+	t.Run("dismiss", func(t *testing.T) {
+		f := setupModeration(t)
+		report := f.report(t, f.reporters[0], models.ReportTargetUser, f.author.ID, "spam")
+
+		resolved, err := f.service.Resolve(ctx, principalOf(f.moderator), report.ID.Hex(), models.ReportResolution{
+			Action: models.ModerationDismiss,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, models.ModerationDismiss, resolved.Resolution.Action)
+		author, _ := f.users.GetByID(ctx, f.author.ID.Hex())
+		assert.False(t, author.IsSuspended())
+
+		// Volver a denunciar un objetivo ya resuelto abre un caso nuevo
+		again := f.report(t, f.reporters[0], models.ReportTargetUser, f.author.ID, "spam")
+		assert.NotEqual(t, report.ID, again.ID)
+		assert.Equal(t, 1, again.ReportCount)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		f := setupModeration(t)
+		report := f.report(t, f.reporters[0], models.ReportTargetUser, f.author.ID, "spam")
+		id := report.ID.Hex()
+		moderator := principalOf(f.moderator)
+
+		_, err := f.service.Resolve(ctx, moderator, id, models.ReportResolution{Action: models.ModerationHideTweet})
+		assert.ErrorIs(t, err, ErrInvalidModerationAction, "no se puede ocultar un usuario")
+
+		_, err = f.service.Resolve(ctx, &rbac.Principal{UserID: primitive.NewObjectID().Hex(), Roles: []string{rbac.RoleModerator}}, id, models.ReportResolution{Action: models.ModerationDismiss})
+		assert.ErrorIs(t, err, ErrModeratorNotFound)
+
+		_, err = f.service.Resolve(ctx, moderator, primitive.NewObjectID().Hex(), models.ReportResolution{Action: models.ModerationDismiss})
+		assert.ErrorIs(t, err, ErrReportNotFound)
+	})
+
+	t.Run("permissions", func(t *testing.T) {
+		f := setupModeration(t)
+		report := f.report(t, f.reporters[0], models.ReportTargetTweet, f.tweet.ID, "spam")
+		id := report.ID.Hex()
+		triage := f.users.add(&models.User{Username: "triaje", Permissions: []string{string(rbac.ReportsRead), string(rbac.ReportsResolve)}})
+
+		_, err := f.service.Resolve(ctx, nil, id, models.ReportResolution{Action: models.ModerationDismiss})
+		assert.ErrorIs(t, err, rbac.ErrUnauthenticated)
+
+		_, err = f.service.Resolve(ctx, principalOf(f.reporters[1]), id, models.ReportResolution{Action: models.ModerationDismiss})
+		assert.ErrorIs(t, err, rbac.ErrPermissionDenied)
+
+		_, err = f.service.Resolve(ctx, principalOf(triage), id, models.ReportResolution{Action: models.ModerationSuspendUser})
+		assert.ErrorIs(t, err, rbac.ErrPermissionDenied, "suspender requiere users:suspend")
+
+		resolved, err := f.service.Resolve(ctx, principalOf(triage), id, models.ReportResolution{Action: models.ModerationDismiss})
+		require.NoError(t, err)
+		assert.Equal(t, triage.ID, resolved.Resolution.ModeratorID)
+	})
+}
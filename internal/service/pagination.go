@@ -0,0 +1,67 @@
+// internal/service/pagination.go
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrInvalidCursor = apperr.Validation("invalid_cursor", "cursor inválido")
+
+const (
+	// DefaultPageSize es el tamaño de página cuando no se indica uno válido
+	DefaultPageSize = 10
+	// MaxPageSize es el tamaño de página máximo para cualquier listado
+	MaxPageSize = 50
+)
+
+// Paginate normaliza los parámetros de paginación: la página mínima es 1, un tamaño
+// no positivo usa DefaultPageSize y uno mayor que MaxPageSize se recorta al máximo
+func Paginate(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	return page, limit
+}
+
+// EncodeTweetCursor devuelve el cursor de una posición en los listados de
+// tweets. Es opaco para los clientes: base64 de la posición con un prefijo, para
+// que no se confunda con otros cursores.
+func EncodeTweetCursor(pos models.TweetPosition) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "tweet:%d:%s", pos.CreatedAt.UnixNano(), pos.ID.Hex()))
+}
+
+// DecodeTweetCursor lee un cursor de EncodeTweetCursor; devuelve
+// ErrInvalidCursor si no lo es
+func DecodeTweetCursor(cursor string) (*models.TweetPosition, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 || parts[0] != "tweet" {
+		return nil, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := primitive.ObjectIDFromHex(parts[2])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &models.TweetPosition{CreatedAt: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
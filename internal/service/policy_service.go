@@ -0,0 +1,318 @@
+// internal/service/policy_service.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/audit"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/policy"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// rulesCacheTTL es cuánto se reutiliza la lista de bloqueo compilada. Los cambios
+// hechos en esta instancia se aplican al momento; los de otras, al vencer.
+const rulesCacheTTL = 30 * time.Second
+
+var (
+	ErrPolicyRuleNotFound = repository.ErrPolicyRuleNotFound
+	ErrPolicyRuleExists   = repository.ErrPolicyRuleExists
+	ErrTweetNotInReview   = repository.ErrTweetNotInReview
+
+	ErrContentRejected      = apperr.InvalidField("content_rejected", "content", "el contenido infringe las normas de publicación")
+	ErrInvalidPolicyPattern = apperr.InvalidField("invalid_policy_pattern", "pattern", "patrón inválido para el tipo de regla")
+	ErrInvalidPolicyAction  = apperr.InvalidField("invalid_policy_action", "action", "la acción debe ser flag, hold o reject")
+	ErrInvalidPolicyStatus  = apperr.InvalidField("invalid_policy_status", "status", "el estado debe ser held o flagged")
+	ErrTooManyMutedWords    = apperr.InvalidField("too_many_muted_words", "words",
+		fmt.Sprintf("no se pueden silenciar más de %d palabras", models.MaxMutedWords)).
+		WithParam("max", models.MaxMutedWords)
+	ErrInvalidMutedWord = apperr.InvalidField("invalid_muted_word", "words",
+		fmt.Sprintf("cada palabra silenciada debe tener entre 1 y %d caracteres y al menos una letra o dígito", models.MaxMutedWordLength)).
+		WithParam("max", models.MaxMutedWordLength)
+)
+
+// PolicyReviewPage es una página de la cola de revisión
+type PolicyReviewPage struct {
+	Page   int
+	Limit  int
+	Tweets []models.PolicyReviewItem
+}
+
+// PolicyService aplica la política de contenido: la lista de bloqueo que
+// gestiona la administración y las heurísticas de spam, evaluadas antes de
+// guardar cada tweet. También gestiona la cola de revisión de los tweets
+// retenidos o marcados y las palabras silenciadas de cada usuario.
+type PolicyService struct {
+	rules      PolicyRuleStore
+	tweets     PolicyTweets
+	users      PolicyUsers
+	heuristics policy.Heuristics
+	audit      AuditLog
+	metrics    Metrics
+	publishers []TweetPublisher
+	now        func() time.Time
+
+	mu        sync.Mutex
+	blocklist *policy.Blocklist
+	loadedAt  time.Time
+}
+
+func NewPolicyService(rules PolicyRuleStore, tweets PolicyTweets, users PolicyUsers, heuristics policy.Heuristics, auditLog AuditLog, metrics Metrics, publishers ...TweetPublisher) *PolicyService {
+	return &PolicyService{
+		rules:      rules,
+		tweets:     tweets,
+		users:      users,
+		heuristics: heuristics,
+		audit:      auditOrNoop(auditLog),
+		metrics:    metricsOrNoop(metrics),
+		publishers: publishers,
+		now:        time.Now,
+	}
+}
+
+// Apply evalúa un tweet antes de guardarlo. Devuelve ErrContentRejected si
+// alguna regla lo rechaza; si lo retiene o lo marca, lo deja en tweet.Policy.
+func (s *PolicyService) Apply(ctx context.Context, tweet *models.Tweet, author *models.User) error {
+	now := s.now()
+	text := policy.NewText(tweet.Content)
+	tweet.ContentHash = text.Hash()
+	tweet.Policy = nil
+
+	blocklist, err := s.loadBlocklist(ctx)
+	if err != nil {
+		return err
+	}
+
+	signals := policy.Signals{Links: text.Links(), AccountAge: now.Sub(author.CreatedAt)}
+	if tweet.ContentHash != "" && s.heuristics.DuplicateEnabled() {
+		since := now.Add(-s.heuristics.Duplicate.Window)
+		if signals.Duplicates, err = s.tweets.CountByContentHash(ctx, tweet.ContentHash, since); err != nil {
+			return err
+		}
+	}
+	if s.heuristics.BurstApplies(signals.AccountAge) {
+		since := now.Add(-s.heuristics.Burst.Window)
+		if signals.RecentTweets, err = s.tweets.CountByUserSince(ctx, author.ID, since); err != nil {
+			return err
+		}
+	}
+
+	decision := policy.Decide(blocklist.Match(text), s.heuristics.Match(signals))
+	switch decision.Action {
+	case policy.ActionReject:
+		slog.InfoContext(ctx, "tweet rechazado por la política de contenido",
+			slog.String("user_id", author.ID.Hex()), slog.Any("rules", decision.Rules()))
+		return ErrContentRejected
+	case policy.ActionHold:
+		tweet.Policy = &models.TweetPolicy{Status: models.PolicyStatusHeld, Rules: decision.Rules()}
+	case policy.ActionFlag:
+		tweet.Policy = &models.TweetPolicy{Status: models.PolicyStatusFlagged, Rules: decision.Rules()}
+	}
+	return nil
+}
+
+// loadBlocklist devuelve la lista de bloqueo compilada, recargándola si venció
+func (s *PolicyService) loadBlocklist(ctx context.Context) (*policy.Blocklist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.blocklist != nil && s.now().Sub(s.loadedAt) < rulesCacheTTL {
+		return s.blocklist, nil
+	}
+	stored, err := s.rules.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]policy.Rule, len(stored))
+	for i, r := range stored {
+		rules[i] = policy.Rule{ID: r.ID.Hex(), Kind: r.Kind, Pattern: r.Pattern, Action: policy.Action(r.Action)}
+	}
+	blocklist, err := policy.NewBlocklist(rules)
+	if err != nil {
+		// Las reglas se validan al crearlas; una inválida solo puede venir de la base
+		slog.WarnContext(ctx, "reglas de la lista de bloqueo ignoradas", slog.Any("error", err))
+	}
+	s.blocklist, s.loadedAt = blocklist, s.now()
+	return blocklist, nil
+}
+
+// invalidate descarta la lista de bloqueo compilada para que la siguiente
+// evaluación lea los cambios
+func (s *PolicyService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blocklist = nil
+}
+
+// Rules devuelve la lista de bloqueo
+func (s *PolicyService) Rules(ctx context.Context) ([]models.PolicyRule, error) {
+	return s.rules.List(ctx)
+}
+
+// CreateRule valida y añade una regla a la lista de bloqueo
+func (s *PolicyService) CreateRule(ctx context.Context, actor *rbac.Principal, rule *models.PolicyRule) error {
+	rule.Pattern = strings.TrimSpace(rule.Pattern)
+	if _, err := policy.Compile(rule.Kind, rule.Pattern); err != nil {
+		return ErrInvalidPolicyPattern.Wrap(err)
+	}
+	if action, err := policy.ParseAction(rule.Action); err != nil || !action.Enabled() {
+		return ErrInvalidPolicyAction
+	}
+	rule.ID = primitive.NilObjectID
+	rule.CreatedBy = ""
+	if actor != nil {
+		rule.CreatedBy = actor.UserID
+	}
+
+	if err := s.rules.Create(ctx, rule); err != nil {
+		return err
+	}
+	s.invalidate()
+	s.audit.Record(ctx, models.AuditEntry{
+		Action:     models.AuditPolicyRuleCreated,
+		TargetType: models.AuditTargetRule,
+		TargetID:   rule.ID.Hex(),
+		After:      ruleState(rule),
+	})
+	return nil
+}
+
+// DeleteRule quita una regla de la lista de bloqueo
+func (s *PolicyService) DeleteRule(ctx context.Context, id string) error {
+	rule, err := s.rules.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.invalidate()
+	s.audit.Record(ctx, models.AuditEntry{
+		Action:     models.AuditPolicyRuleDeleted,
+		TargetType: models.AuditTargetRule,
+		TargetID:   rule.ID.Hex(),
+		Before:     ruleState(rule),
+	})
+	return nil
+}
+
+func ruleState(rule *models.PolicyRule) models.AuditState {
+	return audit.State(map[string]any{"kind": rule.Kind, "pattern": rule.Pattern, "action": rule.Action})
+}
+
+// ReviewQueue devuelve los tweets retenidos (held, por defecto) o marcados
+// (flagged) pendientes de revisión
+func (s *PolicyService) ReviewQueue(ctx context.Context, status string, page, limit int) (*PolicyReviewPage, error) {
+	if status == "" {
+		status = models.PolicyStatusHeld
+	}
+	if status != models.PolicyStatusHeld && status != models.PolicyStatusFlagged {
+		return nil, ErrInvalidPolicyStatus
+	}
+	page, limit = Paginate(page, limit)
+
+	tweets, err := s.tweets.ListByPolicyStatus(ctx, status, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+	applyPollState(tweets, nil, s.now())
+	items := make([]models.PolicyReviewItem, len(tweets))
+	for i, t := range tweets {
+		items[i] = models.PolicyReviewItem{Tweet: t, Policy: t.Policy}
+	}
+	return &PolicyReviewPage{Page: page, Limit: limit, Tweets: items}, nil
+}
+
+// Approve aprueba un tweet de la cola de revisión. Un tweet retenido se publica
+// en ese momento; para descartarlo se borra con la administración de tweets.
+func (s *PolicyService) Approve(ctx context.Context, actor *rbac.Principal, tweetID string) (*models.PolicyReviewItem, error) {
+	id, err := primitive.ObjectIDFromHex(tweetID)
+	if err != nil {
+		return nil, ErrTweetNotFound
+	}
+	reviewer := ""
+	if actor != nil {
+		reviewer = actor.UserID
+	}
+
+	now := s.now()
+	tweet, err := s.tweets.ApprovePolicy(ctx, id, reviewer, now)
+	if err != nil {
+		return nil, err
+	}
+	previous := tweet.Policy.Status
+	tweet.Policy.Status = models.PolicyStatusApproved
+	tweet.Policy.ReviewedBy, tweet.Policy.ReviewedAt = reviewer, &now
+	if tweet.Poll != nil {
+		tweet.Poll.ApplyViewer(-1, now)
+	}
+
+	// El tweet retenido no se contó ni se distribuyó al crearlo
+	if previous == models.PolicyStatusHeld {
+		s.metrics.TweetCreated(tweet)
+		for _, p := range s.publishers {
+			p.PublishTweet(ctx, tweet)
+		}
+	}
+	s.audit.Record(ctx, models.AuditEntry{
+		Action:     models.AuditTweetApproved,
+		TargetType: models.AuditTargetTweet,
+		TargetID:   tweet.ID.Hex(),
+		Before:     audit.State(map[string]any{"status": previous}),
+		After:      audit.State(map[string]any{"status": models.PolicyStatusApproved}),
+		Metadata:   map[string]string{"rules": strings.Join(tweet.Policy.Rules, ","), "user_id": tweet.UserID.Hex()},
+	})
+	return &models.PolicyReviewItem{Tweet: *tweet, Policy: tweet.Policy}, nil
+}
+
+// MutedWords devuelve las palabras silenciadas del usuario
+func (s *PolicyService) MutedWords(ctx context.Context, userID string) (*models.MutedWords, error) {
+	user, err := getUser(ctx, s.users, userID, ErrUserNotFound)
+	if err != nil {
+		return nil, err
+	}
+	words := user.MutedWords
+	if words == nil {
+		words = []string{}
+	}
+	return &models.MutedWords{Words: words}, nil
+}
+
+// SetMutedWords reemplaza las palabras silenciadas del usuario. Se guardan en
+// minúsculas y sin repetidos.
+func (s *PolicyService) SetMutedWords(ctx context.Context, userID string, words []string) (*models.MutedWords, error) {
+	normalized := make([]string, 0, len(words))
+	seen := make(map[string]bool, len(words))
+	for _, w := range words {
+		w = strings.Join(strings.Fields(strings.ToLower(w)), " ")
+		if w == "" || seen[w] {
+			continue
+		}
+		if utf8.RuneCountInString(w) > models.MaxMutedWordLength {
+			return nil, ErrInvalidMutedWord.WithParam("word", w)
+		}
+		if _, err := policy.CompileMuted(w); err != nil {
+			return nil, ErrInvalidMutedWord.WithParam("word", w)
+		}
+		seen[w] = true
+		normalized = append(normalized, w)
+	}
+	if len(normalized) > models.MaxMutedWords {
+		return nil, ErrTooManyMutedWords
+	}
+
+	user, err := getUser(ctx, s.users, userID, ErrUserNotFound)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.SetMutedWords(ctx, user.ID, normalized); err != nil {
+		return nil, err
+	}
+	return &models.MutedWords{Words: normalized}, nil
+}
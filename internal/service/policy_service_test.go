@@ -0,0 +1,253 @@
+// internal/service/policy_service_test.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/policy"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type policyFixture struct {
+	*tweetServiceFixture
+	policy *PolicyService
+	rules  *fakePolicyRules
+	audit  *fakeAudit
+	admin  *rbac.Principal
+}
+
+// newPolicyFixture publica con la política de contenido; el autor es una cuenta
+// antigua salvo que el test cambie su fecha de alta
+func newPolicyFixture(heuristics policy.Heuristics) *policyFixture {
+	f := &policyFixture{
+		tweetServiceFixture: newTweetServiceFixture(),
+		rules:               &fakePolicyRules{},
+		audit:               &fakeAudit{},
+		admin:               &rbac.Principal{UserID: primitive.NewObjectID().Hex(), Roles: []string{rbac.RoleAdmin}},
+	}
+	f.author.CreatedAt = f.now.Add(-365 * 24 * time.Hour)
+	f.tweets.clock = f.now
+	f.policy = NewPolicyService(f.rules, f.tweets, f.users, heuristics, f.audit, f.metrics, f.publisher)
+	f.policy.now = func() time.Time { return f.now }
+	f.service.contentPolicy = f.policy
+	return f
+}
+
+func (f *policyFixture) post(content string) (*models.Tweet, error) {
+	tweet := &models.Tweet{UserID: f.author.ID, Content: content}
+	return tweet, f.service.Create(context.Background(), tweet)
+}
+
+func TestPolicyService_Blocklist(t *testing.T) {
+	ctx := context.Background()
+	f := newPolicyFixture(policy.Heuristics{})
+
+	for _, rule := range []*models.PolicyRule{
+		{Kind: policy.KindExact, Pattern: "casino", Action: string(policy.ActionFlag)},
+		{Kind: policy.KindWildcard, Pattern: "crypt*", Action: string(policy.ActionHold)},
+		{Kind: policy.KindRegex, Pattern: `gana \d+ euros`, Action: string(policy.ActionReject)},
+	} {
+		require.NoError(t, f.policy.CreateRule(ctx, f.admin, rule))
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		_, err := f.post("Gana 500 euros hoy")
+		assert.ErrorIs(t, err, ErrContentRejected)
+		assert.Empty(t, f.tweets.tweets)
+	})
+
+	t.Run("hold keeps the tweet out of the API until approved", func(t *testing.T) {
+		tweet, err := f.post("Invierte en cryptomonedas")
+		require.NoError(t, err)
+		assert.True(t, tweet.Held())
+		assert.Len(t, tweet.Policy.Rules, 1)
+		assert.Empty(t, f.publisher.published, "no se distribuye")
+		assert.Zero(t, f.metrics.tweets)
+		_, err = f.tweets.GetByID(ctx, tweet.ID.Hex())
+		assert.ErrorIs(t, err, ErrTweetNotFound)
+
+		queue, err := f.policy.ReviewQueue(ctx, "", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, queue.Tweets, 1)
+		assert.Equal(t, tweet.ID, queue.Tweets[0].ID)
+
+		approved, err := f.policy.Approve(ctx, f.admin, tweet.ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, models.PolicyStatusApproved, approved.Policy.Status)
+		assert.Equal(t, f.admin.UserID, approved.Policy.ReviewedBy)
+		assert.Equal(t, []primitive.ObjectID{tweet.ID}, f.publisher.published)
+		assert.Equal(t, 1, f.metrics.tweets)
+		_, err = f.tweets.GetByID(ctx, tweet.ID.Hex())
+		assert.NoError(t, err)
+
+		_, err = f.policy.Approve(ctx, f.admin, tweet.ID.Hex())
+		assert.ErrorIs(t, err, ErrTweetNotInReview)
+	})
+
+	t.Run("flag publishes and queues for review", func(t *testing.T) {
+		published := len(f.publisher.published)
+		tweet, err := f.post("Noche de CASINO")
+		require.NoError(t, err)
+		assert.Equal(t, models.PolicyStatusFlagged, tweet.Policy.Status)
+		assert.Len(t, f.publisher.published, published+1)
+
+		queue, err := f.policy.ReviewQueue(ctx, models.PolicyStatusFlagged, 1, 10)
+		require.NoError(t, err)
+		assert.Len(t, queue.Tweets, 1)
+
+		_, err = f.policy.Approve(ctx, f.admin, tweet.ID.Hex())
+		require.NoError(t, err)
+		assert.Len(t, f.publisher.published, published+1, "un tweet marcado ya estaba publicado")
+	})
+
+	t.Run("clean content", func(t *testing.T) {
+		tweet, err := f.post("Hola a todos")
+		require.NoError(t, err)
+		assert.Nil(t, tweet.Policy)
+	})
+
+	t.Run("deleting a rule applies immediately", func(t *testing.T) {
+		rules, err := f.policy.Rules(ctx)
+		require.NoError(t, err)
+		require.NoError(t, f.policy.DeleteRule(ctx, rules[2].ID.Hex()))
+
+		tweet, err := f.post("Gana 500 euros hoy")
+		require.NoError(t, err)
+		assert.Nil(t, tweet.Policy)
+	})
+
+	assert.Equal(t, []string{
+		models.AuditPolicyRuleCreated, models.AuditPolicyRuleCreated, models.AuditPolicyRuleCreated,
+		models.AuditTweetApproved, models.AuditTweetApproved, models.AuditPolicyRuleDeleted,
+	}, f.audit.actions())
+}
+
+func TestPolicyService_RuleValidation(t *testing.T) {
+	ctx := context.Background()
+	f := newPolicyFixture(policy.Heuristics{})
+
+	err := f.policy.CreateRule(ctx, f.admin, &models.PolicyRule{Kind: policy.KindRegex, Pattern: "(", Action: "reject"})
+	assert.ErrorIs(t, err, ErrInvalidPolicyPattern)
+	err = f.policy.CreateRule(ctx, f.admin, &models.PolicyRule{Kind: policy.KindExact, Pattern: "spam", Action: "none"})
+	assert.ErrorIs(t, err, ErrInvalidPolicyAction)
+
+	rule := &models.PolicyRule{Kind: policy.KindExact, Pattern: "  spam ", Action: "flag"}
+	require.NoError(t, f.policy.CreateRule(ctx, f.admin, rule))
+	assert.Equal(t, "spam", rule.Pattern)
+	assert.Equal(t, f.admin.UserID, rule.CreatedBy)
+	err = f.policy.CreateRule(ctx, f.admin, &models.PolicyRule{Kind: policy.KindExact, Pattern: "spam", Action: "hold"})
+	assert.ErrorIs(t, err, ErrPolicyRuleExists)
+
+	assert.ErrorIs(t, f.policy.DeleteRule(ctx, primitive.NewObjectID().Hex()), ErrPolicyRuleNotFound)
+	_, err = f.policy.ReviewQueue(ctx, models.PolicyStatusApproved, 1, 10)
+	assert.ErrorIs(t, err, ErrInvalidPolicyStatus)
+}
+
+func TestPolicyService_RulesCache(t *testing.T) {
+	f := newPolicyFixture(policy.Heuristics{})
+
+	for i := 0; i < 3; i++ {
+		_, err := f.post("hola")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, f.rules.lists, "la lista se reutiliza")
+
+	// Un cambio hecho por otra instancia se ve al vencer la caché
+	f.rules.rules = append(f.rules.rules, models.PolicyRule{ID: primitive.NewObjectID(), Kind: policy.KindExact, Pattern: "hola", Action: "reject"})
+	_, err := f.post("hola")
+	assert.NoError(t, err)
+	f.now = f.now.Add(rulesCacheTTL)
+	_, err = f.post("hola")
+	assert.ErrorIs(t, err, ErrContentRejected)
+	assert.Equal(t, 2, f.rules.lists)
+}
+
+func TestPolicyService_Heuristics(t *testing.T) {
+	heuristics := policy.Heuristics{
+		Duplicate: policy.Duplicate{Action: policy.ActionHold, Threshold: 2, Window: time.Hour},
+		Links:     policy.Links{Action: policy.ActionFlag, Max: 1},
+		Burst:     policy.Burst{Action: policy.ActionReject, AccountAge: 24 * time.Hour, MaxTweets: 3, Window: 10 * time.Minute},
+	}
+
+	t.Run("duplicate content across accounts", func(t *testing.T) {
+		f := newPolicyFixture(heuristics)
+		other := f.users.add(&models.User{Username: "otra"})
+		spam := "Compra seguidores baratos en nuestra web"
+
+		_, err := f.post(spam)
+		require.NoError(t, err)
+		require.NoError(t, f.service.Create(context.Background(), &models.Tweet{UserID: other.ID, Content: "COMPRA seguidores baratos, en nuestra web!"}))
+
+		tweet, err := f.post(spam)
+		require.NoError(t, err)
+		assert.True(t, tweet.Held())
+		assert.Equal(t, []string{policy.RuleDuplicate}, tweet.Policy.Rules)
+
+		// Fuera de la ventana no cuenta
+		f.now = f.now.Add(2 * time.Hour)
+		f.tweets.clock = f.now
+		tweet, err = f.post(spam)
+		require.NoError(t, err)
+		assert.Nil(t, tweet.Policy)
+	})
+
+	t.Run("link density", func(t *testing.T) {
+		f := newPolicyFixture(heuristics)
+		tweet, err := f.post("https://a.example y https://b.example")
+		require.NoError(t, err)
+		assert.Equal(t, models.PolicyStatusFlagged, tweet.Policy.Status)
+		assert.Equal(t, []string{policy.RuleLinks}, tweet.Policy.Rules)
+	})
+
+	t.Run("burst posting by new accounts", func(t *testing.T) {
+		f := newPolicyFixture(heuristics)
+		f.author.CreatedAt = f.now.Add(-time.Hour)
+		for i := 0; i < 3; i++ {
+			_, err := f.post(fmt.Sprintf("tweet %d", i))
+			require.NoError(t, err)
+		}
+		_, err := f.post("tweet 3")
+		assert.ErrorIs(t, err, ErrContentRejected)
+
+		// Una cuenta antigua puede publicar seguido
+		f.author.CreatedAt = f.now.Add(-48 * time.Hour)
+		_, err = f.post("tweet 3")
+		assert.NoError(t, err)
+	})
+}
+
+func TestPolicyService_MutedWords(t *testing.T) {
+	ctx := context.Background()
+	f := newPolicyFixture(policy.Heuristics{})
+	userID := f.author.ID.Hex()
+
+	words, err := f.policy.MutedWords(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, words.Words)
+
+	words, err = f.policy.SetMutedWords(ctx, userID, []string{" Spoiler ", "spoiler", "final  de temporada", "", "capítulo*"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"spoiler", "final de temporada", "capítulo*"}, words.Words)
+
+	stored, err := f.policy.MutedWords(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, words.Words, stored.Words)
+
+	_, err = f.policy.SetMutedWords(ctx, userID, []string{"*"})
+	assert.ErrorIs(t, err, ErrInvalidMutedWord)
+	tooMany := make([]string, models.MaxMutedWords+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("palabra%d", i)
+	}
+	_, err = f.policy.SetMutedWords(ctx, userID, tooMany)
+	assert.ErrorIs(t, err, ErrTooManyMutedWords)
+	_, err = f.policy.SetMutedWords(ctx, primitive.NewObjectID().Hex(), nil)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
@@ -0,0 +1,174 @@
+// internal/service/store.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Interfaces de acceso a datos que usan los servicios. Las implementan los
+// repositorios de MongoDB; en las pruebas se sustituyen por fakes en memoria.
+
+// UserStore es el acceso a usuarios y relaciones de seguimiento
+type UserStore interface {
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.User, error)
+	FollowUser(ctx context.Context, userID, targetID string) error
+	UnfollowUser(ctx context.Context, userID, targetID string) error
+	GetFollowing(ctx context.Context, userID string) ([]models.User, error)
+	GetFollowers(ctx context.Context, userID string) ([]models.User, error)
+}
+
+// UserReader es la parte de UserStore que necesitan los demás servicios
+type UserReader interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+}
+
+// TimelineUsers es el acceso a usuarios de los listados de tweets, que ocultan
+// las cuentas inactivas
+type TimelineUsers interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	InactiveIDs(ctx context.Context) ([]primitive.ObjectID, error)
+}
+
+// TweetStore es el acceso a tweets publicados
+type TweetStore interface {
+	Create(ctx context.Context, tweet *models.Tweet) error
+	GetByID(ctx context.Context, id string) (*models.Tweet, error)
+	GetByUserID(ctx context.Context, userID string) ([]models.Tweet, error)
+	GetByHashtag(ctx context.Context, tag string, excludeAuthors []primitive.ObjectID, limit int) ([]models.Tweet, error)
+	GetByAuthors(ctx context.Context, authorIDs []primitive.ObjectID, skip, limit int) ([]models.Tweet, error)
+	GetByAuthorsAfter(ctx context.Context, authorIDs []primitive.ObjectID, after *models.TweetPosition, limit int) ([]models.Tweet, error)
+	GetByAuthorsSince(ctx context.Context, authorIDs []primitive.ObjectID, since models.TweetPosition, limit int) ([]models.Tweet, error)
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Tweet, error)
+}
+
+// MediaStore verifica los archivos adjuntos de un tweet
+type MediaStore interface {
+	CountOwned(ctx context.Context, ids []primitive.ObjectID, userID primitive.ObjectID) (int64, error)
+}
+
+// MediaMetadataStore guarda y consulta los metadatos de los archivos subidos
+type MediaMetadataStore interface {
+	Create(ctx context.Context, media *models.Media) error
+	GetByID(ctx context.Context, id string) (*models.Media, error)
+}
+
+// PollStore es el acceso a los votos de las encuestas
+type PollStore interface {
+	InsertVote(ctx context.Context, vote *models.PollVote) error
+	DeleteVote(ctx context.Context, id primitive.ObjectID) error
+	CountVote(ctx context.Context, tweetID primitive.ObjectID, option int, now time.Time) (bool, error)
+	VotesByUser(ctx context.Context, userID primitive.ObjectID, tweetIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error)
+}
+
+// ScheduleStore es el acceso a borradores y tweets programados
+type ScheduleStore interface {
+	Create(ctx context.Context, st *models.ScheduledTweet) error
+	GetByID(ctx context.Context, userID, id string) (*models.ScheduledTweet, error)
+	ListByUser(ctx context.Context, userID string, statuses []string) ([]models.ScheduledTweet, error)
+	Update(ctx context.Context, st *models.ScheduledTweet) error
+	Cancel(ctx context.Context, userID, id string) error
+}
+
+// NotificationStore es el acceso a las notificaciones de los usuarios
+type NotificationStore interface {
+	GetByUserID(ctx context.Context, userID string, limit int) ([]models.Notification, error)
+}
+
+// ReportStore es el acceso a la cola de moderación
+type ReportStore interface {
+	Add(ctx context.Context, report *models.Report, entry models.ReportEntry) (*models.Report, error)
+	GetByID(ctx context.Context, id string) (*models.Report, error)
+	ListByStatus(ctx context.Context, status string, skip, limit int) ([]models.Report, error)
+	Resolve(ctx context.Context, id primitive.ObjectID, resolution models.ReportResolution) (*models.Report, error)
+}
+
+// ModeratedTweets es el acceso a los tweets que necesita la moderación
+type ModeratedTweets interface {
+	GetByID(ctx context.Context, id string) (*models.Tweet, error)
+	Hide(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
+
+// ModeratedUsers es el acceso a los usuarios que necesita la moderación
+type ModeratedUsers interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	Suspend(ctx context.Context, id primitive.ObjectID, suspension models.Suspension) error
+}
+
+// AdminUsers es el acceso a los usuarios que necesita la administración
+type AdminUsers interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	SetRoles(ctx context.Context, id primitive.ObjectID, roles, permissions []string) (*models.User, error)
+	CountByRole(ctx context.Context, role string) (int64, error)
+}
+
+// AdminTweets es el acceso a los tweets que necesita la administración
+type AdminTweets interface {
+	Delete(ctx context.Context, id string) error
+}
+
+// AccountUsers es el acceso a usuarios que necesita el ciclo de vida de las cuentas
+type AccountUsers interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	Suspend(ctx context.Context, id primitive.ObjectID, suspension models.Suspension) error
+	LiftSuspension(ctx context.Context, id primitive.ObjectID) error
+	Deactivate(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	Reactivate(ctx context.Context, id primitive.ObjectID) error
+	MarkForDeletion(ctx context.Context, id primitive.ObjectID, at time.Time) error
+}
+
+// DeletionStore es el acceso a los borrados de cuentas
+type DeletionStore interface {
+	Create(ctx context.Context, deletion *models.AccountDeletion) (*models.AccountDeletion, error)
+	GetByUserID(ctx context.Context, userID string) (*models.AccountDeletion, error)
+}
+
+// ExportStore es el acceso a las exportaciones de datos personales
+type ExportStore interface {
+	Create(ctx context.Context, job *models.ExportJob) (*models.ExportJob, error)
+	GetByID(ctx context.Context, id string) (*models.ExportJob, error)
+}
+
+// AuditStore es el acceso a la auditoría que necesita la consulta
+type AuditStore interface {
+	List(ctx context.Context, query models.AuditQuery) ([]models.AuditEntry, error)
+}
+
+// PolicyRuleStore es el acceso a la lista de bloqueo de la política de contenido
+type PolicyRuleStore interface {
+	Create(ctx context.Context, rule *models.PolicyRule) error
+	List(ctx context.Context) ([]models.PolicyRule, error)
+	Delete(ctx context.Context, id string) (*models.PolicyRule, error)
+}
+
+// PolicyTweets es el acceso a los tweets que necesita la política de contenido:
+// los conteos de las heurísticas y la cola de revisión
+type PolicyTweets interface {
+	CountByContentHash(ctx context.Context, hash string, since time.Time) (int64, error)
+	CountByUserSince(ctx context.Context, userID primitive.ObjectID, since time.Time) (int64, error)
+	ListByPolicyStatus(ctx context.Context, status string, skip, limit int) ([]models.Tweet, error)
+	ApprovePolicy(ctx context.Context, id primitive.ObjectID, reviewer string, at time.Time) (*models.Tweet, error)
+}
+
+// PolicyUsers es el acceso a las palabras silenciadas de los usuarios
+type PolicyUsers interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	SetMutedWords(ctx context.Context, id primitive.ObjectID, words []string) error
+}
+
+// ContentPolicy evalúa la política de contenido de un tweet antes de guardarlo
+type ContentPolicy interface {
+	Apply(ctx context.Context, tweet *models.Tweet, author *models.User) error
+}
+
+// TweetPublisher recibe los tweets recién creados para distribuirlos fuera de la API
+// (por ejemplo, federación). Las implementaciones no deben bloquear.
+type TweetPublisher interface {
+	PublishTweet(ctx context.Context, tweet *models.Tweet)
+}
@@ -0,0 +1,386 @@
+// internal/service/timeline_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/policy"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrInvalidHashtag = apperr.InvalidField("invalid_hashtag", "tag", "hashtag inválido")
+
+const (
+	// maxTimelineReads limita cuántas lecturas hace una porción del timeline
+	// para reponer los tweets quitados por palabras silenciadas
+	maxTimelineReads = 5
+	// inactiveCacheTTL es cuánto se reutiliza la lista de cuentas inactivas; una
+	// suspensión o desactivación tarda hasta eso en ocultar los tweets
+	inactiveCacheTTL = 30 * time.Second
+)
+
+// TimelinePage es una página del timeline con los parámetros ya normalizados
+type TimelinePage struct {
+	Page   int
+	Limit  int
+	Tweets []models.Tweet
+}
+
+// TimelineSlice es una porción de un listado de tweets a partir de una posición
+type TimelineSlice struct {
+	Tweets []models.Tweet
+	// Next es la posición desde la que sigue el listado; nil si no hay más. Es
+	// la del último tweet leído, que puede no estar en Tweets si se quitó por
+	// palabras silenciadas.
+	Next *models.TweetPosition
+}
+
+// TimelineService arma los listados de tweets y completa el estado de las
+// encuestas para quien los consulta
+type TimelineService struct {
+	tweets  TweetStore
+	users   TimelineUsers
+	polls   PollStore
+	metrics Metrics
+	now     func() time.Time
+
+	mu             sync.Mutex
+	inactive       []primitive.ObjectID
+	inactiveLoaded time.Time
+}
+
+func NewTimelineService(tweets TweetStore, users TimelineUsers, polls PollStore, metrics Metrics) *TimelineService {
+	return &TimelineService{
+		tweets:  tweets,
+		users:   users,
+		polls:   polls,
+		metrics: metricsOrNoop(metrics),
+		now:     time.Now,
+	}
+}
+
+// Timeline devuelve los tweets propios y de los usuarios seguidos, del más reciente
+// al más antiguo, sin los de cuentas inactivas. Los tweets ajenos con palabras
+// silenciadas por el usuario se quitan después de paginar, así que una página
+// puede traer menos de limit tweets sin ser la última. Los parámetros de
+// paginación se normalizan con Paginate.
+func (s *TimelineService) Timeline(ctx context.Context, userID string, page, limit int) (*TimelinePage, error) {
+	page, limit = Paginate(page, limit)
+
+	user, err := getUser(ctx, s.users, userID, ErrUserNotFound)
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.TimelineServed(page)
+
+	authors, err := s.timelineAuthors(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	tweets, err := s.tweets.GetByAuthors(ctx, authors, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+	if tweets, err = s.forViewer(ctx, user, tweets); err != nil {
+		return nil, err
+	}
+	return &TimelinePage{Page: page, Limit: limit, Tweets: tweets}, nil
+}
+
+// TimelineAfter devuelve hasta limit tweets del timeline que van después de la
+// posición after, con las mismas reglas que Timeline. A diferencia de las
+// páginas, los tweets nuevos no desplazan los siguientes, así que sirve para
+// paginar con cursores, y los tweets quitados por palabras silenciadas se
+// reponen leyendo más, hasta maxTimelineReads veces. Solo la primera porción
+// cuenta en las métricas por página.
+func (s *TimelineService) TimelineAfter(ctx context.Context, userID string, after *models.TweetPosition, limit int) (*TimelineSlice, error) {
+	_, limit = Paginate(1, limit)
+
+	user, err := getUser(ctx, s.users, userID, ErrUserNotFound)
+	if err != nil {
+		return nil, err
+	}
+	if after == nil {
+		s.metrics.TimelineServed(1)
+	}
+
+	authors, err := s.timelineAuthors(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	slice, err := s.fillAfter(ctx, user, authors, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	if slice.Tweets, err = s.forViewer(ctx, user, slice.Tweets); err != nil {
+		return nil, err
+	}
+	return slice, nil
+}
+
+// TimelineSince devuelve hasta limit tweets del timeline posteriores a la
+// posición since, del más antiguo al más reciente, con las mismas reglas que
+// Timeline. Sirve para seguir el timeline a medida que llegan tweets: Next es la
+// posición del tweet más reciente leído, aunque se haya quitado por palabras
+// silenciadas, y es nil si no hay tweets nuevos. No cuenta en las métricas por
+// página.
+func (s *TimelineService) TimelineSince(ctx context.Context, userID string, since models.TweetPosition, limit int) (*TimelineSlice, error) {
+	_, limit = Paginate(1, limit)
+
+	user, err := getUser(ctx, s.users, userID, ErrUserNotFound)
+	if err != nil {
+		return nil, err
+	}
+	authors, err := s.timelineAuthors(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	tweets, err := s.tweets.GetByAuthorsSince(ctx, authors, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	slice := &TimelineSlice{Tweets: tweets}
+	if len(tweets) > 0 {
+		next := tweets[len(tweets)-1].Position()
+		slice.Next = &next
+	}
+	if slice.Tweets, err = s.forViewer(ctx, user, slice.Tweets); err != nil {
+		return nil, err
+	}
+	return slice, nil
+}
+
+// UserTweetsAfter devuelve hasta limit tweets del usuario que van después de la
+// posición after, vistos por un lector anónimo como en UserTweets
+func (s *TimelineService) UserTweetsAfter(ctx context.Context, userID string, after *models.TweetPosition, limit int) (*TimelineSlice, error) {
+	_, limit = Paginate(1, limit)
+
+	user, err := getUser(ctx, s.users, userID, ErrUserNotFound)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActiveAt(s.now()) {
+		return &TimelineSlice{Tweets: []models.Tweet{}}, nil
+	}
+
+	slice, err := s.sliceAfter(ctx, []primitive.ObjectID{user.ID}, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	applyPollState(slice.Tweets, nil, s.now())
+	return slice, nil
+}
+
+// TweetsByID obtiene los tweets visibles con los IDs indicados en una sola
+// consulta, indexados por ID y vistos por un lector anónimo. Los de cuentas
+// inactivas no aparecen, como en los demás listados.
+func (s *TimelineService) TweetsByID(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*models.Tweet, error) {
+	tweets, err := s.tweets.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	inactive, err := s.inactiveIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applyPollState(tweets, nil, s.now())
+
+	byID := make(map[primitive.ObjectID]*models.Tweet, len(tweets))
+	for i := range tweets {
+		if !slices.Contains(inactive, tweets[i].UserID) {
+			byID[tweets[i].ID] = &tweets[i]
+		}
+	}
+	return byID, nil
+}
+
+// UserTweets devuelve los tweets de un usuario vistos por un lector anónimo; una
+// cuenta inactiva no muestra ninguno
+func (s *TimelineService) UserTweets(ctx context.Context, userID string) ([]models.Tweet, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	switch {
+	case err == nil && !user.IsActiveAt(s.now()):
+		return []models.Tweet{}, nil
+	case err != nil && !errors.Is(err, ErrUserNotFound):
+		return nil, err
+	}
+
+	tweets, err := s.tweets.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	applyPollState(tweets, nil, s.now())
+	return tweets, nil
+}
+
+// HashtagTweets devuelve los tweets más recientes con el hashtag indicado, sin los
+// de cuentas inactivas
+func (s *TimelineService) HashtagTweets(ctx context.Context, tag string, limit int) ([]models.Tweet, error) {
+	tag = normalizeHashtag(tag)
+	if tag == "" {
+		return nil, ErrInvalidHashtag
+	}
+	_, limit = Paginate(1, limit)
+
+	inactive, err := s.inactiveIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tweets, err := s.tweets.GetByHashtag(ctx, tag, inactive, limit)
+	if err != nil {
+		return nil, err
+	}
+	applyPollState(tweets, nil, s.now())
+	return tweets, nil
+}
+
+// timelineAuthors devuelve el usuario y las cuentas activas que sigue
+func (s *TimelineService) timelineAuthors(ctx context.Context, user *models.User) ([]primitive.ObjectID, error) {
+	inactive, err := s.inactiveIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hidden := make(map[primitive.ObjectID]bool, len(inactive))
+	for _, id := range inactive {
+		hidden[id] = true
+	}
+
+	// Incluir tweets propios
+	authors := []primitive.ObjectID{user.ID}
+	for _, id := range user.Following {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil && !hidden[objID] {
+			authors = append(authors, objID)
+		}
+	}
+	return authors, nil
+}
+
+// sliceAfter lee un tweet de más para saber si la porción es la última
+func (s *TimelineService) sliceAfter(ctx context.Context, authors []primitive.ObjectID, after *models.TweetPosition, limit int) (*TimelineSlice, error) {
+	tweets, err := s.tweets.GetByAuthorsAfter(ctx, authors, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	slice := &TimelineSlice{Tweets: tweets}
+	if len(tweets) > limit {
+		slice.Tweets = tweets[:limit]
+		next := tweets[limit-1].Position()
+		slice.Next = &next
+	}
+	return slice, nil
+}
+
+// fillAfter lee porciones con sliceAfter hasta juntar limit tweets que el
+// usuario no silenció o llegar al final. Si completa la porción a mitad de una
+// lectura, Next es el último tweet incluido y el resto se vuelve a leer en la
+// siguiente.
+func (s *TimelineService) fillAfter(ctx context.Context, user *models.User, authors []primitive.ObjectID, after *models.TweetPosition, limit int) (*TimelineSlice, error) {
+	muted := policy.NewMuteList(user.MutedWords)
+	if muted.Empty() {
+		return s.sliceAfter(ctx, authors, after, limit)
+	}
+
+	filled := &TimelineSlice{Tweets: make([]models.Tweet, 0, limit)}
+	for range maxTimelineReads {
+		slice, err := s.sliceAfter(ctx, authors, after, limit)
+		if err != nil {
+			return nil, err
+		}
+		filled.Next = slice.Next
+		for i, t := range slice.Tweets {
+			if t.UserID != user.ID && muted.Match(policy.NewText(t.Content)) {
+				continue
+			}
+			filled.Tweets = append(filled.Tweets, t)
+			if len(filled.Tweets) == limit {
+				if i < len(slice.Tweets)-1 {
+					next := t.Position()
+					filled.Next = &next
+				}
+				return filled, nil
+			}
+		}
+		if slice.Next == nil {
+			break
+		}
+		after = slice.Next
+	}
+	return filled, nil
+}
+
+// inactiveIDs devuelve las cuentas inactivas, leídas de nuevo cada
+// inactiveCacheTTL: cada listado las necesita y cambian muy poco
+func (s *TimelineService) inactiveIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if s.inactive != nil && now.Sub(s.inactiveLoaded) < inactiveCacheTTL {
+		return s.inactive, nil
+	}
+	ids, err := s.users.InactiveIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ids == nil {
+		ids = []primitive.ObjectID{}
+	}
+	s.inactive, s.inactiveLoaded = ids, now
+	return ids, nil
+}
+
+// forViewer quita los tweets ajenos con palabras silenciadas por el usuario y
+// completa el estado de las encuestas para él
+func (s *TimelineService) forViewer(ctx context.Context, user *models.User, tweets []models.Tweet) ([]models.Tweet, error) {
+	if muted := policy.NewMuteList(user.MutedWords); !muted.Empty() {
+		tweets = withoutMuted(tweets, user.ID, muted)
+	}
+
+	var pollIDs []primitive.ObjectID
+	for _, t := range tweets {
+		if t.Poll != nil {
+			pollIDs = append(pollIDs, t.ID)
+		}
+	}
+	var votes map[primitive.ObjectID]int
+	if len(pollIDs) > 0 {
+		var err error
+		votes, err = s.polls.VotesByUser(ctx, user.ID, pollIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	applyPollState(tweets, votes, s.now())
+	return tweets, nil
+}
+
+// withoutMuted quita los tweets de otros usuarios que tienen palabras silenciadas
+func withoutMuted(tweets []models.Tweet, viewer primitive.ObjectID, muted *policy.MuteList) []models.Tweet {
+	kept := tweets[:0]
+	for _, t := range tweets {
+		if t.UserID == viewer || !muted.Match(policy.NewText(t.Content)) {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// applyPollState completa el estado de cada encuesta para quien consulta;
+// sin votos (nil) se trata como un lector anónimo
+func applyPollState(tweets []models.Tweet, votes map[primitive.ObjectID]int, now time.Time) {
+	for i := range tweets {
+		if tweets[i].Poll == nil {
+			continue
+		}
+		option, ok := votes[tweets[i].ID]
+		if !ok {
+			option = -1
+		}
+		tweets[i].Poll.ApplyViewer(option, now)
+	}
+}
@@ -0,0 +1,277 @@
+// internal/service/timeline_service_test.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTimelineService_Timeline(t *testing.T) {
+	ctx := context.Background()
+	f := newTweetServiceFixture()
+	metrics := &recordingMetrics{}
+	timeline := NewTimelineService(f.tweets, f.users, f.polls, metrics)
+	timeline.now = f.service.now
+
+	followed := f.users.add(&models.User{Username: "seguido"})
+	stranger := f.users.add(&models.User{Username: "desconocido"})
+	reader := f.users.add(&models.User{Username: "lector", Following: []string{followed.ID.Hex()}})
+
+	for i := 0; i < 12; i++ {
+		assert.NoError(t, f.service.Create(ctx, &models.Tweet{UserID: followed.ID, Content: fmt.Sprintf("tweet %d", i)}))
+	}
+	assert.NoError(t, f.service.Create(ctx, &models.Tweet{UserID: stranger.ID, Content: "ajeno"}))
+	own := &models.Tweet{UserID: reader.ID, Content: "propio"}
+	assert.NoError(t, f.service.Create(ctx, own))
+
+	t.Run("own and followed tweets, newest first", func(t *testing.T) {
+		page, err := timeline.Timeline(ctx, reader.ID.Hex(), 1, 10)
+		assert.NoError(t, err)
+		assert.Len(t, page.Tweets, 10)
+		assert.Equal(t, own.ID, page.Tweets[0].ID)
+		for _, tw := range page.Tweets {
+			assert.NotEqual(t, stranger.ID, tw.UserID)
+		}
+
+		page, err = timeline.Timeline(ctx, reader.ID.Hex(), 2, 10)
+		assert.NoError(t, err)
+		assert.Len(t, page.Tweets, 3)
+		assert.Equal(t, []int{1, 2}, metrics.pages)
+	})
+
+	t.Run("pagination is normalized", func(t *testing.T) {
+		page, err := timeline.Timeline(ctx, reader.ID.Hex(), 0, 1000)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, page.Page)
+		assert.Equal(t, MaxPageSize, page.Limit)
+		assert.Len(t, page.Tweets, 13)
+	})
+
+	t.Run("non-existent user", func(t *testing.T) {
+		_, err := timeline.Timeline(ctx, primitive.NewObjectID().Hex(), 1, 10)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		_, err = timeline.Timeline(ctx, "invalid-id", 1, 10)
+		assert.ErrorIs(t, err, ErrInvalidID)
+	})
+}
+
+func TestTimelineService_TimelineAfter(t *testing.T) {
+	ctx := context.Background()
+	f := newTweetServiceFixture()
+	metrics := &recordingMetrics{}
+	timeline := NewTimelineService(f.tweets, f.users, f.polls, metrics)
+	timeline.now = f.service.now
+
+	followed := f.users.add(&models.User{Username: "seguido"})
+	reader := f.users.add(&models.User{Username: "lector", Following: []string{followed.ID.Hex()}})
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, f.service.Create(ctx, &models.Tweet{UserID: followed.ID, Content: fmt.Sprintf("tweet %d", i)}))
+	}
+
+	first, err := timeline.TimelineAfter(ctx, reader.ID.Hex(), nil, 3)
+	assert.NoError(t, err)
+	assert.Len(t, first.Tweets, 3)
+	if assert.NotNil(t, first.Next) {
+		assert.Equal(t, first.Tweets[2].Position(), *first.Next)
+	}
+
+	// Un tweet nuevo no desplaza la porción siguiente
+	assert.NoError(t, f.service.Create(ctx, &models.Tweet{UserID: followed.ID, Content: "nuevo"}))
+	rest, err := timeline.TimelineAfter(ctx, reader.ID.Hex(), first.Next, 3)
+	assert.NoError(t, err)
+	if assert.Len(t, rest.Tweets, 2) {
+		assert.Equal(t, "tweet 1", rest.Tweets[0].Content)
+	}
+	assert.Nil(t, rest.Next, "es la última porción")
+
+	// TimelineSince sigue desde el tweet más reciente que se leyó
+	since, err := timeline.TimelineSince(ctx, reader.ID.Hex(), first.Tweets[0].Position(), 3)
+	assert.NoError(t, err)
+	if assert.Len(t, since.Tweets, 1) && assert.NotNil(t, since.Next) {
+		assert.Equal(t, "nuevo", since.Tweets[0].Content)
+		since, err = timeline.TimelineSince(ctx, reader.ID.Hex(), *since.Next, 3)
+		assert.NoError(t, err)
+		assert.Empty(t, since.Tweets)
+		assert.Nil(t, since.Next, "no hay tweets nuevos")
+	}
+	assert.Equal(t, []int{1}, metrics.pages)
+
+	tweets, err := timeline.UserTweetsAfter(ctx, followed.ID.Hex(), nil, 2)
+	assert.NoError(t, err)
+	assert.Len(t, tweets.Tweets, 2)
+	assert.Equal(t, "nuevo", tweets.Tweets[0].Content)
+	assert.NotNil(t, tweets.Next)
+
+	_, err = timeline.TimelineAfter(ctx, primitive.NewObjectID().Hex(), nil, 3)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestTimelineService_MutedWords(t *testing.T) {
+	ctx := context.Background()
+	f := newTweetServiceFixture()
+	timeline := NewTimelineService(f.tweets, f.users, f.polls, nil)
+	timeline.now = f.service.now
+
+	followed := f.users.add(&models.User{Username: "seguido"})
+	reader := f.users.add(&models.User{
+		Username:   "lector",
+		Following:  []string{followed.ID.Hex()},
+		MutedWords: []string{"spoiler", "final*"},
+	})
+
+	for _, content := range []string{"buenos días", "SPOILER: muere", "la finalísima"} {
+		assert.NoError(t, f.service.Create(ctx, &models.Tweet{UserID: followed.ID, Content: content}))
+	}
+	assert.NoError(t, f.service.Create(ctx, &models.Tweet{UserID: reader.ID, Content: "sin spoiler, lo prometo"}))
+
+	page, err := timeline.Timeline(ctx, reader.ID.Hex(), 1, 10)
+	assert.NoError(t, err)
+	var contents []string
+	for _, tw := range page.Tweets {
+		contents = append(contents, tw.Content)
+	}
+	assert.Equal(t, []string{"sin spoiler, lo prometo", "buenos días"}, contents, "los tweets propios no se silencian")
+
+	// Las porciones reponen los tweets silenciados en lugar de volver cortas
+	slice, err := timeline.TimelineAfter(ctx, reader.ID.Hex(), nil, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, slice.Tweets, 2) {
+		assert.Equal(t, "buenos días", slice.Tweets[1].Content)
+	}
+	assert.Nil(t, slice.Next, "es la última porción")
+
+	slice, err = timeline.TimelineAfter(ctx, reader.ID.Hex(), nil, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, slice.Tweets, 1) && assert.NotNil(t, slice.Next) {
+		assert.Equal(t, slice.Tweets[0].Position(), *slice.Next)
+		slice, err = timeline.TimelineAfter(ctx, reader.ID.Hex(), slice.Next, 1)
+		assert.NoError(t, err)
+		if assert.Len(t, slice.Tweets, 1) {
+			assert.Equal(t, "buenos días", slice.Tweets[0].Content)
+		}
+		assert.Nil(t, slice.Next)
+	}
+
+	// Si se silencian todos los tweets nuevos, Next avanza igual
+	since, err := timeline.TimelineSince(ctx, reader.ID.Hex(), models.TweetPosition{}, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, since.Tweets, 1) && assert.NotNil(t, since.Next) {
+		since, err = timeline.TimelineSince(ctx, reader.ID.Hex(), *since.Next, 2)
+		assert.NoError(t, err)
+		assert.Empty(t, since.Tweets)
+		assert.NotNil(t, since.Next)
+	}
+}
+
+func TestTimelineService_PollState(t *testing.T) {
+	ctx := context.Background()
+	f := newTweetServiceFixture()
+	timeline := NewTimelineService(f.tweets, f.users, f.polls, nil)
+	timeline.now = f.service.now
+
+	tweet := f.pollTweet(t)
+	voter := f.users.add(&models.User{Username: "votante", Following: []string{f.author.ID.Hex()}})
+	_, err := f.service.Vote(ctx, tweet.ID.Hex(), voter.ID.Hex(), 0)
+	assert.NoError(t, err)
+
+	// El timeline del votante muestra los resultados; el del autor no
+	page, err := timeline.Timeline(ctx, voter.ID.Hex(), 1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, page.Tweets, 1) {
+		assert.True(t, page.Tweets[0].Poll.ResultsVisible)
+		assert.Equal(t, 1, *page.Tweets[0].Poll.Options[0].Votes)
+	}
+
+	page, err = timeline.Timeline(ctx, f.author.ID.Hex(), 1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, page.Tweets, 1) {
+		assert.False(t, page.Tweets[0].Poll.ResultsVisible)
+		assert.Nil(t, page.Tweets[0].Poll.Options[0].Votes)
+	}
+}
+
+func TestTimelineService_HashtagTweets(t *testing.T) {
+	ctx := context.Background()
+	f := newTweetServiceFixture()
+	timeline := NewTimelineService(f.tweets, f.users, f.polls, nil)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, f.service.Create(ctx, &models.Tweet{UserID: f.author.ID, Content: fmt.Sprintf("Tweet %d sobre #feeds", i)}))
+	}
+	assert.NoError(t, f.service.Create(ctx, &models.Tweet{UserID: f.author.ID, Content: "Sin hashtag"}))
+
+	tweets, err := timeline.HashtagTweets(ctx, "#Feeds", 10)
+	assert.NoError(t, err)
+	assert.Len(t, tweets, 3)
+
+	tweets, err = timeline.HashtagTweets(ctx, "feeds", 2)
+	assert.NoError(t, err)
+	assert.Len(t, tweets, 2)
+
+	_, err = timeline.HashtagTweets(ctx, "#", 10)
+	assert.ErrorIs(t, err, ErrInvalidHashtag)
+}
+
+func TestTimelineService_HidesInactiveAccounts(t *testing.T) {
+	ctx := context.Background()
+	f := newTweetServiceFixture()
+	timeline := NewTimelineService(f.tweets, f.users, f.polls, nil)
+	timeline.now = f.service.now
+
+	active := f.users.add(&models.User{Username: "activa"})
+	suspended := f.users.add(&models.User{Username: "suspendida"})
+	deactivated := f.users.add(&models.User{Username: "desactivada"})
+	reader := f.users.add(&models.User{Username: "lector", Following: []string{
+		active.ID.Hex(), suspended.ID.Hex(), deactivated.ID.Hex(),
+	}})
+	for _, u := range []*models.User{active, suspended, deactivated} {
+		assert.NoError(t, f.service.Create(ctx, &models.Tweet{UserID: u.ID, Content: "hola #cuentas"}))
+	}
+	assert.NoError(t, f.users.Suspend(ctx, suspended.ID, models.Suspension{Reason: "spam"}))
+	assert.NoError(t, f.users.Deactivate(ctx, deactivated.ID, f.service.now()))
+
+	page, err := timeline.Timeline(ctx, reader.ID.Hex(), 1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, page.Tweets, 1) {
+		assert.Equal(t, active.ID, page.Tweets[0].UserID)
+	}
+
+	tagged, err := timeline.HashtagTweets(ctx, "cuentas", 10)
+	assert.NoError(t, err)
+	assert.Len(t, tagged, 1)
+
+	tweets, err := timeline.UserTweets(ctx, deactivated.ID.Hex())
+	assert.NoError(t, err)
+	assert.Empty(t, tweets)
+	tweets, err = timeline.UserTweets(ctx, active.ID.Hex())
+	assert.NoError(t, err)
+	assert.Len(t, tweets, 1)
+
+	var ids []primitive.ObjectID
+	for _, tw := range f.tweets.tweets {
+		ids = append(ids, tw.ID)
+	}
+	byID, err := timeline.TweetsByID(ctx, ids)
+	assert.NoError(t, err)
+	assert.Len(t, byID, 1)
+	assert.Contains(t, byID, tweets[0].ID)
+	all, err := timeline.UserTweetsAfter(ctx, suspended.ID.Hex(), nil, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, all.Tweets)
+
+	// La lista de cuentas inactivas se reutiliza durante inactiveCacheTTL
+	assert.NoError(t, f.users.Suspend(ctx, active.ID, models.Suspension{Reason: "spam"}))
+	tagged, err = timeline.HashtagTweets(ctx, "cuentas", 10)
+	assert.NoError(t, err)
+	assert.Len(t, tagged, 1)
+	f.now = f.now.Add(inactiveCacheTTL)
+	tagged, err = timeline.HashtagTweets(ctx, "cuentas", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, tagged)
+}
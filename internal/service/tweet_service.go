@@ -0,0 +1,427 @@
+// internal/service/tweet_service.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxTweetLength es la longitud máxima del contenido de un tweet
+const MaxTweetLength = 280
+
+var (
+	ErrTweetNotFound     = repository.ErrTweetNotFound
+	ErrPollNotFound      = apperr.NotFound("poll_not_found", "encuesta no encontrada")
+	ErrPollClosed        = apperr.Conflict("poll_closed", "la encuesta ya está cerrada")
+	ErrInvalidPollOption = apperr.InvalidField("invalid_poll_option", "option", "opción de encuesta inválida")
+	ErrAlreadyVoted      = repository.ErrAlreadyVoted
+
+	ErrScheduledNotFound = repository.ErrScheduledNotFound
+	ErrScheduledLocked   = repository.ErrScheduledLocked
+)
+
+// Errores de validación de tweets, borradores y encuestas
+var (
+	ErrUserIDRequired  = apperr.InvalidField("user_id_required", "user_id", "el ID de usuario es requerido")
+	ErrAuthorNotFound  = apperr.InvalidField("author_not_found", "user_id", "el usuario especificado no existe")
+	ErrContentRequired = apperr.InvalidField("content_required", "content", "el contenido del tweet no puede estar vacío")
+	ErrContentTooLong  = apperr.InvalidField("content_too_long", "content",
+		fmt.Sprintf("el contenido del tweet no puede exceder los %d caracteres", MaxTweetLength)).
+		WithParam("max", MaxTweetLength)
+	ErrTooManyMedia = apperr.InvalidField("too_many_media", "media",
+		fmt.Sprintf("un tweet no puede tener más de %d archivos adjuntos", models.MaxTweetMedia)).
+		WithParam("max", models.MaxTweetMedia)
+	ErrDuplicateMedia = apperr.InvalidField("duplicate_media", "media", "archivos adjuntos duplicados")
+	ErrMediaNotOwned  = apperr.InvalidField("media_not_owned", "media", "los archivos adjuntos no existen o no pertenecen al autor")
+
+	ErrPollOptionCount = apperr.InvalidField("poll_option_count", "poll.options",
+		fmt.Sprintf("la encuesta debe tener entre %d y %d opciones", models.MinPollOptions, models.MaxPollOptions)).
+		WithParam("min", models.MinPollOptions).
+		WithParam("max", models.MaxPollOptions)
+	ErrPollOptionLength = apperr.InvalidField("poll_option_length", "poll.options",
+		fmt.Sprintf("las opciones de la encuesta deben tener entre 1 y %d caracteres", models.MaxPollOptionLength)).
+		WithParam("max", models.MaxPollOptionLength)
+	ErrPollDuplicateOptions = apperr.InvalidField("poll_duplicate_options", "poll.options", "opciones de encuesta duplicadas")
+	ErrPollDuration         = apperr.InvalidField("poll_duration", "poll.duration_minutes",
+		fmt.Sprintf("la duración de la encuesta debe estar entre %d minutos y %d días",
+			int(models.MinPollDuration.Minutes()), int(models.MaxPollDuration.Hours()/24))).
+		WithParam("min", int(models.MinPollDuration.Minutes())).
+		WithParam("max", int(models.MaxPollDuration.Hours()/24))
+	ErrPublishAtPast = apperr.InvalidField("publish_at_past", "publish_at", "la fecha de publicación debe ser futura")
+)
+
+// TweetService concentra las reglas para publicar tweets, votar encuestas y
+// gestionar borradores y tweets programados
+type TweetService struct {
+	tweets        TweetStore
+	users         UserReader
+	media         MediaStore
+	polls         PollStore
+	scheduled     ScheduleStore
+	contentPolicy ContentPolicy
+	metrics       Metrics
+	publishers    []TweetPublisher
+	now           func() time.Time
+}
+
+// NewTweetService crea el servicio; contentPolicy puede ser nil para publicar sin
+// evaluar la política de contenido
+func NewTweetService(tweets TweetStore, users UserReader, media MediaStore, polls PollStore, scheduled ScheduleStore, contentPolicy ContentPolicy, metrics Metrics, publishers ...TweetPublisher) *TweetService {
+	return &TweetService{
+		tweets:        tweets,
+		users:         users,
+		media:         media,
+		polls:         polls,
+		scheduled:     scheduled,
+		contentPolicy: contentPolicy,
+		metrics:       metricsOrNoop(metrics),
+		publishers:    publishers,
+		now:           time.Now,
+	}
+}
+
+// Create valida y publica un tweet. Es el único camino para crear tweets: lo usan
+// la API y el scheduler de tweets programados. Un tweet que la política de
+// contenido retiene se guarda sin publicarse (tweet.Held()) hasta que se aprueba.
+func (s *TweetService) Create(ctx context.Context, tweet *models.Tweet) error {
+	if tweet.UserID.IsZero() {
+		return ErrUserIDRequired
+	}
+	if err := validateContent(tweet.Content); err != nil {
+		return err
+	}
+
+	// Validar que el usuario existe y puede publicar
+	author, err := getUser(ctx, s.users, tweet.UserID.Hex(), ErrAuthorNotFound)
+	if err != nil {
+		return err
+	}
+	if err := checkCanPost(author); err != nil {
+		return err
+	}
+
+	// Validar que los adjuntos existen y pertenecen al autor
+	if err := s.validateMedia(ctx, tweet); err != nil {
+		return err
+	}
+
+	now := s.now()
+	if tweet.Poll != nil {
+		if err := preparePoll(tweet.Poll, now); err != nil {
+			return err
+		}
+	}
+
+	if s.contentPolicy != nil {
+		if err := s.contentPolicy.Apply(ctx, tweet, author); err != nil {
+			return err
+		}
+	}
+
+	tweet.Hashtags = ExtractHashtags(tweet.Content)
+	if err := s.tweets.Create(ctx, tweet); err != nil {
+		return err
+	}
+	if tweet.Poll != nil {
+		tweet.Poll.ApplyViewer(-1, now)
+	}
+	// El tweet retenido se cuenta y se distribuye cuando se aprueba
+	if tweet.Held() {
+		return nil
+	}
+	s.metrics.TweetCreated(tweet)
+
+	for _, p := range s.publishers {
+		p.PublishTweet(ctx, tweet)
+	}
+	return nil
+}
+
+// Vote registra el voto de un usuario y devuelve el tweet con el estado de la
+// encuesta para ese usuario. El voto no se puede cambiar una vez emitido.
+func (s *TweetService) Vote(ctx context.Context, tweetID, userID string, option int) (*models.Tweet, error) {
+	tweet, err := s.pollTweet(ctx, tweetID)
+	if err != nil {
+		return nil, err
+	}
+	if option < 0 || option >= len(tweet.Poll.Options) {
+		return nil, ErrInvalidPollOption
+	}
+	now := s.now()
+	if !tweet.Poll.IsOpen(now) {
+		return nil, ErrPollClosed
+	}
+
+	user, err := getUser(ctx, s.users, userID, ErrAuthorNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	vote := &models.PollVote{
+		TweetID:   tweet.ID,
+		UserID:    user.ID,
+		Option:    option,
+		CreatedAt: now,
+	}
+	if err := s.polls.InsertVote(ctx, vote); err != nil {
+		return nil, err
+	}
+
+	// Los conteos solo se incrementan si la encuesta sigue abierta al contabilizar
+	counted, err := s.polls.CountVote(ctx, tweet.ID, option, now)
+	if err != nil || !counted {
+		if delErr := s.polls.DeleteVote(ctx, vote.ID); delErr != nil {
+			return nil, delErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrPollClosed
+	}
+
+	tweet, err = s.pollTweet(ctx, tweetID)
+	if err != nil {
+		return nil, err
+	}
+	tweet.Poll.ApplyViewer(option, s.now())
+	return tweet, nil
+}
+
+// CreateScheduled guarda un borrador, o un tweet programado si trae publish_at
+func (s *TweetService) CreateScheduled(ctx context.Context, st *models.ScheduledTweet) error {
+	if st.UserID.IsZero() {
+		return ErrUserIDRequired
+	}
+	if err := validateScheduled(st, s.now()); err != nil {
+		return err
+	}
+	author, err := getUser(ctx, s.users, st.UserID.Hex(), ErrAuthorNotFound)
+	if err != nil {
+		return err
+	}
+	if err := checkCanPost(author); err != nil {
+		return err
+	}
+
+	st.Status = scheduledStatus(st)
+	st.PublishedAt = nil
+	st.TweetID = primitive.NilObjectID
+	st.Attempts = 0
+	st.LastError = ""
+	st.LeaseOwner = ""
+	st.LeaseUntil = nil
+	return s.scheduled.Create(ctx, st)
+}
+
+// GetScheduled obtiene un borrador o tweet programado del usuario
+func (s *TweetService) GetScheduled(ctx context.Context, userID, id string) (*models.ScheduledTweet, error) {
+	return s.scheduled.GetByID(ctx, userID, id)
+}
+
+// ListScheduled lista los borradores y tweets programados del usuario; sin estados
+// devuelve los pendientes
+func (s *TweetService) ListScheduled(ctx context.Context, userID string, statuses []string) ([]models.ScheduledTweet, error) {
+	return s.scheduled.ListByUser(ctx, userID, statuses)
+}
+
+// UpdateScheduled edita un borrador o reprograma un tweet. Fijar publish_at programa
+// también un borrador.
+func (s *TweetService) UpdateScheduled(ctx context.Context, userID, id string, update models.ScheduledTweetUpdate) (*models.ScheduledTweet, error) {
+	st, err := s.scheduled.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Content != nil {
+		st.Content = *update.Content
+	}
+	if update.Media != nil {
+		st.Media = *update.Media
+	}
+	if update.Poll != nil {
+		st.Poll = update.Poll
+	}
+	if update.PublishAt != nil {
+		st.PublishAt = update.PublishAt
+	}
+
+	// Validar el resultado completo de aplicar los cambios
+	if err := validateScheduled(st, s.now()); err != nil {
+		return nil, err
+	}
+	st.Status = scheduledStatus(st)
+
+	if err := s.scheduled.Update(ctx, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// CancelScheduled cancela un borrador o tweet programado que todavía no se publicó
+func (s *TweetService) CancelScheduled(ctx context.Context, userID, id string) error {
+	return s.scheduled.Cancel(ctx, userID, id)
+}
+
+func (s *TweetService) pollTweet(ctx context.Context, tweetID string) (*models.Tweet, error) {
+	if _, err := primitive.ObjectIDFromHex(tweetID); err != nil {
+		return nil, ErrPollNotFound
+	}
+
+	tweet, err := s.tweets.GetByID(ctx, tweetID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTweetNotFound) {
+			return nil, ErrPollNotFound
+		}
+		return nil, err
+	}
+	if tweet.Poll == nil {
+		return nil, ErrPollNotFound
+	}
+	return tweet, nil
+}
+
+func (s *TweetService) validateMedia(ctx context.Context, tweet *models.Tweet) error {
+	if len(tweet.Media) == 0 {
+		return nil
+	}
+	if err := validateMediaCount(tweet.Media); err != nil {
+		return err
+	}
+
+	seen := make(map[primitive.ObjectID]bool, len(tweet.Media))
+	for _, id := range tweet.Media {
+		if seen[id] {
+			return ErrDuplicateMedia
+		}
+		seen[id] = true
+	}
+
+	count, err := s.media.CountOwned(ctx, tweet.Media, tweet.UserID)
+	if err != nil {
+		return err
+	}
+	if count != int64(len(tweet.Media)) {
+		return ErrMediaNotOwned
+	}
+	return nil
+}
+
+func validateContent(content string) error {
+	if content == "" {
+		return ErrContentRequired
+	}
+	if len(content) > MaxTweetLength {
+		return ErrContentTooLong
+	}
+	return nil
+}
+
+func validateMediaCount(media []primitive.ObjectID) error {
+	if len(media) > models.MaxTweetMedia {
+		return ErrTooManyMedia
+	}
+	return nil
+}
+
+// preparePoll valida la encuesta de un tweet nuevo e inicializa su estado
+func preparePoll(poll *models.Poll, now time.Time) error {
+	if err := validatePollOptions(poll.Options); err != nil {
+		return err
+	}
+	for i := range poll.Options {
+		zero := 0
+		poll.Options[i] = models.PollOption{Text: strings.TrimSpace(poll.Options[i].Text), Votes: &zero}
+	}
+
+	duration := time.Duration(poll.DurationMinutes) * time.Minute
+	if duration < models.MinPollDuration || duration > models.MaxPollDuration {
+		return ErrPollDuration
+	}
+
+	total := 0
+	poll.TotalVotes = &total
+	poll.ExpiresAt = now.Add(duration)
+	poll.Closed = false
+	return nil
+}
+
+func validatePollOptions(options []models.PollOption) error {
+	if len(options) < models.MinPollOptions || len(options) > models.MaxPollOptions {
+		return ErrPollOptionCount
+	}
+
+	seen := make(map[string]bool, len(options))
+	for _, option := range options {
+		text := strings.TrimSpace(option.Text)
+		if text == "" || utf8.RuneCountInString(text) > models.MaxPollOptionLength {
+			return ErrPollOptionLength
+		}
+		key := strings.ToLower(text)
+		if seen[key] {
+			return ErrPollDuplicateOptions
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// ValidateImportedTweet aplica a un tweet ya publicado en otra base de datos las
+// validaciones de Create que no dependen de otros datos y recalcula sus
+// hashtags. La encuesta conserva su estado: solo se validan las opciones.
+func ValidateImportedTweet(tweet *models.Tweet) error {
+	if tweet.UserID.IsZero() {
+		return ErrUserIDRequired
+	}
+	if err := validateContent(tweet.Content); err != nil {
+		return err
+	}
+	if err := validateMediaCount(tweet.Media); err != nil {
+		return err
+	}
+	if tweet.Poll != nil {
+		if err := validatePollOptions(tweet.Poll.Options); err != nil {
+			return err
+		}
+		if tweet.Poll.ExpiresAt.IsZero() {
+			return ErrPollDuration
+		}
+	}
+	tweet.Hashtags = ExtractHashtags(tweet.Content)
+	return nil
+}
+
+// validateScheduled aplica por adelantado las validaciones de Create que no
+// dependen de otros datos; al publicar se vuelven a aplicar todas
+func validateScheduled(st *models.ScheduledTweet, now time.Time) error {
+	if err := validateContent(st.Content); err != nil {
+		return err
+	}
+	if err := validateMediaCount(st.Media); err != nil {
+		return err
+	}
+	if st.Poll != nil {
+		if err := preparePoll(st.Poll.ToPoll(), now); err != nil {
+			return err
+		}
+	}
+	if st.PublishAt != nil && !st.PublishAt.After(now) {
+		return ErrPublishAtPast
+	}
+	return nil
+}
+
+func scheduledStatus(st *models.ScheduledTweet) string {
+	if st.PublishAt != nil {
+		return models.ScheduledStatusScheduled
+	}
+	return models.ScheduledStatusDraft
+}
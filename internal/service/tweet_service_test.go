@@ -0,0 +1,276 @@
+// internal/service/tweet_service_test.go
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type tweetServiceFixture struct {
+	service   *TweetService
+	users     *fakeUsers
+	tweets    *fakeTweets
+	media     *fakeMedia
+	polls     *fakePolls
+	scheduled *fakeScheduled
+	publisher *recordingPublisher
+	metrics   *recordingMetrics
+	author    *models.User
+	now       time.Time
+}
+
+func newTweetServiceFixture() *tweetServiceFixture {
+	f := &tweetServiceFixture{
+		author:    &models.User{Username: "autor"},
+		tweets:    &fakeTweets{},
+		media:     &fakeMedia{owners: make(map[primitive.ObjectID]primitive.ObjectID)},
+		scheduled: newFakeScheduled(),
+		publisher: &recordingPublisher{},
+		metrics:   &recordingMetrics{},
+		now:       time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	f.users = newFakeUsers(f.author)
+	f.polls = newFakePolls(f.tweets)
+	f.service = NewTweetService(f.tweets, f.users, f.media, f.polls, f.scheduled, nil, f.metrics, f.publisher)
+	f.service.now = func() time.Time { return f.now }
+	return f
+}
+
+func (f *tweetServiceFixture) pollTweet(t *testing.T) *models.Tweet {
+	tweet := &models.Tweet{
+		UserID:  f.author.ID,
+		Content: "¿Go o Rust?",
+		Poll: &models.Poll{
+			Options:         []models.PollOption{{Text: "Go"}, {Text: "Rust"}},
+			DurationMinutes: 60,
+		},
+	}
+	assert.NoError(t, f.service.Create(context.Background(), tweet))
+	return tweet
+}
+
+func TestTweetService_Create(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successful creation", func(t *testing.T) {
+		f := newTweetServiceFixture()
+		tweet := &models.Tweet{UserID: f.author.ID, Content: "Aprendiendo #Go y #MongoDB, #go otra vez"}
+
+		assert.NoError(t, f.service.Create(ctx, tweet))
+		assert.NotEmpty(t, tweet.ID)
+		assert.Equal(t, []string{"go", "mongodb"}, tweet.Hashtags)
+		assert.Equal(t, []primitive.ObjectID{tweet.ID}, f.publisher.published)
+		assert.Equal(t, 1, f.metrics.tweets)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		f := newTweetServiceFixture()
+		tests := []struct {
+			name  string
+			tweet *models.Tweet
+			want  string
+		}{
+			{"empty content", &models.Tweet{UserID: f.author.ID}, "no puede estar vacío"},
+			{"too long", &models.Tweet{UserID: f.author.ID, Content: strings.Repeat("a", 281)}, "no puede exceder los 280 caracteres"},
+			{"zero user ID", &models.Tweet{Content: "hola"}, "ID de usuario es requerido"},
+			{"non-existent user", &models.Tweet{UserID: primitive.NewObjectID(), Content: "hola"}, "usuario especificado no existe"},
+			{"invalid poll", &models.Tweet{UserID: f.author.ID, Content: "encuesta", Poll: &models.Poll{
+				Options: []models.PollOption{{Text: "sí"}}, DurationMinutes: 60,
+			}}, "entre 2 y 4 opciones"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := f.service.Create(ctx, tt.tweet)
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.want)
+			})
+		}
+		assert.Empty(t, f.tweets.tweets)
+		assert.Empty(t, f.publisher.published)
+	})
+
+	t.Run("attachments must belong to author", func(t *testing.T) {
+		f := newTweetServiceFixture()
+		mine, foreign := primitive.NewObjectID(), primitive.NewObjectID()
+		f.media.owners[mine] = f.author.ID
+		f.media.owners[foreign] = primitive.NewObjectID()
+
+		err := f.service.Create(ctx, &models.Tweet{UserID: f.author.ID, Content: "foto", Media: []primitive.ObjectID{mine}})
+		assert.NoError(t, err)
+
+		err = f.service.Create(ctx, &models.Tweet{UserID: f.author.ID, Content: "ajena", Media: []primitive.ObjectID{foreign}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no pertenecen al autor")
+
+		err = f.service.Create(ctx, &models.Tweet{UserID: f.author.ID, Content: "repetida", Media: []primitive.ObjectID{mine, mine}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicados")
+	})
+
+	t.Run("suspended author cannot post", func(t *testing.T) {
+		f := newTweetServiceFixture()
+		f.author.Suspension = &models.Suspension{Reason: "spam", Since: f.now}
+
+		err := f.service.Create(ctx, &models.Tweet{UserID: f.author.ID, Content: "hola"})
+		assert.ErrorIs(t, err, ErrUserSuspended)
+
+		err = f.service.CreateScheduled(ctx, &models.ScheduledTweet{UserID: f.author.ID, Content: "luego"})
+		assert.ErrorIs(t, err, ErrUserSuspended)
+		assert.Empty(t, f.tweets.tweets)
+	})
+
+	t.Run("poll is initialized", func(t *testing.T) {
+		f := newTweetServiceFixture()
+		tweet := f.pollTweet(t)
+		assert.Equal(t, f.now.Add(time.Hour), tweet.Poll.ExpiresAt)
+		assert.False(t, tweet.Poll.ResultsVisible)
+		assert.Nil(t, tweet.Poll.TotalVotes)
+	})
+}
+
+func TestValidateImportedTweet(t *testing.T) {
+	userID := primitive.NewObjectID()
+	expires := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("keeps the poll state", func(t *testing.T) {
+		votes := 3
+		tweet := &models.Tweet{UserID: userID, Content: "Importado #Go", Poll: &models.Poll{
+			Options:    []models.PollOption{{Text: "sí", Votes: &votes}, {Text: "no"}},
+			ExpiresAt:  expires,
+			Closed:     true,
+			TotalVotes: &votes,
+		}}
+		assert.NoError(t, ValidateImportedTweet(tweet))
+		assert.Equal(t, []string{"go"}, tweet.Hashtags)
+		assert.Equal(t, expires, tweet.Poll.ExpiresAt)
+		assert.Equal(t, 3, *tweet.Poll.Options[0].Votes)
+	})
+
+	tests := []struct {
+		name  string
+		tweet *models.Tweet
+		want  error
+	}{
+		{"zero user ID", &models.Tweet{Content: "hola"}, ErrUserIDRequired},
+		{"empty content", &models.Tweet{UserID: userID}, ErrContentRequired},
+		{"too long", &models.Tweet{UserID: userID, Content: strings.Repeat("a", 281)}, ErrContentTooLong},
+		{"duplicate options", &models.Tweet{UserID: userID, Content: "encuesta", Poll: &models.Poll{
+			Options: []models.PollOption{{Text: "Sí"}, {Text: "sí "}}, ExpiresAt: expires,
+		}}, ErrPollDuplicateOptions},
+		{"poll without expiry", &models.Tweet{UserID: userID, Content: "encuesta", Poll: &models.Poll{
+			Options: []models.PollOption{{Text: "sí"}, {Text: "no"}},
+		}}, ErrPollDuration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, ValidateImportedTweet(tt.tweet), tt.want)
+		})
+	}
+}
+
+func TestTweetService_Vote(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("vote shows results to voter", func(t *testing.T) {
+		f := newTweetServiceFixture()
+		tweet := f.pollTweet(t)
+		voter := f.users.add(&models.User{Username: "votante"})
+
+		voted, err := f.service.Vote(ctx, tweet.ID.Hex(), voter.ID.Hex(), 1)
+		assert.NoError(t, err)
+		assert.True(t, voted.Poll.Voted)
+		assert.True(t, voted.Poll.ResultsVisible)
+		assert.Equal(t, 1, *voted.Poll.TotalVotes)
+		assert.Equal(t, 1, *voted.Poll.Options[1].Votes)
+
+		_, err = f.service.Vote(ctx, tweet.ID.Hex(), voter.ID.Hex(), 0)
+		assert.ErrorIs(t, err, ErrAlreadyVoted)
+	})
+
+	t.Run("invalid votes", func(t *testing.T) {
+		f := newTweetServiceFixture()
+		tweet := f.pollTweet(t)
+		voter := f.users.add(&models.User{Username: "votante"})
+
+		_, err := f.service.Vote(ctx, tweet.ID.Hex(), voter.ID.Hex(), 7)
+		assert.ErrorIs(t, err, ErrInvalidPollOption)
+
+		plain := &models.Tweet{UserID: f.author.ID, Content: "sin encuesta"}
+		assert.NoError(t, f.service.Create(ctx, plain))
+		_, err = f.service.Vote(ctx, plain.ID.Hex(), voter.ID.Hex(), 0)
+		assert.ErrorIs(t, err, ErrPollNotFound)
+
+		_, err = f.service.Vote(ctx, "invalid-id", voter.ID.Hex(), 0)
+		assert.ErrorIs(t, err, ErrPollNotFound)
+	})
+
+	t.Run("closed poll", func(t *testing.T) {
+		f := newTweetServiceFixture()
+		tweet := f.pollTweet(t)
+		voter := f.users.add(&models.User{Username: "votante"})
+
+		f.now = f.now.Add(2 * time.Hour)
+		_, err := f.service.Vote(ctx, tweet.ID.Hex(), voter.ID.Hex(), 0)
+		assert.ErrorIs(t, err, ErrPollClosed)
+		assert.Empty(t, f.polls.votes)
+	})
+}
+
+func TestTweetService_Scheduled(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("draft and schedule", func(t *testing.T) {
+		f := newTweetServiceFixture()
+		draft := &models.ScheduledTweet{UserID: f.author.ID, Content: "borrador"}
+		assert.NoError(t, f.service.CreateScheduled(ctx, draft))
+		assert.Equal(t, models.ScheduledStatusDraft, draft.Status)
+
+		// Fijar publish_at programa el borrador
+		publishAt := f.now.Add(time.Hour)
+		updated, err := f.service.UpdateScheduled(ctx, f.author.ID.Hex(), draft.ID.Hex(), models.ScheduledTweetUpdate{PublishAt: &publishAt})
+		assert.NoError(t, err)
+		assert.Equal(t, models.ScheduledStatusScheduled, updated.Status)
+		assert.Equal(t, "borrador", updated.Content)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		f := newTweetServiceFixture()
+		past := f.now.Add(-time.Hour)
+		err := f.service.CreateScheduled(ctx, &models.ScheduledTweet{UserID: f.author.ID, Content: "tarde", PublishAt: &past})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "debe ser futura")
+
+		future := f.now.Add(time.Hour)
+		err = f.service.CreateScheduled(ctx, &models.ScheduledTweet{
+			UserID:    f.author.ID,
+			Content:   "encuesta",
+			PublishAt: &future,
+			Poll:      &models.PollDraft{Options: []string{"solo una"}, DurationMinutes: 60},
+		})
+		assert.Error(t, err)
+
+		err = f.service.CreateScheduled(ctx, &models.ScheduledTweet{UserID: primitive.NewObjectID(), Content: "x", PublishAt: &future})
+		assert.ErrorIs(t, err, ErrAuthorNotFound)
+		assert.Empty(t, f.scheduled.items)
+	})
+
+	t.Run("update validates result", func(t *testing.T) {
+		f := newTweetServiceFixture()
+		draft := &models.ScheduledTweet{UserID: f.author.ID, Content: "borrador"}
+		assert.NoError(t, f.service.CreateScheduled(ctx, draft))
+
+		empty := ""
+		_, err := f.service.UpdateScheduled(ctx, f.author.ID.Hex(), draft.ID.Hex(), models.ScheduledTweetUpdate{Content: &empty})
+		assert.Error(t, err)
+		assert.Equal(t, "borrador", f.scheduled.items[draft.ID].Content)
+
+		_, err = f.service.UpdateScheduled(ctx, primitive.NewObjectID().Hex(), draft.ID.Hex(), models.ScheduledTweetUpdate{})
+		assert.ErrorIs(t, err, ErrScheduledNotFound)
+	})
+}
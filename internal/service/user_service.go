@@ -0,0 +1,155 @@
+// internal/service/user_service.go
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/rbac"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrUserNotFound    = repository.ErrUserNotFound
+	ErrInvalidID       = repository.ErrInvalidID
+	ErrTargetNotFound  = apperr.NotFound("target_user_not_found", "usuario objetivo no encontrado")
+	ErrSelfFollow      = apperr.Validation("self_follow", "no puedes seguirte a ti mismo")
+	ErrInvalidIdentity = apperr.Unauthorized("invalid_identity", "el usuario que hace la petición no existe")
+)
+
+// UserService concentra las reglas sobre usuarios y relaciones de seguimiento
+type UserService struct {
+	users         UserStore
+	notifications NotificationStore
+	metrics       Metrics
+}
+
+func NewUserService(users UserStore, notifications NotificationStore, metrics Metrics) *UserService {
+	return &UserService{
+		users:         users,
+		notifications: notifications,
+		metrics:       metricsOrNoop(metrics),
+	}
+}
+
+// Create registra un usuario nuevo. Los roles y permisos solo se asignan desde
+// la administración, nunca al registrarse.
+func (s *UserService) Create(ctx context.Context, user *models.User) error {
+	user.Roles, user.Permissions = nil, nil
+	return s.users.Create(ctx, user)
+}
+
+// Principal identifica a quien hace la petición con sus roles y permisos. Una
+// cuenta suspendida no puede actuar aunque tenga roles, y una pendiente de borrado
+// ya no existe para la API.
+func (s *UserService) Principal(ctx context.Context, id string) (*rbac.Principal, error) {
+	user, err := s.users.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrInvalidID) {
+			return nil, ErrInvalidIdentity
+		}
+		return nil, err
+	}
+	// Una cuenta desactivada sí se identifica: puede reactivarse
+	if user.IsPendingDeletion() {
+		return nil, ErrInvalidIdentity
+	}
+	if user.IsSuspended() {
+		return nil, ErrUserSuspended
+	}
+	return &rbac.Principal{UserID: user.ID.Hex(), Roles: user.Roles, Permissions: user.Permissions}, nil
+}
+
+// Get obtiene un usuario por ID; devuelve ErrUserNotFound si no existe
+func (s *UserService) Get(ctx context.Context, id string) (*models.User, error) {
+	return getUser(ctx, s.users, id, ErrUserNotFound)
+}
+
+// GetMany obtiene los usuarios con los IDs indicados en una sola consulta,
+// indexados por ID; los que no existen no aparecen
+func (s *UserService) GetMany(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*models.User, error) {
+	users, err := s.users.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]*models.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+	return byID, nil
+}
+
+// GetByUsername obtiene un usuario por su nombre; devuelve ErrUserNotFound si no existe
+func (s *UserService) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return s.users.GetByUsername(ctx, username)
+}
+
+// Follow hace que userID siga a targetID. Seguir dos veces al mismo usuario no es un error.
+func (s *UserService) Follow(ctx context.Context, userID, targetID string) error {
+	if userID == targetID {
+		return ErrSelfFollow
+	}
+	user, err := getUser(ctx, s.users, userID, ErrUserNotFound)
+	if err != nil {
+		return err
+	}
+	if user.IsPendingDeletion() {
+		return ErrAccountPendingDeletion
+	}
+	// Una cuenta que se está borrando ya no se puede seguir: el borrado quita sus
+	// seguidores una sola vez
+	target, err := getUser(ctx, s.users, targetID, ErrTargetNotFound)
+	if err != nil {
+		return err
+	}
+	if target.IsPendingDeletion() {
+		return ErrTargetNotFound
+	}
+
+	if err := s.users.FollowUser(ctx, userID, targetID); err != nil {
+		return err
+	}
+	s.metrics.FollowChanged(true)
+	return nil
+}
+
+// Unfollow hace que userID deje de seguir a targetID; si no lo seguía no hace nada
+func (s *UserService) Unfollow(ctx context.Context, userID, targetID string) error {
+	if err := s.users.UnfollowUser(ctx, userID, targetID); err != nil {
+		return err
+	}
+	s.metrics.FollowChanged(false)
+	return nil
+}
+
+// Following devuelve los usuarios que sigue userID
+func (s *UserService) Following(ctx context.Context, userID string) ([]models.User, error) {
+	return s.users.GetFollowing(ctx, userID)
+}
+
+// Followers devuelve los seguidores de userID
+func (s *UserService) Followers(ctx context.Context, userID string) ([]models.User, error) {
+	return s.users.GetFollowers(ctx, userID)
+}
+
+// Notifications devuelve las notificaciones más recientes de un usuario
+func (s *UserService) Notifications(ctx context.Context, userID string, limit int) ([]models.Notification, error) {
+	_, limit = Paginate(1, limit)
+	return s.notifications.GetByUserID(ctx, userID, limit)
+}
+
+// getUser obtiene un usuario y traduce ErrUserNotFound a notFound; el resto de
+// errores (ID inválido, base de datos caída) se devuelven tal cual
+func getUser(ctx context.Context, users UserReader, id string, notFound error) (*models.User, error) {
+	user, err := users.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, notFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
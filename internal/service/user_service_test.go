@@ -0,0 +1,97 @@
+// internal/service/user_service_test.go
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUserService_Follow(t *testing.T) {
+	ctx := context.Background()
+	alice := &models.User{Username: "alice"}
+	bob := &models.User{Username: "bob"}
+	users := newFakeUsers(alice, bob)
+	metrics := &recordingMetrics{}
+	s := NewUserService(users, &fakeNotifications{}, metrics)
+
+	t.Run("successful follow", func(t *testing.T) {
+		assert.NoError(t, s.Follow(ctx, alice.ID.Hex(), bob.ID.Hex()))
+		// Seguir dos veces no es un error ni duplica el contador
+		assert.NoError(t, s.Follow(ctx, alice.ID.Hex(), bob.ID.Hex()))
+		assert.Equal(t, 1, users.users[bob.ID.Hex()].FollowersCount)
+	})
+
+	t.Run("cannot follow self", func(t *testing.T) {
+		err := s.Follow(ctx, alice.ID.Hex(), alice.ID.Hex())
+		assert.ErrorIs(t, err, ErrSelfFollow)
+		// Solo se cuentan los follows completados
+		assert.Equal(t, 2, metrics.follows)
+	})
+
+	t.Run("follow non-existent user", func(t *testing.T) {
+		err := s.Follow(ctx, alice.ID.Hex(), primitive.NewObjectID().Hex())
+		assert.ErrorIs(t, err, ErrTargetNotFound)
+
+		err = s.Follow(ctx, "invalid-id", bob.ID.Hex())
+		assert.ErrorIs(t, err, ErrInvalidID)
+	})
+}
+
+func TestUserService_Notifications(t *testing.T) {
+	notifications := &fakeNotifications{}
+	s := NewUserService(newFakeUsers(), notifications, nil)
+
+	_, err := s.Notifications(context.Background(), primitive.NewObjectID().Hex(), 500)
+	assert.NoError(t, err)
+	assert.Equal(t, MaxPageSize, notifications.lastLimit)
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		page, limit         int
+		wantPage, wantLimit int
+	}{
+		{1, 10, 1, 10},
+		{0, 0, 1, DefaultPageSize},
+		{-3, -1, 1, DefaultPageSize},
+		{2, 1000, 2, MaxPageSize},
+	}
+	for _, tt := range tests {
+		page, limit := Paginate(tt.page, tt.limit)
+		assert.Equal(t, tt.wantPage, page)
+		assert.Equal(t, tt.wantLimit, limit)
+	}
+}
+
+func TestUserService_Principal(t *testing.T) {
+	ctx := context.Background()
+	mod := &models.User{Username: "mod", Roles: []string{"moderator"}}
+	banned := &models.User{Username: "banned", Suspension: &models.Suspension{Reason: "spam"}}
+	s := NewUserService(newFakeUsers(mod, banned), &fakeNotifications{}, nil)
+
+	p, err := s.Principal(ctx, mod.ID.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, mod.ID.Hex(), p.UserID)
+	assert.Equal(t, []string{"moderator"}, p.Roles)
+
+	_, err = s.Principal(ctx, banned.ID.Hex())
+	assert.ErrorIs(t, err, ErrUserSuspended)
+	_, err = s.Principal(ctx, primitive.NewObjectID().Hex())
+	assert.ErrorIs(t, err, ErrInvalidIdentity)
+	_, err = s.Principal(ctx, "no-es-un-id")
+	assert.ErrorIs(t, err, ErrInvalidIdentity)
+}
+
+func TestUserService_CreateIgnoresRoles(t *testing.T) {
+	users := newFakeUsers()
+	s := NewUserService(users, &fakeNotifications{}, nil)
+
+	user := &models.User{Username: "eve", Roles: []string{"admin"}, Permissions: []string{"*"}}
+	assert.NoError(t, s.Create(context.Background(), user))
+	assert.Empty(t, users.users[user.ID.Hex()].Roles)
+	assert.Empty(t, users.users[user.ID.Hex()].Permissions)
+}
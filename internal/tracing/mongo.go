@@ -0,0 +1,63 @@
+// internal/tracing/mongo.go
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanKey identifica un comando en curso: el driver numera los comandos por conexión
+type spanKey struct {
+	connectionID string
+	requestID    int64
+}
+
+// CommandMonitor crea un span hijo del span de la petición por cada comando
+// enviado a MongoDB. El cuerpo del comando no se registra porque contiene datos
+// de los usuarios; sí la operación y la colección.
+func CommandMonitor() *event.CommandMonitor {
+	var spans sync.Map
+
+	end := func(e event.CommandFinishedEvent, failure string) {
+		v, ok := spans.LoadAndDelete(spanKey{e.ConnectionID, e.RequestID})
+		if !ok {
+			return
+		}
+		span := v.(trace.Span)
+		if failure != "" {
+			span.SetStatus(codes.Error, failure)
+		}
+		span.End()
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			attrs := []attribute.KeyValue{
+				semconv.DBSystemMongoDB,
+				semconv.DBNamespace(e.DatabaseName),
+				semconv.DBOperationName(e.CommandName),
+			}
+			if collection, ok := e.Command.Index(0).Value().StringValueOK(); ok {
+				attrs = append(attrs, semconv.DBCollectionName(collection))
+			}
+
+			_, span := Tracer().Start(ctx, "mongodb."+e.CommandName,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...),
+			)
+			spans.Store(spanKey{e.ConnectionID, e.RequestID}, span)
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			end(e.CommandFinishedEvent, "")
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			end(e.CommandFinishedEvent, e.Failure)
+		},
+	}
+}
@@ -0,0 +1,133 @@
+// internal/tracing/tracing_test.go
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// useRecorder instala un TracerProvider que guarda los spans en memoria
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestCommandMonitor(t *testing.T) {
+	recorder := useRecorder(t)
+	monitor := CommandMonitor()
+
+	ctx, parent := Tracer().Start(context.Background(), "GET /api/v1/users/:id/timeline")
+	command, err := bson.Marshal(bson.D{{Key: "find", Value: "tweets"}, {Key: "filter", Value: bson.D{{Key: "email", Value: "ana@example.com"}}}})
+	require.NoError(t, err)
+
+	started := &event.CommandStartedEvent{Command: command, DatabaseName: "microblog", CommandName: "find", RequestID: 1, ConnectionID: "c1"}
+	monitor.Started(ctx, started)
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1, ConnectionID: "c1"}})
+
+	failed := &event.CommandStartedEvent{Command: command, DatabaseName: "microblog", CommandName: "insert", RequestID: 2, ConnectionID: "c1"}
+	monitor.Started(ctx, failed)
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "insert", RequestID: 2, ConnectionID: "c1"}, Failure: "E11000"})
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	find := spans[0]
+	assert.Equal(t, "mongodb.find", find.Name())
+	assert.Equal(t, parent.SpanContext().TraceID(), find.SpanContext().TraceID())
+	assert.Equal(t, parent.SpanContext().SpanID(), find.Parent().SpanID())
+	attrs := map[string]string{}
+	for _, kv := range find.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	assert.Equal(t, "mongodb", attrs["db.system"])
+	assert.Equal(t, "tweets", attrs["db.collection.name"])
+	assert.Equal(t, "find", attrs["db.operation.name"])
+	for _, v := range attrs {
+		assert.NotContains(t, v, "ana@example.com")
+	}
+
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
+
+func TestTransport_PropagatesTraceparent(t *testing.T) {
+	useRecorder(t)
+
+	var received string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("traceparent")
+	}))
+	defer srv.Close()
+
+	_, err := Setup(context.Background(), Config{Exporter: ExporterNone})
+	require.NoError(t, err)
+
+	ctx, span := Tracer().Start(context.Background(), "entrega")
+	defer span.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, nil)
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Contains(t, received, span.SpanContext().TraceID().String())
+	assert.Empty(t, req.Header.Get("traceparent"), "la petición original no se modifica")
+}
+
+func TestSetup(t *testing.T) {
+	t.Run("unknown exporter", func(t *testing.T) {
+		_, err := Setup(context.Background(), Config{Exporter: "zipkin", SampleRatio: 1})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid sample ratio", func(t *testing.T) {
+		_, err := Setup(context.Background(), Config{Exporter: ExporterStdout, SampleRatio: 2})
+		assert.Error(t, err)
+	})
+
+	t.Run("file exporter", func(t *testing.T) {
+		previous := otel.GetTracerProvider()
+		t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+		path := filepath.Join(t.TempDir(), "traces.json")
+		shutdown, err := Setup(context.Background(), Config{ServiceName: "test", Exporter: ExporterFile, FilePath: path, SampleRatio: 1})
+		require.NoError(t, err)
+
+		_, span := Tracer().Start(context.Background(), "operación")
+		traceID := span.SpanContext().TraceID().String()
+		span.End()
+		require.NoError(t, shutdown(context.Background()))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), traceID)
+		assert.Contains(t, string(data), "operación")
+	})
+}
+
+func TestTraceID(t *testing.T) {
+	useRecorder(t)
+	assert.Empty(t, TraceID(context.Background()))
+
+	ctx, span := Tracer().Start(context.Background(), "x")
+	defer span.End()
+	assert.Equal(t, span.SpanContext().TraceID().String(), TraceID(ctx))
+}
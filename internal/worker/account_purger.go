@@ -0,0 +1,252 @@
+// internal/worker/account_purger.go
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// DefaultAccountInterval es cada cuánto se revisan suspensiones, desactivaciones y borrados
+	DefaultAccountInterval = time.Minute
+	// DefaultPurgeLease es cuánto tiempo reserva una instancia cada borrado de cuenta
+	DefaultPurgeLease = 5 * time.Minute
+	// maxPurgeBatch limita cuántas cuentas se borran en cada pasada
+	maxPurgeBatch = 20
+	// maxExpireBatch limita cuántas desactivaciones vencidas se pasan a borrado en cada pasada
+	maxExpireBatch = 100
+)
+
+// DeletionJobs es el acceso a los borrados de cuentas que necesita el purgador
+type DeletionJobs interface {
+	ClaimPending(ctx context.Context, owner string, now time.Time, lease time.Duration) (*models.AccountDeletion, error)
+	SaveStep(ctx context.Context, id primitive.ObjectID, owner string, step int, leaseUntil time.Time) error
+	Complete(ctx context.Context, id primitive.ObjectID, owner string, at time.Time) error
+	Release(ctx context.Context, id primitive.ObjectID, owner string, reason string, retryAt time.Time) error
+}
+
+// PurgeUsers es el acceso a usuarios que necesita el purgador
+type PurgeUsers interface {
+	LiftExpiredSuspensions(ctx context.Context, now time.Time) (int64, error)
+	DeactivatedBefore(ctx context.Context, cutoff time.Time, limit int) ([]models.User, error)
+	MarkForDeletion(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	RemoveFollower(ctx context.Context, userID string) error
+	ClearFollowing(ctx context.Context, id primitive.ObjectID) error
+	RecountFollowers(ctx context.Context, ids []string) error
+	AnonymizeModerator(ctx context.Context, id primitive.ObjectID) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// DeletionRequester registra el borrado de una cuenta
+type DeletionRequester interface {
+	RequestDeletion(ctx context.Context, userID, reason string) (*models.AccountDeletion, error)
+}
+
+// UserContent borra el contenido de un usuario en una colección
+type UserContent interface {
+	DeleteByUser(ctx context.Context, userID primitive.ObjectID) error
+}
+
+// ReportAnonymizer quita al usuario de los reportes que hizo o resolvió
+type ReportAnonymizer interface {
+	AnonymizeUser(ctx context.Context, userID primitive.ObjectID) error
+}
+
+// AuditLog registra las acciones en la auditoría
+type AuditLog interface {
+	Record(ctx context.Context, entry models.AuditEntry)
+}
+
+// purgeStep es un paso del borrado; todos se pueden repetir sin efectos dobles
+type purgeStep struct {
+	name string
+	run  func(ctx context.Context, d *models.AccountDeletion) error
+}
+
+// AccountPurger mantiene el ciclo de vida de las cuentas: levanta las
+// suspensiones vencidas, pasa a borrado las desactivaciones que superaron
+// models.DeactivationGracePeriod y ejecuta los borrados pendientes. Cada borrado
+// se reserva con un lease y guarda el último paso completado, así que si la
+// instancia cae otra lo retoma donde quedó.
+type AccountPurger struct {
+	jobs      DeletionJobs
+	users     PurgeUsers
+	requester DeletionRequester
+	reports   ReportAnonymizer
+	content   []UserContent
+	audit     AuditLog
+	owner     string
+	interval  time.Duration
+	lease     time.Duration
+	now       func() time.Time
+}
+
+func NewAccountPurger(jobs DeletionJobs, users PurgeUsers, requester DeletionRequester, reports ReportAnonymizer, content []UserContent, audit AuditLog, interval, lease time.Duration) *AccountPurger {
+	if interval <= 0 {
+		interval = DefaultAccountInterval
+	}
+	if lease <= 0 {
+		lease = DefaultPurgeLease
+	}
+	hostname, _ := os.Hostname()
+	return &AccountPurger{
+		jobs:      jobs,
+		users:     users,
+		requester: requester,
+		reports:   reports,
+		content:   content,
+		audit:     audit,
+		owner:     fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), primitive.NewObjectID().Hex()),
+		interval:  interval,
+		lease:     lease,
+		now:       time.Now,
+	}
+}
+
+// Run ejecuta el purgador hasta que se cancela el contexto
+func (w *AccountPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce hace una pasada completa; los errores se registran y se reintentan en
+// la siguiente
+func (w *AccountPurger) RunOnce(ctx context.Context) {
+	if n, err := w.users.LiftExpiredSuspensions(ctx, w.now()); err != nil {
+		slog.ErrorContext(ctx, "error al levantar suspensiones vencidas", slog.Any("error", err))
+	} else if n > 0 {
+		slog.InfoContext(ctx, "suspensiones vencidas levantadas", slog.Int64("count", n))
+	}
+	if _, err := w.ExpireDeactivations(ctx); err != nil {
+		slog.ErrorContext(ctx, "error al vencer desactivaciones", slog.Any("error", err))
+	}
+	if _, err := w.PurgePending(ctx); err != nil {
+		slog.ErrorContext(ctx, "error al borrar cuentas", slog.Any("error", err))
+	}
+}
+
+// ExpireDeactivations pide el borrado de las cuentas desactivadas hace más de
+// models.DeactivationGracePeriod y devuelve cuántas pasó a borrado
+func (w *AccountPurger) ExpireDeactivations(ctx context.Context) (int, error) {
+	users, err := w.users.DeactivatedBefore(ctx, w.now().Add(-models.DeactivationGracePeriod), maxExpireBatch)
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for _, user := range users {
+		if _, err := w.requester.RequestDeletion(ctx, user.ID.Hex(), models.DeletionReasonDeactivated); err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// PurgePending ejecuta los borrados pendientes y devuelve cuántos terminó
+func (w *AccountPurger) PurgePending(ctx context.Context) (int, error) {
+	purged := 0
+	for i := 0; i < maxPurgeBatch; i++ {
+		if ctx.Err() != nil {
+			return purged, nil
+		}
+
+		d, err := w.jobs.ClaimPending(ctx, w.owner, w.now(), w.lease)
+		if err != nil {
+			return purged, err
+		}
+		if d == nil {
+			return purged, nil
+		}
+
+		// Como en el scheduler, un borrado reservado no se corta por el apagado:
+		// cada paso es corto y el siguiente intento retoma desde el último guardado
+		if err := w.purge(context.WithoutCancel(ctx), d); err != nil {
+			slog.ErrorContext(ctx, "error al borrar cuenta",
+				slog.String("user_id", d.UserID.Hex()), slog.Any("error", err))
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (w *AccountPurger) purge(ctx context.Context, d *models.AccountDeletion) error {
+	steps := w.steps()
+	for i := d.Step; i < len(steps); i++ {
+		if err := steps[i].run(ctx, d); err != nil {
+			// Reintentar más tarde con una espera creciente desde el mismo paso
+			retryAt := w.now().Add(time.Duration(d.Attempts) * w.interval)
+			reason := fmt.Sprintf("%s: %v", steps[i].name, err)
+			if releaseErr := w.jobs.Release(ctx, d.ID, w.owner, reason, retryAt); releaseErr != nil {
+				return releaseErr
+			}
+			return err
+		}
+		if err := w.jobs.SaveStep(ctx, d.ID, w.owner, i+1, w.now().Add(w.lease)); err != nil {
+			return err
+		}
+	}
+	if err := w.jobs.Complete(ctx, d.ID, w.owner, w.now()); err != nil {
+		return err
+	}
+	w.audit.Record(ctx, models.AuditEntry{
+		Action:     models.AuditUserDeleted,
+		TargetType: models.AuditTargetUser,
+		TargetID:   d.UserID.Hex(),
+		Metadata:   map[string]string{"reason": d.Reason, "username": d.Username},
+	})
+	return nil
+}
+
+// steps devuelve los pasos del borrado en orden. El usuario se borra al final
+// para que, mientras tanto, siga marcado como pendiente y no se vea en ningún
+// listado.
+func (w *AccountPurger) steps() []purgeStep {
+	return []purgeStep{
+		{"mark", func(ctx context.Context, d *models.AccountDeletion) error {
+			return w.users.MarkForDeletion(ctx, d.UserID, d.RequestedAt)
+		}},
+		{"content", func(ctx context.Context, d *models.AccountDeletion) error {
+			for _, content := range w.content {
+				if err := content.DeleteByUser(ctx, d.UserID); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"unfollow", func(ctx context.Context, d *models.AccountDeletion) error {
+			if err := w.users.RemoveFollower(ctx, d.UserID.Hex()); err != nil {
+				return err
+			}
+			return w.users.ClearFollowing(ctx, d.UserID)
+		}},
+		{"followers_count", func(ctx context.Context, d *models.AccountDeletion) error {
+			return w.users.RecountFollowers(ctx, d.Following)
+		}},
+		{"anonymize", func(ctx context.Context, d *models.AccountDeletion) error {
+			if err := w.reports.AnonymizeUser(ctx, d.UserID); err != nil {
+				return err
+			}
+			return w.users.AnonymizeModerator(ctx, d.UserID)
+		}},
+		{"user", func(ctx context.Context, d *models.AccountDeletion) error {
+			return w.users.Delete(ctx, d.UserID)
+		}},
+	}
+}
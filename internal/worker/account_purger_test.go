@@ -0,0 +1,324 @@
+// internal/worker/account_purger_test.go
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeDeletionJobs reproduce en memoria la semántica de lease del repositorio
+type fakeDeletionJobs struct {
+	items []*models.AccountDeletion
+}
+
+func (f *fakeDeletionJobs) ClaimPending(ctx context.Context, owner string, now time.Time, lease time.Duration) (*models.AccountDeletion, error) {
+	for _, d := range f.items {
+		if d.Status != models.DeletionStatusPending {
+			continue
+		}
+		if d.LeaseUntil != nil && !d.LeaseUntil.Before(now) {
+			continue
+		}
+		until := now.Add(lease)
+		d.LeaseOwner, d.LeaseUntil = owner, &until
+		d.Attempts++
+		claimed := *d
+		return &claimed, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeDeletionJobs) finish(id primitive.ObjectID, owner string, apply func(d *models.AccountDeletion)) error {
+	for _, d := range f.items {
+		if d.ID == id && d.LeaseOwner == owner {
+			apply(d)
+			return nil
+		}
+	}
+	return repository.ErrDeletionLeaseLost
+}
+
+func (f *fakeDeletionJobs) SaveStep(ctx context.Context, id primitive.ObjectID, owner string, step int, leaseUntil time.Time) error {
+	return f.finish(id, owner, func(d *models.AccountDeletion) {
+		d.Step, d.LeaseUntil = step, &leaseUntil
+	})
+}
+
+func (f *fakeDeletionJobs) Complete(ctx context.Context, id primitive.ObjectID, owner string, at time.Time) error {
+	return f.finish(id, owner, func(d *models.AccountDeletion) {
+		d.Status, d.CompletedAt = models.DeletionStatusDone, &at
+		d.LeaseOwner, d.LeaseUntil, d.LastError = "", nil, ""
+	})
+}
+
+func (f *fakeDeletionJobs) Release(ctx context.Context, id primitive.ObjectID, owner string, reason string, retryAt time.Time) error {
+	return f.finish(id, owner, func(d *models.AccountDeletion) {
+		d.LastError = reason
+		d.LeaseOwner, d.LeaseUntil = "", &retryAt
+	})
+}
+
+// fakePurgeUsers guarda los usuarios en memoria y puede fallar una vez en RemoveFollower
+type fakePurgeUsers struct {
+	users       map[primitive.ObjectID]*models.User
+	unfollowErr error
+	calls       map[string]int
+}
+
+func newFakePurgeUsers(users ...*models.User) *fakePurgeUsers {
+	f := &fakePurgeUsers{users: make(map[primitive.ObjectID]*models.User), calls: make(map[string]int)}
+	for _, u := range users {
+		f.users[u.ID] = u
+	}
+	return f
+}
+
+func (f *fakePurgeUsers) LiftExpiredSuspensions(ctx context.Context, now time.Time) (int64, error) {
+	var n int64
+	for _, u := range f.users {
+		if u.Suspension != nil && u.Suspension.ExpiresAt != nil && !u.Suspension.ExpiresAt.After(now) {
+			u.Suspension = nil
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakePurgeUsers) DeactivatedBefore(ctx context.Context, cutoff time.Time, limit int) ([]models.User, error) {
+	var out []models.User
+	for _, u := range f.users {
+		if u.DeactivatedAt != nil && u.DeactivatedAt.Before(cutoff) && u.DeletionRequestedAt == nil {
+			out = append(out, *u)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakePurgeUsers) MarkForDeletion(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	f.calls["mark"]++
+	if u, ok := f.users[id]; ok && u.DeletionRequestedAt == nil {
+		u.DeletionRequestedAt = &at
+	}
+	return nil
+}
+
+func (f *fakePurgeUsers) RemoveFollower(ctx context.Context, userID string) error {
+	f.calls["unfollow"]++
+	if err := f.unfollowErr; err != nil {
+		f.unfollowErr = nil
+		return err
+	}
+	for _, u := range f.users {
+		following := u.Following[:0]
+		for _, id := range u.Following {
+			if id != userID {
+				following = append(following, id)
+			}
+		}
+		u.Following = following
+	}
+	return nil
+}
+
+func (f *fakePurgeUsers) ClearFollowing(ctx context.Context, id primitive.ObjectID) error {
+	if u, ok := f.users[id]; ok {
+		u.Following = nil
+	}
+	return nil
+}
+
+func (f *fakePurgeUsers) RecountFollowers(ctx context.Context, ids []string) error {
+	f.calls["recount"]++
+	for _, id := range ids {
+		objectID, _ := primitive.ObjectIDFromHex(id)
+		target, ok := f.users[objectID]
+		if !ok {
+			continue
+		}
+		target.FollowersCount = 0
+		for _, u := range f.users {
+			for _, followed := range u.Following {
+				if followed == id {
+					target.FollowersCount++
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakePurgeUsers) AnonymizeModerator(ctx context.Context, id primitive.ObjectID) error {
+	f.calls["anonymize"]++
+	return nil
+}
+
+func (f *fakePurgeUsers) Delete(ctx context.Context, id primitive.ObjectID) error {
+	delete(f.users, id)
+	return nil
+}
+
+type fakeUserContent struct {
+	deleted map[primitive.ObjectID]int
+}
+
+func (f *fakeUserContent) DeleteByUser(ctx context.Context, userID primitive.ObjectID) error {
+	f.deleted[userID]++
+	return nil
+}
+
+type fakeAuditLog struct {
+	entries []models.AuditEntry
+}
+
+func (f *fakeAuditLog) Record(ctx context.Context, entry models.AuditEntry) {
+	f.entries = append(f.entries, entry)
+}
+
+type fakeReportAnonymizer struct {
+	anonymized []primitive.ObjectID
+}
+
+func (f *fakeReportAnonymizer) AnonymizeUser(ctx context.Context, userID primitive.ObjectID) error {
+	f.anonymized = append(f.anonymized, userID)
+	return nil
+}
+
+// fakeRequester registra el borrado como lo hace AccountService
+type fakeRequester struct {
+	jobs  *fakeDeletionJobs
+	users *fakePurgeUsers
+	now   time.Time
+}
+
+func (f *fakeRequester) RequestDeletion(ctx context.Context, userID, reason string) (*models.AccountDeletion, error) {
+	objectID, _ := primitive.ObjectIDFromHex(userID)
+	d := &models.AccountDeletion{
+		ID:          primitive.NewObjectID(),
+		UserID:      objectID,
+		Reason:      reason,
+		Status:      models.DeletionStatusPending,
+		Following:   f.users.users[objectID].Following,
+		RequestedAt: f.now,
+	}
+	f.jobs.items = append(f.jobs.items, d)
+	return d, f.users.MarkForDeletion(ctx, objectID, f.now)
+}
+
+type purgeFixture struct {
+	purger   *AccountPurger
+	jobs     *fakeDeletionJobs
+	users    *fakePurgeUsers
+	content  *fakeUserContent
+	reports  *fakeReportAnonymizer
+	audit    *fakeAuditLog
+	deleted  *models.User
+	followed *models.User
+	follower *models.User
+	now      time.Time
+}
+
+func newPurgeFixture() *purgeFixture {
+	f := &purgeFixture{
+		jobs:     &fakeDeletionJobs{},
+		content:  &fakeUserContent{deleted: make(map[primitive.ObjectID]int)},
+		reports:  &fakeReportAnonymizer{},
+		audit:    &fakeAuditLog{},
+		followed: &models.User{ID: primitive.NewObjectID(), Username: "seguido", FollowersCount: 2},
+		now:      time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC),
+	}
+	f.deleted = &models.User{ID: primitive.NewObjectID(), Username: "borrado", Following: []string{f.followed.ID.Hex()}}
+	f.follower = &models.User{
+		ID:        primitive.NewObjectID(),
+		Username:  "seguidor",
+		Following: []string{f.followed.ID.Hex(), f.deleted.ID.Hex()},
+	}
+	f.users = newFakePurgeUsers(f.deleted, f.followed, f.follower)
+	requester := &fakeRequester{jobs: f.jobs, users: f.users, now: f.now}
+	f.purger = NewAccountPurger(f.jobs, f.users, requester, f.reports, []UserContent{f.content}, f.audit, time.Minute, time.Minute)
+	f.purger.now = func() time.Time { return f.now }
+	return f
+}
+
+func TestAccountPurger_Purge(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("removes the account and fixes references", func(t *testing.T) {
+		f := newPurgeFixture()
+		_, err := f.purger.requester.RequestDeletion(ctx, f.deleted.ID.Hex(), models.DeletionReasonAdmin)
+		require.NoError(t, err)
+
+		n, err := f.purger.PurgePending(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		assert.NotContains(t, f.users.users, f.deleted.ID)
+		assert.Equal(t, []string{f.followed.ID.Hex()}, f.follower.Following)
+		assert.Equal(t, 1, f.followed.FollowersCount)
+		assert.Equal(t, 1, f.content.deleted[f.deleted.ID])
+		assert.Equal(t, []primitive.ObjectID{f.deleted.ID}, f.reports.anonymized)
+
+		d := f.jobs.items[0]
+		assert.Equal(t, models.DeletionStatusDone, d.Status)
+		assert.Equal(t, len(f.purger.steps()), d.Step)
+
+		require.Len(t, f.audit.entries, 1)
+		assert.Equal(t, models.AuditUserDeleted, f.audit.entries[0].Action)
+		assert.Equal(t, f.deleted.ID.Hex(), f.audit.entries[0].TargetID)
+	})
+
+	t.Run("resumes from the failed step", func(t *testing.T) {
+		f := newPurgeFixture()
+		f.users.unfollowErr = errors.New("sin conexión")
+		_, err := f.purger.requester.RequestDeletion(ctx, f.deleted.ID.Hex(), models.DeletionReasonAdmin)
+		require.NoError(t, err)
+
+		n, err := f.purger.PurgePending(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+		d := f.jobs.items[0]
+		assert.Equal(t, 2, d.Step, "guarda los pasos ya completados")
+		assert.Contains(t, d.LastError, "unfollow")
+		assert.Contains(t, f.users.users, f.deleted.ID)
+
+		// Antes de la espera no se reintenta
+		n, _ = f.purger.PurgePending(ctx)
+		assert.Equal(t, 0, n)
+
+		f.now = f.now.Add(time.Hour)
+		n, err = f.purger.PurgePending(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, models.DeletionStatusDone, d.Status)
+		assert.Equal(t, 1, f.content.deleted[f.deleted.ID], "los pasos completados no se repiten")
+		assert.Equal(t, 2, f.users.calls["unfollow"])
+		assert.NotContains(t, f.users.users, f.deleted.ID)
+	})
+}
+
+func TestAccountPurger_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	f := newPurgeFixture()
+
+	expired := f.now.Add(-time.Minute)
+	f.followed.Suspension = &models.Suspension{Reason: "spam", ExpiresAt: &expired}
+	deactivated := f.now.Add(-models.DeactivationGracePeriod - time.Hour)
+	f.deleted.DeactivatedAt = &deactivated
+	recent := f.now.Add(-time.Hour)
+	f.follower.DeactivatedAt = &recent
+
+	f.purger.RunOnce(ctx)
+
+	assert.Nil(t, f.followed.Suspension, "la suspensión vencida se levanta")
+	require.Len(t, f.jobs.items, 1, "solo vence la desactivación fuera de plazo")
+	assert.Equal(t, models.DeletionReasonDeactivated, f.jobs.items[0].Reason)
+	assert.NotContains(t, f.users.users, f.deleted.ID)
+	assert.Contains(t, f.users.users, f.follower.ID)
+}
@@ -0,0 +1,304 @@
+// internal/worker/exporter.go
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/export"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"github.com/ffelixf/microblog-platform/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// DefaultExportInterval es cada cuánto se buscan exportaciones pendientes
+	DefaultExportInterval = 15 * time.Second
+	// DefaultExportLease es cuánto tiempo reserva una instancia cada exportación
+	DefaultExportLease = 10 * time.Minute
+	// DefaultExportRetention es cuánto tiempo se guarda el archivo generado
+	DefaultExportRetention = 7 * 24 * time.Hour
+	// maxExportBatch limita cuántas exportaciones se generan en cada pasada
+	maxExportBatch = 5
+	// maxExportAttempts es cuántas veces se intenta una exportación antes de darla por fallida
+	maxExportAttempts = 5
+	// maxExpireExportBatch limita cuántos archivos vencidos se borran en cada pasada
+	maxExpireExportBatch = 100
+)
+
+// ExportJobs es el acceso a las exportaciones que necesita el exportador
+type ExportJobs interface {
+	ClaimPending(ctx context.Context, owner string, now time.Time, lease time.Duration) (*models.ExportJob, error)
+	Complete(ctx context.Context, id primitive.ObjectID, owner, key string, size int64, at, expiresAt time.Time) error
+	Release(ctx context.Context, id primitive.ObjectID, owner string, reason string, retryAt time.Time) error
+	Fail(ctx context.Context, id primitive.ObjectID, owner string, reason string, at time.Time) error
+	ExpiredBefore(ctx context.Context, now time.Time, limit int) ([]models.ExportJob, error)
+	MarkExpired(ctx context.Context, id primitive.ObjectID) error
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.ExportJob, error)
+	DeleteByUser(ctx context.Context, userID primitive.ObjectID) error
+}
+
+// ExportUsers es el acceso a usuarios que necesita el exportador
+type ExportUsers interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	GetFollowing(ctx context.Context, userID string) ([]models.User, error)
+	GetFollowers(ctx context.Context, userID string) ([]models.User, error)
+}
+
+// ExportTweets devuelve todos los tweets de un usuario, ocultos incluidos
+type ExportTweets interface {
+	AllByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Tweet, error)
+}
+
+// ExportMedia devuelve los archivos subidos por un usuario
+type ExportMedia interface {
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Media, error)
+}
+
+// Exporter genera las exportaciones de datos personales pendientes: reúne los
+// datos del usuario, arma el ZIP y lo guarda en el almacenamiento de archivos.
+// Como el purgador, reserva cada exportación con un lease para que varias
+// instancias no generen la misma. También borra los archivos vencidos.
+type Exporter struct {
+	jobs      ExportJobs
+	users     ExportUsers
+	tweets    ExportTweets
+	media     ExportMedia
+	store     storage.BlobStore
+	owner     string
+	interval  time.Duration
+	lease     time.Duration
+	retention time.Duration
+	now       func() time.Time
+}
+
+func NewExporter(jobs ExportJobs, users ExportUsers, tweets ExportTweets, media ExportMedia, store storage.BlobStore, interval, lease, retention time.Duration) *Exporter {
+	if interval <= 0 {
+		interval = DefaultExportInterval
+	}
+	if lease <= 0 {
+		lease = DefaultExportLease
+	}
+	if retention <= 0 {
+		retention = DefaultExportRetention
+	}
+	hostname, _ := os.Hostname()
+	return &Exporter{
+		jobs:      jobs,
+		users:     users,
+		tweets:    tweets,
+		media:     media,
+		store:     store,
+		owner:     fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), primitive.NewObjectID().Hex()),
+		interval:  interval,
+		lease:     lease,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// Run ejecuta el exportador hasta que se cancela el contexto
+func (w *Exporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce hace una pasada completa; los errores se registran y se reintentan en
+// la siguiente
+func (w *Exporter) RunOnce(ctx context.Context) {
+	if _, err := w.ExportPending(ctx); err != nil {
+		slog.ErrorContext(ctx, "error al generar exportaciones", slog.Any("error", err))
+	}
+	if _, err := w.ExpireArchives(ctx); err != nil {
+		slog.ErrorContext(ctx, "error al borrar exportaciones vencidas", slog.Any("error", err))
+	}
+}
+
+// ExportPending genera las exportaciones pendientes y devuelve cuántas terminó
+func (w *Exporter) ExportPending(ctx context.Context) (int, error) {
+	exported := 0
+	for i := 0; i < maxExportBatch; i++ {
+		if ctx.Err() != nil {
+			return exported, nil
+		}
+
+		job, err := w.jobs.ClaimPending(ctx, w.owner, w.now(), w.lease)
+		if err != nil {
+			return exported, err
+		}
+		if job == nil {
+			return exported, nil
+		}
+
+		// Una exportación reservada no se corta por el apagado; si la instancia
+		// cae, otra la repite al vencer el lease
+		if err := w.export(context.WithoutCancel(ctx), job); err != nil {
+			slog.ErrorContext(ctx, "error al generar exportación",
+				slog.String("export_id", job.ID.Hex()), slog.String("user_id", job.UserID.Hex()), slog.Any("error", err))
+			continue
+		}
+		exported++
+	}
+	return exported, nil
+}
+
+func (w *Exporter) export(ctx context.Context, job *models.ExportJob) error {
+	// El ZIP se arma en un archivo temporal y se sube desde ahí: ni el
+	// archivo ni las imágenes que contiene se cargan enteros en memoria
+	tmp, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		return w.retry(ctx, job, fmt.Errorf("error al crear archivo temporal: %w", err))
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := w.build(ctx, job, tmp); err != nil {
+		return w.retry(ctx, job, err)
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return w.retry(ctx, job, fmt.Errorf("error al leer archivo temporal: %w", err))
+	}
+
+	key := fmt.Sprintf("exports/%s/%s.zip", job.UserID.Hex(), job.ID.Hex())
+	if err := w.store.PutStream(ctx, key, tmp, size, export.ContentType); err != nil {
+		return w.retry(ctx, job, fmt.Errorf("error al guardar archivo: %w", err))
+	}
+	now := w.now()
+	if err := w.jobs.Complete(ctx, job.ID, w.owner, key, size, now, now.Add(w.retention)); err != nil {
+		// Otra instancia tomó la exportación o el usuario se borró mientras
+		// tanto: el archivo no debe quedar huérfano
+		if errors.Is(err, repository.ErrExportLeaseLost) {
+			w.deleteArchive(ctx, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// retry libera la exportación para reintentarla más tarde, o la da por
+// fallida si el usuario ya no existe o se agotaron los intentos
+func (w *Exporter) retry(ctx context.Context, job *models.ExportJob, cause error) error {
+	if errors.Is(cause, repository.ErrUserNotFound) || job.Attempts >= maxExportAttempts {
+		if err := w.jobs.Fail(ctx, job.ID, w.owner, cause.Error(), w.now()); err != nil {
+			return err
+		}
+		return cause
+	}
+	retryAt := w.now().Add(time.Duration(job.Attempts) * w.interval)
+	if err := w.jobs.Release(ctx, job.ID, w.owner, cause.Error(), retryAt); err != nil {
+		return err
+	}
+	return cause
+}
+
+// build reúne los datos del usuario y escribe el ZIP en out
+func (w *Exporter) build(ctx context.Context, job *models.ExportJob, out io.Writer) error {
+	user, err := w.users.GetByID(ctx, job.UserID.Hex())
+	if err != nil {
+		return err
+	}
+	tweets, err := w.tweets.AllByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	following, err := w.users.GetFollowing(ctx, user.ID.Hex())
+	if err != nil {
+		return err
+	}
+	followers, err := w.users.GetFollowers(ctx, user.ID.Hex())
+	if err != nil {
+		return err
+	}
+	media, err := w.media.ListByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// La plataforma todavía no tiene me gusta ni guardados; el archivo los
+	// incluye vacíos para que su formato no cambie cuando existan
+	archive := &export.Archive{
+		GeneratedAt: w.now(),
+		Profile:     export.NewProfile(user),
+		Tweets:      tweets,
+		Following:   export.NewAccounts(following),
+		Followers:   export.NewAccounts(followers),
+		Media:       media,
+		Open: func(key string) (io.ReadCloser, error) {
+			blob, err := w.store.Get(ctx, key)
+			if errors.Is(err, storage.ErrNotFound) {
+				// Un archivo perdido no impide exportar el resto; queda listado sin contenido
+				slog.WarnContext(ctx, "archivo no encontrado al exportar", slog.String("key", key))
+				return nil, export.ErrFileMissing
+			}
+			if err != nil {
+				return nil, err
+			}
+			return blob, nil
+		},
+	}
+	return archive.Build(out)
+}
+
+// ExpireArchives borra los archivos de las exportaciones vencidas y devuelve
+// cuántos borró
+func (w *Exporter) ExpireArchives(ctx context.Context) (int, error) {
+	jobs, err := w.jobs.ExpiredBefore(ctx, w.now(), maxExpireExportBatch)
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for _, job := range jobs {
+		if err := w.store.Delete(ctx, job.Key); err != nil {
+			return expired, fmt.Errorf("error al borrar archivo %s: %w", job.Key, err)
+		}
+		if err := w.jobs.MarkExpired(ctx, job.ID); err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// DeleteByUser borra las exportaciones del usuario y sus archivos; el
+// purgador lo usa al borrar una cuenta
+func (w *Exporter) DeleteByUser(ctx context.Context, userID primitive.ObjectID) error {
+	jobs, err := w.jobs.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if job.Key == "" {
+			continue
+		}
+		if err := w.store.Delete(ctx, job.Key); err != nil {
+			return fmt.Errorf("error al borrar archivo %s: %w", job.Key, err)
+		}
+	}
+	return w.jobs.DeleteByUser(ctx, userID)
+}
+
+func (w *Exporter) deleteArchive(ctx context.Context, key string) {
+	if err := w.store.Delete(ctx, key); err != nil {
+		slog.ErrorContext(ctx, "error al borrar exportación huérfana", slog.String("key", key), slog.Any("error", err))
+	}
+}
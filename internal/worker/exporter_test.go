@@ -0,0 +1,311 @@
+// internal/worker/exporter_test.go
+package worker
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"github.com/ffelixf/microblog-platform/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeExportJobs reproduce en memoria la semántica de lease del repositorio
+type fakeExportJobs struct {
+	items []*models.ExportJob
+}
+
+func (f *fakeExportJobs) add(userID primitive.ObjectID) *models.ExportJob {
+	job := &models.ExportJob{ID: primitive.NewObjectID(), UserID: userID, Status: models.ExportStatusPending}
+	f.items = append(f.items, job)
+	return job
+}
+
+func (f *fakeExportJobs) ClaimPending(ctx context.Context, owner string, now time.Time, lease time.Duration) (*models.ExportJob, error) {
+	for _, j := range f.items {
+		if j.Status != models.ExportStatusPending || (j.LeaseUntil != nil && !j.LeaseUntil.Before(now)) {
+			continue
+		}
+		until := now.Add(lease)
+		j.LeaseOwner, j.LeaseUntil = owner, &until
+		j.Attempts++
+		claimed := *j
+		return &claimed, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeExportJobs) finish(id primitive.ObjectID, owner string, apply func(j *models.ExportJob)) error {
+	for _, j := range f.items {
+		if j.ID == id && j.LeaseOwner == owner {
+			apply(j)
+			return nil
+		}
+	}
+	return repository.ErrExportLeaseLost
+}
+
+func (f *fakeExportJobs) Complete(ctx context.Context, id primitive.ObjectID, owner, key string, size int64, at, expiresAt time.Time) error {
+	return f.finish(id, owner, func(j *models.ExportJob) {
+		j.Status, j.Key, j.Size, j.CompletedAt, j.ExpiresAt = models.ExportStatusDone, key, size, &at, &expiresAt
+		j.LeaseOwner, j.LeaseUntil, j.LastError = "", nil, ""
+	})
+}
+
+func (f *fakeExportJobs) Release(ctx context.Context, id primitive.ObjectID, owner string, reason string, retryAt time.Time) error {
+	return f.finish(id, owner, func(j *models.ExportJob) {
+		j.LastError, j.LeaseOwner, j.LeaseUntil = reason, "", &retryAt
+	})
+}
+
+func (f *fakeExportJobs) Fail(ctx context.Context, id primitive.ObjectID, owner string, reason string, at time.Time) error {
+	return f.finish(id, owner, func(j *models.ExportJob) {
+		j.Status, j.LastError, j.CompletedAt = models.ExportStatusFailed, reason, &at
+		j.LeaseOwner, j.LeaseUntil = "", nil
+	})
+}
+
+func (f *fakeExportJobs) ExpiredBefore(ctx context.Context, now time.Time, limit int) ([]models.ExportJob, error) {
+	var out []models.ExportJob
+	for _, j := range f.items {
+		if j.Status == models.ExportStatusDone && j.ExpiresAt.Before(now) {
+			out = append(out, *j)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeExportJobs) MarkExpired(ctx context.Context, id primitive.ObjectID) error {
+	for _, j := range f.items {
+		if j.ID == id {
+			j.Status, j.Key = models.ExportStatusExpired, ""
+		}
+	}
+	return nil
+}
+
+func (f *fakeExportJobs) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.ExportJob, error) {
+	var out []models.ExportJob
+	for _, j := range f.items {
+		if j.UserID == userID {
+			out = append(out, *j)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeExportJobs) DeleteByUser(ctx context.Context, userID primitive.ObjectID) error {
+	items := f.items[:0]
+	for _, j := range f.items {
+		if j.UserID != userID {
+			items = append(items, j)
+		}
+	}
+	f.items = items
+	return nil
+}
+
+// fakeExportData implementa ExportUsers, ExportTweets y ExportMedia con un
+// único usuario y puede fallar en cada lectura de tweets
+type fakeExportData struct {
+	user      *models.User
+	follower  models.User
+	tweets    []models.Tweet
+	media     []models.Media
+	tweetsErr error
+}
+
+func (f *fakeExportData) GetByID(ctx context.Context, id string) (*models.User, error) {
+	if f.user == nil || f.user.ID.Hex() != id {
+		return nil, repository.ErrUserNotFound
+	}
+	return f.user, nil
+}
+
+func (f *fakeExportData) GetFollowing(ctx context.Context, userID string) ([]models.User, error) {
+	return nil, nil
+}
+
+func (f *fakeExportData) GetFollowers(ctx context.Context, userID string) ([]models.User, error) {
+	return []models.User{f.follower}, nil
+}
+
+func (f *fakeExportData) AllByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Tweet, error) {
+	return f.tweets, f.tweetsErr
+}
+
+func (f *fakeExportData) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Media, error) {
+	return f.media, nil
+}
+
+type exporterFixture struct {
+	exporter *Exporter
+	jobs     *fakeExportJobs
+	data     *fakeExportData
+	store    *storage.LocalStore
+	now      time.Time
+}
+
+func setupExporter(t *testing.T) *exporterFixture {
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	user := &models.User{ID: primitive.NewObjectID(), Username: "ana"}
+	f := &exporterFixture{
+		jobs: &fakeExportJobs{},
+		data: &fakeExportData{
+			user:     user,
+			follower: models.User{ID: primitive.NewObjectID(), Username: "beto"},
+			tweets:   []models.Tweet{{ID: primitive.NewObjectID(), UserID: user.ID, Content: "hola"}},
+		},
+		store: store,
+		now:   time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	f.exporter = NewExporter(f.jobs, f.data, f.data, f.data, store, time.Minute, time.Minute, 24*time.Hour)
+	f.exporter.now = func() time.Time { return f.now }
+	return f
+}
+
+func zipNames(t *testing.T, data []byte) []string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	names := make([]string, 0, len(zr.File))
+	for _, file := range zr.File {
+		names = append(names, file.Name)
+	}
+	return names
+}
+
+func TestExporter_ExportPending(t *testing.T) {
+	ctx := context.Background()
+	f := setupExporter(t)
+
+	mediaID := primitive.NewObjectID()
+	key := "media/" + f.data.user.ID.Hex() + "/" + mediaID.Hex() + "/original.png"
+	require.NoError(t, f.store.Put(ctx, key, []byte("png-data"), "image/png"))
+	f.data.media = []models.Media{{ID: mediaID, UserID: f.data.user.ID, Variants: map[string]models.MediaVariant{
+		"original":  {Key: key, ContentType: "image/png"},
+		"thumbnail": {Key: "media/perdido.png", ContentType: "image/png"},
+	}}}
+	job := f.jobs.add(f.data.user.ID)
+
+	n, err := f.exporter.ExportPending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.Equal(t, models.ExportStatusDone, job.Status)
+	assert.Equal(t, f.now.Add(24*time.Hour), *job.ExpiresAt)
+	blob, err := f.store.Get(ctx, job.Key)
+	require.NoError(t, err)
+	assert.Equal(t, "application/zip", blob.ContentType)
+	data, err := io.ReadAll(blob)
+	blob.Close()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), job.Size)
+
+	names := zipNames(t, data)
+	assert.Contains(t, names, "index.html")
+	assert.Contains(t, names, "tweets.json")
+	assert.Contains(t, names, "media/"+mediaID.Hex()+"/original.png")
+	assert.NotContains(t, names, "media/"+mediaID.Hex()+"/thumbnail.png", "un archivo perdido no impide la exportación")
+}
+
+func TestExporter_Retry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("transient errors are retried until attempts run out", func(t *testing.T) {
+		f := setupExporter(t)
+		f.data.tweetsErr = errors.New("mongo caído")
+		job := f.jobs.add(f.data.user.ID)
+
+		for i := 1; i < maxExportAttempts; i++ {
+			n, err := f.exporter.ExportPending(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, n)
+			assert.Equal(t, models.ExportStatusPending, job.Status)
+			assert.Equal(t, "mongo caído", job.LastError)
+			f.now = f.now.Add(time.Hour)
+		}
+
+		_, err := f.exporter.ExportPending(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, models.ExportStatusFailed, job.Status)
+		assert.Equal(t, maxExportAttempts, job.Attempts)
+	})
+
+	t.Run("a deleted user fails at once", func(t *testing.T) {
+		f := setupExporter(t)
+		job := f.jobs.add(primitive.NewObjectID())
+
+		_, err := f.exporter.ExportPending(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, models.ExportStatusFailed, job.Status)
+		assert.Equal(t, 1, job.Attempts)
+	})
+
+	t.Run("lost lease removes the archive", func(t *testing.T) {
+		f := setupExporter(t)
+		job := f.jobs.add(f.data.user.ID)
+		// El usuario se borra mientras se genera: las exportaciones desaparecen
+		f.exporter.tweets = deletingTweets{f}
+
+		_, err := f.exporter.ExportPending(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, f.jobs.items)
+		_, err = f.store.Get(ctx, "exports/"+f.data.user.ID.Hex()+"/"+job.ID.Hex()+".zip")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
+
+// deletingTweets borra las exportaciones del usuario al leer sus tweets
+type deletingTweets struct {
+	f *exporterFixture
+}
+
+func (d deletingTweets) AllByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Tweet, error) {
+	return nil, d.f.jobs.DeleteByUser(ctx, userID)
+}
+
+func TestExporter_Expiry(t *testing.T) {
+	ctx := context.Background()
+	f := setupExporter(t)
+	job := f.jobs.add(f.data.user.ID)
+	_, err := f.exporter.ExportPending(ctx)
+	require.NoError(t, err)
+	key := job.Key
+
+	n, err := f.exporter.ExpireArchives(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "todavía no venció")
+
+	f.now = f.now.Add(25 * time.Hour)
+	n, err = f.exporter.ExpireArchives(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, models.ExportStatusExpired, job.Status)
+	_, err = f.store.Get(ctx, key)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestExporter_DeleteByUser(t *testing.T) {
+	ctx := context.Background()
+	f := setupExporter(t)
+	job := f.jobs.add(f.data.user.ID)
+	_, err := f.exporter.ExportPending(ctx)
+	require.NoError(t, err)
+	key := job.Key
+	f.jobs.add(f.data.user.ID)
+
+	require.NoError(t, f.exporter.DeleteByUser(ctx, f.data.user.ID))
+	assert.Empty(t, f.jobs.items)
+	_, err = f.store.Get(ctx, key)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
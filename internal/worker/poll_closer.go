@@ -0,0 +1,121 @@
+// internal/worker/poll_closer.go
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultPollCloseInterval es cada cuánto se buscan encuestas vencidas
+const DefaultPollCloseInterval = time.Minute
+
+// pollNotifyLease es cuánto dura la reserva del aviso de una encuesta cerrada;
+// si la instancia cae sin terminarlo, otra lo retoma al vencer
+const pollNotifyLease = 5 * time.Minute
+
+// PollStore es el acceso a encuestas que necesita el job de cierre
+type PollStore interface {
+	CloseExpired(ctx context.Context, now time.Time) (int, error)
+	ClaimUnnotified(ctx context.Context, now time.Time, lease time.Duration) ([]models.Tweet, error)
+	MarkNotified(ctx context.Context, tweetID primitive.ObjectID) error
+	Voters(ctx context.Context, tweetID primitive.ObjectID) ([]primitive.ObjectID, error)
+}
+
+// NotificationStore guarda los avisos generados por el job
+type NotificationStore interface {
+	CreateMany(ctx context.Context, notifications []models.Notification) error
+}
+
+// PollCloser cierra periódicamente las encuestas vencidas y avisa a votantes y autor
+type PollCloser struct {
+	polls         PollStore
+	notifications NotificationStore
+	interval      time.Duration
+	now           func() time.Time
+}
+
+func NewPollCloser(polls PollStore, notifications NotificationStore, interval time.Duration) *PollCloser {
+	if interval <= 0 {
+		interval = DefaultPollCloseInterval
+	}
+	return &PollCloser{
+		polls:         polls,
+		notifications: notifications,
+		interval:      interval,
+		now:           time.Now,
+	}
+}
+
+// Run ejecuta el job hasta que se cancela el contexto
+func (w *PollCloser) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.CloseExpired(ctx); err != nil {
+			slog.ErrorContext(ctx, "error al cerrar encuestas", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CloseExpired cierra las encuestas vencidas, avisa a votantes y autor y
+// devuelve cuántas cerró. El aviso se marca como enviado solo después de crear
+// las notificaciones: si falla, o la instancia cae antes, la encuesta se vuelve
+// a tomar en una pasada posterior.
+func (w *PollCloser) CloseExpired(ctx context.Context) (int, error) {
+	now := w.now()
+	// Si el cierre falla se siguen enviando los avisos pendientes de pasadas
+	// anteriores; el error se devuelve al final
+	closed, err := w.polls.CloseExpired(ctx, now)
+
+	pending, claimErr := w.polls.ClaimUnnotified(ctx, now, pollNotifyLease)
+	// Los avisos reservados se envían aunque haya empezado el apagado
+	notifyCtx := context.WithoutCancel(ctx)
+	for _, tweet := range pending {
+		if notifyErr := w.notify(notifyCtx, tweet); notifyErr != nil {
+			slog.ErrorContext(ctx, "error al notificar cierre de encuesta",
+				slog.String("tweet_id", tweet.ID.Hex()), slog.Any("error", notifyErr))
+		}
+	}
+	if err == nil {
+		err = claimErr
+	}
+	return closed, err
+}
+
+func (w *PollCloser) notify(ctx context.Context, tweet models.Tweet) error {
+	voters, err := w.polls.Voters(ctx, tweet.ID)
+	if err != nil {
+		return err
+	}
+
+	recipients := append([]primitive.ObjectID{tweet.UserID}, voters...)
+	seen := make(map[primitive.ObjectID]bool, len(recipients))
+	notifications := make([]models.Notification, 0, len(recipients))
+	for _, userID := range recipients {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		notifications = append(notifications, models.Notification{
+			UserID:  userID,
+			Type:    models.NotificationPollClosed,
+			TweetID: tweet.ID,
+		})
+	}
+
+	if err := w.notifications.CreateMany(ctx, notifications); err != nil {
+		return err
+	}
+	return w.polls.MarkNotified(ctx, tweet.ID)
+}
@@ -0,0 +1,177 @@
+// internal/worker/poll_closer_test.go
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakePollStore guarda el estado de cada encuesta como el repositorio: vencida,
+// cerrada, avisada y hasta cuándo está reservado el aviso
+type fakePollStore struct {
+	tweets     []models.Tweet
+	expired    map[primitive.ObjectID]bool
+	leaseUntil map[primitive.ObjectID]time.Time
+	notified   map[primitive.ObjectID]bool
+	voters     map[primitive.ObjectID][]primitive.ObjectID
+	closeErr   error
+	calledAt   time.Time
+}
+
+func newFakePollStore(tweets ...models.Tweet) *fakePollStore {
+	f := &fakePollStore{
+		tweets:     tweets,
+		expired:    map[primitive.ObjectID]bool{},
+		leaseUntil: map[primitive.ObjectID]time.Time{},
+		notified:   map[primitive.ObjectID]bool{},
+		voters:     map[primitive.ObjectID][]primitive.ObjectID{},
+	}
+	for _, tweet := range tweets {
+		f.expired[tweet.ID] = true
+	}
+	return f
+}
+
+func (f *fakePollStore) CloseExpired(ctx context.Context, now time.Time) (int, error) {
+	f.calledAt = now
+	if f.closeErr != nil {
+		return 0, f.closeErr
+	}
+	closed := 0
+	for i := range f.tweets {
+		poll := f.tweets[i].Poll
+		if !poll.Closed && f.expired[f.tweets[i].ID] {
+			poll.Closed = true
+			closed++
+		}
+	}
+	return closed, nil
+}
+
+func (f *fakePollStore) ClaimUnnotified(ctx context.Context, now time.Time, lease time.Duration) ([]models.Tweet, error) {
+	var claimed []models.Tweet
+	for _, tweet := range f.tweets {
+		if !tweet.Poll.Closed || f.notified[tweet.ID] || f.leaseUntil[tweet.ID].After(now) {
+			continue
+		}
+		f.leaseUntil[tweet.ID] = now.Add(lease)
+		claimed = append(claimed, tweet)
+	}
+	return claimed, nil
+}
+
+func (f *fakePollStore) MarkNotified(ctx context.Context, tweetID primitive.ObjectID) error {
+	f.notified[tweetID] = true
+	delete(f.leaseUntil, tweetID)
+	return nil
+}
+
+func (f *fakePollStore) Voters(ctx context.Context, tweetID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return f.voters[tweetID], nil
+}
+
+type fakeNotificationStore struct {
+	created []models.Notification
+	err     error
+}
+
+func (f *fakeNotificationStore) CreateMany(ctx context.Context, notifications []models.Notification) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.created = append(f.created, notifications...)
+	return nil
+}
+
+func TestPollCloser(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	author := primitive.NewObjectID()
+	voter := primitive.NewObjectID()
+	newTweet := func() models.Tweet {
+		return models.Tweet{ID: primitive.NewObjectID(), UserID: author, Poll: &models.Poll{}}
+	}
+
+	t.Run("notifies voters and author once", func(t *testing.T) {
+		tweet := newTweet()
+		polls := newFakePollStore(tweet)
+		// El autor votó en su propia encuesta: no debe recibir dos avisos
+		polls.voters[tweet.ID] = []primitive.ObjectID{voter, author}
+		notifications := &fakeNotificationStore{}
+		closer := NewPollCloser(polls, notifications, time.Minute)
+		closer.now = func() time.Time { return now }
+
+		n, err := closer.CloseExpired(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, now, polls.calledAt)
+		assert.True(t, polls.notified[tweet.ID])
+
+		assert.Len(t, notifications.created, 2)
+		recipients := []primitive.ObjectID{}
+		for _, notification := range notifications.created {
+			assert.Equal(t, models.NotificationPollClosed, notification.Type)
+			assert.Equal(t, tweet.ID, notification.TweetID)
+			recipients = append(recipients, notification.UserID)
+		}
+		assert.ElementsMatch(t, []primitive.ObjectID{author, voter}, recipients)
+
+		// Una segunda pasada no vuelve a notificar
+		n, err = closer.CloseExpired(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+		assert.Len(t, notifications.created, 2)
+	})
+
+	t.Run("retries notifications that failed", func(t *testing.T) {
+		tweet := newTweet()
+		polls := newFakePollStore(tweet)
+		notifications := &fakeNotificationStore{err: errors.New("fallo")}
+		closer := NewPollCloser(polls, notifications, time.Minute)
+		current := now
+		closer.now = func() time.Time { return current }
+
+		n, err := closer.CloseExpired(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.True(t, tweet.Poll.Closed)
+		assert.False(t, polls.notified[tweet.ID])
+
+		// Mientras dura la reserva no se reintenta
+		notifications.err = nil
+		current = now.Add(time.Minute)
+		_, err = closer.CloseExpired(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, notifications.created)
+
+		current = now.Add(pollNotifyLease)
+		n, err = closer.CloseExpired(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+		assert.Len(t, notifications.created, 1)
+		assert.Equal(t, author, notifications.created[0].UserID)
+		assert.True(t, polls.notified[tweet.ID])
+	})
+
+	t.Run("notifies pending polls even if closing fails", func(t *testing.T) {
+		tweet := newTweet()
+		tweet.Poll.Closed = true
+		polls := newFakePollStore(tweet)
+		polls.closeErr = errors.New("fallo")
+		notifications := &fakeNotificationStore{}
+		closer := NewPollCloser(polls, notifications, time.Minute)
+
+		n, err := closer.CloseExpired(ctx)
+		assert.Error(t, err)
+		assert.Equal(t, 0, n)
+		assert.Len(t, notifications.created, 1)
+		assert.True(t, polls.notified[tweet.ID])
+	})
+}
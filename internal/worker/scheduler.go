@@ -0,0 +1,154 @@
+// internal/worker/scheduler.go
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// DefaultScheduleInterval es cada cuánto se buscan tweets programados vencidos
+	DefaultScheduleInterval = 15 * time.Second
+	// DefaultScheduleLease es cuánto tiempo reserva una instancia cada tweet que publica
+	DefaultScheduleLease = time.Minute
+	// maxPublishAttempts es el número de intentos antes de marcar el tweet como fallido
+	maxPublishAttempts = 5
+	// maxPublishBatch limita cuántos tweets se publican en cada pasada
+	maxPublishBatch = 100
+)
+
+// ScheduleStore es el acceso a tweets programados que necesita el scheduler
+type ScheduleStore interface {
+	ClaimDue(ctx context.Context, owner string, now time.Time, lease time.Duration) (*models.ScheduledTweet, error)
+	MarkPublished(ctx context.Context, id primitive.ObjectID, owner string, tweetID primitive.ObjectID, at time.Time) error
+	MarkFailed(ctx context.Context, id primitive.ObjectID, owner string, reason string) error
+	Release(ctx context.Context, id primitive.ObjectID, owner string, reason string, retryAt time.Time) error
+}
+
+// TweetCreator crea tweets con las mismas validaciones y efectos que la API
+type TweetCreator interface {
+	Create(ctx context.Context, tweet *models.Tweet) error
+}
+
+// Scheduler publica los tweets programados cuando llega su hora. Cada tweet se
+// reserva con un lease en MongoDB, de modo que con varias instancias solo una lo
+// publica; además el tweet se crea con el ID del programado, así que si una
+// instancia cae después de crearlo el reintento no lo duplica.
+type Scheduler struct {
+	store    ScheduleStore
+	tweets   TweetCreator
+	owner    string
+	interval time.Duration
+	lease    time.Duration
+	now      func() time.Time
+}
+
+func NewScheduler(store ScheduleStore, tweets TweetCreator, interval, lease time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = DefaultScheduleInterval
+	}
+	if lease <= 0 {
+		lease = DefaultScheduleLease
+	}
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		store:    store,
+		tweets:   tweets,
+		owner:    fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), primitive.NewObjectID().Hex()),
+		interval: interval,
+		lease:    lease,
+		now:      time.Now,
+	}
+}
+
+// Run ejecuta el scheduler hasta que se cancela el contexto
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.PublishDue(ctx); err != nil {
+			slog.ErrorContext(ctx, "error al publicar tweets programados", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishDue publica los tweets programados vencidos y devuelve cuántos publicó
+func (s *Scheduler) PublishDue(ctx context.Context) (int, error) {
+	published := 0
+	for i := 0; i < maxPublishBatch; i++ {
+		if ctx.Err() != nil {
+			return published, nil
+		}
+
+		st, err := s.store.ClaimDue(ctx, s.owner, s.now(), s.lease)
+		if err != nil {
+			return published, err
+		}
+		if st == nil {
+			return published, nil
+		}
+
+		// Un tweet ya reservado se publica aunque empiece el apagado; cancelarlo a
+		// medias solo lo dejaría esperando a que venza el lease
+		ok, err := s.publish(context.WithoutCancel(ctx), st)
+		if err != nil {
+			slog.ErrorContext(ctx, "error al publicar tweet programado",
+				slog.String("scheduled_id", st.ID.Hex()), slog.Any("error", err))
+		}
+		if ok {
+			published++
+		}
+	}
+	return published, nil
+}
+
+func (s *Scheduler) publish(ctx context.Context, st *models.ScheduledTweet) (bool, error) {
+	tweet := st.ToTweet()
+	createErr := s.tweets.Create(ctx, tweet)
+	if createErr != nil && !errors.Is(createErr, repository.ErrTweetExists) {
+		if !retryable(createErr) || st.Attempts >= maxPublishAttempts {
+			return false, s.store.MarkFailed(ctx, st.ID, s.owner, createErr.Error())
+		}
+		// Reintentar más tarde con una espera creciente
+		retryAt := s.now().Add(time.Duration(st.Attempts) * s.interval)
+		if err := s.store.Release(ctx, st.ID, s.owner, createErr.Error(), retryAt); err != nil {
+			return false, err
+		}
+		return false, createErr
+	}
+
+	// Con ErrTweetExists un intento anterior ya lo creó y solo falta marcarlo
+	if err := s.store.MarkPublished(ctx, st.ID, s.owner, tweet.ID, s.now()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// retryable indica si vale la pena reintentar la publicación. Los errores de
+// validación, de la política de contenido o de una cuenta que no puede publicar
+// se repetirían igual en cada intento; solo se reintentan los transitorios y
+// los internos, como una base de datos caída.
+func retryable(err error) bool {
+	switch apperr.KindOf(err) {
+	case apperr.KindInternal, apperr.KindUnavailable, apperr.KindTooManyRequests:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,217 @@
+// internal/worker/scheduler_test.go
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ffelixf/microblog-platform/internal/apperr"
+	"github.com/ffelixf/microblog-platform/internal/models"
+	"github.com/ffelixf/microblog-platform/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeScheduleStore reproduce en memoria la semántica de lease del repositorio
+type fakeScheduleStore struct {
+	mu    sync.Mutex
+	items map[primitive.ObjectID]*models.ScheduledTweet
+}
+
+func newFakeScheduleStore(items ...*models.ScheduledTweet) *fakeScheduleStore {
+	s := &fakeScheduleStore{items: make(map[primitive.ObjectID]*models.ScheduledTweet)}
+	for _, st := range items {
+		s.items[st.ID] = st
+	}
+	return s
+}
+
+func (s *fakeScheduleStore) ClaimDue(ctx context.Context, owner string, now time.Time, lease time.Duration) (*models.ScheduledTweet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range s.items {
+		if st.Status != models.ScheduledStatusScheduled || st.PublishAt.After(now) {
+			continue
+		}
+		if st.LeaseUntil != nil && !st.LeaseUntil.Before(now) {
+			continue
+		}
+		until := now.Add(lease)
+		st.LeaseOwner = owner
+		st.LeaseUntil = &until
+		st.Attempts++
+		claimed := *st
+		return &claimed, nil
+	}
+	return nil, nil
+}
+
+func (s *fakeScheduleStore) finish(id primitive.ObjectID, owner string, apply func(st *models.ScheduledTweet)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.items[id]
+	if !ok || st.LeaseOwner != owner {
+		return repository.ErrLeaseLost
+	}
+	apply(st)
+	return nil
+}
+
+func (s *fakeScheduleStore) MarkPublished(ctx context.Context, id primitive.ObjectID, owner string, tweetID primitive.ObjectID, at time.Time) error {
+	return s.finish(id, owner, func(st *models.ScheduledTweet) {
+		st.Status = models.ScheduledStatusPublished
+		st.TweetID = tweetID
+		st.LeaseOwner, st.LeaseUntil = "", nil
+	})
+}
+
+func (s *fakeScheduleStore) MarkFailed(ctx context.Context, id primitive.ObjectID, owner string, reason string) error {
+	return s.finish(id, owner, func(st *models.ScheduledTweet) {
+		st.Status = models.ScheduledStatusFailed
+		st.LastError = reason
+		st.LeaseOwner, st.LeaseUntil = "", nil
+	})
+}
+
+func (s *fakeScheduleStore) Release(ctx context.Context, id primitive.ObjectID, owner string, reason string, retryAt time.Time) error {
+	return s.finish(id, owner, func(st *models.ScheduledTweet) {
+		st.LastError = reason
+		st.LeaseOwner, st.LeaseUntil = "", &retryAt
+	})
+}
+
+type fakeTweetCreator struct {
+	mu      sync.Mutex
+	created map[primitive.ObjectID]int
+	err     error
+}
+
+func (f *fakeTweetCreator) Create(ctx context.Context, tweet *models.Tweet) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	if f.created[tweet.ID] > 0 {
+		return repository.ErrTweetExists
+	}
+	f.created[tweet.ID]++
+	return nil
+}
+
+func scheduledAt(t time.Time) *models.ScheduledTweet {
+	return &models.ScheduledTweet{
+		ID:        primitive.NewObjectID(),
+		UserID:    primitive.NewObjectID(),
+		Content:   "programado",
+		Status:    models.ScheduledStatusScheduled,
+		PublishAt: &t,
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("publishes due tweets exactly once across instances", func(t *testing.T) {
+		var items []*models.ScheduledTweet
+		for i := 0; i < 20; i++ {
+			items = append(items, scheduledAt(now.Add(-time.Minute)))
+		}
+		future := scheduledAt(now.Add(time.Hour))
+		store := newFakeScheduleStore(append(items, future)...)
+		tweets := &fakeTweetCreator{created: make(map[primitive.ObjectID]int)}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			s := NewScheduler(store, tweets, time.Second, time.Minute)
+			s.now = func() time.Time { return now }
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := s.PublishDue(ctx)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Len(t, tweets.created, 20)
+		for _, st := range items {
+			assert.Equal(t, 1, tweets.created[st.ID])
+			assert.Equal(t, models.ScheduledStatusPublished, st.Status)
+			assert.Equal(t, st.ID, st.TweetID)
+		}
+		assert.Equal(t, models.ScheduledStatusScheduled, future.Status)
+	})
+
+	t.Run("recovers tweet created before a crash", func(t *testing.T) {
+		st := scheduledAt(now.Add(-time.Minute))
+		// Una instancia anterior creó el tweet y cayó antes de marcarlo; su lease ya venció
+		expired := now.Add(-time.Second)
+		st.LeaseOwner, st.LeaseUntil = "caida", &expired
+		store := newFakeScheduleStore(st)
+		tweets := &fakeTweetCreator{created: map[primitive.ObjectID]int{st.ID: 1}}
+
+		s := NewScheduler(store, tweets, time.Second, time.Minute)
+		s.now = func() time.Time { return now }
+		n, err := s.PublishDue(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, 1, tweets.created[st.ID])
+		assert.Equal(t, models.ScheduledStatusPublished, st.Status)
+	})
+
+	t.Run("retries and then fails", func(t *testing.T) {
+		st := scheduledAt(now.Add(-time.Minute))
+		store := newFakeScheduleStore(st)
+		tweets := &fakeTweetCreator{created: make(map[primitive.ObjectID]int), err: errors.New("el usuario especificado no existe")}
+
+		s := NewScheduler(store, tweets, time.Second, time.Minute)
+		clock := now
+		s.now = func() time.Time { return clock }
+
+		for i := 1; i < maxPublishAttempts; i++ {
+			n, err := s.PublishDue(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, n)
+			assert.Equal(t, models.ScheduledStatusScheduled, st.Status)
+			assert.Equal(t, i, st.Attempts)
+			assert.Contains(t, st.LastError, "no existe")
+
+			// Antes de la espera no se vuelve a intentar
+			n, _ = s.PublishDue(ctx)
+			assert.Equal(t, 0, n)
+			assert.Equal(t, i, st.Attempts)
+
+			clock = clock.Add(time.Hour)
+		}
+
+		_, err := s.PublishDue(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, models.ScheduledStatusFailed, st.Status)
+	})
+
+	t.Run("permanent errors fail on the first attempt", func(t *testing.T) {
+		for _, createErr := range []error{
+			apperr.InvalidField("content_too_long", "content", "el contenido del tweet es demasiado largo"),
+			apperr.Forbidden("user_suspended", "el usuario está suspendido"),
+			apperr.InvalidField("author_not_found", "user_id", "el usuario especificado no existe"),
+		} {
+			st := scheduledAt(now.Add(-time.Minute))
+			store := newFakeScheduleStore(st)
+			tweets := &fakeTweetCreator{created: make(map[primitive.ObjectID]int), err: createErr}
+
+			s := NewScheduler(store, tweets, time.Second, time.Minute)
+			s.now = func() time.Time { return now }
+			n, err := s.PublishDue(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, n)
+			assert.Equal(t, models.ScheduledStatusFailed, st.Status, createErr.Error())
+			assert.Equal(t, 1, st.Attempts)
+			assert.Equal(t, createErr.Error(), st.LastError)
+		}
+	})
+}
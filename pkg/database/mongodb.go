@@ -3,45 +3,53 @@ package database
 
 import (
 	"context"
-	"log"
-	"os"
+	"fmt"
 	"time"
 
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func ConnectDB() *mongo.Client {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// Config es la configuración de conexión a MongoDB
+type Config struct {
+	URI                    string
+	MaxPoolSize            uint64
+	MinPoolSize            uint64
+	ConnectTimeout         time.Duration
+	ServerSelectionTimeout time.Duration
+	// Monitor recibe los comandos enviados; ver ChainMonitors para combinar varios
+	Monitor *event.CommandMonitor
+}
 
-	// Obtener URI de MongoDB desde variables de entorno
-	uri := os.Getenv("MONGODB_URI")
-	if uri == "" {
-		log.Fatal("MONGODB_URI no está configurado en .env")
+// ConnectDB abre la conexión y verifica que el servidor responde
+func ConnectDB(ctx context.Context, cfg Config) (*mongo.Client, error) {
+	opts := options.Client().ApplyURI(cfg.URI).SetMonitor(cfg.Monitor)
+	if cfg.MaxPoolSize > 0 {
+		opts.SetMaxPoolSize(cfg.MaxPoolSize)
 	}
-
-	// Conectar a MongoDB
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
-	if err != nil {
-		log.Fatal(err)
+	if cfg.MinPoolSize > 0 {
+		opts.SetMinPoolSize(cfg.MinPoolSize)
+	}
+	if cfg.ConnectTimeout > 0 {
+		opts.SetConnectTimeout(cfg.ConnectTimeout)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.ConnectTimeout)
+		defer cancel()
+	}
+	if cfg.ServerSelectionTimeout > 0 {
+		opts.SetServerSelectionTimeout(cfg.ServerSelectionTimeout)
 	}
 
-	// Verificar la conexión
-	err = client.Ping(ctx, nil)
+	client, err := mongo.Connect(ctx, opts)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("error al conectar a MongoDB: %w", err)
 	}
 
-	log.Println("Conectado a MongoDB!")
-	return client
-}
-
-// GetCollection obtiene una colección específica
-func GetCollection(client *mongo.Client, collectionName string) *mongo.Collection {
-	database := os.Getenv("MONGODB_DATABASE")
-	if database == "" {
-		database = "microblog"
+	// Verificar la conexión
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("error al verificar la conexión a MongoDB: %w", err)
 	}
-	return client.Database(database).Collection(collectionName)
+	return client, nil
 }
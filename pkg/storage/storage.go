@@ -0,0 +1,46 @@
+// pkg/storage/storage.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrNotFound se devuelve cuando la clave solicitada no existe en el almacenamiento
+var ErrNotFound = errors.New("blob no encontrado")
+
+// Blob es un objeto abierto del almacenamiento. El contenido se lee a medida
+// desde el backend y el llamador debe cerrarlo. Size es -1 si el backend no
+// informa el tamaño.
+type Blob struct {
+	io.ReadCloser
+	Size        int64
+	ContentType string
+}
+
+// BlobStore abstrae el almacenamiento de archivos binarios. La interfaz sigue el
+// modelo de objetos de S3 (clave plana, escritura completa, sin renombres) para que
+// cualquier backend compatible pueda enchufarse sin cambios en los llamadores.
+// PutStream copia el contenido desde r a medida, para archivos que no conviene
+// tener enteros en memoria; size es obligatorio porque S3 necesita conocerlo.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	PutStream(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (*Blob, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// validateKey rechaza claves vacías, absolutas o que intenten salir del prefijo
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("clave de blob inválida: %q", key)
+	}
+	if cleaned := path.Clean(key); cleaned != key || cleaned == "." || strings.HasPrefix(cleaned, "..") {
+		return fmt.Errorf("clave de blob inválida: %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// pkg/storage/storage_test.go
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("put and get", func(t *testing.T) {
+		err := store.Put(ctx, "media/u1/m1/original.png", []byte("png-data"), "image/png")
+		assert.NoError(t, err)
+
+		blob, err := store.Get(ctx, "media/u1/m1/original.png")
+		require.NoError(t, err)
+		assert.Equal(t, int64(len("png-data")), blob.Size)
+		assert.Equal(t, []byte("png-data"), readBlob(t, blob))
+		assert.Equal(t, "image/png", blob.ContentType)
+	})
+
+	t.Run("put stream", func(t *testing.T) {
+		err := store.PutStream(ctx, "exports/u1/e1.zip", strings.NewReader("zip-data"), -1, "application/zip")
+		assert.NoError(t, err)
+
+		blob, err := store.Get(ctx, "exports/u1/e1.zip")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("zip-data"), readBlob(t, blob))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		assert.NoError(t, store.Delete(ctx, "media/u1/m1/original.png"))
+		_, err := store.Get(ctx, "media/u1/m1/original.png")
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.NoError(t, store.Delete(ctx, "media/u1/m1/original.png"), "borrar dos veces no es error")
+	})
+
+	t.Run("rejects path traversal", func(t *testing.T) {
+		for _, key := range []string{"../escape", "/etc/passwd", "a/../../b", "", "a\\b"} {
+			assert.Error(t, store.Put(ctx, key, []byte("x"), ""), key)
+		}
+	})
+}
+
+// readBlob lee todo el contenido de un blob y lo cierra
+func readBlob(t *testing.T, blob *Blob) []byte {
+	t.Helper()
+	defer blob.Close()
+	data, err := io.ReadAll(blob)
+	require.NoError(t, err)
+	return data
+}
+
+// fakeS3 es un servidor S3 mínimo en memoria que exige firma SigV4
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") ||
+		!strings.Contains(auth, "SignedHeaders=host;x-amz-content-sha256;x-amz-date") ||
+		r.Header.Get("X-Amz-Date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		hash := r.Header.Get("X-Amz-Content-Sha256")
+		if (hash != unsignedPayload && hash != sha256Hex(body)) || r.ContentLength != int64(len(body)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[r.URL.Path] = body
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", f.types[r.URL.Path])
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	backend := &fakeS3{objects: make(map[string][]byte), types: make(map[string]string)}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{
+		Endpoint:  server.URL,
+		Bucket:    "media-bucket",
+		AccessKey: "AKID",
+		SecretKey: "secret",
+		PathStyle: true,
+	}, server.Client())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	err = store.Put(ctx, "media/u1/m1/thumbnail.jpg", []byte("jpeg-data"), "image/jpeg")
+	assert.NoError(t, err)
+	assert.Contains(t, backend.objects, "/media-bucket/media/u1/m1/thumbnail.jpg")
+
+	blob, err := store.Get(ctx, "media/u1/m1/thumbnail.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("jpeg-data")), blob.Size)
+	assert.Equal(t, []byte("jpeg-data"), readBlob(t, blob))
+	assert.Equal(t, "image/jpeg", blob.ContentType)
+
+	assert.NoError(t, store.Delete(ctx, "media/u1/m1/thumbnail.jpg"))
+	_, err = store.Get(ctx, "media/u1/m1/thumbnail.jpg")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// PutStream envía el tamaño y el contenido sin firmar
+	err = store.PutStream(ctx, "exports/u1/e1.zip", strings.NewReader("zip-data"), int64(len("zip-data")), "application/zip")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("zip-data"), backend.objects["/media-bucket/exports/u1/e1.zip"])
+	assert.Error(t, store.PutStream(ctx, "exports/u1/e2.zip", strings.NewReader("zip-data"), -1, ""), "S3 necesita el tamaño")
+
+	_, err = NewS3Store(S3Config{Endpoint: server.URL}, nil)
+	assert.Error(t, err, "bucket y credenciales son requeridos")
+}